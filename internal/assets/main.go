@@ -6,3 +6,6 @@ import (
 
 //go:embed migrations/*.sql
 var Migrations embed.FS
+
+//go:embed schemas
+var Schemas embed.FS
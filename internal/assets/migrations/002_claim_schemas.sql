@@ -0,0 +1,15 @@
+-- +migrate Up
+
+CREATE TABLE claim_schemas(
+    schema_type             VARCHAR(256) PRIMARY KEY       NOT NULL,
+    schema_url              TEXT                           NOT NULL,
+    json_ld_context         TEXT                           NOT NULL,
+    display_name            VARCHAR(256)                   NOT NULL,
+    merklized_root_position VARCHAR(16)                    NOT NULL DEFAULT '',
+    is_deprecated           BOOLEAN                        NOT NULL DEFAULT FALSE,
+    created_at              TIMESTAMP    WITHOUT TIME ZONE NOT NULL
+);
+
+-- +migrate Down
+
+DROP TABLE claim_schemas;
@@ -0,0 +1,16 @@
+-- +migrate Up
+
+ALTER TABLE claim_schemas ADD COLUMN schema_hash CHAR(64) NOT NULL DEFAULT '';
+
+CREATE TABLE schema_documents(
+    url        TEXT      PRIMARY KEY       NOT NULL,
+    body       BYTEA                       NOT NULL,
+    hash       CHAR(64)                    NOT NULL,
+    fetched_at TIMESTAMP WITHOUT TIME ZONE NOT NULL
+);
+
+-- +migrate Down
+
+DROP TABLE schema_documents;
+
+ALTER TABLE claim_schemas DROP COLUMN schema_hash;
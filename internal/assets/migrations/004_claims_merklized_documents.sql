@@ -0,0 +1,7 @@
+-- +migrate Up
+
+ALTER TABLE claims ADD COLUMN merklized_document BYTEA;
+
+-- +migrate Down
+
+ALTER TABLE claims DROP COLUMN merklized_document;
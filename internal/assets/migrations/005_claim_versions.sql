@@ -0,0 +1,15 @@
+-- +migrate Up
+
+CREATE TABLE claim_versions(
+    claim_id           CHAR(36)                    NOT NULL REFERENCES claims(id) ON DELETE CASCADE,
+    version            BIGINT                      NOT NULL,
+    data               BYTEA,
+    core_claim         BYTEA                       NOT NULL,
+    merklized_document BYTEA,
+    replaced_at        TIMESTAMP WITHOUT TIME ZONE NOT NULL,
+    PRIMARY KEY (claim_id, version)
+);
+
+-- +migrate Down
+
+DROP TABLE claim_versions;
@@ -0,0 +1,11 @@
+-- +migrate Up
+
+ALTER TABLE claims ADD COLUMN created_at TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT NOW();
+
+CREATE INDEX claims_user_id_schema_type_idx ON claims(user_id, schema_type);
+
+-- +migrate Down
+
+DROP INDEX claims_user_id_schema_type_idx;
+
+ALTER TABLE claims DROP COLUMN created_at;
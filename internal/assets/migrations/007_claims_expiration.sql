@@ -0,0 +1,17 @@
+-- +migrate Up
+
+ALTER TABLE claims ADD COLUMN expiration TIMESTAMP WITHOUT TIME ZONE;
+
+UPDATE claims
+SET expiration = (convert_from(data, 'UTF8')::jsonb ->> 'expirationDate')::TIMESTAMP WITH TIME ZONE AT TIME ZONE 'UTC'
+WHERE data IS NOT NULL AND convert_from(data, 'UTF8')::jsonb ? 'expirationDate';
+
+CREATE INDEX claims_created_at_idx ON claims(created_at);
+CREATE INDEX claims_expiration_idx ON claims(expiration);
+
+-- +migrate Down
+
+DROP INDEX claims_expiration_idx;
+DROP INDEX claims_created_at_idx;
+
+ALTER TABLE claims DROP COLUMN expiration;
@@ -0,0 +1,15 @@
+-- +migrate Up
+
+CREATE TABLE claim_revocations(
+    rev_nonce  NUMERIC(20, 0)              PRIMARY KEY,
+    claim_id   CHAR(36)                    REFERENCES claims(id) ON DELETE SET NULL,
+    reason     TEXT                        NOT NULL,
+    operator   TEXT                        NOT NULL DEFAULT '',
+    revoked_at TIMESTAMP WITHOUT TIME ZONE NOT NULL
+);
+
+CREATE INDEX claim_revocations_claim_id_idx ON claim_revocations(claim_id);
+
+-- +migrate Down
+
+DROP TABLE claim_revocations;
@@ -0,0 +1,29 @@
+-- +migrate Up
+
+ALTER TABLE claims ADD COLUMN rev_nonce NUMERIC(20, 0);
+ALTER TABLE claims ADD COLUMN expired BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- the revocation nonce is the little-endian uint64 in the beginning of the first value slot,
+-- the value slots follow the four 32 bytes index slots in the binary core claim
+UPDATE claims
+SET rev_nonce = (
+    SELECT SUM(get_byte(core_claim, 128 + i)::NUMERIC * (256::NUMERIC ^ i))
+    FROM generate_series(0, 7) AS i
+);
+
+ALTER TABLE claims ALTER COLUMN rev_nonce SET NOT NULL;
+
+CREATE INDEX claims_rev_nonce_idx ON claims(rev_nonce);
+CREATE INDEX claims_not_expired_expiration_idx ON claims(expiration) WHERE NOT expired;
+
+ALTER TABLE claim_schemas ADD COLUMN revoke_on_expiration BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- +migrate Down
+
+ALTER TABLE claim_schemas DROP COLUMN revoke_on_expiration;
+
+DROP INDEX claims_not_expired_expiration_idx;
+DROP INDEX claims_rev_nonce_idx;
+
+ALTER TABLE claims DROP COLUMN expired;
+ALTER TABLE claims DROP COLUMN rev_nonce;
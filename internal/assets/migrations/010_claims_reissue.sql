@@ -0,0 +1,11 @@
+-- +migrate Up
+
+ALTER TABLE claims ADD COLUMN previous_claim_id CHAR(36) REFERENCES claims(id) ON DELETE SET NULL;
+
+CREATE INDEX claims_previous_claim_id_idx ON claims(previous_claim_id);
+
+-- +migrate Down
+
+DROP INDEX claims_previous_claim_id_idx;
+
+ALTER TABLE claims DROP COLUMN previous_claim_id;
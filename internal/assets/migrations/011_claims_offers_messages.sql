@@ -0,0 +1,7 @@
+-- +migrate Up
+
+ALTER TABLE claims_offers ADD COLUMN message BYTEA;
+
+-- +migrate Down
+
+ALTER TABLE claims_offers DROP COLUMN message;
@@ -0,0 +1,11 @@
+-- +migrate Up
+
+ALTER TABLE claims_offers ADD COLUMN canceled_at TIMESTAMP WITHOUT TIME ZONE;
+
+CREATE INDEX claims_offers_created_at_idx ON claims_offers(created_at);
+
+-- +migrate Down
+
+DROP INDEX claims_offers_created_at_idx;
+
+ALTER TABLE claims_offers DROP COLUMN canceled_at;
@@ -0,0 +1,18 @@
+-- +migrate Up
+
+CREATE TABLE claim_offer_credentials(
+    offer_id    CHAR(36)                       NOT NULL REFERENCES claims_offers(id) ON DELETE CASCADE,
+    claim_id    CHAR(36)                       NOT NULL,
+    is_received BOOLEAN                        NOT NULL DEFAULT FALSE,
+    received_at TIMESTAMP WITHOUT TIME ZONE,
+    PRIMARY KEY (offer_id, claim_id)
+);
+
+CREATE INDEX claim_offer_credentials_claim_id_idx ON claim_offer_credentials(claim_id);
+
+INSERT INTO claim_offer_credentials(offer_id, claim_id, is_received)
+SELECT id, claim_id, COALESCE(is_received, FALSE) FROM claims_offers;
+
+-- +migrate Down
+
+DROP TABLE claim_offer_credentials;
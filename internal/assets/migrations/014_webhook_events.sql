@@ -0,0 +1,22 @@
+-- +migrate Up
+
+CREATE TABLE webhook_events(
+    id              BIGSERIAL                   PRIMARY KEY,
+    event_id        CHAR(36)                    NOT NULL,
+    event_type      TEXT                        NOT NULL,
+    endpoint        TEXT                        NOT NULL,
+    payload         BYTEA                       NOT NULL,
+    status          TEXT                        NOT NULL,
+    attempts        INTEGER                     NOT NULL DEFAULT 0,
+    last_error      TEXT                        NOT NULL DEFAULT '',
+    created_at      TIMESTAMP WITHOUT TIME ZONE NOT NULL,
+    next_attempt_at TIMESTAMP WITHOUT TIME ZONE NOT NULL,
+    finished_at     TIMESTAMP WITHOUT TIME ZONE
+);
+
+CREATE INDEX webhook_events_pending_idx ON webhook_events(next_attempt_at) WHERE status = 'pending';
+CREATE INDEX webhook_events_finished_at_idx ON webhook_events(finished_at);
+
+-- +migrate Down
+
+DROP TABLE webhook_events;
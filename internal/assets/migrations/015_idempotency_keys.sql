@@ -0,0 +1,17 @@
+-- +migrate Up
+
+CREATE TABLE idempotency_keys(
+    id           TEXT                        PRIMARY KEY,
+    request_hash BYTEA                       NOT NULL,
+    status_code  INTEGER,
+    content_type TEXT                        NOT NULL DEFAULT '',
+    response     BYTEA,
+    created_at   TIMESTAMP WITHOUT TIME ZONE NOT NULL,
+    completed_at TIMESTAMP WITHOUT TIME ZONE
+);
+
+CREATE INDEX idempotency_keys_created_at_idx ON idempotency_keys(created_at);
+
+-- +migrate Down
+
+DROP TABLE idempotency_keys;
@@ -0,0 +1,18 @@
+-- +migrate Up
+
+CREATE TABLE api_keys(
+    id           CHAR(36)                    PRIMARY KEY,
+    name         TEXT                        NOT NULL,
+    key_prefix   TEXT                        NOT NULL,
+    key_hash     BYTEA                       NOT NULL UNIQUE,
+    scopes       TEXT[]                      NOT NULL,
+    schema_types TEXT[]                      NOT NULL DEFAULT '{}',
+    created_at   TIMESTAMP WITHOUT TIME ZONE NOT NULL,
+    expires_at   TIMESTAMP WITHOUT TIME ZONE,
+    revoked_at   TIMESTAMP WITHOUT TIME ZONE,
+    last_used_at TIMESTAMP WITHOUT TIME ZONE
+);
+
+-- +migrate Down
+
+DROP TABLE api_keys;
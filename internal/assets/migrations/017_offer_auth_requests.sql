@@ -0,0 +1,16 @@
+-- +migrate Up
+
+CREATE TABLE offer_auth_requests(
+    id            CHAR(36)                    PRIMARY KEY,
+    user_id       TEXT                        NOT NULL,
+    schema_type   TEXT                        NOT NULL,
+    created_at    TIMESTAMP WITHOUT TIME ZONE NOT NULL,
+    expires_at    TIMESTAMP WITHOUT TIME ZONE NOT NULL,
+    authorized_at TIMESTAMP WITHOUT TIME ZONE
+);
+
+CREATE INDEX offer_auth_requests_expires_at_idx ON offer_auth_requests(expires_at);
+
+-- +migrate Down
+
+DROP TABLE offer_auth_requests;
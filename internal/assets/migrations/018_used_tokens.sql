@@ -0,0 +1,13 @@
+-- +migrate Up
+
+CREATE TABLE used_tokens(
+    id         TEXT                        PRIMARY KEY,
+    user_id    TEXT                        NOT NULL,
+    created_at TIMESTAMP WITHOUT TIME ZONE NOT NULL
+);
+
+CREATE INDEX used_tokens_created_at_idx ON used_tokens(created_at);
+
+-- +migrate Down
+
+DROP TABLE used_tokens;
@@ -0,0 +1,41 @@
+-- +migrate Up
+
+CREATE TABLE audit_events(
+    id                 BIGSERIAL                   PRIMARY KEY,
+    event_type         TEXT                        NOT NULL,
+    actor_type         TEXT                        NOT NULL,
+    actor_id           TEXT                        NOT NULL DEFAULT '',
+    request_id         TEXT,
+    claim_id           CHAR(36),
+    offer_id           CHAR(36),
+    rev_nonce          NUMERIC(20, 0),
+    committed_state_id BIGINT,
+    created_at         TIMESTAMP WITHOUT TIME ZONE NOT NULL
+);
+
+CREATE INDEX audit_events_created_at_idx ON audit_events(created_at);
+CREATE INDEX audit_events_claim_id_idx ON audit_events(claim_id) WHERE claim_id IS NOT NULL;
+CREATE INDEX audit_events_actor_id_idx ON audit_events(actor_id);
+
+-- the audit events are append-only, so the rows are protected from the changes by the service or the operators
+
+-- +migrate StatementBegin
+CREATE FUNCTION audit_events_reject_change() RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'audit events are append-only';
+END;
+$$ LANGUAGE plpgsql;
+-- +migrate StatementEnd
+
+CREATE TRIGGER audit_events_append_only
+    BEFORE UPDATE OR DELETE ON audit_events
+    FOR EACH ROW EXECUTE PROCEDURE audit_events_reject_change();
+
+CREATE TRIGGER audit_events_no_truncate
+    BEFORE TRUNCATE ON audit_events
+    FOR EACH STATEMENT EXECUTE PROCEDURE audit_events_reject_change();
+
+-- +migrate Down
+
+DROP TABLE audit_events;
+DROP FUNCTION audit_events_reject_change();
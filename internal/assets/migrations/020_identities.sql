@@ -0,0 +1,132 @@
+-- +migrate Up
+
+-- the keys of the identity are sealed with the configured keys encryption key, they are empty
+-- for the default identity that is hosted with the configured keys
+CREATE TABLE identities(
+    id                     BIGSERIAL                   PRIMARY KEY,
+    name                   TEXT                        NOT NULL UNIQUE,
+    did                    TEXT                        UNIQUE,
+    babyjubjub_private_key BYTEA,
+    signing_key            BYTEA,
+    created_at             TIMESTAMP WITHOUT TIME ZONE NOT NULL
+);
+
+-- the data of the identity the service hosted before belongs to the default one
+INSERT INTO identities(id, name, created_at) VALUES (1, 'default', NOW() AT TIME ZONE 'UTC');
+SELECT setval('identities_id_seq', 1);
+
+ALTER TABLE claims_tree ADD COLUMN identity_id BIGINT NOT NULL DEFAULT 1 REFERENCES identities(id);
+ALTER TABLE claims_tree ALTER COLUMN identity_id DROP DEFAULT;
+ALTER TABLE claims_tree DROP CONSTRAINT claims_tree_pkey;
+ALTER TABLE claims_tree ADD PRIMARY KEY (identity_id, key);
+
+ALTER TABLE revocation_tree ADD COLUMN identity_id BIGINT NOT NULL DEFAULT 1 REFERENCES identities(id);
+ALTER TABLE revocation_tree ALTER COLUMN identity_id DROP DEFAULT;
+ALTER TABLE revocation_tree DROP CONSTRAINT revocation_tree_pkey;
+ALTER TABLE revocation_tree ADD PRIMARY KEY (identity_id, key);
+
+ALTER TABLE roots_tree ADD COLUMN identity_id BIGINT NOT NULL DEFAULT 1 REFERENCES identities(id);
+ALTER TABLE roots_tree ALTER COLUMN identity_id DROP DEFAULT;
+ALTER TABLE roots_tree DROP CONSTRAINT roots_tree_pkey;
+ALTER TABLE roots_tree ADD PRIMARY KEY (identity_id, key);
+
+ALTER TABLE committed_states ADD COLUMN identity_id BIGINT NOT NULL DEFAULT 1 REFERENCES identities(id);
+ALTER TABLE committed_states ALTER COLUMN identity_id DROP DEFAULT;
+
+CREATE INDEX committed_states_identity_id_status_idx ON committed_states(identity_id, status);
+
+ALTER TABLE claims ADD COLUMN identity_id BIGINT NOT NULL DEFAULT 1 REFERENCES identities(id);
+ALTER TABLE claims ALTER COLUMN identity_id DROP DEFAULT;
+
+DROP INDEX claims_user_id_schema_type_idx;
+CREATE INDEX claims_identity_id_user_id_schema_type_idx ON claims(identity_id, user_id, schema_type);
+CREATE INDEX claims_identity_id_created_at_idx ON claims(identity_id, created_at);
+
+ALTER TABLE claim_versions ADD COLUMN identity_id BIGINT NOT NULL DEFAULT 1 REFERENCES identities(id);
+ALTER TABLE claim_versions ALTER COLUMN identity_id DROP DEFAULT;
+
+-- the revocation nonces are random per identity, so they are unique only within the identity
+ALTER TABLE claim_revocations ADD COLUMN identity_id BIGINT NOT NULL DEFAULT 1 REFERENCES identities(id);
+ALTER TABLE claim_revocations ALTER COLUMN identity_id DROP DEFAULT;
+ALTER TABLE claim_revocations DROP CONSTRAINT claim_revocations_pkey;
+ALTER TABLE claim_revocations ADD PRIMARY KEY (identity_id, rev_nonce);
+
+ALTER TABLE claims_offers ADD COLUMN identity_id BIGINT NOT NULL DEFAULT 1 REFERENCES identities(id);
+ALTER TABLE claims_offers ALTER COLUMN identity_id DROP DEFAULT;
+
+ALTER TABLE claim_offer_credentials ADD COLUMN identity_id BIGINT NOT NULL DEFAULT 1 REFERENCES identities(id);
+ALTER TABLE claim_offer_credentials ALTER COLUMN identity_id DROP DEFAULT;
+
+ALTER TABLE offer_auth_requests ADD COLUMN identity_id BIGINT NOT NULL DEFAULT 1 REFERENCES identities(id);
+ALTER TABLE offer_auth_requests ALTER COLUMN identity_id DROP DEFAULT;
+
+ALTER TABLE api_keys ADD COLUMN identity_id BIGINT NOT NULL DEFAULT 1 REFERENCES identities(id);
+ALTER TABLE api_keys ALTER COLUMN identity_id DROP DEFAULT;
+
+-- the keys are chosen by the clients, so the keys of the different identities may collide
+ALTER TABLE idempotency_keys ADD COLUMN identity_id BIGINT NOT NULL DEFAULT 1 REFERENCES identities(id);
+ALTER TABLE idempotency_keys ALTER COLUMN identity_id DROP DEFAULT;
+ALTER TABLE idempotency_keys DROP CONSTRAINT idempotency_keys_pkey;
+ALTER TABLE idempotency_keys ADD PRIMARY KEY (identity_id, id);
+
+ALTER TABLE audit_events ADD COLUMN identity_id BIGINT NOT NULL DEFAULT 1 REFERENCES identities(id);
+ALTER TABLE audit_events ALTER COLUMN identity_id DROP DEFAULT;
+
+CREATE INDEX audit_events_identity_id_created_at_idx ON audit_events(identity_id, created_at);
+
+-- +migrate Down
+
+-- only the default identity fits the unscoped tables, the audit events of the other identities
+-- are kept, since they are append-only, and the column is dropped along with them
+DROP INDEX audit_events_identity_id_created_at_idx;
+ALTER TABLE audit_events DROP COLUMN identity_id;
+
+DELETE FROM idempotency_keys WHERE identity_id <> 1;
+ALTER TABLE idempotency_keys DROP CONSTRAINT idempotency_keys_pkey;
+ALTER TABLE idempotency_keys DROP COLUMN identity_id;
+ALTER TABLE idempotency_keys ADD PRIMARY KEY (id);
+
+DELETE FROM api_keys WHERE identity_id <> 1;
+ALTER TABLE api_keys DROP COLUMN identity_id;
+
+DELETE FROM offer_auth_requests WHERE identity_id <> 1;
+ALTER TABLE offer_auth_requests DROP COLUMN identity_id;
+
+DELETE FROM claims_offers WHERE identity_id <> 1;
+ALTER TABLE claim_offer_credentials DROP COLUMN identity_id;
+ALTER TABLE claims_offers DROP COLUMN identity_id;
+
+DELETE FROM claim_revocations WHERE identity_id <> 1;
+ALTER TABLE claim_revocations DROP CONSTRAINT claim_revocations_pkey;
+ALTER TABLE claim_revocations DROP COLUMN identity_id;
+ALTER TABLE claim_revocations ADD PRIMARY KEY (rev_nonce);
+
+DELETE FROM claims WHERE identity_id <> 1;
+ALTER TABLE claim_versions DROP COLUMN identity_id;
+
+DROP INDEX claims_identity_id_created_at_idx;
+DROP INDEX claims_identity_id_user_id_schema_type_idx;
+ALTER TABLE claims DROP COLUMN identity_id;
+CREATE INDEX claims_user_id_schema_type_idx ON claims(user_id, schema_type);
+
+DROP INDEX committed_states_identity_id_status_idx;
+
+DELETE FROM committed_states WHERE identity_id <> 1;
+ALTER TABLE committed_states DROP COLUMN identity_id;
+
+DELETE FROM roots_tree WHERE identity_id <> 1;
+ALTER TABLE roots_tree DROP CONSTRAINT roots_tree_pkey;
+ALTER TABLE roots_tree DROP COLUMN identity_id;
+ALTER TABLE roots_tree ADD PRIMARY KEY (key);
+
+DELETE FROM revocation_tree WHERE identity_id <> 1;
+ALTER TABLE revocation_tree DROP CONSTRAINT revocation_tree_pkey;
+ALTER TABLE revocation_tree DROP COLUMN identity_id;
+ALTER TABLE revocation_tree ADD PRIMARY KEY (key);
+
+DELETE FROM claims_tree WHERE identity_id <> 1;
+ALTER TABLE claims_tree DROP CONSTRAINT claims_tree_pkey;
+ALTER TABLE claims_tree DROP COLUMN identity_id;
+ALTER TABLE claims_tree ADD PRIMARY KEY (key);
+
+DROP TABLE identities;
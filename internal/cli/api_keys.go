@@ -0,0 +1,108 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"gitlab.com/distributed_lab/logan/v3"
+	"gitlab.com/distributed_lab/logan/v3/errors"
+
+	"github.com/rarimo/issuer/internal/config"
+	"github.com/rarimo/issuer/internal/data"
+	"github.com/rarimo/issuer/internal/data/pg"
+	"github.com/rarimo/issuer/internal/service/core/apikeys"
+)
+
+// CreateAPIKey creates the private API key of the identity and prints it to the stdout, it is the only time
+// the raw key is available, since only its hash is stored.
+func CreateAPIKey(
+	cfg config.Config,
+	identityRef, name string,
+	scopes, schemaTypes []string,
+	expiresIn time.Duration,
+) error {
+	apiKeysQ, err := identityAPIKeysQ(cfg, identityRef)
+	if err != nil {
+		return err
+	}
+
+	var expiresAt *time.Time
+	if expiresIn > 0 {
+		expiration := time.Now().UTC().Add(expiresIn)
+		expiresAt = &expiration
+	}
+
+	rawKey, apiKey, err := apikeys.NewManager(apiKeysQ).Create(name, scopes, schemaTypes, expiresAt)
+	if err != nil {
+		return errors.Wrap(err, "failed to create api key")
+	}
+
+	cfg.Log().WithFields(apiKeyFields(apiKey.ID, apiKey.Name, apiKey.KeyPrefix, apiKey.Scopes, apiKey.SchemaTypes)).
+		Info("api key created, store it securely, it won't be shown again")
+	fmt.Println(rawKey)
+
+	return nil
+}
+
+func ListAPIKeys(cfg config.Config, identityRef string) error {
+	apiKeysQ, err := identityAPIKeysQ(cfg, identityRef)
+	if err != nil {
+		return err
+	}
+
+	apiKeys, err := apikeys.NewManager(apiKeysQ).List()
+	if err != nil {
+		return errors.Wrap(err, "failed to list api keys")
+	}
+
+	for _, apiKey := range apiKeys {
+		cfg.Log().
+			WithFields(apiKeyFields(apiKey.ID, apiKey.Name, apiKey.KeyPrefix, apiKey.Scopes, apiKey.SchemaTypes)).
+			WithFields(logan.F{
+				"created_at":   apiKey.CreatedAt,
+				"expires_at":   apiKey.ExpiresAt,
+				"revoked_at":   apiKey.RevokedAt,
+				"last_used_at": apiKey.LastUsedAt,
+			}).
+			Info("api key")
+	}
+	cfg.Log().WithField("count", len(apiKeys)).Info("api keys listed")
+
+	return nil
+}
+
+func RevokeAPIKey(cfg config.Config, identityRef, id string) error {
+	apiKeysQ, err := identityAPIKeysQ(cfg, identityRef)
+	if err != nil {
+		return err
+	}
+
+	err = apikeys.NewManager(apiKeysQ).Revoke(id)
+	if err != nil {
+		return errors.Wrap(err, "failed to revoke api key", logan.F{"id": id})
+	}
+
+	cfg.Log().WithField("id", id).Info("api key revoked")
+	return nil
+}
+
+// identityAPIKeysQ returns the API keys query scoped by the identity referenced by the DID or the name.
+func identityAPIKeysQ(cfg config.Config, identityRef string) (data.APIKeysQ, error) {
+	model, err := findIdentity(cfg, identityRef)
+	if err != nil {
+		return nil, err
+	}
+
+	return pg.NewAPIKeysQ(cfg.DB(), model.ID), nil
+}
+
+func apiKeyFields(id, name, keyPrefix string, scopes, schemaTypes []string) logan.F {
+	return logan.F{
+		"id":           id,
+		"name":         name,
+		"key_prefix":   keyPrefix,
+		"scopes":       strings.Join(scopes, ","),
+		"schema_types": strings.Join(schemaTypes, ","),
+	}
+}
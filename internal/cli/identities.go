@@ -0,0 +1,95 @@
+package cli
+
+import (
+	"context"
+	"strings"
+
+	"gitlab.com/distributed_lab/logan/v3"
+	"gitlab.com/distributed_lab/logan/v3/errors"
+
+	"github.com/rarimo/issuer/internal/config"
+	"github.com/rarimo/issuer/internal/data"
+	"github.com/rarimo/issuer/internal/data/pg"
+	"github.com/rarimo/issuer/internal/service/core/identity"
+	"github.com/rarimo/issuer/internal/service/core/webhooks"
+)
+
+const defaultIdentityName = "default"
+
+// CreateIdentity creates the identity with the keys of its own and its genesis state,
+// the running instances host it once they reload the identities.
+func CreateIdentity(ctx context.Context, cfg config.Config, name string) error {
+	model, err := identity.Create(ctx, cfg, name)
+	if err != nil {
+		return errors.Wrap(err, "failed to create identity", logan.F{"name": name})
+	}
+
+	cfg.Log().WithFields(identityFields(model)).Info("identity created")
+	return nil
+}
+
+func ListIdentities(cfg config.Config) error {
+	identities, err := pg.NewIdentitiesQ(cfg.DB()).Select()
+	if err != nil {
+		return errors.Wrap(err, "failed to list identities")
+	}
+
+	for i := range identities {
+		cfg.Log().
+			WithFields(identityFields(&identities[i])).
+			WithField("created_at", identities[i].CreatedAt).
+			Info("identity")
+	}
+	cfg.Log().WithField("count", len(identities)).Info("identities listed")
+
+	return nil
+}
+
+// InitIdentity generates the genesis state of the identity if it doesn't have one yet.
+func InitIdentity(ctx context.Context, cfg config.Config, ref string) error {
+	model, err := findIdentity(cfg, ref)
+	if err != nil {
+		return err
+	}
+
+	webhooksOutbox, err := webhooks.NewOutbox(cfg.Webhooks())
+	if err != nil {
+		return errors.Wrap(err, "failed to create webhooks outbox")
+	}
+
+	_, err = identity.New(ctx, cfg, model, webhooksOutbox)
+	return err
+}
+
+// findIdentity returns the identity referenced by the DID or the name.
+func findIdentity(cfg config.Config, ref string) (*data.Identity, error) {
+	identitiesQ := pg.NewIdentitiesQ(cfg.DB())
+
+	var model *data.Identity
+	var err error
+	if strings.HasPrefix(ref, "did:") {
+		model, err = identitiesQ.GetByDID(ref)
+	} else {
+		model, err = identitiesQ.GetByName(ref)
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get identity", logan.F{"identity": ref})
+	}
+	if model == nil {
+		return nil, errors.From(errors.New("identity is not exist"), logan.F{"identity": ref})
+	}
+
+	return model, nil
+}
+
+func identityFields(model *data.Identity) logan.F {
+	fields := logan.F{
+		"id":   model.ID,
+		"name": model.Name,
+	}
+	if model.DID != nil {
+		fields["did"] = *model.DID
+	}
+
+	return fields
+}
@@ -16,7 +16,6 @@ import (
 
 	"github.com/rarimo/issuer/internal/config"
 	"github.com/rarimo/issuer/internal/service/api"
-	"github.com/rarimo/issuer/internal/service/core/identity"
 )
 
 func Run(args []string) bool {
@@ -43,6 +42,34 @@ func Run(args []string) bool {
 
 	initCmd := app.Command("init", "init command")
 	identityCmd := initCmd.Command("identity", "initialize new issuer's identity state if not present")
+	initIdentity := identityFlag(identityCmd)
+
+	identitiesCmd := app.Command("identities", "manage issuer identities")
+	identitiesCreateCmd := identitiesCmd.Command("create", "create new identity with the keys of its own")
+	identityName := identitiesCreateCmd.Flag("name", "unique name of the identity").Required().String()
+	identitiesListCmd := identitiesCmd.Command("list", "list identities")
+
+	apiKeysCmd := app.Command("api-keys", "manage private API keys")
+	apiKeysCreateCmd := apiKeysCmd.Command("create", "create new API key and print it")
+	apiKeyCreateIdentity := identityFlag(apiKeysCreateCmd)
+	apiKeyName := apiKeysCreateCmd.Flag("name", "name of the API key client").Required().String()
+	apiKeyScopes := apiKeysCreateCmd.Flag("scope", "scope granted to the API key, repeatable").Required().Strings()
+	apiKeySchemaTypes := apiKeysCreateCmd.Flag("schema-type", "schema type the API key is limited to, repeatable").
+		Strings()
+	apiKeyExpiresIn := apiKeysCreateCmd.Flag("expires-in", "lifetime of the API key, it doesn't expire if omitted").
+		Duration()
+	apiKeysListCmd := apiKeysCmd.Command("list", "list API keys")
+	apiKeyListIdentity := identityFlag(apiKeysListCmd)
+	apiKeysRevokeCmd := apiKeysCmd.Command("revoke", "revoke API key")
+	apiKeyRevokeIdentity := identityFlag(apiKeysRevokeCmd)
+	apiKeyID := apiKeysRevokeCmd.Arg("id", "ID of the API key").Required().String()
+
+	schemasCmd := app.Command("schemas", "manage claim schemas")
+	schemasVendorCmd := schemasCmd.Command(
+		"vendor", "fetch the built-in schemas and JSON-LD contexts into the embedded schemas directory",
+	)
+	schemasVendorDir := schemasVendorCmd.Flag("dir", "directory to write the documents to").
+		Default("internal/assets/schemas").String()
 
 	cmd, err := app.Parse(args[1:])
 	if err != nil {
@@ -62,7 +89,21 @@ func Run(args []string) bool {
 	case migrateDownCmd.FullCommand():
 		err = MigrateDown(cfg)
 	case identityCmd.FullCommand():
-		_, err = identity.New(ctx, cfg)
+		err = InitIdentity(ctx, cfg, *initIdentity)
+	case identitiesCreateCmd.FullCommand():
+		err = CreateIdentity(ctx, cfg, *identityName)
+	case identitiesListCmd.FullCommand():
+		err = ListIdentities(cfg)
+	case apiKeysCreateCmd.FullCommand():
+		err = CreateAPIKey(
+			cfg, *apiKeyCreateIdentity, *apiKeyName, *apiKeyScopes, *apiKeySchemaTypes, *apiKeyExpiresIn,
+		)
+	case apiKeysListCmd.FullCommand():
+		err = ListAPIKeys(cfg, *apiKeyListIdentity)
+	case apiKeysRevokeCmd.FullCommand():
+		err = RevokeAPIKey(cfg, *apiKeyRevokeIdentity, *apiKeyID)
+	case schemasVendorCmd.FullCommand():
+		err = VendorSchemas(ctx, cfg, *schemasVendorDir)
 	default:
 		log.Errorf("unknown command %s", cmd)
 		cancel()
@@ -96,6 +137,11 @@ func Run(args []string) bool {
 	return true
 }
 
+// identityFlag adds the flag of the identity the command is run for, it is referenced by the DID or the name.
+func identityFlag(cmd *kingpin.CmdClause) *string {
+	return cmd.Flag("identity", "DID or name of the identity").Default(defaultIdentityName).String()
+}
+
 func run(
 	wg *sync.WaitGroup, ctx context.Context,
 	cfg config.Config, runner func(context.Context, config.Config),
@@ -0,0 +1,30 @@
+package cli
+
+import (
+	"context"
+
+	"gitlab.com/distributed_lab/logan/v3"
+	"gitlab.com/distributed_lab/logan/v3/errors"
+
+	"github.com/rarimo/issuer/internal/config"
+	"github.com/rarimo/issuer/internal/service/core/claims/schemas"
+)
+
+// VendorSchemas writes the built-in schemas and JSON-LD contexts to the directory, the embedded
+// ones are compiled into the binary, so the issuer starts offline with their hashes pinned.
+func VendorSchemas(ctx context.Context, cfg config.Config, dir string) error {
+	documents, err := schemas.VendorDocuments(ctx, cfg.Issuer().SchemasBaseURL, dir)
+	if err != nil {
+		return errors.Wrap(err, "failed to vendor schema documents")
+	}
+
+	for _, document := range documents {
+		cfg.Log().WithFields(logan.F{
+			"url":  document.URL,
+			"path": document.Path,
+			"hash": document.Hash,
+		}).Info("document vendored")
+	}
+
+	return nil
+}
@@ -0,0 +1,31 @@
+package config
+
+import (
+	"gitlab.com/distributed_lab/figure"
+	"gitlab.com/distributed_lab/kit/kv"
+	"gitlab.com/distributed_lab/logan/v3/errors"
+)
+
+// AuthConfig configures the private API authentication, the API keys are always accepted unless
+// the authentication is disabled, the HS256 JWTs are accepted if the JWTSecret is set. The JWTIssuer
+// is the required iss claim of the tokens, it isn't checked if empty.
+type AuthConfig struct {
+	Disabled  bool   `fig:"disabled"`
+	JWTSecret string `fig:"jwt_secret"`
+	JWTIssuer string `fig:"jwt_issuer"`
+}
+
+func (c *config) Auth() *AuthConfig {
+	return c.auth.Do(func() interface{} {
+		cfg := AuthConfig{}
+		err := figure.
+			Out(&cfg).
+			From(kv.MustGetStringMap(c.getter, "auth")).
+			Please()
+		if err != nil {
+			panic(errors.Wrap(err, "failed to figure out"))
+		}
+
+		return &cfg
+	}).(*AuthConfig)
+}
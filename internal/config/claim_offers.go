@@ -0,0 +1,56 @@
+package config
+
+import (
+	"time"
+
+	"gitlab.com/distributed_lab/figure"
+	"gitlab.com/distributed_lab/kit/kv"
+	"gitlab.com/distributed_lab/logan/v3/errors"
+)
+
+const (
+	defaultClaimOffersTTL           = 24 * time.Hour
+	defaultClaimOffersCleanupPeriod = time.Hour
+	defaultClaimOffersRetention     = 7 * 24 * time.Hour
+	defaultOfferAuthRequestTTL      = 5 * time.Minute
+)
+
+// ClaimOffersConfig configures the offers lifetime, the section is optional and the defaults are used without it.
+// The zero TTL disables the offers expiration. The expired and canceled offers are kept for the Retention
+// before the cleanup deletes them, so the wallets get the explicit problem instead of not found meanwhile.
+// The offers of the AuthSchemaTypes are given only to the holders that answered the iden3comm authorization
+// request within the AuthRequestTTL.
+type ClaimOffersConfig struct {
+	TTL             time.Duration `fig:"ttl"`
+	CleanupDisabled bool          `fig:"cleanup_disabled"`
+	CleanupPeriod   time.Duration `fig:"cleanup_period"`
+	Retention       time.Duration `fig:"retention"`
+	AuthSchemaTypes []string      `fig:"auth_schema_types"`
+	AuthRequestTTL  time.Duration `fig:"auth_request_ttl"`
+}
+
+func (c *config) ClaimOffers() *ClaimOffersConfig {
+	return c.claimOffers.Do(func() interface{} {
+		cfg := ClaimOffersConfig{
+			TTL:            defaultClaimOffersTTL,
+			CleanupPeriod:  defaultClaimOffersCleanupPeriod,
+			Retention:      defaultClaimOffersRetention,
+			AuthRequestTTL: defaultOfferAuthRequestTTL,
+		}
+		err := figure.
+			Out(&cfg).
+			From(kv.MustGetStringMap(c.getter, "claim_offers")).
+			Please()
+		if err != nil {
+			panic(errors.Wrap(err, "failed to figure out"))
+		}
+		if cfg.TTL < 0 || cfg.Retention < 0 {
+			panic(errors.New("claim offers ttl and retention must not be negative"))
+		}
+		if cfg.CleanupPeriod <= 0 || cfg.AuthRequestTTL <= 0 {
+			panic(errors.New("claim offers cleanup period and auth request ttl must be positive"))
+		}
+
+		return &cfg
+	}).(*ClaimOffersConfig)
+}
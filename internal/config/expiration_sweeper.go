@@ -0,0 +1,43 @@
+package config
+
+import (
+	"time"
+
+	"gitlab.com/distributed_lab/figure"
+	"gitlab.com/distributed_lab/kit/kv"
+	"gitlab.com/distributed_lab/logan/v3/errors"
+)
+
+const (
+	defaultExpirationSweeperPeriod    = time.Minute
+	defaultExpirationSweeperBatchSize = 100
+)
+
+// ExpirationSweeperConfig configures the worker that marks the expired claims,
+// the section is optional and the sweeper runs with the defaults without it.
+type ExpirationSweeperConfig struct {
+	Disabled  bool          `fig:"disabled"`
+	Period    time.Duration `fig:"period"`
+	BatchSize uint64        `fig:"batch_size"`
+}
+
+func (c *config) ExpirationSweeper() *ExpirationSweeperConfig {
+	return c.expirationSweeper.Do(func() interface{} {
+		cfg := ExpirationSweeperConfig{
+			Period:    defaultExpirationSweeperPeriod,
+			BatchSize: defaultExpirationSweeperBatchSize,
+		}
+		err := figure.
+			Out(&cfg).
+			From(kv.MustGetStringMap(c.getter, "expiration_sweeper")).
+			Please()
+		if err != nil {
+			panic(errors.Wrap(err, "failed to figure out"))
+		}
+		if cfg.Period <= 0 || cfg.BatchSize == 0 {
+			panic(errors.New("expiration sweeper period and batch size must be positive"))
+		}
+
+		return &cfg
+	}).(*ExpirationSweeperConfig)
+}
@@ -0,0 +1,47 @@
+package config
+
+import (
+	"time"
+
+	"gitlab.com/distributed_lab/figure"
+	"gitlab.com/distributed_lab/kit/kv"
+	"gitlab.com/distributed_lab/logan/v3/errors"
+)
+
+const (
+	defaultIdempotencyTTL           = 24 * time.Hour
+	defaultIdempotencyLockTimeout   = time.Minute
+	defaultIdempotencyCleanupPeriod = time.Hour
+)
+
+// IdempotencyConfig configures the idempotency keys lifetime, the section is optional and the defaults are used
+// without it. The repeated request gets the stored response during the TTL, the key of the request that isn't
+// completed during the LockTimeout, e.g. because the service was stopped, is released for the retries.
+type IdempotencyConfig struct {
+	TTL             time.Duration `fig:"ttl"`
+	LockTimeout     time.Duration `fig:"lock_timeout"`
+	CleanupDisabled bool          `fig:"cleanup_disabled"`
+	CleanupPeriod   time.Duration `fig:"cleanup_period"`
+}
+
+func (c *config) Idempotency() *IdempotencyConfig {
+	return c.idempotency.Do(func() interface{} {
+		cfg := IdempotencyConfig{
+			TTL:           defaultIdempotencyTTL,
+			LockTimeout:   defaultIdempotencyLockTimeout,
+			CleanupPeriod: defaultIdempotencyCleanupPeriod,
+		}
+		err := figure.
+			Out(&cfg).
+			From(kv.MustGetStringMap(c.getter, "idempotency")).
+			Please()
+		if err != nil {
+			panic(errors.Wrap(err, "failed to figure out"))
+		}
+		if cfg.TTL <= 0 || cfg.LockTimeout <= 0 || cfg.CleanupPeriod <= 0 {
+			panic(errors.New("idempotency ttl, lock timeout and cleanup period must be positive"))
+		}
+
+		return &cfg
+	}).(*IdempotencyConfig)
+}
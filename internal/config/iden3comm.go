@@ -0,0 +1,74 @@
+package config
+
+import (
+	"crypto/ecdsa"
+	"net/url"
+	"time"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"gitlab.com/distributed_lab/figure"
+	"gitlab.com/distributed_lab/kit/kv"
+	"gitlab.com/distributed_lab/logan/v3/errors"
+)
+
+const (
+	defaultIden3CommSigningKeyID    = "key-1"
+	defaultIden3CommResolverTimeout = 10 * time.Second
+)
+
+// Iden3CommConfig configures the signed and encrypted iden3comm messages. The messages of the default identity
+// are signed with the secp256k1 SigningKey (ES256K), the other identities have the signing keys of their own. The
+// verification method of the key in the issuer DID document has the SigningKeyID fragment. The keys the messages
+// are encrypted to are taken from the key agreement of the recipients DID documents, that are resolved by the
+// universal resolver at the ResolverURL.
+type Iden3CommConfig struct {
+	ResolverURL     string
+	ResolverTimeout time.Duration
+	SigningKey      *ecdsa.PrivateKey
+	SigningKeyID    string
+}
+
+type iden3CommConfigRaw struct {
+	ResolverURL     string        `fig:"resolver_url,required"`
+	ResolverTimeout time.Duration `fig:"resolver_timeout"`
+	SigningKey      string        `fig:"signing_key,required"`
+	SigningKeyID    string        `fig:"signing_key_id"`
+}
+
+func (c *config) Iden3Comm() *Iden3CommConfig {
+	return c.iden3comm.Do(func() interface{} {
+		configRaw := iden3CommConfigRaw{
+			ResolverTimeout: defaultIden3CommResolverTimeout,
+			SigningKeyID:    defaultIden3CommSigningKeyID,
+		}
+		err := figure.
+			Out(&configRaw).
+			From(kv.MustGetStringMap(c.getter, "iden3comm")).
+			Please()
+		if err != nil {
+			panic(errors.Wrap(err, "failed to figure out"))
+		}
+
+		if _, err := url.ParseRequestURI(configRaw.ResolverURL); err != nil {
+			panic(errors.Wrap(err, "failed to parse resolver url"))
+		}
+		if configRaw.ResolverTimeout <= 0 {
+			panic(errors.New("resolver timeout must be positive"))
+		}
+		if configRaw.SigningKeyID == "" {
+			panic(errors.New("signing key id must not be empty"))
+		}
+
+		signingKey, err := crypto.HexToECDSA(configRaw.SigningKey)
+		if err != nil {
+			panic(errors.Wrap(err, "failed to parse signing key"))
+		}
+
+		return &Iden3CommConfig{
+			ResolverURL:     configRaw.ResolverURL,
+			ResolverTimeout: configRaw.ResolverTimeout,
+			SigningKey:      signingKey,
+			SigningKeyID:    configRaw.SigningKeyID,
+		}
+	}).(*Iden3CommConfig)
+}
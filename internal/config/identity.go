@@ -9,16 +9,24 @@ import (
 	"gitlab.com/distributed_lab/logan/v3/errors"
 )
 
+// keysEncryptionKeyLen is the length of the AES-256 key
+const keysEncryptionKeyLen = 32
+
+// IdentityConfig configures the hosted identities. The BabyJubJubPrivateKey is the key of the default
+// identity, the keys of the other ones are generated on their creation and sealed with the KeysEncryptionKey,
+// so they can't be created if it isn't set.
 type IdentityConfig struct {
 	CircuitsPath         string
 	TreeDepth            int
 	BabyJubJubPrivateKey *babyjub.PrivateKey
+	KeysEncryptionKey    []byte
 }
 
 type identityConfig struct {
 	CircuitsPath         string `fig:"circuits_path,required"`
 	TreeDepth            int    `fig:"tree_depth,required"`
 	BabyJubJubPrivateKey string `fig:"babyjubjub_private_key,required"`
+	KeysEncryptionKey    string `fig:"keys_encryption_key"`
 }
 
 func (c *config) Identity() *IdentityConfig {
@@ -47,10 +55,22 @@ func parseConfig(configRaw *identityConfig) (*IdentityConfig, error) {
 		return nil, errors.Wrap(err, "failed to parse BabyJubJub private key")
 	}
 
+	var keysEncryptionKey []byte
+	if configRaw.KeysEncryptionKey != "" {
+		keysEncryptionKey, err = hex.DecodeString(configRaw.KeysEncryptionKey)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to decode keys encryption key from hex")
+		}
+		if len(keysEncryptionKey) != keysEncryptionKeyLen {
+			return nil, errors.Errorf("keys encryption key must be %d bytes long", keysEncryptionKeyLen)
+		}
+	}
+
 	return &IdentityConfig{
 		CircuitsPath:         configRaw.CircuitsPath,
 		TreeDepth:            configRaw.TreeDepth,
 		BabyJubJubPrivateKey: babyJubJubPrivateKey,
+		KeysEncryptionKey:    keysEncryptionKey,
 	}, nil
 }
 
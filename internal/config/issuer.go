@@ -1,19 +1,33 @@
 package config
 
 import (
+	"time"
+
 	"gitlab.com/distributed_lab/figure"
 	"gitlab.com/distributed_lab/kit/kv"
 	"gitlab.com/distributed_lab/logan/v3/errors"
 )
 
+const defaultIdentitiesReloadPeriod = 30 * time.Second
+
 type IssuerConfig struct {
 	BaseURL        string `fig:"base_url,required"`
 	SchemasBaseURL string `fig:"schemas_base_url,required"`
+	// SchemasLocalDir is a directory with the JSON schemas and JSON-LD contexts
+	// that are used before reaching the network
+	SchemasLocalDir string `fig:"schemas_local_dir"`
+	// SchemasOffline forbids the network requests for the schemas and contexts,
+	// they are taken only from the local directory, embedded assets and db cache
+	SchemasOffline bool `fig:"schemas_offline"`
+	// IdentitiesReloadPeriod is how often the identities created by the other instances start being hosted
+	IdentitiesReloadPeriod time.Duration `fig:"identities_reload_period"`
 }
 
 func (c *config) Issuer() *IssuerConfig {
 	return c.issuer.Do(func() interface{} {
-		cfg := IssuerConfig{}
+		cfg := IssuerConfig{
+			IdentitiesReloadPeriod: defaultIdentitiesReloadPeriod,
+		}
 		err := figure.
 			Out(&cfg).
 			From(kv.MustGetStringMap(c.getter, "issuer")).
@@ -22,6 +36,10 @@ func (c *config) Issuer() *IssuerConfig {
 			panic(errors.Wrap(err, "failed to figure out"))
 		}
 
+		if cfg.IdentitiesReloadPeriod <= 0 {
+			panic(errors.New("identities reload period must be positive"))
+		}
+
 		return &cfg
 	}).(*IssuerConfig)
 }
@@ -0,0 +1,48 @@
+package config
+
+import (
+	"time"
+
+	"gitlab.com/distributed_lab/figure"
+	"gitlab.com/distributed_lab/kit/kv"
+	"gitlab.com/distributed_lab/logan/v3/errors"
+)
+
+const (
+	defaultJWZRootExpiration      = time.Hour
+	defaultJWZUsedTokensTTL       = 24 * time.Hour
+	defaultJWZTokensCleanupPeriod = time.Hour
+)
+
+// JWZConfig configures the verification of the holders JWZ tokens, the section is optional and the defaults
+// are used without it. The tokens proven against the GIST root or the user state that was replaced on the state
+// contract longer than the RootExpiration ago are rejected. The used tokens are remembered for the UsedTokensTTL
+// to reject their replays, so it should be longer than the RootExpiration.
+type JWZConfig struct {
+	RootExpiration  time.Duration `fig:"root_expiration"`
+	UsedTokensTTL   time.Duration `fig:"used_tokens_ttl"`
+	CleanupDisabled bool          `fig:"cleanup_disabled"`
+	CleanupPeriod   time.Duration `fig:"cleanup_period"`
+}
+
+func (c *config) JWZ() *JWZConfig {
+	return c.jwz.Do(func() interface{} {
+		cfg := JWZConfig{
+			RootExpiration: defaultJWZRootExpiration,
+			UsedTokensTTL:  defaultJWZUsedTokensTTL,
+			CleanupPeriod:  defaultJWZTokensCleanupPeriod,
+		}
+		err := figure.
+			Out(&cfg).
+			From(kv.MustGetStringMap(c.getter, "jwz")).
+			Please()
+		if err != nil {
+			panic(errors.Wrap(err, "failed to figure out"))
+		}
+		if cfg.RootExpiration <= 0 || cfg.UsedTokensTTL <= 0 || cfg.CleanupPeriod <= 0 {
+			panic(errors.New("jwz root expiration, used tokens ttl and cleanup period must be positive"))
+		}
+
+		return &cfg
+	}).(*JWZConfig)
+}
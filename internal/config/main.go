@@ -16,6 +16,13 @@ type Config interface {
 
 	EthClient() *EthClientConfig
 	StatePublisher() *StatePublisherConfig
+	ExpirationSweeper() *ExpirationSweeperConfig
+	ClaimOffers() *ClaimOffersConfig
+	Webhooks() *WebhooksConfig
+	Idempotency() *IdempotencyConfig
+	Auth() *AuthConfig
+	JWZ() *JWZConfig
+	Iden3Comm() *Iden3CommConfig
 	Identity() *IdentityConfig
 	Issuer() *IssuerConfig
 }
@@ -27,10 +34,17 @@ type config struct {
 	types.Copuser
 	getter kv.Getter
 
-	ethClient      comfig.Once
-	statePublisher comfig.Once
-	issuer         comfig.Once
-	identity       comfig.Once
+	ethClient         comfig.Once
+	statePublisher    comfig.Once
+	expirationSweeper comfig.Once
+	claimOffers       comfig.Once
+	webhooks          comfig.Once
+	idempotency       comfig.Once
+	auth              comfig.Once
+	jwz               comfig.Once
+	iden3comm         comfig.Once
+	issuer            comfig.Once
+	identity          comfig.Once
 }
 
 func New(getter kv.Getter) Config {
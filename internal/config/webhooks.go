@@ -0,0 +1,105 @@
+package config
+
+import (
+	"reflect"
+	"time"
+
+	"github.com/spf13/cast"
+	"gitlab.com/distributed_lab/figure"
+	"gitlab.com/distributed_lab/kit/kv"
+	"gitlab.com/distributed_lab/logan/v3"
+	"gitlab.com/distributed_lab/logan/v3/errors"
+)
+
+const (
+	defaultWebhooksPeriod      = 5 * time.Second
+	defaultWebhooksBatchSize   = 100
+	defaultWebhooksTimeout     = 10 * time.Second
+	defaultWebhooksMaxAttempts = 10
+	defaultWebhooksMinBackoff  = 10 * time.Second
+	defaultWebhooksMaxBackoff  = time.Hour
+	defaultWebhooksRetention   = 7 * 24 * time.Hour
+)
+
+// WebhooksConfig configures the delivery of the lifecycle events to the endpoints, the section is optional
+// and nothing is sent without it. The failed delivery is retried with the backoff that doubles from
+// the MinBackoff up to the MaxBackoff, the event is given up after the MaxAttempts.
+type WebhooksConfig struct {
+	Disabled    bool              `fig:"disabled"`
+	Endpoints   []WebhookEndpoint `fig:"endpoints"`
+	Period      time.Duration     `fig:"period"`
+	BatchSize   uint64            `fig:"batch_size"`
+	Timeout     time.Duration     `fig:"timeout"`
+	MaxAttempts int               `fig:"max_attempts"`
+	MinBackoff  time.Duration     `fig:"min_backoff"`
+	MaxBackoff  time.Duration     `fig:"max_backoff"`
+	Retention   time.Duration     `fig:"retention"`
+}
+
+// WebhookEndpoint receives the events of the Events types, or all of them if the Events are empty,
+// the payloads are signed with the Secret.
+type WebhookEndpoint struct {
+	URL    string   `fig:"url,required"`
+	Secret string   `fig:"secret,required"`
+	Events []string `fig:"events"`
+}
+
+var webhooksHooks = figure.Hooks{
+	"[]config.WebhookEndpoint": func(value interface{}) (reflect.Value, error) {
+		rawEndpoints, err := cast.ToSliceE(value)
+		if err != nil {
+			return reflect.Value{}, errors.Wrap(err, "failed to cast endpoints to slice")
+		}
+
+		endpoints := make([]WebhookEndpoint, 0, len(rawEndpoints))
+		for i, rawEndpoint := range rawEndpoints {
+			values, err := cast.ToStringMapE(rawEndpoint)
+			if err != nil {
+				return reflect.Value{}, errors.Wrap(err, "failed to cast endpoint to map", logan.F{"index": i})
+			}
+
+			var endpoint WebhookEndpoint
+			err = figure.Out(&endpoint).From(values).Please()
+			if err != nil {
+				return reflect.Value{}, errors.Wrap(err, "failed to figure out endpoint", logan.F{"index": i})
+			}
+
+			endpoints = append(endpoints, endpoint)
+		}
+
+		return reflect.ValueOf(endpoints), nil
+	},
+}
+
+func (c *config) Webhooks() *WebhooksConfig {
+	return c.webhooks.Do(func() interface{} {
+		cfg := WebhooksConfig{
+			Period:      defaultWebhooksPeriod,
+			BatchSize:   defaultWebhooksBatchSize,
+			Timeout:     defaultWebhooksTimeout,
+			MaxAttempts: defaultWebhooksMaxAttempts,
+			MinBackoff:  defaultWebhooksMinBackoff,
+			MaxBackoff:  defaultWebhooksMaxBackoff,
+			Retention:   defaultWebhooksRetention,
+		}
+		err := figure.
+			Out(&cfg).
+			With(figure.BaseHooks, webhooksHooks).
+			From(kv.MustGetStringMap(c.getter, "webhooks")).
+			Please()
+		if err != nil {
+			panic(errors.Wrap(err, "failed to figure out"))
+		}
+		if cfg.Period <= 0 || cfg.Timeout <= 0 || cfg.MinBackoff <= 0 || cfg.MaxBackoff < cfg.MinBackoff {
+			panic(errors.New("webhooks period, timeout and backoffs must be positive, max backoff must not be less than min one"))
+		}
+		if cfg.BatchSize == 0 || cfg.MaxAttempts <= 0 {
+			panic(errors.New("webhooks batch size and max attempts must be positive"))
+		}
+		if cfg.Retention < 0 {
+			panic(errors.New("webhooks retention must not be negative"))
+		}
+
+		return &cfg
+	}).(*WebhooksConfig)
+}
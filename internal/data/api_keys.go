@@ -0,0 +1,37 @@
+package data
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+)
+
+type APIKeysQ interface {
+	New() APIKeysQ
+
+	Insert(apiKey *APIKey) error
+	Get(id string) (*APIKey, error)
+	GetByHash(keyHash []byte) (*APIKey, error)
+	Select() ([]APIKey, error)
+	// Revoke revokes the key if it isn't revoked yet, false is returned otherwise
+	Revoke(id string, revokedAt time.Time) (bool, error)
+	// MarkUsed sets the last usage time of the key if it wasn't used since the usedBefore,
+	// so the frequent requests don't update the key every time
+	MarkUsed(id string, usedAt, usedBefore time.Time) error
+}
+
+// APIKey is the key of the private API client, only the hash of the key is stored, the prefix
+// is kept to recognize it. The key is limited to the SchemaTypes unless they are empty.
+type APIKey struct {
+	ID          string         `db:"id"           structs:"id"`
+	IdentityID  uint64         `db:"identity_id"  structs:"-"`
+	Name        string         `db:"name"         structs:"name"`
+	KeyPrefix   string         `db:"key_prefix"   structs:"key_prefix"`
+	KeyHash     []byte         `db:"key_hash"     structs:"key_hash"`
+	Scopes      pq.StringArray `db:"scopes"       structs:"scopes"`
+	SchemaTypes pq.StringArray `db:"schema_types" structs:"schema_types"`
+	CreatedAt   time.Time      `db:"created_at"   structs:"created_at"`
+	ExpiresAt   *time.Time     `db:"expires_at"   structs:"expires_at"`
+	RevokedAt   *time.Time     `db:"revoked_at"   structs:"revoked_at"`
+	LastUsedAt  *time.Time     `db:"last_used_at" structs:"last_used_at"`
+}
@@ -0,0 +1,41 @@
+package data
+
+import (
+	"time"
+
+	"gitlab.com/distributed_lab/kit/pgdb"
+)
+
+type AuditEventsQ interface {
+	New() AuditEventsQ
+
+	Insert(events ...AuditEvent) error
+
+	Select() ([]AuditEvent, error)
+	Page(page pgdb.OffsetPageParams) AuditEventsQ
+	// After selects the events following the one with the ID in the order of insertion,
+	// it is the cursor of the export that walks all the events
+	After(id uint64, limit uint64) AuditEventsQ
+	FilterByEventType(eventTypes ...string) AuditEventsQ
+	FilterByActorID(actorIDs ...string) AuditEventsQ
+	FilterByRequestID(requestIDs ...string) AuditEventsQ
+	FilterByClaimID(claimIDs ...string) AuditEventsQ
+	FilterByCommittedStateID(committedStateIDs ...uint64) AuditEventsQ
+	FilterByCreatedAt(from, to *time.Time) AuditEventsQ
+}
+
+// AuditEvent is the append-only record of the change made by the actor, the RequestID is nil for the
+// changes that aren't made by the API requests, such as the expiration sweeps and the state publishing.
+type AuditEvent struct {
+	ID               uint64           `db:"id"                 structs:"-"`
+	IdentityID       uint64           `db:"identity_id"        structs:"-"`
+	EventType        string           `db:"event_type"         structs:"event_type"`
+	ActorType        string           `db:"actor_type"         structs:"actor_type"`
+	ActorID          string           `db:"actor_id"           structs:"actor_id"`
+	RequestID        *string          `db:"request_id"         structs:"request_id"`
+	ClaimID          *string          `db:"claim_id"           structs:"claim_id"`
+	OfferID          *string          `db:"offer_id"           structs:"offer_id"`
+	RevNonce         *RevocationNonce `db:"rev_nonce"          structs:"rev_nonce"`
+	CommittedStateID *uint64          `db:"committed_state_id" structs:"committed_state_id"`
+	CreatedAt        time.Time        `db:"created_at"         structs:"created_at"`
+}
@@ -0,0 +1,22 @@
+package data
+
+import "time"
+
+type ClaimOfferCredentialsQ interface {
+	New() ClaimOfferCredentialsQ
+
+	Insert(credentials ...ClaimOfferCredential) error
+	SelectByOfferID(offerID string) ([]ClaimOfferCredential, error)
+	// MarkReceived marks the offered credential as received if it isn't received yet,
+	// false is returned otherwise, so every credential of the offer is delivered only once
+	MarkReceived(offerID, claimID string, receivedAt time.Time) (bool, error)
+}
+
+// ClaimOfferCredential is the credential of the offer, the delivery of every credential is tracked separately.
+type ClaimOfferCredential struct {
+	OfferID    string     `db:"offer_id"    structs:"offer_id"`
+	IdentityID uint64     `db:"identity_id" structs:"-"`
+	ClaimID    string     `db:"claim_id"    structs:"claim_id"`
+	IsReceived bool       `db:"is_received" structs:"is_received"`
+	ReceivedAt *time.Time `db:"received_at" structs:"received_at"`
+}
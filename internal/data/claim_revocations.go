@@ -0,0 +1,68 @@
+package data
+
+import (
+	"database/sql/driver"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+type ClaimRevocationsQ interface {
+	New() ClaimRevocationsQ
+
+	Get(revNonce uint64) (*ClaimRevocation, error)
+	Insert(*ClaimRevocation) error
+}
+
+// ClaimRevocation is the record of the revocation nonce added to the revocations tree,
+// the ClaimID is nil if the nonce was revoked without the claim record.
+type ClaimRevocation struct {
+	RevNonce   RevocationNonce  `db:"rev_nonce"   structs:"rev_nonce"`
+	IdentityID uint64           `db:"identity_id" structs:"-"`
+	ClaimID    *string          `db:"claim_id"    structs:"claim_id"`
+	Reason     RevocationReason `db:"reason"      structs:"reason"`
+	Operator   string           `db:"operator"    structs:"operator"`
+	RevokedAt  time.Time        `db:"revoked_at"  structs:"revoked_at"`
+}
+
+type RevocationReason string
+
+const (
+	RevocationReasonUnspecified          = "unspecified"
+	RevocationReasonKeyCompromise        = "key_compromise"
+	RevocationReasonSuperseded           = "superseded"
+	RevocationReasonCessationOfOperation = "cessation_of_operation"
+	RevocationReasonPrivilegeWithdrawn   = "privilege_withdrawn"
+	RevocationReasonAffiliationChanged   = "affiliation_changed"
+	// RevocationReasonExpired is set by the expiration sweeper, it isn't accepted from the operators
+	RevocationReasonExpired = "expired"
+)
+
+// RevocationNonce is stored as the decimal number, because the database driver
+// doesn't support the uint64 values with the high bit set.
+type RevocationNonce uint64
+
+func (n RevocationNonce) Value() (driver.Value, error) {
+	return strconv.FormatUint(uint64(n), 10), nil
+}
+
+func (n *RevocationNonce) Scan(src interface{}) error {
+	var raw string
+	switch source := src.(type) {
+	case []byte:
+		raw = string(source)
+	case string:
+		raw = source
+	default:
+		return errors.New("type assertion src.([]byte) failed")
+	}
+
+	parsed, err := strconv.ParseUint(raw, 10, 64)
+	if err != nil {
+		return errors.Wrap(err, "failed to parse revocation nonce")
+	}
+
+	*n = RevocationNonce(parsed)
+	return nil
+}
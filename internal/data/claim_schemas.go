@@ -0,0 +1,25 @@
+package data
+
+import "time"
+
+type ClaimSchemasQ interface {
+	New() ClaimSchemasQ
+
+	Get(schemaType string) (*ClaimSchema, error)
+	Select() ([]ClaimSchema, error)
+	// Insert stores the schema if its type isn't registered yet, false is returned otherwise
+	Insert(*ClaimSchema) (bool, error)
+	Update(*ClaimSchema) error
+}
+
+type ClaimSchema struct {
+	SchemaType            string    `db:"schema_type"             structs:"schema_type"`
+	SchemaURL             string    `db:"schema_url"              structs:"schema_url"`
+	JSONLdContext         string    `db:"json_ld_context"         structs:"json_ld_context"`
+	DisplayName           string    `db:"display_name"            structs:"display_name"`
+	MerklizedRootPosition string    `db:"merklized_root_position" structs:"merklized_root_position"`
+	IsDeprecated          bool      `db:"is_deprecated"           structs:"is_deprecated"`
+	SchemaHash            string    `db:"schema_hash"             structs:"schema_hash"`
+	RevokeOnExpiration    bool      `db:"revoke_on_expiration"    structs:"revoke_on_expiration"`
+	CreatedAt             time.Time `db:"created_at"              structs:"created_at"`
+}
@@ -0,0 +1,23 @@
+package data
+
+import "time"
+
+type ClaimVersionsQ interface {
+	New() ClaimVersionsQ
+
+	Insert(*ClaimVersion) error
+	Get(claimID string, version uint32) (*ClaimVersion, error)
+	// SelectByClaimID returns the replaced versions of the claim in the ascending version order
+	SelectByClaimID(claimID string) ([]ClaimVersion, error)
+}
+
+// ClaimVersion is the snapshot of the updatable claim version that was replaced by the newer one.
+type ClaimVersion struct {
+	ClaimID           string     `db:"claim_id"           structs:"claim_id"`
+	IdentityID        uint64     `db:"identity_id"        structs:"-"`
+	Version           uint32     `db:"version"            structs:"version"`
+	Credential        []byte     `db:"data"               structs:"data"`
+	CoreClaim         *CoreClaim `db:"core_claim"         structs:"-"`
+	MerklizedDocument []byte     `db:"merklized_document" structs:"merklized_document"`
+	ReplacedAt        time.Time  `db:"replaced_at"        structs:"replaced_at"`
+}
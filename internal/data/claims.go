@@ -2,9 +2,11 @@ package data
 
 import (
 	"database/sql/driver"
+	"time"
 
 	core "github.com/iden3/go-iden3-core"
 	"github.com/pkg/errors"
+	"gitlab.com/distributed_lab/kit/pgdb"
 
 	"github.com/rarimo/issuer/internal/service/core/claims"
 )
@@ -14,18 +16,46 @@ type ClaimsQ interface {
 
 	Get(id string) (*Claim, error)
 	GetAuthClaim() (*Claim, error)
+	// GetBySchemaType returns the latest not revoked claim of the type, or the
+	// latest revoked one if there are no active claims
 	GetBySchemaType(schemaType string, userID string) (*Claim, error)
+	GetByRevNonce(revNonce uint64) (*Claim, error)
+	// GetByPreviousClaimID returns the claim that replaced the given one on reissue
+	GetByPreviousClaimID(id string) (*Claim, error)
 	Insert(*Claim) error
 	Update(*Claim) error
+	// MarkRevoked sets only the revoked flag, so the concurrent update of the claim is kept
+	MarkRevoked(id string) error
+	// MarkExpired sets the expired flag and the revoked one if revoke is true, the other columns are kept
+	MarkExpired(id string, revoke bool) error
+
+	Select() ([]Claim, error)
+	Page(page pgdb.OffsetPageParams) ClaimsQ
+	FilterByUserID(userIDs ...string) ClaimsQ
+	FilterBySchemaType(schemaTypes ...string) ClaimsQ
+	FilterByRevoked(revoked bool) ClaimsQ
+	FilterByExpired(expired bool) ClaimsQ
+	// FilterByReceived filters the claims by whether they were delivered by any offer
+	FilterByReceived(received bool) ClaimsQ
+	FilterByID(ids ...string) ClaimsQ
+	FilterByCreatedAt(from, to *time.Time) ClaimsQ
+	FilterByExpiration(from, to *time.Time) ClaimsQ
 }
 
 type Claim struct {
-	ID         string     `db:"id"          structs:"id"`
-	ClaimType  string     `db:"schema_type" structs:"schema_type"`
-	Revoked    bool       `db:"revoked"     structs:"revoked"`
-	Credential []byte     `db:"data"        structs:"data"`
-	CoreClaim  *CoreClaim `db:"core_claim"  structs:"-"`
-	UserID     string     `db:"user_id"     structs:"user_id"`
+	ID                string          `db:"id"                 structs:"id"`
+	IdentityID        uint64          `db:"identity_id"        structs:"-"`
+	ClaimType         string          `db:"schema_type"        structs:"schema_type"`
+	Revoked           bool            `db:"revoked"            structs:"revoked"`
+	Credential        []byte          `db:"data"               structs:"data"`
+	CoreClaim         *CoreClaim      `db:"core_claim"         structs:"-"`
+	UserID            string          `db:"user_id"            structs:"user_id"`
+	MerklizedDocument []byte          `db:"merklized_document" structs:"merklized_document"`
+	CreatedAt         time.Time       `db:"created_at"         structs:"created_at"`
+	Expiration        *time.Time      `db:"expiration"         structs:"expiration"`
+	RevNonce          RevocationNonce `db:"rev_nonce"          structs:"rev_nonce"`
+	Expired           bool            `db:"expired"            structs:"expired"`
+	PreviousClaimID   *string         `db:"previous_claim_id"  structs:"previous_claim_id"`
 
 	MTP            *claims.Iden3SparseMerkleTreeProof `db:"-" structs:"-"`
 	SignatureProof *claims.BJJSignatureProof2021      `db:"-" structs:"-"`
@@ -8,13 +8,33 @@ type ClaimsOffersQ interface {
 	Get(string) (*ClaimOffer, error)
 	Insert(*ClaimOffer) error
 	Update(*ClaimOffer) error
+	// LockActive locks the offer that isn't canceled and was created after the time till the end of the
+	// transaction, false is returned if there is no such offer, the offers don't expire if the time is zero.
+	// The concurrent cancels and deliveries of the offer wait for the transaction, so they see its result.
+	LockActive(id string, createdAfter time.Time) (bool, error)
+	// MarkReceived marks the offer that is neither received nor canceled as received when all its credentials
+	// are received, false is returned if there is no such offer, so the offer can be redeemed only once
+	MarkReceived(id string) (bool, error)
+	// Cancel cancels the offer that is neither received nor canceled, false is returned if there is no such offer
+	Cancel(id string, canceledAt time.Time) (bool, error)
+	// DeleteCreatedBefore deletes the offers created before the time and returns the deleted count,
+	// the offers any credential of which was received are kept
+	DeleteCreatedBefore(createdAt time.Time) (int64, error)
+	// DeleteCanceledBefore deletes the offers canceled before the time and returns the deleted count,
+	// the offers any credential of which was received are kept
+	DeleteCanceledBefore(canceledAt time.Time) (int64, error)
 }
 
+// ClaimOffer is the offer sent to the claim owner, the Message is the plain offer message
+// served by the offer link, it is nil for the offers created before the messages were stored.
 type ClaimOffer struct {
-	ID         string    `db:"id"          structs:"id"`
-	From       string    `db:"from_id"     structs:"from_id"`
-	To         string    `db:"to_id"       structs:"to_id"`
-	CreatedAt  time.Time `db:"created_at"  structs:"created_at"`
-	ClaimID    string    `db:"claim_id"    structs:"claim_id"`
-	IsReceived bool      `db:"is_received" structs:"is_received"`
+	ID         string     `db:"id"          structs:"id"`
+	IdentityID uint64     `db:"identity_id" structs:"-"`
+	From       string     `db:"from_id"     structs:"from_id"`
+	To         string     `db:"to_id"       structs:"to_id"`
+	CreatedAt  time.Time  `db:"created_at"  structs:"created_at"`
+	ClaimID    string     `db:"claim_id"    structs:"claim_id"`
+	IsReceived bool       `db:"is_received" structs:"is_received"`
+	Message    []byte     `db:"message"     structs:"message"`
+	CanceledAt *time.Time `db:"canceled_at" structs:"canceled_at"`
 }
@@ -16,12 +16,17 @@ type CommittedStatesQ interface {
 	Sort(sort pgdb.SortedOffsetPageParams) CommittedStatesQ
 
 	GetLatest() (*CommittedState, error)
+	GetEarliest() (*CommittedState, error)
 	GetGenesis() (*CommittedState, error)
-	WhereStatus(status Status) CommittedStatesQ
+	WhereStatus(statuses ...Status) CommittedStatesQ
+	WhereCreatedAtFrom(from time.Time) CommittedStatesQ
 }
 
+// CommittedState is the state of the identity, its IdentityID is set by the queries
+// the committed states are scoped with.
 type CommittedState struct {
 	ID                  uint64    `db:"id"                    structs:"-"`
+	IdentityID          uint64    `db:"identity_id"           structs:"-"`
 	Status              Status    `db:"status"                structs:"status"`
 	Message             string    `db:"message"               structs:"message"`
 	TxID                string    `db:"tx_id"                 structs:"tx_id"`
@@ -0,0 +1,28 @@
+package data
+
+import "time"
+
+type IdempotencyKeysQ interface {
+	New() IdempotencyKeysQ
+
+	Get(id string) (*IdempotencyKey, error)
+	// Lock inserts the key, or takes over the existing one if it was created before the expiredBefore
+	// or wasn't completed since the abandonedBefore, false is returned if the key is taken
+	Lock(key *IdempotencyKey, expiredBefore, abandonedBefore time.Time) (bool, error)
+	Complete(id string, statusCode int, contentType string, response []byte, completedAt time.Time) error
+	Delete(id string) error
+	DeleteCreatedBefore(createdAt time.Time) (int64, error)
+}
+
+// IdempotencyKey is the key of the request with the response to it, the key
+// is in progress until the StatusCode and the Response are stored.
+type IdempotencyKey struct {
+	ID          string     `db:"id"           structs:"id"`
+	IdentityID  uint64     `db:"identity_id"  structs:"-"`
+	RequestHash []byte     `db:"request_hash" structs:"request_hash"`
+	StatusCode  *int       `db:"status_code"  structs:"status_code"`
+	ContentType string     `db:"content_type" structs:"content_type"`
+	Response    []byte     `db:"response"     structs:"response"`
+	CreatedAt   time.Time  `db:"created_at"   structs:"created_at"`
+	CompletedAt *time.Time `db:"completed_at" structs:"completed_at"`
+}
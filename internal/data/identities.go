@@ -0,0 +1,35 @@
+package data
+
+import "time"
+
+// DefaultIdentityID is the identity the service hosted before it hosted many ones, it is hosted with
+// the configured keys and serves the URLs that aren't scoped by the issuer DID.
+const DefaultIdentityID uint64 = 1
+
+type IdentitiesQ interface {
+	New() IdentitiesQ
+
+	// Insert inserts the identity and sets its ID, false is returned if the name is already taken
+	Insert(identity *Identity) (bool, error)
+	Get(id uint64) (*Identity, error)
+	GetByDID(did string) (*Identity, error)
+	GetByName(name string) (*Identity, error)
+	Select() ([]Identity, error)
+	// SetDID sets the DID of the identity derived from its genesis state
+	SetDID(id uint64, did string) error
+	// Lock locks the identity till the end of the transaction, it serializes
+	// the changes of the identity trees made by the concurrent transactions
+	Lock(id uint64) error
+}
+
+// Identity is the issuer identity hosted by the service. The DID is nil until the genesis state of the
+// identity is generated. The keys are sealed with the keys encryption key, they are nil for the default
+// identity that is hosted with the configured keys.
+type Identity struct {
+	ID                   uint64    `db:"id"                     structs:"-"`
+	Name                 string    `db:"name"                   structs:"name"`
+	DID                  *string   `db:"did"                    structs:"did"`
+	BabyJubJubPrivateKey []byte    `db:"babyjubjub_private_key" structs:"babyjubjub_private_key"`
+	SigningKey           []byte    `db:"signing_key"            structs:"signing_key"`
+	CreatedAt            time.Time `db:"created_at"             structs:"created_at"`
+}
@@ -4,8 +4,21 @@ type MasterQ interface {
 	New() MasterQ
 
 	ClaimsQ() ClaimsQ
+	ClaimVersionsQ() ClaimVersionsQ
+	ClaimRevocationsQ() ClaimRevocationsQ
 	CommittedStatesQ() CommittedStatesQ
 	ClaimsOffersQ() ClaimsOffersQ
+	ClaimOfferCredentialsQ() ClaimOfferCredentialsQ
+	ClaimSchemasQ() ClaimSchemasQ
+	SchemaDocumentsQ() SchemaDocumentsQ
+	WebhookEventsQ() WebhookEventsQ
+	IdempotencyKeysQ() IdempotencyKeysQ
+	APIKeysQ() APIKeysQ
+	OfferAuthRequestsQ() OfferAuthRequestsQ
+	UsedTokensQ() UsedTokensQ
+	AuditEventsQ() AuditEventsQ
+	IdentitiesQ() IdentitiesQ
+	TreeStorageQ(treeName string) TreeStorageQ
 
 	Transaction(func() error) error
 }
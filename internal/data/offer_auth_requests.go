@@ -0,0 +1,26 @@
+package data
+
+import "time"
+
+type OfferAuthRequestsQ interface {
+	New() OfferAuthRequestsQ
+
+	// Insert stores the answered request, false is returned if it is already stored,
+	// so the request can be answered only once
+	Insert(*OfferAuthRequest) (bool, error)
+	// DeleteExpiredBefore deletes the requests expired before the time and returns the deleted count
+	DeleteExpiredBefore(expiresAt time.Time) (int64, error)
+}
+
+// OfferAuthRequest is the iden3comm authorization request the holder answers to get the offer of the claim
+// of the schema type. The request isn't stored until it is answered, its thread ID carries it signed by the
+// issuer, the answered request is kept until it expires, so it can't be answered again.
+type OfferAuthRequest struct {
+	ID           string     `db:"id"            structs:"id"`
+	IdentityID   uint64     `db:"identity_id"   structs:"-"`
+	UserID       string     `db:"user_id"       structs:"user_id"`
+	SchemaType   string     `db:"schema_type"   structs:"schema_type"`
+	CreatedAt    time.Time  `db:"created_at"    structs:"created_at"`
+	ExpiresAt    time.Time  `db:"expires_at"    structs:"expires_at"`
+	AuthorizedAt *time.Time `db:"authorized_at" structs:"authorized_at"`
+}
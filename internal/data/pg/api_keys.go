@@ -0,0 +1,131 @@
+package pg
+
+import (
+	"database/sql"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/fatih/structs"
+	"github.com/pkg/errors"
+	"gitlab.com/distributed_lab/kit/pgdb"
+
+	"github.com/rarimo/issuer/internal/data"
+)
+
+const (
+	apiKeysTableName     = "api_keys"
+	keyHashColumnName    = "key_hash"
+	revokedAtColumnName  = "revoked_at"
+	lastUsedAtColumnName = "last_used_at"
+)
+
+// apiKeysQ is scoped by the identity, the keys of the other ones neither authenticate nor are listed.
+type apiKeysQ struct {
+	db         *pgdb.DB
+	identityID uint64
+}
+
+func NewAPIKeysQ(db *pgdb.DB, identityID uint64) data.APIKeysQ {
+	return &apiKeysQ{
+		db:         db,
+		identityID: identityID,
+	}
+}
+
+func (q *apiKeysQ) New() data.APIKeysQ {
+	return NewAPIKeysQ(q.db.Clone(), q.identityID)
+}
+
+func (q *apiKeysQ) Insert(apiKey *data.APIKey) error {
+	clauses := structs.Map(apiKey)
+	clauses[identityIDColumnName] = q.identityID
+
+	err := q.db.Exec(sq.Insert(apiKeysTableName).SetMap(clauses))
+	if err != nil {
+		return errors.Wrap(err, "failed to insert rows")
+	}
+
+	return nil
+}
+
+func (q *apiKeysQ) Get(id string) (*data.APIKey, error) {
+	return q.get(sq.Eq{idColumnName: id})
+}
+
+func (q *apiKeysQ) GetByHash(keyHash []byte) (*data.APIKey, error) {
+	return q.get(sq.Eq{keyHashColumnName: keyHash})
+}
+
+func (q *apiKeysQ) get(condition sq.Sqlizer) (*data.APIKey, error) {
+	var result data.APIKey
+
+	err := q.db.Get(&result,
+		sq.Select("*").
+			From(apiKeysTableName).
+			Where(condition).
+			Where(sq.Eq{identityIDColumnName: q.identityID}),
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, errors.Wrap(err, "failed to select rows")
+	}
+
+	return &result, nil
+}
+
+func (q *apiKeysQ) Select() ([]data.APIKey, error) {
+	var result []data.APIKey
+
+	err := q.db.Select(&result,
+		sq.Select("*").
+			From(apiKeysTableName).
+			Where(sq.Eq{identityIDColumnName: q.identityID}).
+			OrderBy(createdAtColumnName),
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to select rows")
+	}
+
+	return result, nil
+}
+
+func (q *apiKeysQ) Revoke(id string, revokedAt time.Time) (bool, error) {
+	result, err := q.db.ExecWithResult(
+		sq.Update(apiKeysTableName).
+			Set(revokedAtColumnName, revokedAt).
+			Where(sq.Eq{
+				idColumnName:         id,
+				revokedAtColumnName:  nil,
+				identityIDColumnName: q.identityID,
+			}),
+	)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to update rows")
+	}
+
+	updated, err := result.RowsAffected()
+	if err != nil {
+		return false, errors.Wrap(err, "failed to get updated rows count")
+	}
+
+	return updated > 0, nil
+}
+
+func (q *apiKeysQ) MarkUsed(id string, usedAt, usedBefore time.Time) error {
+	err := q.db.Exec(
+		sq.Update(apiKeysTableName).
+			Set(lastUsedAtColumnName, usedAt).
+			Where(sq.Eq{idColumnName: id, identityIDColumnName: q.identityID}).
+			Where(sq.Or{
+				sq.Eq{lastUsedAtColumnName: nil},
+				sq.Lt{lastUsedAtColumnName: usedBefore},
+			}),
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to update rows")
+	}
+
+	return nil
+}
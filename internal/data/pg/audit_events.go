@@ -0,0 +1,117 @@
+package pg
+
+import (
+	"database/sql"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/pkg/errors"
+	"gitlab.com/distributed_lab/kit/pgdb"
+
+	"github.com/rarimo/issuer/internal/data"
+)
+
+const (
+	auditEventsTableName       = "audit_events"
+	actorTypeColumnName        = "actor_type"
+	actorIDColumnName          = "actor_id"
+	requestIDColumnName        = "request_id"
+	committedStateIDColumnName = "committed_state_id"
+)
+
+// auditEventsQ is scoped by the identity, it neither selects nor inserts the events of the other ones.
+type auditEventsQ struct {
+	db         *pgdb.DB
+	sel        sq.SelectBuilder
+	identityID uint64
+}
+
+func NewAuditEventsQ(db *pgdb.DB, identityID uint64) data.AuditEventsQ {
+	return &auditEventsQ{
+		db:         db,
+		sel:        sq.Select("*").From(auditEventsTableName).Where(sq.Eq{identityIDColumnName: identityID}),
+		identityID: identityID,
+	}
+}
+
+func (q *auditEventsQ) New() data.AuditEventsQ {
+	return NewAuditEventsQ(q.db.Clone(), q.identityID)
+}
+
+func (q *auditEventsQ) Insert(events ...data.AuditEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	stmt := sq.Insert(auditEventsTableName).Columns(
+		eventTypeColumnName, actorTypeColumnName, actorIDColumnName, requestIDColumnName, claimIDColumnName,
+		offerIDColumnName, revNonceColumnName, committedStateIDColumnName, createdAtColumnName, identityIDColumnName,
+	)
+	for _, event := range events {
+		stmt = stmt.Values(
+			event.EventType, event.ActorType, event.ActorID, event.RequestID, event.ClaimID,
+			event.OfferID, event.RevNonce, event.CommittedStateID, event.CreatedAt, q.identityID,
+		)
+	}
+
+	err := q.db.Exec(stmt)
+	if err != nil {
+		return errors.Wrap(err, "failed to insert rows")
+	}
+
+	return nil
+}
+
+func (q *auditEventsQ) Select() ([]data.AuditEvent, error) {
+	var result []data.AuditEvent
+
+	err := q.db.Select(&result, q.sel)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, errors.Wrap(err, "failed to select rows")
+	}
+
+	return result, nil
+}
+
+func (q *auditEventsQ) Page(page pgdb.OffsetPageParams) data.AuditEventsQ {
+	q.sel = page.ApplyTo(q.sel, createdAtColumnName, idColumnName)
+	return q
+}
+
+func (q *auditEventsQ) After(id uint64, limit uint64) data.AuditEventsQ {
+	q.sel = q.sel.Where(sq.Gt{idColumnName: id}).OrderBy(idColumnName).Limit(limit)
+	return q
+}
+
+func (q *auditEventsQ) FilterByEventType(eventTypes ...string) data.AuditEventsQ {
+	q.sel = q.sel.Where(sq.Eq{eventTypeColumnName: eventTypes})
+	return q
+}
+
+func (q *auditEventsQ) FilterByActorID(actorIDs ...string) data.AuditEventsQ {
+	q.sel = q.sel.Where(sq.Eq{actorIDColumnName: actorIDs})
+	return q
+}
+
+func (q *auditEventsQ) FilterByRequestID(requestIDs ...string) data.AuditEventsQ {
+	q.sel = q.sel.Where(sq.Eq{requestIDColumnName: requestIDs})
+	return q
+}
+
+func (q *auditEventsQ) FilterByClaimID(claimIDs ...string) data.AuditEventsQ {
+	q.sel = q.sel.Where(sq.Eq{claimIDColumnName: claimIDs})
+	return q
+}
+
+func (q *auditEventsQ) FilterByCommittedStateID(committedStateIDs ...uint64) data.AuditEventsQ {
+	q.sel = q.sel.Where(sq.Eq{committedStateIDColumnName: committedStateIDs})
+	return q
+}
+
+func (q *auditEventsQ) FilterByCreatedAt(from, to *time.Time) data.AuditEventsQ {
+	q.sel = filterByTimeRange(q.sel, createdAtColumnName, from, to)
+	return q
+}
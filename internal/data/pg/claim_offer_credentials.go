@@ -0,0 +1,97 @@
+package pg
+
+import (
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/pkg/errors"
+	"gitlab.com/distributed_lab/kit/pgdb"
+
+	"github.com/rarimo/issuer/internal/data"
+)
+
+const (
+	claimOfferCredentialsTableName = "claim_offer_credentials"
+	offerIDColumnName              = "offer_id"
+	claimIDColumnName              = "claim_id"
+	receivedAtColumnName           = "received_at"
+)
+
+// claimOfferCredentialsQ is scoped by the identity, it neither selects nor changes the credentials
+// of the offers of the other ones.
+type claimOfferCredentialsQ struct {
+	db         *pgdb.DB
+	identityID uint64
+}
+
+func NewClaimOfferCredentialsQ(db *pgdb.DB, identityID uint64) data.ClaimOfferCredentialsQ {
+	return &claimOfferCredentialsQ{
+		db:         db,
+		identityID: identityID,
+	}
+}
+
+func (q *claimOfferCredentialsQ) New() data.ClaimOfferCredentialsQ {
+	return NewClaimOfferCredentialsQ(q.db.Clone(), q.identityID)
+}
+
+func (q *claimOfferCredentialsQ) Insert(credentials ...data.ClaimOfferCredential) error {
+	if len(credentials) == 0 {
+		return nil
+	}
+
+	stmt := sq.Insert(claimOfferCredentialsTableName).Columns(
+		offerIDColumnName, claimIDColumnName, isReceivedColumnName, receivedAtColumnName, identityIDColumnName,
+	)
+	for _, credential := range credentials {
+		stmt = stmt.Values(
+			credential.OfferID, credential.ClaimID, credential.IsReceived, credential.ReceivedAt, q.identityID,
+		)
+	}
+
+	err := q.db.Exec(stmt)
+	if err != nil {
+		return errors.Wrap(err, "failed to insert rows")
+	}
+
+	return nil
+}
+
+func (q *claimOfferCredentialsQ) SelectByOfferID(offerID string) ([]data.ClaimOfferCredential, error) {
+	var result []data.ClaimOfferCredential
+
+	err := q.db.Select(&result,
+		sq.Select("*").
+			From(claimOfferCredentialsTableName).
+			Where(sq.Eq{offerIDColumnName: offerID, identityIDColumnName: q.identityID}),
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to select rows")
+	}
+
+	return result, nil
+}
+
+func (q *claimOfferCredentialsQ) MarkReceived(offerID, claimID string, receivedAt time.Time) (bool, error) {
+	result, err := q.db.ExecWithResult(
+		sq.Update(claimOfferCredentialsTableName).
+			Set(isReceivedColumnName, true).
+			Set(receivedAtColumnName, receivedAt).
+			Where(sq.Eq{
+				offerIDColumnName:    offerID,
+				claimIDColumnName:    claimID,
+				isReceivedColumnName: false,
+				identityIDColumnName: q.identityID,
+			}),
+	)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to update rows")
+	}
+
+	updated, err := result.RowsAffected()
+	if err != nil {
+		return false, errors.Wrap(err, "failed to get updated rows count")
+	}
+
+	return updated > 0, nil
+}
@@ -0,0 +1,64 @@
+package pg
+
+import (
+	"database/sql"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/fatih/structs"
+	"github.com/pkg/errors"
+	"gitlab.com/distributed_lab/kit/pgdb"
+
+	"github.com/rarimo/issuer/internal/data"
+)
+
+const (
+	claimRevocationsTableName = "claim_revocations"
+	revNonceColumnName        = "rev_nonce"
+)
+
+// claimRevocationsQ is scoped by the identity, the revocation nonces are unique only within it.
+type claimRevocationsQ struct {
+	db         *pgdb.DB
+	identityID uint64
+}
+
+func NewClaimRevocationsQ(db *pgdb.DB, identityID uint64) data.ClaimRevocationsQ {
+	return &claimRevocationsQ{
+		db:         db,
+		identityID: identityID,
+	}
+}
+
+func (q *claimRevocationsQ) New() data.ClaimRevocationsQ {
+	return NewClaimRevocationsQ(q.db.Clone(), q.identityID)
+}
+
+func (q *claimRevocationsQ) Get(revNonce uint64) (*data.ClaimRevocation, error) {
+	var result data.ClaimRevocation
+
+	err := q.db.Get(&result,
+		sq.Select("*").
+			From(claimRevocationsTableName).
+			Where(sq.Eq{revNonceColumnName: data.RevocationNonce(revNonce), identityIDColumnName: q.identityID}),
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, errors.Wrap(err, "failed to select rows")
+	}
+
+	return &result, nil
+}
+
+func (q *claimRevocationsQ) Insert(revocation *data.ClaimRevocation) error {
+	clauses := structs.Map(revocation)
+	clauses[identityIDColumnName] = q.identityID
+
+	err := q.db.Exec(sq.Insert(claimRevocationsTableName).SetMap(clauses))
+	if err != nil {
+		return errors.Wrap(err, "failed to insert rows")
+	}
+
+	return nil
+}
@@ -0,0 +1,92 @@
+package pg
+
+import (
+	"database/sql"
+	"fmt"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/fatih/structs"
+	"github.com/pkg/errors"
+	"gitlab.com/distributed_lab/kit/pgdb"
+
+	"github.com/rarimo/issuer/internal/data"
+)
+
+const (
+	claimSchemasTableName = "claim_schemas"
+)
+
+type claimSchemasQ struct {
+	db  *pgdb.DB
+	sel sq.SelectBuilder
+}
+
+func NewClaimSchemasQ(db *pgdb.DB) data.ClaimSchemasQ {
+	return &claimSchemasQ{
+		db:  db,
+		sel: sq.Select("*").From(claimSchemasTableName),
+	}
+}
+
+func (q *claimSchemasQ) New() data.ClaimSchemasQ {
+	return NewClaimSchemasQ(q.db.Clone())
+}
+
+func (q *claimSchemasQ) Get(schemaType string) (*data.ClaimSchema, error) {
+	var result data.ClaimSchema
+
+	err := q.db.Get(&result,
+		sq.Select("*").
+			From(claimSchemasTableName).
+			Where(sq.Eq{schemaTypeColumnName: schemaType}))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, errors.Wrap(err, "failed to select rows")
+	}
+
+	return &result, nil
+}
+
+func (q *claimSchemasQ) Select() ([]data.ClaimSchema, error) {
+	var result []data.ClaimSchema
+
+	err := q.db.Select(&result, q.sel.OrderBy(createdAtColumnName))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to select rows")
+	}
+
+	return result, nil
+}
+
+func (q *claimSchemasQ) Insert(claimSchema *data.ClaimSchema) (bool, error) {
+	result, err := q.db.ExecWithResult(
+		sq.Insert(claimSchemasTableName).
+			SetMap(structs.Map(claimSchema)).
+			Suffix(fmt.Sprintf("ON CONFLICT (%s) DO NOTHING", schemaTypeColumnName)),
+	)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to insert rows")
+	}
+
+	inserted, err := result.RowsAffected()
+	if err != nil {
+		return false, errors.Wrap(err, "failed to get inserted rows count")
+	}
+
+	return inserted > 0, nil
+}
+
+func (q *claimSchemasQ) Update(claimSchema *data.ClaimSchema) error {
+	err := q.db.Exec(
+		sq.Update(claimSchemasTableName).
+			SetMap(structs.Map(claimSchema)).
+			Where(sq.Eq{schemaTypeColumnName: claimSchema.SchemaType}),
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to update rows")
+	}
+
+	return nil
+}
@@ -0,0 +1,81 @@
+package pg
+
+import (
+	"database/sql"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/fatih/structs"
+	"github.com/pkg/errors"
+	"gitlab.com/distributed_lab/kit/pgdb"
+
+	"github.com/rarimo/issuer/internal/data"
+)
+
+const (
+	claimVersionsTableName = "claim_versions"
+	versionColumnName      = "version"
+)
+
+// claimVersionsQ is scoped by the identity, it neither selects nor inserts the versions of the other ones.
+type claimVersionsQ struct {
+	db         *pgdb.DB
+	identityID uint64
+}
+
+func NewClaimVersionsQ(db *pgdb.DB, identityID uint64) data.ClaimVersionsQ {
+	return &claimVersionsQ{
+		db:         db,
+		identityID: identityID,
+	}
+}
+
+func (q *claimVersionsQ) New() data.ClaimVersionsQ {
+	return NewClaimVersionsQ(q.db.Clone(), q.identityID)
+}
+
+func (q *claimVersionsQ) Insert(claimVersion *data.ClaimVersion) error {
+	clauses := structs.Map(claimVersion)
+	clauses[coreClaimColumnName] = claimVersion.CoreClaim
+	clauses[identityIDColumnName] = q.identityID
+
+	err := q.db.Exec(sq.Insert(claimVersionsTableName).SetMap(clauses))
+	if err != nil {
+		return errors.Wrap(err, "failed to insert rows")
+	}
+
+	return nil
+}
+
+func (q *claimVersionsQ) Get(claimID string, version uint32) (*data.ClaimVersion, error) {
+	var result data.ClaimVersion
+
+	err := q.db.Get(&result,
+		sq.Select("*").
+			From(claimVersionsTableName).
+			Where(sq.Eq{claimIDColumnName: claimID, versionColumnName: version, identityIDColumnName: q.identityID}),
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, errors.Wrap(err, "failed to select rows")
+	}
+
+	return &result, nil
+}
+
+func (q *claimVersionsQ) SelectByClaimID(claimID string) ([]data.ClaimVersion, error) {
+	var result []data.ClaimVersion
+
+	err := q.db.Select(&result,
+		sq.Select("*").
+			From(claimVersionsTableName).
+			Where(sq.Eq{claimIDColumnName: claimID, identityIDColumnName: q.identityID}).
+			OrderBy(versionColumnName),
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to select rows")
+	}
+
+	return result, nil
+}
@@ -3,6 +3,7 @@ package pg
 import (
 	"database/sql"
 	"fmt"
+	"time"
 
 	sq "github.com/Masterminds/squirrel"
 	"github.com/fatih/structs"
@@ -19,25 +20,36 @@ const (
 	coreClaimColumnName  = "core_claim"
 	schemaTypeColumnName = "schema_type"
 	userIDColumnName     = "user_id"
+	revokedColumnName    = "revoked"
+	expirationColumnName = "expiration"
+	expiredColumnName    = "expired"
+
+	previousClaimIDColumnName = "previous_claim_id"
 )
 
+// claimsQ is scoped by the identity, it neither selects nor changes the claims of the other ones.
 type claimsQ struct {
-	db *pgdb.DB
+	db         *pgdb.DB
+	sel        sq.SelectBuilder
+	identityID uint64
 }
 
-func NewClaimsQ(db *pgdb.DB) data.ClaimsQ {
+func NewClaimsQ(db *pgdb.DB, identityID uint64) data.ClaimsQ {
 	return &claimsQ{
-		db: db,
+		db:         db,
+		sel:        sq.Select("*").From(claimsTableName).Where(sq.Eq{identityIDColumnName: identityID}),
+		identityID: identityID,
 	}
 }
 
 func (q *claimsQ) New() data.ClaimsQ {
-	return NewClaimsQ(q.db.Clone())
+	return NewClaimsQ(q.db.Clone(), q.identityID)
 }
 
 func (q *claimsQ) Insert(claim *data.Claim) error {
 	clauses := structs.Map(claim)
 	clauses[coreClaimColumnName] = claim.CoreClaim
+	clauses[identityIDColumnName] = q.identityID
 
 	err := q.db.Get(&claim.ID,
 		sq.Insert(claimsTableName).
@@ -58,7 +70,20 @@ func (q *claimsQ) Update(claim *data.Claim) error {
 	err := q.db.Exec(
 		sq.Update(claimsTableName).
 			SetMap(clauses).
-			Where(sq.Eq{idColumnName: claim.ID}),
+			Where(sq.Eq{idColumnName: claim.ID, identityIDColumnName: q.identityID}),
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to update rows")
+	}
+
+	return nil
+}
+
+func (q *claimsQ) MarkRevoked(id string) error {
+	err := q.db.Exec(
+		sq.Update(claimsTableName).
+			Set(revokedColumnName, true).
+			Where(sq.Eq{idColumnName: id, identityIDColumnName: q.identityID}),
 	)
 	if err != nil {
 		return errors.Wrap(err, "failed to update rows")
@@ -67,13 +92,28 @@ func (q *claimsQ) Update(claim *data.Claim) error {
 	return nil
 }
 
+func (q *claimsQ) MarkExpired(id string, revoke bool) error {
+	stmt := sq.Update(claimsTableName).
+		Set(expiredColumnName, true).
+		Where(sq.Eq{idColumnName: id, identityIDColumnName: q.identityID})
+	if revoke {
+		stmt = stmt.Set(revokedColumnName, true)
+	}
+
+	if err := q.db.Exec(stmt); err != nil {
+		return errors.Wrap(err, "failed to update rows")
+	}
+
+	return nil
+}
+
 func (q *claimsQ) Get(id string) (*data.Claim, error) {
 	var result data.Claim
 
 	err := q.db.Get(&result,
 		sq.Select("*").
 			From(claimsTableName).
-			Where(sq.Eq{idColumnName: id}))
+			Where(sq.Eq{idColumnName: id, identityIDColumnName: q.identityID}))
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, nil
@@ -90,7 +130,9 @@ func (q *claimsQ) GetAuthClaim() (*data.Claim, error) {
 	err := q.db.Get(&result,
 		sq.Select("*").
 			From(claimsTableName).
-			Where(sq.Eq{schemaTypeColumnName: claims.AuthBJJCredentialClaimType}))
+			Where(sq.Eq{
+				schemaTypeColumnName: claims.AuthBJJCredentialClaimType, identityIDColumnName: q.identityID,
+			}))
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, nil
@@ -108,7 +150,10 @@ func (q *claimsQ) GetBySchemaType(schemaType string, userID string) (*data.Claim
 		sq.Select("*").
 			From(claimsTableName).
 			Where(sq.Eq{schemaTypeColumnName: schemaType}).
-			Where(sq.Eq{userIDColumnName: userID}))
+			Where(sq.Eq{userIDColumnName: userID}).
+			Where(sq.Eq{identityIDColumnName: q.identityID}).
+			OrderBy(revokedColumnName, fmt.Sprintf("%s DESC", createdAtColumnName)).
+			Limit(1))
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, nil
@@ -118,3 +163,121 @@ func (q *claimsQ) GetBySchemaType(schemaType string, userID string) (*data.Claim
 
 	return &result, nil
 }
+
+func (q *claimsQ) GetByRevNonce(revNonce uint64) (*data.Claim, error) {
+	var result data.Claim
+
+	err := q.db.Get(&result,
+		sq.Select("*").
+			From(claimsTableName).
+			Where(sq.Eq{revNonceColumnName: data.RevocationNonce(revNonce), identityIDColumnName: q.identityID}).
+			OrderBy(fmt.Sprintf("%s DESC", createdAtColumnName)).
+			Limit(1))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, errors.Wrap(err, "failed to select rows")
+	}
+
+	return &result, nil
+}
+
+func (q *claimsQ) GetByPreviousClaimID(id string) (*data.Claim, error) {
+	var result data.Claim
+
+	err := q.db.Get(&result,
+		sq.Select("*").
+			From(claimsTableName).
+			Where(sq.Eq{previousClaimIDColumnName: id, identityIDColumnName: q.identityID}).
+			OrderBy(fmt.Sprintf("%s DESC", createdAtColumnName)).
+			Limit(1))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, errors.Wrap(err, "failed to select rows")
+	}
+
+	return &result, nil
+}
+
+func (q *claimsQ) Select() ([]data.Claim, error) {
+	var result []data.Claim
+
+	err := q.db.Select(&result, q.sel)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, errors.Wrap(err, "failed to select rows")
+	}
+
+	return result, nil
+}
+
+func (q *claimsQ) Page(page pgdb.OffsetPageParams) data.ClaimsQ {
+	q.sel = page.ApplyTo(q.sel, createdAtColumnName, idColumnName)
+	return q
+}
+
+func (q *claimsQ) FilterByUserID(userIDs ...string) data.ClaimsQ {
+	q.sel = q.sel.Where(sq.Eq{userIDColumnName: userIDs})
+	return q
+}
+
+func (q *claimsQ) FilterBySchemaType(schemaTypes ...string) data.ClaimsQ {
+	q.sel = q.sel.Where(sq.Eq{schemaTypeColumnName: schemaTypes})
+	return q
+}
+
+func (q *claimsQ) FilterByRevoked(revoked bool) data.ClaimsQ {
+	q.sel = q.sel.Where(sq.Eq{revokedColumnName: revoked})
+	return q
+}
+
+func (q *claimsQ) FilterByExpired(expired bool) data.ClaimsQ {
+	q.sel = q.sel.Where(sq.Eq{expiredColumnName: expired})
+	return q
+}
+
+func (q *claimsQ) FilterByReceived(received bool) data.ClaimsQ {
+	receivedClaims, args, _ := sq.Select(claimIDColumnName).
+		From(claimOfferCredentialsTableName).
+		Where(sq.Eq{isReceivedColumnName: true}).
+		ToSql()
+
+	operator := "NOT IN"
+	if received {
+		operator = "IN"
+	}
+
+	q.sel = q.sel.Where(fmt.Sprintf("%s %s (%s)", idColumnName, operator, receivedClaims), args...)
+	return q
+}
+
+func (q *claimsQ) FilterByID(ids ...string) data.ClaimsQ {
+	q.sel = q.sel.Where(sq.Eq{idColumnName: ids})
+	return q
+}
+
+func (q *claimsQ) FilterByCreatedAt(from, to *time.Time) data.ClaimsQ {
+	q.sel = filterByTimeRange(q.sel, createdAtColumnName, from, to)
+	return q
+}
+
+func (q *claimsQ) FilterByExpiration(from, to *time.Time) data.ClaimsQ {
+	q.sel = filterByTimeRange(q.sel, expirationColumnName, from, to)
+	return q
+}
+
+func filterByTimeRange(sel sq.SelectBuilder, column string, from, to *time.Time) sq.SelectBuilder {
+	if from != nil {
+		sel = sel.Where(sq.GtOrEq{column: *from})
+	}
+	if to != nil {
+		sel = sel.Where(sq.Lt{column: *to})
+	}
+
+	return sel
+}
@@ -2,6 +2,8 @@ package pg
 
 import (
 	"database/sql"
+	"fmt"
+	"time"
 
 	sq "github.com/Masterminds/squirrel"
 	"github.com/fatih/structs"
@@ -13,22 +15,28 @@ import (
 
 const (
 	claimsOffersTableName = "claims_offers"
+
+	isReceivedColumnName = "is_received"
+	canceledAtColumnName = "canceled_at"
 )
 
+// claimsOffersQ is scoped by the identity, it neither selects nor changes the offers of the other ones.
 type claimsOffersQ struct {
-	db  *pgdb.DB
-	sel sq.SelectBuilder
+	db         *pgdb.DB
+	sel        sq.SelectBuilder
+	identityID uint64
 }
 
-func NewClaimsOffersQ(db *pgdb.DB) data.ClaimsOffersQ {
+func NewClaimsOffersQ(db *pgdb.DB, identityID uint64) data.ClaimsOffersQ {
 	return &claimsOffersQ{
-		db:  db,
-		sel: sq.Select("*").From(claimsOffersTableName),
+		db:         db,
+		sel:        sq.Select("*").From(claimsOffersTableName).Where(sq.Eq{identityIDColumnName: identityID}),
+		identityID: identityID,
 	}
 }
 
 func (q *claimsOffersQ) New() data.ClaimsOffersQ {
-	return NewClaimsOffersQ(q.db.Clone())
+	return NewClaimsOffersQ(q.db.Clone(), q.identityID)
 }
 
 func (q *claimsOffersQ) Get(id string) (*data.ClaimOffer, error) {
@@ -37,7 +45,7 @@ func (q *claimsOffersQ) Get(id string) (*data.ClaimOffer, error) {
 	err := q.db.Get(&result,
 		sq.Select("*").
 			From(claimsOffersTableName).
-			Where(sq.Eq{idColumnName: id}))
+			Where(sq.Eq{idColumnName: id, identityIDColumnName: q.identityID}))
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, nil
@@ -49,7 +57,10 @@ func (q *claimsOffersQ) Get(id string) (*data.ClaimOffer, error) {
 }
 
 func (q *claimsOffersQ) Insert(claimOffer *data.ClaimOffer) error {
-	err := q.db.Exec(sq.Insert(claimsOffersTableName).SetMap(structs.Map(claimOffer)))
+	clauses := structs.Map(claimOffer)
+	clauses[identityIDColumnName] = q.identityID
+
+	err := q.db.Exec(sq.Insert(claimsOffersTableName).SetMap(clauses))
 	if err != nil {
 		return errors.Wrap(err, "failed to insert rows")
 	}
@@ -61,7 +72,7 @@ func (q *claimsOffersQ) Update(claimOffer *data.ClaimOffer) error {
 	err := q.db.Exec(
 		sq.Update(claimsOffersTableName).
 			SetMap(structs.Map(claimOffer)).
-			Where(sq.Eq{idColumnName: claimOffer.ID}),
+			Where(sq.Eq{idColumnName: claimOffer.ID, identityIDColumnName: q.identityID}),
 	)
 	if err != nil {
 		return errors.Wrap(err, "failed to insert rows")
@@ -69,3 +80,111 @@ func (q *claimsOffersQ) Update(claimOffer *data.ClaimOffer) error {
 
 	return nil
 }
+
+func (q *claimsOffersQ) LockActive(id string, createdAfter time.Time) (bool, error) {
+	stmt := sq.Select(idColumnName).
+		From(claimsOffersTableName).
+		Where(sq.Eq{
+			idColumnName:         id,
+			canceledAtColumnName: nil,
+			identityIDColumnName: q.identityID,
+		}).
+		Suffix("FOR UPDATE")
+	if !createdAfter.IsZero() {
+		stmt = stmt.Where(sq.Gt{createdAtColumnName: createdAfter})
+	}
+
+	var lockedID string
+	err := q.db.Get(&lockedID, stmt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, nil
+		}
+		return false, errors.Wrap(err, "failed to lock rows")
+	}
+
+	return true, nil
+}
+
+func (q *claimsOffersQ) MarkReceived(id string) (bool, error) {
+	pendingCredentials, args, _ := sq.Select("1").
+		From(claimOfferCredentialsTableName).
+		Where(sq.Eq{
+			offerIDColumnName:    id,
+			isReceivedColumnName: false,
+			identityIDColumnName: q.identityID,
+		}).
+		ToSql()
+
+	return q.updateActive(id, isReceivedColumnName, true,
+		sq.Expr(fmt.Sprintf("NOT EXISTS (%s)", pendingCredentials), args...),
+	)
+}
+
+func (q *claimsOffersQ) Cancel(id string, canceledAt time.Time) (bool, error) {
+	return q.updateActive(id, canceledAtColumnName, canceledAt)
+}
+
+// updateActive sets the column of the offer only if it is neither received nor canceled,
+// the condition is checked by the update itself, so the concurrent updates can't both succeed.
+func (q *claimsOffersQ) updateActive(id, column string, value interface{}, conditions ...sq.Sqlizer) (bool, error) {
+	stmt := sq.Update(claimsOffersTableName).
+		Set(column, value).
+		Where(sq.Eq{
+			idColumnName:         id,
+			isReceivedColumnName: false,
+			canceledAtColumnName: nil,
+			identityIDColumnName: q.identityID,
+		})
+	for _, condition := range conditions {
+		stmt = stmt.Where(condition)
+	}
+
+	result, err := q.db.ExecWithResult(stmt)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to update rows")
+	}
+
+	updated, err := result.RowsAffected()
+	if err != nil {
+		return false, errors.Wrap(err, "failed to get updated rows count")
+	}
+
+	return updated > 0, nil
+}
+
+func (q *claimsOffersQ) DeleteCreatedBefore(createdAt time.Time) (int64, error) {
+	return q.delete(sq.Lt{createdAtColumnName: createdAt})
+}
+
+func (q *claimsOffersQ) DeleteCanceledBefore(canceledAt time.Time) (int64, error) {
+	return q.delete(sq.Lt{canceledAtColumnName: canceledAt})
+}
+
+// delete deletes the offers none of whose credentials were received, the received state of the claims
+// is stored in the offer credentials, so the delivered claims would be offered again otherwise.
+func (q *claimsOffersQ) delete(condition sq.Sqlizer) (int64, error) {
+	receivedCredentials := fmt.Sprintf(
+		"NOT EXISTS (SELECT 1 FROM %s WHERE %s.%s = %s.%s AND %s.%s)",
+		claimOfferCredentialsTableName,
+		claimOfferCredentialsTableName, offerIDColumnName, claimsOffersTableName, idColumnName,
+		claimOfferCredentialsTableName, isReceivedColumnName,
+	)
+
+	result, err := q.db.ExecWithResult(
+		sq.Delete(claimsOffersTableName).
+			Where(condition).
+			Where(sq.Eq{isReceivedColumnName: false, identityIDColumnName: q.identityID}).
+			Where(receivedCredentials),
+	)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to delete rows")
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to get deleted rows count")
+	}
+
+	return deleted, nil
+}
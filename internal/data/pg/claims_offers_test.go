@@ -0,0 +1,157 @@
+package pg
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"gitlab.com/distributed_lab/kit/pgdb"
+
+	"github.com/rarimo/issuer/internal/data"
+)
+
+func insertTestOffer(t *testing.T, db *pgdb.DB, identityID uint64, createdAt time.Time, claimIDs ...string) string {
+	t.Helper()
+
+	offer := data.ClaimOffer{
+		ID:        uuid.NewString(),
+		From:      "from",
+		To:        "to",
+		CreatedAt: createdAt,
+		ClaimID:   claimIDs[0],
+	}
+	if err := NewClaimsOffersQ(db, identityID).Insert(&offer); err != nil {
+		t.Fatalf("failed to insert offer: %v", err)
+	}
+
+	credentials := make([]data.ClaimOfferCredential, 0, len(claimIDs))
+	for _, claimID := range claimIDs {
+		credentials = append(credentials, data.ClaimOfferCredential{OfferID: offer.ID, ClaimID: claimID})
+	}
+	if err := NewClaimOfferCredentialsQ(db, identityID).Insert(credentials...); err != nil {
+		t.Fatalf("failed to insert offer credentials: %v", err)
+	}
+
+	return offer.ID
+}
+
+func TestClaimsOffersQLockActive(t *testing.T) {
+	db := newTestDB(t)
+	identityID := newTestIdentity(t, db)
+	otherIdentityID := newTestIdentity(t, db)
+	now := time.Now().UTC()
+
+	active := insertTestOffer(t, db, identityID, now, uuid.NewString())
+	expired := insertTestOffer(t, db, identityID, now.Add(-time.Hour), uuid.NewString())
+	canceled := insertTestOffer(t, db, identityID, now, uuid.NewString())
+	if ok, err := NewClaimsOffersQ(db, identityID).Cancel(canceled, now); err != nil || !ok {
+		t.Fatalf("failed to cancel offer: %v", err)
+	}
+
+	cases := []struct {
+		name         string
+		identityID   uint64
+		id           string
+		createdAfter time.Time
+		expected     bool
+	}{
+		{"active", identityID, active, now.Add(-time.Minute), true},
+		{"offers don't expire without ttl", identityID, expired, time.Time{}, true},
+		{"expired", identityID, expired, now.Add(-time.Minute), false},
+		{"canceled", identityID, canceled, time.Time{}, false},
+		{"offer of the other identity", otherIdentityID, active, time.Time{}, false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			db := db.Clone()
+			err := db.Transaction(func() error {
+				ok, err := NewClaimsOffersQ(db, tc.identityID).LockActive(tc.id, tc.createdAfter)
+				if err != nil {
+					return err
+				}
+				if ok != tc.expected {
+					t.Fatalf("got %t, want %t", ok, tc.expected)
+				}
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("failed to lock offer: %v", err)
+			}
+		})
+	}
+}
+
+func TestClaimsOffersQMarkReceived(t *testing.T) {
+	db := newTestDB(t)
+	identityID := newTestIdentity(t, db)
+	offersQ := NewClaimsOffersQ(db, identityID)
+	credentialsQ := NewClaimOfferCredentialsQ(db, identityID)
+
+	first, second := uuid.NewString(), uuid.NewString()
+	id := insertTestOffer(t, db, identityID, time.Now().UTC(), first, second)
+
+	if ok, err := credentialsQ.MarkReceived(id, first, time.Now().UTC()); err != nil || !ok {
+		t.Fatalf("failed to mark credential as received: %v", err)
+	}
+	if ok, err := offersQ.MarkReceived(id); err != nil || ok {
+		t.Fatalf("offer with the pending credential is marked as received: %v", err)
+	}
+
+	if ok, err := credentialsQ.MarkReceived(id, second, time.Now().UTC()); err != nil || !ok {
+		t.Fatalf("failed to mark credential as received: %v", err)
+	}
+	if ok, err := offersQ.MarkReceived(id); err != nil || !ok {
+		t.Fatalf("failed to mark offer as received: %v", err)
+	}
+
+	if ok, err := offersQ.Cancel(id, time.Now().UTC()); err != nil || ok {
+		t.Fatalf("received offer is canceled: %v", err)
+	}
+}
+
+// TestClaimsOffersQLockActiveBlocksCancel checks that the offer canceled concurrently with the delivery
+// is either not delivered or not canceled.
+func TestClaimsOffersQLockActiveBlocksCancel(t *testing.T) {
+	db := newTestDB(t)
+	identityID := newTestIdentity(t, db)
+
+	claimID := uuid.NewString()
+	id := insertTestOffer(t, db, identityID, time.Now().UTC(), claimID)
+
+	canceled := make(chan bool)
+	tx := db.Clone()
+	err := tx.Transaction(func() error {
+		ok, err := NewClaimsOffersQ(tx, identityID).LockActive(id, time.Time{})
+		if err != nil || !ok {
+			t.Fatalf("failed to lock offer: %v", err)
+		}
+
+		go func() {
+			ok, err := NewClaimsOffersQ(db.Clone(), identityID).Cancel(id, time.Now().UTC())
+			if err != nil {
+				t.Errorf("failed to cancel offer: %v", err)
+			}
+			canceled <- ok
+		}()
+
+		select {
+		case <-canceled:
+			t.Fatal("offer is canceled while it is locked")
+		case <-time.After(200 * time.Millisecond):
+		}
+
+		if _, err := NewClaimOfferCredentialsQ(tx, identityID).MarkReceived(id, claimID, time.Now().UTC()); err != nil {
+			return err
+		}
+		_, err = NewClaimsOffersQ(tx, identityID).MarkReceived(id)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("failed to deliver offer: %v", err)
+	}
+
+	if <-canceled {
+		t.Fatal("delivered offer is canceled")
+	}
+}
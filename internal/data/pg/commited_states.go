@@ -3,6 +3,7 @@ package pg
 import (
 	"database/sql"
 	"fmt"
+	"time"
 
 	sq "github.com/Masterminds/squirrel"
 	"github.com/fatih/structs"
@@ -18,24 +19,28 @@ const (
 	createdAtColumnName      = "created_at"
 	statusColumnName         = "status"
 	isGenesisColumnName      = "is_genesis"
+	identityIDColumnName     = "identity_id"
 
 	descCreatedAtColumnName = "-" + createdAtColumnName
 )
 
+// committedStatesQ is scoped by the identity, it neither selects nor changes the states of the other ones.
 type committedStatesQ struct {
-	db  *pgdb.DB
-	sel sq.SelectBuilder
+	db         *pgdb.DB
+	sel        sq.SelectBuilder
+	identityID uint64
 }
 
-func NewCommittedStateQ(db *pgdb.DB) data.CommittedStatesQ {
+func NewCommittedStateQ(db *pgdb.DB, identityID uint64) data.CommittedStatesQ {
 	return &committedStatesQ{
-		db:  db,
-		sel: sq.Select("*").From(committedStatesTableName),
+		db:         db,
+		sel:        sq.Select("*").From(committedStatesTableName).Where(sq.Eq{identityIDColumnName: identityID}),
+		identityID: identityID,
 	}
 }
 
 func (q *committedStatesQ) New() data.CommittedStatesQ {
-	return NewCommittedStateQ(q.db.Clone())
+	return NewCommittedStateQ(q.db.Clone(), q.identityID)
 }
 
 func (q *committedStatesQ) Select() ([]data.CommittedState, error) {
@@ -57,7 +62,7 @@ func (q *committedStatesQ) Get(id uint64) (*data.CommittedState, error) {
 	err := q.db.Get(&result,
 		sq.Select("*").
 			From(committedStatesTableName).
-			Where(sq.Eq{idColumnName: id}),
+			Where(sq.Eq{idColumnName: id, identityIDColumnName: q.identityID}),
 	)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -90,13 +95,29 @@ func (q *committedStatesQ) GetLatest() (*data.CommittedState, error) {
 	return &result[0], nil
 }
 
+func (q *committedStatesQ) GetEarliest() (*data.CommittedState, error) {
+	result, err := q.Sort(pgdb.SortedOffsetPageParams{
+		Limit: 1,
+		Sort:  []string{createdAtColumnName},
+	}).Select()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to select rows")
+	}
+
+	if len(result) == 0 {
+		return nil, nil
+	}
+
+	return &result[0], nil
+}
+
 func (q *committedStatesQ) GetGenesis() (*data.CommittedState, error) {
 	var result data.CommittedState
 
 	err := q.db.Get(&result,
 		sq.Select("*").
 			From(committedStatesTableName).
-			Where(sq.Eq{isGenesisColumnName: true}),
+			Where(sq.Eq{isGenesisColumnName: true, identityIDColumnName: q.identityID}),
 	)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -109,15 +130,19 @@ func (q *committedStatesQ) GetGenesis() (*data.CommittedState, error) {
 }
 
 func (q *committedStatesQ) Insert(committedState *data.CommittedState) error {
+	clauses := structs.Map(committedState)
+	clauses[identityIDColumnName] = q.identityID
+
 	err := q.db.Get(&committedState.ID,
 		sq.Insert(committedStatesTableName).
-			SetMap(structs.Map(committedState)).
+			SetMap(clauses).
 			Suffix(fmt.Sprintf("returning %s", idColumnName)),
 	)
 	if err != nil {
 		return errors.Wrap(err, "failed to insert rows")
 	}
 
+	committedState.IdentityID = q.identityID
 	return nil
 }
 
@@ -125,7 +150,7 @@ func (q *committedStatesQ) Update(committedState *data.CommittedState) error {
 	err := q.db.Exec(
 		sq.Update(committedStatesTableName).
 			SetMap(structs.Map(committedState)).
-			Where(sq.Eq{idColumnName: committedState.ID}),
+			Where(sq.Eq{idColumnName: committedState.ID, identityIDColumnName: q.identityID}),
 	)
 	if err != nil {
 		return errors.Wrap(err, "failed to update rows")
@@ -134,7 +159,12 @@ func (q *committedStatesQ) Update(committedState *data.CommittedState) error {
 	return nil
 }
 
-func (q *committedStatesQ) WhereStatus(status data.Status) data.CommittedStatesQ {
-	q.sel = q.sel.Where(sq.Eq{statusColumnName: status})
+func (q *committedStatesQ) WhereStatus(statuses ...data.Status) data.CommittedStatesQ {
+	q.sel = q.sel.Where(sq.Eq{statusColumnName: statuses})
+	return q
+}
+
+func (q *committedStatesQ) WhereCreatedAtFrom(from time.Time) data.CommittedStatesQ {
+	q.sel = q.sel.Where(sq.GtOrEq{createdAtColumnName: from})
 	return q
 }
@@ -0,0 +1,141 @@
+package pg
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/fatih/structs"
+	"github.com/pkg/errors"
+	"gitlab.com/distributed_lab/kit/pgdb"
+
+	"github.com/rarimo/issuer/internal/data"
+)
+
+const (
+	idempotencyKeysTableName = "idempotency_keys"
+	statusCodeColumnName     = "status_code"
+	contentTypeColumnName    = "content_type"
+	responseColumnName       = "response"
+	completedAtColumnName    = "completed_at"
+)
+
+// lockIdempotencyKeySuffix takes over the conflicting key only if it is expired or abandoned,
+// otherwise nothing is affected.
+var lockIdempotencyKeySuffix = fmt.Sprintf(
+	"ON CONFLICT (%[5]s, %[2]s) DO UPDATE SET "+
+		"request_hash = EXCLUDED.request_hash, status_code = NULL, content_type = '', response = NULL, "+
+		"created_at = EXCLUDED.created_at, completed_at = NULL "+
+		"WHERE %[1]s.%[3]s < ? OR (%[1]s.%[4]s IS NULL AND %[1]s.%[3]s < ?)",
+	idempotencyKeysTableName, idColumnName, createdAtColumnName, completedAtColumnName, identityIDColumnName,
+)
+
+// idempotencyKeysQ is scoped by the identity, the keys are chosen by the clients, so the same key
+// of the other identity is the other one.
+type idempotencyKeysQ struct {
+	db         *pgdb.DB
+	identityID uint64
+}
+
+func NewIdempotencyKeysQ(db *pgdb.DB, identityID uint64) data.IdempotencyKeysQ {
+	return &idempotencyKeysQ{
+		db:         db,
+		identityID: identityID,
+	}
+}
+
+func (q *idempotencyKeysQ) New() data.IdempotencyKeysQ {
+	return NewIdempotencyKeysQ(q.db.Clone(), q.identityID)
+}
+
+func (q *idempotencyKeysQ) Get(id string) (*data.IdempotencyKey, error) {
+	var result data.IdempotencyKey
+
+	err := q.db.Get(&result,
+		sq.Select("*").
+			From(idempotencyKeysTableName).
+			Where(sq.Eq{idColumnName: id, identityIDColumnName: q.identityID}),
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, errors.Wrap(err, "failed to select rows")
+	}
+
+	return &result, nil
+}
+
+func (q *idempotencyKeysQ) Lock(key *data.IdempotencyKey, expiredBefore, abandonedBefore time.Time) (bool, error) {
+	clauses := structs.Map(key)
+	clauses[identityIDColumnName] = q.identityID
+
+	result, err := q.db.ExecWithResult(
+		sq.Insert(idempotencyKeysTableName).
+			SetMap(clauses).
+			Suffix(lockIdempotencyKeySuffix, expiredBefore, abandonedBefore),
+	)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to insert rows")
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, errors.Wrap(err, "failed to get inserted rows count")
+	}
+
+	return affected > 0, nil
+}
+
+func (q *idempotencyKeysQ) Complete(
+	id string,
+	statusCode int,
+	contentType string,
+	response []byte,
+	completedAt time.Time,
+) error {
+	err := q.db.Exec(
+		sq.Update(idempotencyKeysTableName).
+			Set(statusCodeColumnName, statusCode).
+			Set(contentTypeColumnName, contentType).
+			Set(responseColumnName, response).
+			Set(completedAtColumnName, completedAt).
+			Where(sq.Eq{idColumnName: id, identityIDColumnName: q.identityID}),
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to update rows")
+	}
+
+	return nil
+}
+
+func (q *idempotencyKeysQ) Delete(id string) error {
+	err := q.db.Exec(
+		sq.Delete(idempotencyKeysTableName).
+			Where(sq.Eq{idColumnName: id, identityIDColumnName: q.identityID}),
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to delete rows")
+	}
+
+	return nil
+}
+
+func (q *idempotencyKeysQ) DeleteCreatedBefore(createdAt time.Time) (int64, error) {
+	result, err := q.db.ExecWithResult(
+		sq.Delete(idempotencyKeysTableName).
+			Where(sq.Lt{createdAtColumnName: createdAt}).
+			Where(sq.Eq{identityIDColumnName: q.identityID}),
+	)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to delete rows")
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to get deleted rows count")
+	}
+
+	return deleted, nil
+}
@@ -0,0 +1,88 @@
+package pg
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/rarimo/issuer/internal/data"
+)
+
+func TestIdempotencyKeysQLock(t *testing.T) {
+	db := newTestDB(t)
+	now := time.Now().UTC()
+	expiredBefore, abandonedBefore := now.Add(-time.Hour), now.Add(-time.Minute)
+
+	cases := []struct {
+		name        string
+		createdAt   time.Time
+		isCompleted bool
+		expected    bool
+	}{
+		{"completed key is replayed", now.Add(-30 * time.Minute), true, false},
+		{"key is in progress", now.Add(-30 * time.Second), false, false},
+		{"abandoned key is taken over", now.Add(-30 * time.Minute), false, true},
+		{"expired key is taken over", now.Add(-2 * time.Hour), true, true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			q := NewIdempotencyKeysQ(db, newTestIdentity(t, db))
+			key := uuid.NewString()
+
+			ok, err := q.Lock(&data.IdempotencyKey{ID: key, RequestHash: []byte("first"), CreatedAt: tc.createdAt},
+				expiredBefore, abandonedBefore)
+			if err != nil || !ok {
+				t.Fatalf("failed to lock new key: %v", err)
+			}
+			if tc.isCompleted {
+				if err := q.Complete(key, 201, "application/json", []byte(`{}`), tc.createdAt); err != nil {
+					t.Fatalf("failed to complete key: %v", err)
+				}
+			}
+
+			ok, err = q.Lock(&data.IdempotencyKey{ID: key, RequestHash: []byte("second"), CreatedAt: now},
+				expiredBefore, abandonedBefore)
+			if err != nil {
+				t.Fatalf("failed to lock key: %v", err)
+			}
+			if ok != tc.expected {
+				t.Fatalf("got locked %t, want %t", ok, tc.expected)
+			}
+
+			stored, err := q.Get(key)
+			if err != nil {
+				t.Fatalf("failed to get key: %v", err)
+			}
+
+			if !tc.expected {
+				if string(stored.RequestHash) != "first" || (stored.StatusCode != nil) != tc.isCompleted {
+					t.Fatal("key that isn't taken over is changed")
+				}
+				return
+			}
+
+			// the taken over key is in progress of the new request
+			if string(stored.RequestHash) != "second" || stored.StatusCode != nil || stored.Response != nil ||
+				stored.CompletedAt != nil || stored.CreatedAt.Before(abandonedBefore) {
+				t.Fatalf("taken over key isn't reset: %+v", stored)
+			}
+		})
+	}
+}
+
+func TestIdempotencyKeysQScopedByIdentity(t *testing.T) {
+	db := newTestDB(t)
+	now := time.Now().UTC()
+	key := uuid.NewString()
+
+	for i := 0; i < 2; i++ {
+		q := NewIdempotencyKeysQ(db, newTestIdentity(t, db))
+
+		ok, err := q.Lock(&data.IdempotencyKey{ID: key, CreatedAt: now}, now.Add(-time.Hour), now.Add(-time.Minute))
+		if err != nil || !ok {
+			t.Fatalf("key of the identity %d collides with the other one: %v", i, err)
+		}
+	}
+}
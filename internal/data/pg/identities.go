@@ -0,0 +1,121 @@
+package pg
+
+import (
+	"database/sql"
+	"fmt"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/fatih/structs"
+	"github.com/pkg/errors"
+	"gitlab.com/distributed_lab/kit/pgdb"
+
+	"github.com/rarimo/issuer/internal/data"
+)
+
+const (
+	identitiesTableName = "identities"
+	nameColumnName      = "name"
+	didColumnName       = "did"
+)
+
+type identitiesQ struct {
+	db *pgdb.DB
+}
+
+func NewIdentitiesQ(db *pgdb.DB) data.IdentitiesQ {
+	return &identitiesQ{
+		db: db,
+	}
+}
+
+func (q *identitiesQ) New() data.IdentitiesQ {
+	return NewIdentitiesQ(q.db.Clone())
+}
+
+func (q *identitiesQ) Insert(identity *data.Identity) (bool, error) {
+	var ids []uint64
+
+	err := q.db.Select(&ids,
+		sq.Insert(identitiesTableName).
+			SetMap(structs.Map(identity)).
+			Suffix(fmt.Sprintf("ON CONFLICT (%s) DO NOTHING RETURNING %s", nameColumnName, idColumnName)),
+	)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to insert rows")
+	}
+	if len(ids) == 0 {
+		return false, nil
+	}
+
+	identity.ID = ids[0]
+	return true, nil
+}
+
+func (q *identitiesQ) Get(id uint64) (*data.Identity, error) {
+	return q.get(sq.Eq{idColumnName: id})
+}
+
+func (q *identitiesQ) GetByDID(did string) (*data.Identity, error) {
+	return q.get(sq.Eq{didColumnName: did})
+}
+
+func (q *identitiesQ) GetByName(name string) (*data.Identity, error) {
+	return q.get(sq.Eq{nameColumnName: name})
+}
+
+func (q *identitiesQ) get(condition sq.Sqlizer) (*data.Identity, error) {
+	var result data.Identity
+
+	err := q.db.Get(&result, sq.Select("*").From(identitiesTableName).Where(condition))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, errors.Wrap(err, "failed to select rows")
+	}
+
+	return &result, nil
+}
+
+func (q *identitiesQ) Select() ([]data.Identity, error) {
+	var result []data.Identity
+
+	err := q.db.Select(&result, sq.Select("*").From(identitiesTableName).OrderBy(idColumnName))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to select rows")
+	}
+
+	return result, nil
+}
+
+func (q *identitiesQ) SetDID(id uint64, did string) error {
+	err := q.db.Exec(
+		sq.Update(identitiesTableName).
+			Set(didColumnName, did).
+			Where(sq.Eq{idColumnName: id}),
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to update rows")
+	}
+
+	return nil
+}
+
+func (q *identitiesQ) Lock(id uint64) error {
+	var lockedID uint64
+
+	err := q.db.Get(&lockedID,
+		sq.Select(idColumnName).
+			From(identitiesTableName).
+			Where(sq.Eq{idColumnName: id}).
+			Suffix("FOR UPDATE"),
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return errors.Errorf("identity %d doesn't exist", id)
+		}
+		return errors.Wrap(err, "failed to select rows")
+	}
+
+	return nil
+}
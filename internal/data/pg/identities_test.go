@@ -0,0 +1,154 @@
+package pg
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	core "github.com/iden3/go-iden3-core"
+	"gitlab.com/distributed_lab/kit/pgdb"
+
+	"github.com/rarimo/issuer/internal/data"
+)
+
+func insertTestClaim(t *testing.T, db *pgdb.DB, identityID uint64, revNonce uint64) *data.Claim {
+	t.Helper()
+
+	coreClaim, err := core.NewClaim(core.SchemaHash{})
+	if err != nil {
+		t.Fatalf("failed to create core claim: %v", err)
+	}
+
+	claim := &data.Claim{
+		ID:        uuid.NewString(),
+		ClaimType: "KYCAgeCredential",
+		CoreClaim: data.NewCoreClaim(coreClaim),
+		UserID:    "user",
+		CreatedAt: time.Now().UTC(),
+		RevNonce:  data.RevocationNonce(revNonce),
+	}
+	if err := NewClaimsQ(db, identityID).Insert(claim); err != nil {
+		t.Fatalf("failed to insert claim: %v", err)
+	}
+
+	return claim
+}
+
+// TestQueriesScopedByIdentity checks that the identities hosted by the instance
+// neither see nor change the data of each other.
+func TestQueriesScopedByIdentity(t *testing.T) {
+	db := newTestDB(t)
+	identityID := newTestIdentity(t, db)
+	otherIdentityID := newTestIdentity(t, db)
+	now := time.Now().UTC()
+
+	t.Run("claims", func(t *testing.T) {
+		claim := insertTestClaim(t, db, identityID, 1)
+
+		if got, err := NewClaimsQ(db, otherIdentityID).Get(claim.ID); err != nil || got != nil {
+			t.Fatalf("claim of the other identity is got: %v", err)
+		}
+		if got, err := NewClaimsQ(db, otherIdentityID).GetByRevNonce(1); err != nil || got != nil {
+			t.Fatalf("claim of the other identity is got by rev nonce: %v", err)
+		}
+		selected, err := NewClaimsQ(db, otherIdentityID).FilterByUserID(claim.UserID).Select()
+		if err != nil || len(selected) != 0 {
+			t.Fatalf("claims of the other identity are selected: %v", err)
+		}
+
+		if got, err := NewClaimsQ(db, identityID).Get(claim.ID); err != nil || got == nil {
+			t.Fatalf("claim of the identity isn't got: %v", err)
+		}
+	})
+
+	t.Run("claim revocations", func(t *testing.T) {
+		// the revocation nonces are unique only within the identity
+		for _, id := range []uint64{identityID, otherIdentityID} {
+			err := NewClaimRevocationsQ(db, id).Insert(&data.ClaimRevocation{
+				RevNonce:  2,
+				Reason:    data.RevocationReasonUnspecified,
+				Operator:  "operator",
+				RevokedAt: now,
+			})
+			if err != nil {
+				t.Fatalf("failed to insert claim revocation: %v", err)
+			}
+		}
+
+		if got, err := NewClaimRevocationsQ(db, newTestIdentity(t, db)).Get(2); err != nil || got != nil {
+			t.Fatalf("claim revocation of the other identity is got: %v", err)
+		}
+	})
+
+	t.Run("claim offers", func(t *testing.T) {
+		offerID := insertTestOffer(t, db, identityID, now, uuid.NewString())
+
+		if got, err := NewClaimsOffersQ(db, otherIdentityID).Get(offerID); err != nil || got != nil {
+			t.Fatalf("offer of the other identity is got: %v", err)
+		}
+		if ok, err := NewClaimsOffersQ(db, otherIdentityID).Cancel(offerID, now); err != nil || ok {
+			t.Fatalf("offer of the other identity is canceled: %v", err)
+		}
+		if ok, err := NewClaimsOffersQ(db, otherIdentityID).MarkReceived(offerID); err != nil || ok {
+			t.Fatalf("offer of the other identity is marked received: %v", err)
+		}
+	})
+
+	t.Run("api keys", func(t *testing.T) {
+		apiKey := &data.APIKey{
+			ID:        uuid.NewString(),
+			Name:      "client",
+			KeyPrefix: "isk_test",
+			KeyHash:   []byte(uuid.NewString()),
+			Scopes:    []string{"claims:read"},
+			CreatedAt: now,
+		}
+		if err := NewAPIKeysQ(db, identityID).Insert(apiKey); err != nil {
+			t.Fatalf("failed to insert api key: %v", err)
+		}
+
+		// the key of one identity doesn't authenticate the clients of the other one
+		if got, err := NewAPIKeysQ(db, otherIdentityID).GetByHash(apiKey.KeyHash); err != nil || got != nil {
+			t.Fatalf("api key of the other identity is got: %v", err)
+		}
+		if ok, err := NewAPIKeysQ(db, otherIdentityID).Revoke(apiKey.ID, now); err != nil || ok {
+			t.Fatalf("api key of the other identity is revoked: %v", err)
+		}
+	})
+
+	t.Run("audit events", func(t *testing.T) {
+		err := NewAuditEventsQ(db, identityID).Insert(data.AuditEvent{
+			EventType: "claim.issued",
+			ActorType: "api_key",
+			CreatedAt: now,
+		})
+		if err != nil {
+			t.Fatalf("failed to insert audit event: %v", err)
+		}
+
+		events, err := NewAuditEventsQ(db, otherIdentityID).Select()
+		if err != nil || len(events) != 0 {
+			t.Fatalf("audit events of the other identity are selected: %v", err)
+		}
+	})
+
+	t.Run("trees", func(t *testing.T) {
+		// the nodes are addressed by their content, so the identities store the same keys
+		key := []byte(uuid.NewString())
+		if err := NewTreeStorageQ(db, "claims_tree", identityID).Insert(key, []byte("value")); err != nil {
+			t.Fatalf("failed to insert tree node: %v", err)
+		}
+
+		if value, err := NewTreeStorageQ(db, "claims_tree", otherIdentityID).Get(key); err != nil || value != nil {
+			t.Fatalf("tree node of the other identity is got: %v", err)
+		}
+		if err := NewTreeStorageQ(db, "claims_tree", otherIdentityID).Upsert(key, []byte("other")); err != nil {
+			t.Fatalf("failed to upsert tree node: %v", err)
+		}
+
+		value, err := NewTreeStorageQ(db, "claims_tree", identityID).Get(key)
+		if err != nil || string(value) != "value" {
+			t.Fatalf("tree node of the identity is changed by the other one: %q %v", value, err)
+		}
+	})
+}
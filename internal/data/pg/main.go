@@ -10,30 +10,86 @@ var sortColumns = map[string]string{
 	createdAtColumnName: createdAtColumnName,
 }
 
+// masterQ scopes the queries of the identity data by the identity, the used tokens, the webhook events
+// and the schemas are shared by all the hosted identities.
 type masterQ struct {
-	db *pgdb.DB
+	db         *pgdb.DB
+	identityID uint64
 }
 
-func NewMasterQ(db *pgdb.DB) data.MasterQ {
+func NewMasterQ(db *pgdb.DB, identityID uint64) data.MasterQ {
 	return &masterQ{
-		db: db,
+		db:         db,
+		identityID: identityID,
 	}
 }
 
 func (q *masterQ) New() data.MasterQ {
-	return NewMasterQ(q.db.Clone())
+	return NewMasterQ(q.db.Clone(), q.identityID)
 }
 
 func (q *masterQ) ClaimsQ() data.ClaimsQ {
-	return NewClaimsQ(q.db)
+	return NewClaimsQ(q.db, q.identityID)
+}
+
+func (q *masterQ) ClaimVersionsQ() data.ClaimVersionsQ {
+	return NewClaimVersionsQ(q.db, q.identityID)
+}
+
+func (q *masterQ) ClaimRevocationsQ() data.ClaimRevocationsQ {
+	return NewClaimRevocationsQ(q.db, q.identityID)
 }
 
 func (q *masterQ) CommittedStatesQ() data.CommittedStatesQ {
-	return NewCommittedStateQ(q.db)
+	return NewCommittedStateQ(q.db, q.identityID)
 }
 
 func (q *masterQ) ClaimsOffersQ() data.ClaimsOffersQ {
-	return NewClaimsOffersQ(q.db)
+	return NewClaimsOffersQ(q.db, q.identityID)
+}
+
+func (q *masterQ) ClaimOfferCredentialsQ() data.ClaimOfferCredentialsQ {
+	return NewClaimOfferCredentialsQ(q.db, q.identityID)
+}
+
+func (q *masterQ) ClaimSchemasQ() data.ClaimSchemasQ {
+	return NewClaimSchemasQ(q.db)
+}
+
+func (q *masterQ) SchemaDocumentsQ() data.SchemaDocumentsQ {
+	return NewSchemaDocumentsQ(q.db)
+}
+
+func (q *masterQ) WebhookEventsQ() data.WebhookEventsQ {
+	return NewWebhookEventsQ(q.db)
+}
+
+func (q *masterQ) IdempotencyKeysQ() data.IdempotencyKeysQ {
+	return NewIdempotencyKeysQ(q.db, q.identityID)
+}
+
+func (q *masterQ) APIKeysQ() data.APIKeysQ {
+	return NewAPIKeysQ(q.db, q.identityID)
+}
+
+func (q *masterQ) OfferAuthRequestsQ() data.OfferAuthRequestsQ {
+	return NewOfferAuthRequestsQ(q.db, q.identityID)
+}
+
+func (q *masterQ) UsedTokensQ() data.UsedTokensQ {
+	return NewUsedTokensQ(q.db)
+}
+
+func (q *masterQ) AuditEventsQ() data.AuditEventsQ {
+	return NewAuditEventsQ(q.db, q.identityID)
+}
+
+func (q *masterQ) IdentitiesQ() data.IdentitiesQ {
+	return NewIdentitiesQ(q.db)
+}
+
+func (q *masterQ) TreeStorageQ(treeName string) data.TreeStorageQ {
+	return NewTreeStorageQ(q.db, treeName, q.identityID)
 }
 
 func (q *masterQ) Transaction(fn func() error) error {
@@ -0,0 +1,64 @@
+package pg
+
+import (
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	migrate "github.com/rubenv/sql-migrate"
+	"gitlab.com/distributed_lab/kit/pgdb"
+
+	"github.com/rarimo/issuer/internal/assets"
+	"github.com/rarimo/issuer/internal/data"
+)
+
+// testDBURLEnv is the URL of the postgres database the queries are tested against, the tests are skipped
+// if it isn't set. The migrations are applied to it, the tests don't clean it up, since they scope their
+// data by the identities they create.
+const testDBURLEnv = "ISSUER_TEST_DB_URL"
+
+var (
+	testDB     *pgdb.DB
+	testDBErr  error
+	testDBOnce sync.Once
+)
+
+func newTestDB(t *testing.T) *pgdb.DB {
+	t.Helper()
+
+	url := os.Getenv(testDBURLEnv)
+	if url == "" {
+		t.Skipf("%s isn't set", testDBURLEnv)
+	}
+
+	testDBOnce.Do(func() {
+		testDB, testDBErr = pgdb.Open(pgdb.Opts{URL: url, MaxOpenConnections: 10, MaxIdleConnections: 10})
+		if testDBErr != nil {
+			return
+		}
+
+		_, testDBErr = migrate.Exec(testDB.RawDB(), "postgres", &migrate.EmbedFileSystemMigrationSource{
+			FileSystem: assets.Migrations,
+			Root:       "migrations",
+		}, migrate.Up)
+	})
+	if testDBErr != nil {
+		t.Fatalf("failed to prepare test db: %v", testDBErr)
+	}
+
+	return testDB.Clone()
+}
+
+// newTestIdentity inserts the identity the test data is scoped by.
+func newTestIdentity(t *testing.T, db *pgdb.DB) uint64 {
+	t.Helper()
+
+	identity := data.Identity{Name: "test-" + uuid.NewString(), CreatedAt: time.Now().UTC()}
+	if _, err := NewIdentitiesQ(db).Insert(&identity); err != nil {
+		t.Fatalf("failed to insert identity: %v", err)
+	}
+
+	return identity.ID
+}
@@ -0,0 +1,74 @@
+package pg
+
+import (
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/fatih/structs"
+	"github.com/pkg/errors"
+	"gitlab.com/distributed_lab/kit/pgdb"
+
+	"github.com/rarimo/issuer/internal/data"
+)
+
+const (
+	offerAuthRequestsTableName = "offer_auth_requests"
+
+	expiresAtColumnName = "expires_at"
+)
+
+// offerAuthRequestsQ is scoped by the identity, it neither selects nor changes the requests of the other ones.
+type offerAuthRequestsQ struct {
+	db         *pgdb.DB
+	identityID uint64
+}
+
+func NewOfferAuthRequestsQ(db *pgdb.DB, identityID uint64) data.OfferAuthRequestsQ {
+	return &offerAuthRequestsQ{
+		db:         db,
+		identityID: identityID,
+	}
+}
+
+func (q *offerAuthRequestsQ) New() data.OfferAuthRequestsQ {
+	return NewOfferAuthRequestsQ(q.db.Clone(), q.identityID)
+}
+
+func (q *offerAuthRequestsQ) Insert(authRequest *data.OfferAuthRequest) (bool, error) {
+	clauses := structs.Map(authRequest)
+	clauses[identityIDColumnName] = q.identityID
+
+	result, err := q.db.ExecWithResult(
+		sq.Insert(offerAuthRequestsTableName).
+			SetMap(clauses).
+			Suffix("ON CONFLICT (id) DO NOTHING"),
+	)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to insert rows")
+	}
+
+	inserted, err := result.RowsAffected()
+	if err != nil {
+		return false, errors.Wrap(err, "failed to get inserted rows count")
+	}
+
+	return inserted > 0, nil
+}
+
+func (q *offerAuthRequestsQ) DeleteExpiredBefore(expiresAt time.Time) (int64, error) {
+	result, err := q.db.ExecWithResult(
+		sq.Delete(offerAuthRequestsTableName).
+			Where(sq.Lt{expiresAtColumnName: expiresAt}).
+			Where(sq.Eq{identityIDColumnName: q.identityID}),
+	)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to delete rows")
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to get deleted rows count")
+	}
+
+	return deleted, nil
+}
@@ -0,0 +1,40 @@
+package pg
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/rarimo/issuer/internal/data"
+)
+
+func TestOfferAuthRequestsQInsert(t *testing.T) {
+	db := newTestDB(t)
+	q := NewOfferAuthRequestsQ(db, newTestIdentity(t, db))
+
+	now := time.Now().UTC()
+	authRequest := &data.OfferAuthRequest{
+		ID:           uuid.NewString(),
+		UserID:       "user",
+		SchemaType:   "KYCAgeCredential",
+		CreatedAt:    now,
+		ExpiresAt:    now.Add(time.Minute),
+		AuthorizedAt: &now,
+	}
+
+	if ok, err := q.Insert(authRequest); err != nil || !ok {
+		t.Fatalf("failed to insert offer auth request: %v", err)
+	}
+	// the request is answered only once
+	if ok, err := q.Insert(authRequest); err != nil || ok {
+		t.Fatalf("answered offer auth request is inserted again: %v", err)
+	}
+
+	if _, err := q.DeleteExpiredBefore(now.Add(2 * time.Minute)); err != nil {
+		t.Fatalf("failed to delete expired offer auth requests: %v", err)
+	}
+	if ok, err := q.Insert(authRequest); err != nil || !ok {
+		t.Fatalf("expired offer auth request isn't deleted: %v", err)
+	}
+}
@@ -0,0 +1,72 @@
+package pg
+
+import (
+	"database/sql"
+	"fmt"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/fatih/structs"
+	"github.com/pkg/errors"
+	"gitlab.com/distributed_lab/kit/pgdb"
+
+	"github.com/rarimo/issuer/internal/data"
+)
+
+const (
+	schemaDocumentsTableName = "schema_documents"
+	urlColumnName            = "url"
+	bodyColumnName           = "body"
+	hashColumnName           = "hash"
+	fetchedAtColumnName      = "fetched_at"
+)
+
+type schemaDocumentsQ struct {
+	db *pgdb.DB
+}
+
+func NewSchemaDocumentsQ(db *pgdb.DB) data.SchemaDocumentsQ {
+	return &schemaDocumentsQ{
+		db: db,
+	}
+}
+
+func (q *schemaDocumentsQ) New() data.SchemaDocumentsQ {
+	return NewSchemaDocumentsQ(q.db.Clone())
+}
+
+func (q *schemaDocumentsQ) Get(url string) (*data.SchemaDocument, error) {
+	var result data.SchemaDocument
+
+	err := q.db.Get(&result,
+		sq.Select("*").
+			From(schemaDocumentsTableName).
+			Where(sq.Eq{urlColumnName: url}))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, errors.Wrap(err, "failed to select rows")
+	}
+
+	return &result, nil
+}
+
+func (q *schemaDocumentsQ) Upsert(document *data.SchemaDocument) error {
+	err := q.db.Exec(
+		sq.Insert(schemaDocumentsTableName).
+			SetMap(structs.Map(document)).
+			Suffix(
+				fmt.Sprintf("ON CONFLICT (%s) DO UPDATE SET %s = EXCLUDED.%s, %s = EXCLUDED.%s, %s = EXCLUDED.%s",
+					urlColumnName,
+					bodyColumnName, bodyColumnName,
+					hashColumnName, hashColumnName,
+					fetchedAtColumnName, fetchedAtColumnName,
+				),
+			),
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to insert rows")
+	}
+
+	return nil
+}
@@ -16,26 +16,34 @@ const (
 	valueColumnName = "value"
 )
 
+// treeStorageQ stores the nodes of the tree of the identity, the identities share the tree tables.
 type treeStorageQ struct {
-	db       *pgdb.DB
-	treeName string
+	db         *pgdb.DB
+	treeName   string
+	identityID uint64
 }
 
-func NewTreeStorageQ(db *pgdb.DB, treeName string) data.TreeStorageQ {
+func NewTreeStorageQ(db *pgdb.DB, treeName string, identityID uint64) data.TreeStorageQ {
 	return &treeStorageQ{
-		db:       db,
-		treeName: treeName,
+		db:         db,
+		treeName:   treeName,
+		identityID: identityID,
 	}
 }
 
 func (q *treeStorageQ) New() data.TreeStorageQ {
-	return NewTreeStorageQ(q.db.Clone(), q.treeName)
+	return NewTreeStorageQ(q.db.Clone(), q.treeName, q.identityID)
 }
 
+// Insert skips the node that is already stored, the nodes are addressed by the hash of their content,
+// so the node is stored again when the tree returns to one of its previous states after the deletion.
 func (q *treeStorageQ) Insert(key, value []byte) error {
 	err := q.db.Exec(
 		sq.Insert(q.treeName).
-			SetMap(map[string]interface{}{keyColumnName: key, valueColumnName: value}),
+			SetMap(map[string]interface{}{
+				identityIDColumnName: q.identityID, keyColumnName: key, valueColumnName: value,
+			}).
+			Suffix(fmt.Sprintf("ON CONFLICT (%s, %s) DO NOTHING", identityIDColumnName, keyColumnName)),
 	)
 	if err != nil {
 		return errors.Wrap(err, "failed to insert rows")
@@ -47,10 +55,12 @@ func (q *treeStorageQ) Insert(key, value []byte) error {
 func (q *treeStorageQ) Upsert(key, value []byte) error {
 	err := q.db.Exec(
 		sq.Insert(q.treeName).
-			SetMap(map[string]interface{}{keyColumnName: key, valueColumnName: value}).
+			SetMap(map[string]interface{}{
+				identityIDColumnName: q.identityID, keyColumnName: key, valueColumnName: value,
+			}).
 			Suffix(
-				fmt.Sprintf("ON CONFLICT (%s) DO UPDATE SET %s = EXCLUDED.%s",
-					keyColumnName, valueColumnName, valueColumnName),
+				fmt.Sprintf("ON CONFLICT (%s, %s) DO UPDATE SET %s = EXCLUDED.%s",
+					identityIDColumnName, keyColumnName, valueColumnName, valueColumnName),
 			),
 	)
 	if err != nil {
@@ -66,7 +76,7 @@ func (q *treeStorageQ) Get(key []byte) ([]byte, error) {
 	err := q.db.
 		Get(&result, sq.Select("*").
 			From(q.treeName).
-			Where(sq.Eq{keyColumnName: key}))
+			Where(sq.Eq{identityIDColumnName: q.identityID, keyColumnName: key}))
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, nil
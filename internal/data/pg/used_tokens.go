@@ -0,0 +1,63 @@
+package pg
+
+import (
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/fatih/structs"
+	"github.com/pkg/errors"
+	"gitlab.com/distributed_lab/kit/pgdb"
+
+	"github.com/rarimo/issuer/internal/data"
+)
+
+const usedTokensTableName = "used_tokens"
+
+type usedTokensQ struct {
+	db *pgdb.DB
+}
+
+func NewUsedTokensQ(db *pgdb.DB) data.UsedTokensQ {
+	return &usedTokensQ{
+		db: db,
+	}
+}
+
+func (q *usedTokensQ) New() data.UsedTokensQ {
+	return NewUsedTokensQ(q.db.Clone())
+}
+
+func (q *usedTokensQ) Insert(usedToken *data.UsedToken) (bool, error) {
+	result, err := q.db.ExecWithResult(
+		sq.Insert(usedTokensTableName).
+			SetMap(structs.Map(usedToken)).
+			Suffix("ON CONFLICT (id) DO NOTHING"),
+	)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to insert rows")
+	}
+
+	inserted, err := result.RowsAffected()
+	if err != nil {
+		return false, errors.Wrap(err, "failed to get inserted rows count")
+	}
+
+	return inserted > 0, nil
+}
+
+func (q *usedTokensQ) DeleteCreatedBefore(createdAt time.Time) (int64, error) {
+	result, err := q.db.ExecWithResult(
+		sq.Delete(usedTokensTableName).
+			Where(sq.Lt{createdAtColumnName: createdAt}),
+	)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to delete rows")
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to get deleted rows count")
+	}
+
+	return deleted, nil
+}
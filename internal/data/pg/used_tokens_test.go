@@ -0,0 +1,44 @@
+package pg
+
+import (
+	"testing"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+
+	"github.com/rarimo/issuer/internal/data"
+)
+
+func TestUsedTokensQInsert(t *testing.T) {
+	db := newTestDB(t)
+	q := NewUsedTokensQ(db)
+
+	now := time.Now().UTC()
+	usedToken := &data.UsedToken{ID: uuid.NewString(), UserID: "user", CreatedAt: now}
+	t.Cleanup(func() {
+		_ = db.Exec(sq.Delete(usedTokensTableName).Where(sq.Eq{idColumnName: usedToken.ID}))
+	})
+
+	if ok, err := q.Insert(usedToken); err != nil || !ok {
+		t.Fatalf("failed to insert used token: %v", err)
+	}
+	// the token is accepted only once, whoever sends it
+	if ok, err := q.Insert(&data.UsedToken{ID: usedToken.ID, UserID: "other", CreatedAt: now}); err != nil || ok {
+		t.Fatalf("used token is inserted again: %v", err)
+	}
+
+	if _, err := q.DeleteCreatedBefore(now.Add(-time.Minute)); err != nil {
+		t.Fatalf("failed to delete used tokens: %v", err)
+	}
+	if ok, err := q.Insert(usedToken); err != nil || ok {
+		t.Fatalf("used token within the ttl is deleted: %v", err)
+	}
+
+	if _, err := q.DeleteCreatedBefore(now.Add(time.Minute)); err != nil {
+		t.Fatalf("failed to delete used tokens: %v", err)
+	}
+	if ok, err := q.Insert(usedToken); err != nil || !ok {
+		t.Fatalf("expired used token isn't deleted: %v", err)
+	}
+}
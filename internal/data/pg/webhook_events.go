@@ -0,0 +1,122 @@
+package pg
+
+import (
+	"fmt"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/fatih/structs"
+	"github.com/pkg/errors"
+	"gitlab.com/distributed_lab/kit/pgdb"
+
+	"github.com/rarimo/issuer/internal/data"
+)
+
+const (
+	webhookEventsTableName  = "webhook_events"
+	eventIDColumnName       = "event_id"
+	eventTypeColumnName     = "event_type"
+	endpointColumnName      = "endpoint"
+	payloadColumnName       = "payload"
+	attemptsColumnName      = "attempts"
+	lastErrorColumnName     = "last_error"
+	nextAttemptAtColumnName = "next_attempt_at"
+	finishedAtColumnName    = "finished_at"
+)
+
+type webhookEventsQ struct {
+	db *pgdb.DB
+}
+
+func NewWebhookEventsQ(db *pgdb.DB) data.WebhookEventsQ {
+	return &webhookEventsQ{
+		db: db,
+	}
+}
+
+func (q *webhookEventsQ) New() data.WebhookEventsQ {
+	return NewWebhookEventsQ(q.db.Clone())
+}
+
+func (q *webhookEventsQ) Insert(events ...data.WebhookEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	stmt := sq.Insert(webhookEventsTableName).Columns(
+		eventIDColumnName, eventTypeColumnName, endpointColumnName, payloadColumnName, statusColumnName,
+		attemptsColumnName, lastErrorColumnName, createdAtColumnName, nextAttemptAtColumnName,
+		finishedAtColumnName,
+	)
+	for _, event := range events {
+		stmt = stmt.Values(
+			event.EventID, event.EventType, event.Endpoint, event.Payload, event.Status,
+			event.Attempts, event.LastError, event.CreatedAt, event.NextAttemptAt,
+			event.FinishedAt,
+		)
+	}
+
+	err := q.db.Exec(stmt)
+	if err != nil {
+		return errors.Wrap(err, "failed to insert rows")
+	}
+
+	return nil
+}
+
+func (q *webhookEventsQ) Update(event *data.WebhookEvent) error {
+	err := q.db.Exec(
+		sq.Update(webhookEventsTableName).
+			SetMap(structs.Map(event)).
+			Where(sq.Eq{idColumnName: event.ID}),
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to update rows")
+	}
+
+	return nil
+}
+
+// LeaseDue locks the due rows skipping the ones locked by the concurrent senders and leases them
+// by the same statement, so every due event is taken by the single sender.
+func (q *webhookEventsQ) LeaseDue(now, leasedUntil time.Time, limit uint64) ([]data.WebhookEvent, error) {
+	var result []data.WebhookEvent
+
+	dueEvents, args, _ := sq.Select(idColumnName).
+		From(webhookEventsTableName).
+		Where(sq.Eq{statusColumnName: data.WebhookEventStatusPending}).
+		Where(sq.LtOrEq{nextAttemptAtColumnName: now}).
+		OrderBy(nextAttemptAtColumnName, idColumnName).
+		Limit(limit).
+		Suffix("FOR UPDATE SKIP LOCKED").
+		ToSql()
+
+	err := q.db.Select(&result,
+		sq.Update(webhookEventsTableName).
+			Set(nextAttemptAtColumnName, leasedUntil).
+			Where(sq.Expr(fmt.Sprintf("%s IN (%s)", idColumnName, dueEvents), args...)).
+			Suffix("RETURNING *"),
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to update rows")
+	}
+
+	return result, nil
+}
+
+func (q *webhookEventsQ) DeleteFinishedBefore(before time.Time) (int64, error) {
+	result, err := q.db.ExecWithResult(
+		sq.Delete(webhookEventsTableName).
+			Where(sq.Lt{finishedAtColumnName: before}),
+	)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to delete rows")
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to get deleted rows count")
+	}
+
+	return deleted, nil
+}
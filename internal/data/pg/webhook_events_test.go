@@ -0,0 +1,132 @@
+package pg
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	"gitlab.com/distributed_lab/kit/pgdb"
+
+	"github.com/rarimo/issuer/internal/data"
+)
+
+// insertTestWebhookEvents inserts the pending events due at the time, the events aren't scoped by the
+// identity, so they are deleted after the test and the leased events of the other tests are ignored.
+func insertTestWebhookEvents(t *testing.T, db *pgdb.DB, dueAt time.Time, count int) map[string]bool {
+	t.Helper()
+
+	eventIDs := make(map[string]bool, count)
+	events := make([]data.WebhookEvent, 0, count)
+	for i := 0; i < count; i++ {
+		eventID := uuid.NewString()
+		eventIDs[eventID] = true
+		events = append(events, data.WebhookEvent{
+			EventID:       eventID,
+			EventType:     "claim.issued",
+			Endpoint:      "http://test",
+			Payload:       []byte(`{}`),
+			Status:        data.WebhookEventStatusPending,
+			CreatedAt:     dueAt,
+			NextAttemptAt: dueAt,
+		})
+	}
+
+	if err := NewWebhookEventsQ(db).Insert(events...); err != nil {
+		t.Fatalf("failed to insert webhook events: %v", err)
+	}
+	t.Cleanup(func() {
+		ids := make([]string, 0, len(eventIDs))
+		for id := range eventIDs {
+			ids = append(ids, id)
+		}
+		_ = db.Exec(sq.Delete(webhookEventsTableName).Where(sq.Eq{eventIDColumnName: ids}))
+	})
+
+	return eventIDs
+}
+
+func TestWebhookEventsQLeaseDue(t *testing.T) {
+	db := newTestDB(t)
+	q := NewWebhookEventsQ(db)
+
+	dueAt := time.Now().UTC().Add(-time.Minute)
+	eventIDs := insertTestWebhookEvents(t, db, dueAt, 3)
+
+	leaseDue := func(now time.Time) []data.WebhookEvent {
+		t.Helper()
+
+		events, err := q.LeaseDue(now, now.Add(time.Minute), 100)
+		if err != nil {
+			t.Fatalf("failed to lease due events: %v", err)
+		}
+
+		var result []data.WebhookEvent
+		for _, event := range events {
+			if eventIDs[event.EventID] {
+				result = append(result, event)
+			}
+		}
+		return result
+	}
+
+	now := time.Now().UTC()
+	if leased := leaseDue(now); len(leased) != len(eventIDs) {
+		t.Fatalf("%d due events are leased, want %d", len(leased), len(eventIDs))
+	}
+	if leased := leaseDue(now); len(leased) != 0 {
+		t.Fatalf("%d leased events are leased again", len(leased))
+	}
+	// the events of the sender stopped during the delivery are retried after the lease expires
+	if leased := leaseDue(now.Add(2 * time.Minute)); len(leased) != len(eventIDs) {
+		t.Fatalf("%d events are leased after the lease expired, want %d", len(leased), len(eventIDs))
+	}
+}
+
+func TestWebhookEventsQLeaseDueConcurrently(t *testing.T) {
+	db := newTestDB(t)
+
+	const senders, count = 5, 20
+	dueAt := time.Now().UTC().Add(-time.Minute)
+	eventIDs := insertTestWebhookEvents(t, db, dueAt, count)
+
+	var (
+		mu     sync.Mutex
+		leased = map[string]int{}
+		wg     sync.WaitGroup
+	)
+
+	now := time.Now().UTC()
+	for i := 0; i < senders; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			q := NewWebhookEventsQ(db.Clone())
+			for {
+				events, err := q.LeaseDue(now, now.Add(time.Minute), 2)
+				if err != nil {
+					t.Errorf("failed to lease due events: %v", err)
+					return
+				}
+				if len(events) == 0 {
+					return
+				}
+
+				mu.Lock()
+				for _, event := range events {
+					leased[event.EventID]++
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	for eventID := range eventIDs {
+		if leased[eventID] != 1 {
+			t.Fatalf("event %s is leased %d times, want 1", eventID, leased[eventID])
+		}
+	}
+}
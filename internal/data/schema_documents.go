@@ -0,0 +1,18 @@
+package data
+
+import "time"
+
+type SchemaDocumentsQ interface {
+	New() SchemaDocumentsQ
+
+	Get(url string) (*SchemaDocument, error)
+	Upsert(*SchemaDocument) error
+}
+
+// SchemaDocument is the last fetched version of the JSON schema or JSON-LD context.
+type SchemaDocument struct {
+	URL       string    `db:"url"        structs:"url"`
+	Body      []byte    `db:"body"       structs:"body"`
+	Hash      string    `db:"hash"       structs:"hash"`
+	FetchedAt time.Time `db:"fetched_at" structs:"fetched_at"`
+}
@@ -0,0 +1,21 @@
+package data
+
+import "time"
+
+type UsedTokensQ interface {
+	New() UsedTokensQ
+
+	// Insert stores the token if it isn't stored yet, false is returned otherwise,
+	// so the token can be used only once
+	Insert(*UsedToken) (bool, error)
+	// DeleteCreatedBefore deletes the tokens used before the time and returns the deleted count
+	DeleteCreatedBefore(createdAt time.Time) (int64, error)
+}
+
+// UsedToken is the JWZ token that was accepted once, the ID is the hex of the token message hash,
+// that is the challenge of its proof, so the same message can't be replayed with the new proof either.
+type UsedToken struct {
+	ID        string    `db:"id"         structs:"id"`
+	UserID    string    `db:"user_id"    structs:"user_id"`
+	CreatedAt time.Time `db:"created_at" structs:"created_at"`
+}
@@ -0,0 +1,40 @@
+package data
+
+import "time"
+
+type WebhookEventsQ interface {
+	New() WebhookEventsQ
+
+	Insert(events ...WebhookEvent) error
+	Update(event *WebhookEvent) error
+	// LeaseDue takes the pending events which next attempt time has come, the oldest ones first, and moves
+	// their next attempt to the leasedUntil, so the concurrent senders skip them until the lease expires
+	LeaseDue(now, leasedUntil time.Time, limit uint64) ([]WebhookEvent, error)
+	// DeleteFinishedBefore deletes the delivered and failed events finished before the time,
+	// the deleted rows count is returned
+	DeleteFinishedBefore(before time.Time) (int64, error)
+}
+
+type WebhookEventStatus string
+
+const (
+	WebhookEventStatusPending   = "pending"
+	WebhookEventStatusDelivered = "delivered"
+	WebhookEventStatusFailed    = "failed"
+)
+
+// WebhookEvent is the outbox entry of the event for the single endpoint, the entries of the same event
+// for the different endpoints share the EventID, so the receivers are able to deduplicate the retries.
+type WebhookEvent struct {
+	ID            uint64             `db:"id"              structs:"-"`
+	EventID       string             `db:"event_id"        structs:"event_id"`
+	EventType     string             `db:"event_type"      structs:"event_type"`
+	Endpoint      string             `db:"endpoint"        structs:"endpoint"`
+	Payload       []byte             `db:"payload"         structs:"payload"`
+	Status        WebhookEventStatus `db:"status"          structs:"status"`
+	Attempts      int                `db:"attempts"        structs:"attempts"`
+	LastError     string             `db:"last_error"      structs:"last_error"`
+	CreatedAt     time.Time          `db:"created_at"      structs:"created_at"`
+	NextAttemptAt time.Time          `db:"next_attempt_at" structs:"next_attempt_at"`
+	FinishedAt    *time.Time         `db:"finished_at"     structs:"finished_at"`
+}
@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/iden3/iden3comm/packers"
+	"github.com/pkg/errors"
+	"gitlab.com/distributed_lab/ape"
+	"gitlab.com/distributed_lab/ape/problems"
+	"gitlab.com/distributed_lab/logan/v3"
+
+	"github.com/rarimo/issuer/internal/service/api/requests"
+	"github.com/rarimo/issuer/internal/service/core/issuer"
+)
+
+func Agent(w http.ResponseWriter, r *http.Request) {
+	req, err := requests.NewAgent(r)
+	if err != nil {
+		Log(r).WithField("reason", err).Debug("Bad request")
+		ape.RenderErr(w, problems.BadRequest(err)...)
+		return
+	}
+
+	params, ok := newPackerParams(w, r, req.Response.MediaType, req.Response.Recipient)
+	if !ok {
+		return
+	}
+
+	if req.ProblemReport != nil {
+		Log(r).WithFields(logan.F{
+			"thid":    req.ProblemReport.ThreadID,
+			"from":    req.ProblemReport.From,
+			"code":    req.ProblemReport.Body.Code,
+			"comment": req.ProblemReport.Body.Comment,
+		}).Warn("Problem is reported by the agent message")
+	}
+
+	response, err := Issuer(r).HandleAgentMessage(r.Context(), req)
+	switch {
+	case errors.Is(err, issuer.ErrClaimRetrieverIsNotClaimOwner),
+		errors.Is(err, issuer.ErrProofVerifyFailed),
+		errors.Is(err, issuer.ErrTokenIsReplayed):
+		Log(r).WithField("reason", err).Debug("Forbidden")
+		ape.RenderErr(w, problems.Forbidden())
+		return
+	case errors.Is(err, issuer.ErrMessageRecipientIsNotIssuer):
+		Log(r).WithField("reason", err).Debug("Bad request")
+		ape.RenderErr(w, problems.BadRequest(errors.Cause(err))...)
+		return
+	case errors.Is(err, issuer.ErrClaimIsNotExist):
+		Log(r).WithField("reason", err).Debug("Not found")
+		ape.RenderErr(w, problems.NotFound())
+		return
+	case errors.Is(err, issuer.ErrClaimIsAlreadyRevoked):
+		Log(r).WithField("reason", err).Debug("Conflict")
+		ape.RenderErr(w, problems.Conflict())
+		return
+	case err != nil:
+		Log(r).WithError(err).
+			WithField("message-type", req.Message.Type).
+			Error("Failed to handle agent message")
+		ape.RenderErr(w, problems.InternalError())
+		return
+	}
+
+	if response == nil {
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+
+	if req.Response.MediaType == packers.MediaTypePlainMessage {
+		ape.Render(w, response)
+		return
+	}
+
+	renderPackedMessage(w, r, req.Response.MediaType, response, params)
+}
@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"gitlab.com/distributed_lab/ape"
+	"gitlab.com/distributed_lab/ape/problems"
+
+	"github.com/rarimo/issuer/internal/data"
+	"github.com/rarimo/issuer/internal/service/api/requests"
+	"github.com/rarimo/issuer/internal/service/api/responses"
+)
+
+const contentTypeJSONLines = "application/x-ndjson"
+
+func ListAuditEvents(w http.ResponseWriter, r *http.Request) {
+	req, err := requests.NewListAuditEvents(r)
+	if err != nil {
+		Log(r).WithField("reason", err).Debug("Bad request")
+		ape.RenderErr(w, problems.BadRequest(err)...)
+		return
+	}
+
+	if !authorizeUnrestricted(w, r) {
+		return
+	}
+
+	events, err := Issuer(r).ListAuditEvents(req)
+	if err != nil {
+		Log(r).WithError(err).Error("Failed to list audit events")
+		ape.RenderErr(w, problems.InternalError())
+		return
+	}
+
+	ape.Render(w, responses.NewAuditEventList(events, pageLinks(r, req.Page, len(events))))
+}
+
+// ExportAuditEvents streams all the audit events matching the filters as JSON lines in the order of insertion,
+// the failure after the first line is written can't be reported with the problem, so the export is cut short.
+func ExportAuditEvents(w http.ResponseWriter, r *http.Request) {
+	req, err := requests.NewListAuditEvents(r)
+	if err != nil {
+		Log(r).WithField("reason", err).Debug("Bad request")
+		ape.RenderErr(w, problems.BadRequest(err)...)
+		return
+	}
+
+	if !authorizeUnrestricted(w, r) {
+		return
+	}
+
+	isWritten := false
+	encoder := json.NewEncoder(w)
+
+	err = Issuer(r).ExportAuditEvents(req, func(events []data.AuditEvent) error {
+		if !isWritten {
+			w.Header().Set("Content-Type", contentTypeJSONLines)
+			w.WriteHeader(http.StatusOK)
+			isWritten = true
+		}
+
+		for i := range events {
+			if err := encoder.Encode(responses.NewAuditEventData(&events[i])); err != nil {
+				return err
+			}
+		}
+
+		if flusher, ok := w.(http.Flusher); ok {
+			flusher.Flush()
+		}
+
+		return nil
+	})
+	if err != nil {
+		Log(r).WithError(err).Error("Failed to export audit events")
+		if !isWritten {
+			ape.RenderErr(w, problems.InternalError())
+		}
+		return
+	}
+
+	if !isWritten {
+		w.Header().Set("Content-Type", contentTypeJSONLines)
+		w.WriteHeader(http.StatusOK)
+	}
+}
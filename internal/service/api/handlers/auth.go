@@ -0,0 +1,189 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"gitlab.com/distributed_lab/ape"
+	"gitlab.com/distributed_lab/ape/problems"
+	"gitlab.com/distributed_lab/logan/v3"
+
+	"github.com/rarimo/issuer/internal/service/core/apikeys"
+	"github.com/rarimo/issuer/internal/service/core/audit"
+	"github.com/rarimo/issuer/internal/service/core/issuer"
+)
+
+const (
+	authorizationHeader = "Authorization"
+	APIKeyHeader        = "X-API-Key"
+
+	bearerPrefix = "Bearer "
+)
+
+// Authenticate authenticates the request with the API key or the JWT from the bearer authorization
+// or the X-API-Key header. The principal is added to the request context and to the log fields, so
+// every request and the changes it makes are attributed to the client. The requests pass as is if the
+// authentication is disabled, their changes are audited as made by the anonymous actor.
+func Authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authenticator := Authenticator(r)
+		if authenticator == nil {
+			ctx := audit.CtxActor(r.Context(), audit.Actor{Type: audit.ActorTypeAnonymous})
+			next.ServeHTTP(w, r.WithContext(ctx))
+			return
+		}
+
+		credentials := requestCredentials(r)
+		if credentials == "" {
+			Log(r).Debug("Unauthorized")
+			ape.RenderErr(w, problems.Unauthorized())
+			return
+		}
+
+		principal, err := authenticator.Authenticate(credentials)
+		switch {
+		case errors.Is(err, apikeys.ErrInvalidCredentials):
+			Log(r).WithField("reason", err).Debug("Unauthorized")
+			ape.RenderErr(w, problems.Unauthorized())
+			return
+		case err != nil:
+			Log(r).WithError(err).Error("Failed to authenticate request")
+			ape.RenderErr(w, problems.InternalError())
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), principalCtxKey, principal)
+		ctx = audit.CtxActor(ctx, audit.Actor{Type: audit.ActorType(principal.Method), ID: principal.ID})
+		ctx = CtxLog(Log(r).WithFields(logan.F{
+			"principal_id":   principal.ID,
+			"principal_name": principal.Name,
+			"auth_method":    principal.Method,
+		}))(ctx)
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// RequireScope rejects the requests of the principals that don't have all the scopes.
+func RequireScope(scopes ...apikeys.Scope) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			principal := Principal(r)
+			if principal == nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			for _, scope := range scopes {
+				if !principal.HasScope(scope) {
+					Log(r).WithField("scope", scope).Debug("Forbidden")
+					ape.RenderErr(w, problems.Forbidden())
+					return
+				}
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func requestCredentials(r *http.Request) string {
+	if apiKey := r.Header.Get(APIKeyHeader); apiKey != "" {
+		return apiKey
+	}
+
+	authorization := r.Header.Get(authorizationHeader)
+	if len(authorization) > len(bearerPrefix) && strings.EqualFold(authorization[:len(bearerPrefix)], bearerPrefix) {
+		return strings.TrimSpace(authorization[len(bearerPrefix):])
+	}
+
+	return ""
+}
+
+// authorizeSchemaTypes renders the forbidden problem and returns false if the principal
+// is limited to the schema types and any of the requested ones isn't among them.
+func authorizeSchemaTypes(w http.ResponseWriter, r *http.Request, schemaTypes ...string) bool {
+	principal := Principal(r)
+	if principal == nil {
+		return true
+	}
+
+	for _, schemaType := range schemaTypes {
+		if !principal.AllowsSchemaType(schemaType) {
+			Log(r).WithField("schema-type", schemaType).Debug("Forbidden")
+			ape.RenderErr(w, problems.Forbidden())
+			return false
+		}
+	}
+
+	return true
+}
+
+// authorizeUnrestricted renders the forbidden problem and returns false if the principal is limited
+// to the schema types, it guards the endpoints that don't address the claims of the specific types.
+func authorizeUnrestricted(w http.ResponseWriter, r *http.Request) bool {
+	principal := Principal(r)
+	if principal == nil || !principal.IsRestricted() {
+		return true
+	}
+
+	Log(r).Debug("Forbidden, the principal is limited to the schema types")
+	ape.RenderErr(w, problems.Forbidden())
+	return false
+}
+
+// authorizeClaims renders the problem and returns false if the principal is limited to the schema types
+// and any of the claims addressed by the ID or by the revocation nonce if the ID is empty isn't of them.
+// The claims that don't exist are skipped to be reported by the handler, but the nonces without the
+// claims are forbidden, since their schema types are unknown.
+func authorizeClaims(w http.ResponseWriter, r *http.Request, claims ...issuer.BulkRevocation) bool {
+	principal := Principal(r)
+	if principal == nil || !principal.IsRestricted() {
+		return true
+	}
+
+	for _, claim := range claims {
+		var schemaType string
+		var err error
+		if claim.ClaimID != "" {
+			schemaType, err = Issuer(r).GetClaimSchemaType(uuid.MustParse(claim.ClaimID))
+		} else {
+			schemaType, err = Issuer(r).GetClaimSchemaTypeByNonce(claim.RevNonce)
+		}
+
+		switch {
+		case errors.Is(err, issuer.ErrClaimIsNotExist) && claim.ClaimID != "":
+			continue
+		case errors.Is(err, issuer.ErrClaimIsNotExist):
+			Log(r).WithField("rev-nonce", claim.RevNonce).Debug("Forbidden, the nonce claim is not exist")
+			ape.RenderErr(w, problems.Forbidden())
+			return false
+		case err != nil:
+			Log(r).WithError(err).
+				WithField("credential-id", claim.ClaimID).
+				WithField("rev-nonce", claim.RevNonce).
+				Error("Failed to get claim schema type")
+			ape.RenderErr(w, problems.InternalError())
+			return false
+		}
+
+		if !authorizeSchemaTypes(w, r, schemaType) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// authorizeClaimByID is authorizeClaims for the single claim addressed by the ID.
+func authorizeClaimByID(w http.ResponseWriter, r *http.Request, claimID uuid.UUID) bool {
+	return authorizeClaims(w, r, issuer.BulkRevocation{ClaimID: claimID.String()})
+}
+
+// authorizeClaimByNonce is authorizeClaims for the single claim addressed by the revocation nonce.
+func authorizeClaimByNonce(w http.ResponseWriter, r *http.Request, revNonce uint64) bool {
+	return authorizeClaims(w, r, issuer.BulkRevocation{RevNonce: revNonce})
+}
@@ -0,0 +1,168 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"gitlab.com/distributed_lab/logan/v3"
+
+	"github.com/rarimo/issuer/internal/config"
+	"github.com/rarimo/issuer/internal/data"
+	"github.com/rarimo/issuer/internal/service/core/apikeys"
+	"github.com/rarimo/issuer/internal/service/core/audit"
+)
+
+type testAPIKeysQ struct {
+	data.APIKeysQ
+	keys []*data.APIKey
+}
+
+func (q *testAPIKeysQ) Insert(apiKey *data.APIKey) error {
+	q.keys = append(q.keys, apiKey)
+	return nil
+}
+
+func (q *testAPIKeysQ) GetByHash(keyHash []byte) (*data.APIKey, error) {
+	for _, apiKey := range q.keys {
+		if string(apiKey.KeyHash) == string(keyHash) {
+			return apiKey, nil
+		}
+	}
+
+	return nil, nil
+}
+
+func (q *testAPIKeysQ) MarkUsed(string, time.Time, time.Time) error {
+	return nil
+}
+
+// newTestAuthenticator returns the authenticator of the single API key with the scopes.
+func newTestAuthenticator(t *testing.T, scopes ...apikeys.Scope) (*apikeys.Authenticator, string) {
+	t.Helper()
+
+	rawScopes := make([]string, 0, len(scopes))
+	for _, scope := range scopes {
+		rawScopes = append(rawScopes, string(scope))
+	}
+
+	q := &testAPIKeysQ{}
+	rawKey, _, err := apikeys.NewManager(q).Create("client", rawScopes, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create api key: %v", err)
+	}
+
+	return apikeys.NewAuthenticator(&config.AuthConfig{}, q, "did:iden3:readonly:issuer", true), rawKey
+}
+
+// serveAuthenticated serves the request through the authentication and the scope check, the principal
+// and the actor the handler gets are returned.
+func serveAuthenticated(
+	authenticator *apikeys.Authenticator,
+	r *http.Request,
+	scopes ...apikeys.Scope,
+) (*httptest.ResponseRecorder, *apikeys.Principal, *audit.Actor) {
+	var principal *apikeys.Principal
+	var actor *audit.Actor
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		principal = Principal(r)
+		ctxActor := audit.ActorFromCtx(r.Context())
+		actor = &ctxActor
+	})
+
+	ctx := CtxLog(logan.New())(r.Context())
+	ctx = CtxAuthenticator(authenticator)(ctx)
+
+	w := httptest.NewRecorder()
+	Authenticate(RequireScope(scopes...)(handler)).ServeHTTP(w, r.WithContext(ctx))
+
+	return w, principal, actor
+}
+
+func TestAuthenticate(t *testing.T) {
+	authenticator, rawKey := newTestAuthenticator(t, apikeys.ScopeClaimsRead)
+
+	cases := []struct {
+		name     string
+		header   string
+		value    string
+		expected int
+	}{
+		{"api key header", APIKeyHeader, rawKey, http.StatusOK},
+		{"bearer authorization", authorizationHeader, "Bearer " + rawKey, http.StatusOK},
+		{"bearer authorization in the other case", authorizationHeader, "bearer " + rawKey, http.StatusOK},
+		{"no credentials", "", "", http.StatusUnauthorized},
+		{"other authorization scheme", authorizationHeader, "Basic " + rawKey, http.StatusUnauthorized},
+		{"unknown api key", APIKeyHeader, "isk_unknown", http.StatusUnauthorized},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/claims", nil)
+			if tc.header != "" {
+				r.Header.Set(tc.header, tc.value)
+			}
+
+			w, principal, actor := serveAuthenticated(authenticator, r)
+			if w.Code != tc.expected {
+				t.Fatalf("got status %d, want %d", w.Code, tc.expected)
+			}
+			if tc.expected != http.StatusOK {
+				if principal != nil || actor != nil {
+					t.Fatal("unauthorized request is handled")
+				}
+				return
+			}
+
+			if principal == nil || principal.Method != apikeys.MethodAPIKey {
+				t.Fatalf("got principal %+v", principal)
+			}
+			if actor.Type != audit.ActorType(apikeys.MethodAPIKey) || actor.ID != principal.ID {
+				t.Fatalf("request is attributed to %+v", actor)
+			}
+		})
+	}
+}
+
+func TestAuthenticateDisabled(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/claims", nil)
+
+	w, principal, actor := serveAuthenticated(nil, r, apikeys.ScopeClaimsIssue)
+	if w.Code != http.StatusOK {
+		t.Fatalf("got status %d, want %d", w.Code, http.StatusOK)
+	}
+	if principal != nil || actor.Type != audit.ActorTypeAnonymous {
+		t.Fatalf("request is attributed to %+v of %+v", actor, principal)
+	}
+}
+
+func TestRequireScope(t *testing.T) {
+	authenticator, rawKey := newTestAuthenticator(t, apikeys.ScopeClaimsRead, apikeys.ScopeClaimsIssue)
+
+	cases := []struct {
+		name     string
+		scopes   []apikeys.Scope
+		expected int
+	}{
+		{"granted scope", []apikeys.Scope{apikeys.ScopeClaimsRead}, http.StatusOK},
+		{"all granted scopes", []apikeys.Scope{apikeys.ScopeClaimsRead, apikeys.ScopeClaimsIssue}, http.StatusOK},
+		{"missing scope", []apikeys.Scope{apikeys.ScopeClaimsRevoke}, http.StatusForbidden},
+		{"one of the scopes is missing", []apikeys.Scope{apikeys.ScopeClaimsRead, apikeys.ScopeIdentitiesManage}, http.StatusForbidden},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/claims", nil)
+			r.Header.Set(APIKeyHeader, rawKey)
+
+			w, principal, _ := serveAuthenticated(authenticator, r, tc.scopes...)
+			if w.Code != tc.expected {
+				t.Fatalf("got status %d, want %d", w.Code, tc.expected)
+			}
+			if tc.expected == http.StatusForbidden && principal != nil {
+				t.Fatal("forbidden request is handled")
+			}
+		})
+	}
+}
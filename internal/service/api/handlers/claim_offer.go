@@ -3,6 +3,9 @@ package handlers
 import (
 	"net/http"
 
+	"github.com/iden3/iden3comm"
+	"github.com/iden3/iden3comm/packers"
+	"github.com/iden3/iden3comm/protocol"
 	"github.com/pkg/errors"
 	"gitlab.com/distributed_lab/ape"
 	"gitlab.com/distributed_lab/ape/problems"
@@ -20,7 +23,7 @@ func ClaimOffer(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	claimOffer, err := Issuer(r).CreateClaimOffer(req.UserDID, req.ClaimType)
+	claimOffer, err := Issuer(r).CreateClaimOffer(r.Context(), req.UserDID, req.ClaimType)
 	switch {
 	case errors.Is(err, issuer.ErrClaimIsNotExist):
 		Log(r).WithField("reason", err).Debug("Not found")
@@ -30,6 +33,10 @@ func ClaimOffer(w http.ResponseWriter, r *http.Request) {
 		Log(r).WithField("reason", err).Debug("Forbidden")
 		ape.RenderErr(w, problems.Forbidden())
 		return
+	case errors.Is(err, issuer.ErrOfferAuthIsRequired):
+		Log(r).WithField("reason", err).Debug("Unauthorized")
+		ape.RenderErr(w, unauthorized(err))
+		return
 	case err != nil:
 		Log(r).WithError(err).
 			WithField("claim-id", req.ClaimType).
@@ -39,5 +46,94 @@ func ClaimOffer(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	ape.Render(w, responses.NewClaimOffer(claimOffer))
+	renderClaimOffer(w, r, req.MediaType, claimOffer)
+}
+
+func ClaimOfferByID(w http.ResponseWriter, r *http.Request) {
+	req, err := requests.NewClaimOfferByID(r)
+	if err != nil {
+		Log(r).WithField("reason", err).Debug("Bad request")
+		ape.RenderErr(w, problems.BadRequest(err)...)
+		return
+	}
+
+	claimOffer, err := Issuer(r).CreateClaimOfferByID(r.Context(), req.UserDID, req.ClaimID)
+	switch {
+	case errors.Is(err, issuer.ErrClaimIsNotExist):
+		Log(r).WithField("reason", err).Debug("Not found")
+		ape.RenderErr(w, problems.NotFound())
+		return
+	case errors.Is(err, issuer.ErrClaimRetrieverIsNotClaimOwner):
+		Log(r).WithField("reason", err).Debug("Forbidden")
+		ape.RenderErr(w, problems.Forbidden())
+		return
+	case errors.Is(err, issuer.ErrOfferAuthIsRequired):
+		Log(r).WithField("reason", err).Debug("Unauthorized")
+		ape.RenderErr(w, unauthorized(err))
+		return
+	case err != nil:
+		Log(r).WithError(err).
+			WithField("credential-id", req.ClaimID).
+			WithField("user-did", req.UserDID.String()).
+			Error("Failed get claim offer")
+		ape.RenderErr(w, problems.InternalError())
+		return
+	}
+
+	renderClaimOffer(w, r, req.MediaType, claimOffer)
+}
+
+func ClaimsBundleOffer(w http.ResponseWriter, r *http.Request) {
+	req, err := requests.NewClaimsBundleOffer(r)
+	if err != nil {
+		Log(r).WithField("reason", err).Debug("Bad request")
+		ape.RenderErr(w, problems.BadRequest(err)...)
+		return
+	}
+
+	claimOffer, err := Issuer(r).CreateClaimsBundleOffer(r.Context(), req)
+	switch {
+	case errors.Is(err, issuer.ErrClaimIsNotExist):
+		Log(r).WithField("reason", err).Debug("Not found")
+		ape.RenderErr(w, problems.NotFound())
+		return
+	case errors.Is(err, issuer.ErrClaimRetrieverIsNotClaimOwner):
+		Log(r).WithField("reason", err).Debug("Forbidden")
+		ape.RenderErr(w, problems.Forbidden())
+		return
+	case errors.Is(err, issuer.ErrOfferAuthIsRequired):
+		Log(r).WithField("reason", err).Debug("Unauthorized")
+		ape.RenderErr(w, unauthorized(err))
+		return
+	case err != nil:
+		Log(r).WithError(err).
+			WithField("credential-ids", req.ClaimIDs).
+			WithField("user-did", req.UserDID.String()).
+			Error("Failed get claims bundle offer")
+		ape.RenderErr(w, problems.InternalError())
+		return
+	}
+
+	renderClaimOffer(w, r, req.MediaType, claimOffer)
+}
+
+// renderClaimOffer renders the plain offer as the resource to keep it unchanged for the older wallets.
+func renderClaimOffer(
+	w http.ResponseWriter,
+	r *http.Request,
+	mediaType iden3comm.MediaType,
+	claimOffer *protocol.CredentialsOfferMessage,
+) {
+	if mediaType == packers.MediaTypePlainMessage {
+		ape.Render(w, responses.NewClaimOffer(claimOffer, Issuer(r).GetClaimOfferLinks(claimOffer.ThreadID)))
+		return
+	}
+
+	params, ok := newPackerParams(w, r, mediaType, "")
+	if !ok {
+		return
+	}
+
+	claimOffer.Typ = mediaType
+	renderPackedMessage(w, r, mediaType, claimOffer, params)
 }
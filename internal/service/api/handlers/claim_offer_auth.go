@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/iden3/iden3comm/packers"
+	"github.com/pkg/errors"
+	"gitlab.com/distributed_lab/ape"
+	"gitlab.com/distributed_lab/ape/problems"
+
+	"github.com/rarimo/issuer/internal/service/api/requests"
+	"github.com/rarimo/issuer/internal/service/api/responses"
+	"github.com/rarimo/issuer/internal/service/core/issuer"
+)
+
+func ClaimOfferAuthRequest(w http.ResponseWriter, r *http.Request) {
+	req, err := requests.NewClaimOffer(r)
+	if err != nil {
+		Log(r).WithField("reason", err).Debug("Bad request")
+		ape.RenderErr(w, problems.BadRequest(err)...)
+		return
+	}
+
+	authRequest, err := Issuer(r).CreateClaimOfferAuthRequest(req.UserDID, req.ClaimType)
+	if err != nil {
+		Log(r).WithError(err).
+			WithField("claim-type", req.ClaimType).
+			WithField("user-did", req.UserDID.String()).
+			Error("Failed to create claim offer auth request")
+		ape.RenderErr(w, problems.InternalError())
+		return
+	}
+
+	if req.MediaType == packers.MediaTypePlainMessage {
+		ape.Render(w, authRequest)
+		return
+	}
+
+	params, ok := newPackerParams(w, r, req.MediaType, "")
+	if !ok {
+		return
+	}
+
+	authRequest.Typ = req.MediaType
+	renderPackedMessage(w, r, req.MediaType, authRequest, params)
+}
+
+func ClaimOfferAuthCallback(w http.ResponseWriter, r *http.Request) {
+	req, err := requests.NewClaimOfferAuthCallback(r)
+	if err != nil {
+		Log(r).WithField("reason", err).Debug("Bad request")
+		ape.RenderErr(w, problems.BadRequest(err)...)
+		return
+	}
+
+	params, ok := newPackerParams(w, r, req.Response.MediaType, req.Response.Recipient)
+	if !ok {
+		return
+	}
+
+	claimOffer, err := Issuer(r).AuthorizeClaimOffer(r.Context(), req)
+	switch {
+	case errors.Is(err, issuer.ErrClaimRetrieverIsNotClaimOwner),
+		errors.Is(err, issuer.ErrOfferAuthRequestIsAnswered),
+		errors.Is(err, issuer.ErrProofVerifyFailed),
+		errors.Is(err, issuer.ErrTokenIsReplayed):
+		Log(r).WithField("reason", err).Debug("Forbidden")
+		ape.RenderErr(w, problems.Forbidden())
+		return
+	case errors.Is(err, issuer.ErrMessageRecipientIsNotIssuer):
+		Log(r).WithField("reason", err).Debug("Bad request")
+		ape.RenderErr(w, problems.BadRequest(errors.Cause(err))...)
+		return
+	case errors.Is(err, issuer.ErrOfferAuthRequestIsExpired):
+		Log(r).WithField("reason", err).Debug("Gone")
+		ape.RenderErr(w, gone(err))
+		return
+	case errors.Is(err, issuer.ErrOfferAuthRequestIsNotExist), errors.Is(err, issuer.ErrClaimIsNotExist):
+		Log(r).WithField("reason", err).Debug("Not found")
+		ape.RenderErr(w, problems.NotFound())
+		return
+	case err != nil:
+		Log(r).WithError(err).
+			WithField("thread-id", req.AuthResponse.ThreadID).
+			Error("Failed to authorize claim offer")
+		ape.RenderErr(w, problems.InternalError())
+		return
+	}
+
+	if req.Response.MediaType == packers.MediaTypePlainMessage {
+		ape.Render(w, responses.NewClaimOffer(claimOffer, Issuer(r).GetClaimOfferLinks(claimOffer.ThreadID)))
+		return
+	}
+
+	claimOffer.Typ = req.Response.MediaType
+	renderPackedMessage(w, r, req.Response.MediaType, claimOffer, params)
+}
@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/iden3/iden3comm/packers"
+	"github.com/pkg/errors"
+	"gitlab.com/distributed_lab/ape"
+	"gitlab.com/distributed_lab/ape/problems"
+
+	"github.com/rarimo/issuer/internal/service/api/requests"
+	"github.com/rarimo/issuer/internal/service/core/issuer"
+	"github.com/rarimo/issuer/internal/service/core/qrcode"
+)
+
+// ClaimOfferMessage serves the plain offer message as is, so the wallet can fetch it by the request_uri of the deep link.
+func ClaimOfferMessage(w http.ResponseWriter, r *http.Request) {
+	req, err := requests.NewClaimOfferID(r)
+	if err != nil {
+		Log(r).WithField("reason", err).Debug("Bad request")
+		ape.RenderErr(w, problems.BadRequest(err)...)
+		return
+	}
+
+	message, err := Issuer(r).GetClaimOfferMessage(req.OfferID)
+	switch {
+	case errors.Is(err, issuer.ErrClaimOfferIsExpired), errors.Is(err, issuer.ErrClaimOfferIsCanceled):
+		Log(r).WithField("reason", err).Debug("Gone")
+		ape.RenderErr(w, gone(err))
+		return
+	case errors.Is(err, issuer.ErrClaimOfferIsNotExist):
+		Log(r).WithField("reason", err).Debug("Not found")
+		ape.RenderErr(w, problems.NotFound())
+		return
+	case err != nil:
+		Log(r).WithError(err).
+			WithField("offer-id", req.OfferID).
+			Error("Failed to get claim offer message")
+		ape.RenderErr(w, problems.InternalError())
+		return
+	}
+
+	w.Header().Set("Content-Type", string(packers.MediaTypePlainMessage))
+	if _, err := w.Write(message); err != nil {
+		Log(r).WithError(err).Error("Failed to write claim offer message")
+	}
+}
+
+func ClaimOfferQRCode(w http.ResponseWriter, r *http.Request) {
+	req, err := requests.NewClaimOfferQRCode(r)
+	if err != nil {
+		Log(r).WithField("reason", err).Debug("Bad request")
+		ape.RenderErr(w, problems.BadRequest(err)...)
+		return
+	}
+
+	image, err := Issuer(r).RenderClaimOfferQRCode(req.OfferID, req.Format, req.Size)
+	switch {
+	case errors.Is(err, issuer.ErrClaimOfferIsExpired), errors.Is(err, issuer.ErrClaimOfferIsCanceled):
+		Log(r).WithField("reason", err).Debug("Gone")
+		ape.RenderErr(w, gone(err))
+		return
+	case errors.Is(err, issuer.ErrClaimOfferIsNotExist):
+		Log(r).WithField("reason", err).Debug("Not found")
+		ape.RenderErr(w, problems.NotFound())
+		return
+	case err != nil:
+		Log(r).WithError(err).
+			WithField("offer-id", req.OfferID).
+			Error("Failed to render claim offer qr code")
+		ape.RenderErr(w, problems.InternalError())
+		return
+	}
+
+	w.Header().Set("Content-Type", qrcode.ContentType(req.Format))
+	if _, err := w.Write(image); err != nil {
+		Log(r).WithError(err).Error("Failed to write claim offer qr code")
+	}
+}
+
+func CancelClaimOffer(w http.ResponseWriter, r *http.Request) {
+	req, err := requests.NewClaimOfferID(r)
+	if err != nil {
+		Log(r).WithField("reason", err).Debug("Bad request")
+		ape.RenderErr(w, problems.BadRequest(err)...)
+		return
+	}
+
+	if !authorizeUnrestricted(w, r) {
+		return
+	}
+
+	err = Issuer(r).CancelClaimOffer(req.OfferID)
+	switch {
+	case errors.Is(err, issuer.ErrClaimOfferIsNotExist):
+		Log(r).WithField("reason", err).Debug("Not found")
+		ape.RenderErr(w, problems.NotFound())
+		return
+	case errors.Is(err, issuer.ErrClaimOfferIsAlreadyReceived), errors.Is(err, issuer.ErrClaimOfferIsCanceled):
+		Log(r).WithField("reason", err).Debug("Conflict")
+		ape.RenderErr(w, problems.Conflict())
+		return
+	case err != nil:
+		Log(r).WithError(err).
+			WithField("offer-id", req.OfferID).
+			Error("Failed to cancel claim offer")
+		ape.RenderErr(w, problems.InternalError())
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
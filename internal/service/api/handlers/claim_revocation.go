@@ -5,6 +5,7 @@ import (
 
 	"github.com/pkg/errors"
 	"github.com/rarimo/issuer/internal/service/api/requests"
+	"github.com/rarimo/issuer/internal/service/api/responses"
 	"github.com/rarimo/issuer/internal/service/core/issuer"
 	"gitlab.com/distributed_lab/ape"
 	"gitlab.com/distributed_lab/ape/problems"
@@ -18,7 +19,11 @@ func ClaimRevocation(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err = Issuer(r).RevokeClaim(r.Context(), req.UserID, req.ClaimType)
+	if !authorizeSchemaTypes(w, r, string(req.ClaimType)) {
+		return
+	}
+
+	revocation, err := Issuer(r).RevokeClaim(r.Context(), req.UserID, req.ClaimType, revocationDetails(req.Details))
 	switch {
 	case errors.Is(err, issuer.ErrClaimIsNotExist):
 		Log(r).WithField("reason", err).Debug("Not found")
@@ -28,6 +33,10 @@ func ClaimRevocation(w http.ResponseWriter, r *http.Request) {
 		Log(r).WithField("reason", err).Debug("Conflict")
 		ape.RenderErr(w, problems.Conflict())
 		return
+	case errors.Is(err, issuer.ErrAuthClaimIsNotRevocable):
+		Log(r).WithField("reason", err).Debug("Unprocessable entity")
+		ape.RenderErr(w, unprocessableEntity(err))
+		return
 	case err != nil:
 		Log(r).WithError(err).
 			WithField("claim-id", req.ClaimType).
@@ -37,5 +46,147 @@ func ClaimRevocation(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	w.WriteHeader(http.StatusNoContent)
+	ape.Render(w, responses.NewClaimRevocation(revocation))
+}
+
+func ClaimRevocationByID(w http.ResponseWriter, r *http.Request) {
+	req, err := requests.NewClaimRevocationByID(r)
+	if err != nil {
+		Log(r).WithField("reason", err).Debug("Bad request")
+		ape.RenderErr(w, problems.BadRequest(err)...)
+		return
+	}
+
+	if !authorizeClaimByID(w, r, req.ClaimID) {
+		return
+	}
+
+	revocation, err := Issuer(r).RevokeClaimByID(r.Context(), req.ClaimID, revocationDetails(req.Details))
+	switch {
+	case errors.Is(err, issuer.ErrClaimIsNotExist):
+		Log(r).WithField("reason", err).Debug("Not found")
+		ape.RenderErr(w, problems.NotFound())
+		return
+	case errors.Is(err, issuer.ErrClaimIsAlreadyRevoked):
+		Log(r).WithField("reason", err).Debug("Conflict")
+		ape.RenderErr(w, problems.Conflict())
+		return
+	case errors.Is(err, issuer.ErrAuthClaimIsNotRevocable):
+		Log(r).WithField("reason", err).Debug("Unprocessable entity")
+		ape.RenderErr(w, unprocessableEntity(err))
+		return
+	case err != nil:
+		Log(r).WithError(err).
+			WithField("credential-id", req.ClaimID).
+			Error("Failed to revoke claim")
+		ape.RenderErr(w, problems.InternalError())
+		return
+	}
+
+	ape.Render(w, responses.NewClaimRevocation(revocation))
+}
+
+func ClaimRevocationByNonce(w http.ResponseWriter, r *http.Request) {
+	req, err := requests.NewClaimRevocationByNonce(r)
+	if err != nil {
+		Log(r).WithField("reason", err).Debug("Bad request")
+		ape.RenderErr(w, problems.BadRequest(err)...)
+		return
+	}
+
+	if !authorizeClaimByNonce(w, r, req.RevNonce) {
+		return
+	}
+
+	revocation, err := Issuer(r).RevokeClaimByNonce(r.Context(), req.RevNonce, revocationDetails(req.Details))
+	switch {
+	case errors.Is(err, issuer.ErrClaimIsAlreadyRevoked):
+		Log(r).WithField("reason", err).Debug("Conflict")
+		ape.RenderErr(w, problems.Conflict())
+		return
+	case errors.Is(err, issuer.ErrAuthClaimIsNotRevocable):
+		Log(r).WithField("reason", err).Debug("Unprocessable entity")
+		ape.RenderErr(w, unprocessableEntity(err))
+		return
+	case err != nil:
+		Log(r).WithError(err).
+			WithField("rev-nonce", req.RevNonce).
+			Error("Failed to revoke nonce")
+		ape.RenderErr(w, problems.InternalError())
+		return
+	}
+
+	ape.Render(w, responses.NewClaimRevocation(revocation))
+}
+
+func ClaimRevocationBulk(w http.ResponseWriter, r *http.Request) {
+	req, err := requests.NewClaimRevocationBulk(r)
+	if err != nil {
+		Log(r).WithField("reason", err).Debug("Bad request")
+		ape.RenderErr(w, problems.BadRequest(err)...)
+		return
+	}
+
+	items := make([]issuer.BulkRevocation, 0, len(req.Items))
+	for _, item := range req.Items {
+		items = append(items, issuer.BulkRevocation{
+			ClaimID:  item.ClaimID,
+			RevNonce: item.RevNonce,
+		})
+	}
+
+	if !authorizeClaims(w, r, items...) {
+		return
+	}
+
+	results, err := Issuer(r).RevokeClaimsBulk(r.Context(), items, revocationDetails(req.Details))
+	switch {
+	case errors.Is(err, issuer.ErrAuthClaimIsNotRevocable):
+		Log(r).WithField("reason", err).Debug("Unprocessable entity")
+		ape.RenderErr(w, unprocessableEntity(err))
+		return
+	case err != nil:
+		Log(r).WithError(err).
+			WithField("bulk-size", len(items)).
+			Error("Failed to revoke claims in bulk")
+		ape.RenderErr(w, problems.InternalError())
+		return
+	}
+
+	ape.Render(w, responses.NewClaimRevocationBulk(results))
+}
+
+func GetClaimRevocation(w http.ResponseWriter, r *http.Request) {
+	req, err := requests.NewRevocationByNonce(r)
+	if err != nil {
+		Log(r).WithField("reason", err).Debug("Bad request")
+		ape.RenderErr(w, problems.BadRequest(err)...)
+		return
+	}
+
+	if !authorizeClaimByNonce(w, r, req.RevNonce) {
+		return
+	}
+
+	revocation, err := Issuer(r).GetRevocation(r.Context(), req.RevNonce)
+	switch {
+	case errors.Is(err, issuer.ErrRevocationIsNotExist):
+		Log(r).WithField("reason", err).Debug("Not found")
+		ape.RenderErr(w, problems.NotFound())
+		return
+	case err != nil:
+		Log(r).WithError(err).
+			WithField("rev-nonce", req.RevNonce).
+			Error("Failed to get claim revocation")
+		ape.RenderErr(w, problems.InternalError())
+		return
+	}
+
+	ape.Render(w, responses.NewClaimRevocation(revocation))
+}
+
+func revocationDetails(details requests.RevocationDetails) issuer.RevocationDetails {
+	return issuer.RevocationDetails{
+		Reason: details.Reason,
+	}
 }
@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/pkg/errors"
+	"gitlab.com/distributed_lab/ape"
+	"gitlab.com/distributed_lab/ape/problems"
+
+	"github.com/rarimo/issuer/internal/service/api/requests"
+	"github.com/rarimo/issuer/internal/service/api/responses"
+	"github.com/rarimo/issuer/internal/service/core/claims/schemas"
+)
+
+func RegisterClaimSchema(w http.ResponseWriter, r *http.Request) {
+	claimSchema, err := requests.NewRegisterClaimSchema(r)
+	if err != nil {
+		Log(r).WithField("reason", err).Debug("Bad request")
+		ape.RenderErr(w, problems.BadRequest(err)...)
+		return
+	}
+
+	if !authorizeSchemaTypes(w, r, claimSchema.SchemaType) {
+		return
+	}
+
+	err = Issuer(r).RegisterClaimSchema(r.Context(), claimSchema)
+	switch {
+	case errors.Is(err, schemas.ErrSchemaAlreadyExists):
+		Log(r).WithField("reason", err).Debug("Conflict")
+		ape.RenderErr(w, problems.Conflict())
+		return
+	case err != nil:
+		Log(r).WithError(err).
+			WithField("schema-type", claimSchema.SchemaType).
+			WithField("schema-url", claimSchema.SchemaURL).
+			Error("Failed to register claim schema")
+		ape.RenderErr(w, problems.InternalError())
+		return
+	}
+
+	ape.Render(w, responses.NewClaimSchema(claimSchema))
+}
+
+func GetClaimSchemas(w http.ResponseWriter, r *http.Request) {
+	claimSchemas, err := Issuer(r).GetClaimSchemas()
+	if err != nil {
+		Log(r).WithError(err).Error("Failed to get claim schemas")
+		ape.RenderErr(w, problems.InternalError())
+		return
+	}
+
+	ape.Render(w, responses.NewClaimSchemaList(claimSchemas))
+}
+
+func GetClaimSchema(w http.ResponseWriter, r *http.Request) {
+	req, err := requests.NewClaimSchema(r)
+	if err != nil {
+		Log(r).WithField("reason", err).Debug("Bad request")
+		ape.RenderErr(w, problems.BadRequest(err)...)
+		return
+	}
+
+	claimSchema, err := Issuer(r).GetClaimSchema(req.SchemaType)
+	switch {
+	case errors.Is(err, schemas.ErrSchemaIsNotExist):
+		Log(r).WithField("reason", err).Debug("Not found")
+		ape.RenderErr(w, problems.NotFound())
+		return
+	case err != nil:
+		Log(r).WithError(err).
+			WithField("schema-type", req.SchemaType).
+			Error("Failed to get claim schema")
+		ape.RenderErr(w, problems.InternalError())
+		return
+	}
+
+	ape.Render(w, responses.NewClaimSchema(claimSchema))
+}
+
+func DeprecateClaimSchema(w http.ResponseWriter, r *http.Request) {
+	req, err := requests.NewClaimSchema(r)
+	if err != nil {
+		Log(r).WithField("reason", err).Debug("Bad request")
+		ape.RenderErr(w, problems.BadRequest(err)...)
+		return
+	}
+
+	if !authorizeSchemaTypes(w, r, req.SchemaType) {
+		return
+	}
+
+	err = Issuer(r).DeprecateClaimSchema(req.SchemaType)
+	switch {
+	case errors.Is(err, schemas.ErrSchemaIsNotExist):
+		Log(r).WithField("reason", err).Debug("Not found")
+		ape.RenderErr(w, problems.NotFound())
+		return
+	case errors.Is(err, schemas.ErrSchemaIsDeprecated):
+		Log(r).WithField("reason", err).Debug("Conflict")
+		ape.RenderErr(w, problems.Conflict())
+		return
+	case err != nil:
+		Log(r).WithError(err).
+			WithField("schema-type", req.SchemaType).
+			Error("Failed to deprecate claim schema")
+		ape.RenderErr(w, problems.InternalError())
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
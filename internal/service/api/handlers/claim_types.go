@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/pkg/errors"
+	"gitlab.com/distributed_lab/ape"
+	"gitlab.com/distributed_lab/ape/problems"
+
+	"github.com/rarimo/issuer/internal/service/api/requests"
+	"github.com/rarimo/issuer/internal/service/api/responses"
+	"github.com/rarimo/issuer/internal/service/core/claims"
+	"github.com/rarimo/issuer/internal/service/core/claims/schemas"
+)
+
+func GetClaimTypes(w http.ResponseWriter, r *http.Request) {
+	claimTypes, err := Issuer(r).GetClaimTypes(r.Context())
+	if err != nil {
+		Log(r).WithError(err).Error("Failed to get claim types")
+		ape.RenderErr(w, problems.InternalError())
+		return
+	}
+
+	response, err := responses.NewClaimTypeList(claimTypes)
+	if err != nil {
+		Log(r).WithError(err).Error("Failed to build claim types response")
+		ape.RenderErr(w, problems.InternalError())
+		return
+	}
+
+	ape.Render(w, response)
+}
+
+func GetClaimType(w http.ResponseWriter, r *http.Request) {
+	req, err := requests.NewClaimSchema(r)
+	if err != nil {
+		Log(r).WithField("reason", err).Debug("Bad request")
+		ape.RenderErr(w, problems.BadRequest(err)...)
+		return
+	}
+
+	claimType, err := Issuer(r).GetClaimType(r.Context(), claims.ClaimSchemaType(req.SchemaType))
+	switch {
+	case errors.Is(err, schemas.ErrSchemaIsNotExist):
+		Log(r).WithField("reason", err).Debug("Not found")
+		ape.RenderErr(w, problems.NotFound())
+		return
+	case err != nil:
+		Log(r).WithError(err).
+			WithField("schema-type", req.SchemaType).
+			Error("Failed to get claim type")
+		ape.RenderErr(w, problems.InternalError())
+		return
+	}
+
+	response, err := responses.NewClaimType(claimType)
+	if err != nil {
+		Log(r).WithError(err).
+			WithField("schema-type", req.SchemaType).
+			Error("Failed to build claim type response")
+		ape.RenderErr(w, problems.InternalError())
+		return
+	}
+
+	ape.Render(w, response)
+}
@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/pkg/errors"
+	"gitlab.com/distributed_lab/ape"
+	"gitlab.com/distributed_lab/ape/problems"
+
+	"github.com/rarimo/issuer/internal/service/api/requests"
+	"github.com/rarimo/issuer/internal/service/api/responses"
+	"github.com/rarimo/issuer/internal/service/core/issuer"
+)
+
+func ListClaimVersions(w http.ResponseWriter, r *http.Request) {
+	req, err := requests.NewClaimByID(r)
+	if err != nil {
+		Log(r).WithField("reason", err).Debug("Bad request")
+		ape.RenderErr(w, problems.BadRequest(err)...)
+		return
+	}
+
+	if !authorizeClaimByID(w, r, req.ClaimID) {
+		return
+	}
+
+	versions, err := Issuer(r).ListClaimVersions(req.ClaimID)
+	switch {
+	case errors.Is(err, issuer.ErrClaimIsNotExist):
+		Log(r).WithField("reason", err).Debug("Not found")
+		ape.RenderErr(w, problems.NotFound())
+		return
+	case err != nil:
+		Log(r).WithError(err).
+			WithField("credential-id", req.ClaimID).
+			Error("Failed to list claim versions")
+		ape.RenderErr(w, problems.InternalError())
+		return
+	}
+
+	ape.Render(w, responses.NewClaimVersionList(versions))
+}
+
+func GetClaimVersion(w http.ResponseWriter, r *http.Request) {
+	req, err := requests.NewClaimVersion(r)
+	if err != nil {
+		Log(r).WithField("reason", err).Debug("Bad request")
+		ape.RenderErr(w, problems.BadRequest(err)...)
+		return
+	}
+
+	if !authorizeClaimByID(w, r, req.ClaimID) {
+		return
+	}
+
+	version, err := Issuer(r).GetClaimVersion(req.ClaimID, req.Version)
+	switch {
+	case errors.Is(err, issuer.ErrClaimIsNotExist), errors.Is(err, issuer.ErrClaimVersionIsNotExist):
+		Log(r).WithField("reason", err).Debug("Not found")
+		ape.RenderErr(w, problems.NotFound())
+		return
+	case err != nil:
+		Log(r).WithError(err).
+			WithField("credential-id", req.ClaimID).
+			WithField("version", req.Version).
+			Error("Failed to get claim version")
+		ape.RenderErr(w, problems.InternalError())
+		return
+	}
+
+	ape.Render(w, responses.NewClaimVersion(version))
+}
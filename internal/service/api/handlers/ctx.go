@@ -6,6 +6,8 @@ import (
 
 	"gitlab.com/distributed_lab/logan/v3"
 
+	"github.com/rarimo/issuer/internal/service/core/apikeys"
+	"github.com/rarimo/issuer/internal/service/core/idempotency"
 	"github.com/rarimo/issuer/internal/service/core/issuer"
 )
 
@@ -14,6 +16,10 @@ type ctxKey int
 const (
 	logCtxKey ctxKey = iota
 	issuerCtxKey
+	issuersCtxKey
+	idempotencyStoreCtxKey
+	authenticatorCtxKey
+	principalCtxKey
 )
 
 func CtxLog(entry *logan.Entry) func(context.Context) context.Context {
@@ -35,3 +41,41 @@ func CtxIssuer(issuer issuer.Issuer) func(context.Context) context.Context {
 func Issuer(r *http.Request) issuer.Issuer {
 	return r.Context().Value(issuerCtxKey).(issuer.Issuer)
 }
+
+func CtxIssuers(issuers *issuer.Registry) func(context.Context) context.Context {
+	return func(ctx context.Context) context.Context {
+		return context.WithValue(ctx, issuersCtxKey, issuers)
+	}
+}
+
+func Issuers(r *http.Request) *issuer.Registry {
+	return r.Context().Value(issuersCtxKey).(*issuer.Registry)
+}
+
+func CtxIdempotencyStore(store *idempotency.Store) func(context.Context) context.Context {
+	return func(ctx context.Context) context.Context {
+		return context.WithValue(ctx, idempotencyStoreCtxKey, store)
+	}
+}
+
+func IdempotencyStore(r *http.Request) *idempotency.Store {
+	return r.Context().Value(idempotencyStoreCtxKey).(*idempotency.Store)
+}
+
+// CtxAuthenticator adds the private API authenticator, the nil one disables the authentication.
+func CtxAuthenticator(authenticator *apikeys.Authenticator) func(context.Context) context.Context {
+	return func(ctx context.Context) context.Context {
+		return context.WithValue(ctx, authenticatorCtxKey, authenticator)
+	}
+}
+
+func Authenticator(r *http.Request) *apikeys.Authenticator {
+	return r.Context().Value(authenticatorCtxKey).(*apikeys.Authenticator)
+}
+
+// Principal returns the authenticated client of the request, it is nil for the public API
+// and if the authentication is disabled.
+func Principal(r *http.Request) *apikeys.Principal {
+	principal, _ := r.Context().Value(principalCtxKey).(*apikeys.Principal)
+	return principal
+}
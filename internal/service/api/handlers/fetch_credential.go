@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/pkg/errors"
+	"gitlab.com/distributed_lab/ape"
+	"gitlab.com/distributed_lab/ape/problems"
+
+	"github.com/rarimo/issuer/internal/service/api/requests"
+	"github.com/rarimo/issuer/internal/service/core/issuer"
+)
+
+func FetchCredential(w http.ResponseWriter, r *http.Request) {
+	req, err := requests.NewFetchCredential(r)
+	if err != nil {
+		Log(r).WithField("reason", err).Debug("Bad request")
+		ape.RenderErr(w, problems.BadRequest(err)...)
+		return
+	}
+
+	params, ok := newPackerParams(w, r, req.Response.MediaType, req.Response.Recipient)
+	if !ok {
+		return
+	}
+
+	response, err := Issuer(r).FetchCredential(r.Context(), req)
+	switch {
+	case errors.Is(err, issuer.ErrClaimRetrieverIsNotClaimOwner),
+		errors.Is(err, issuer.ErrProofVerifyFailed),
+		errors.Is(err, issuer.ErrTokenIsReplayed):
+		Log(r).WithField("reason", err).Debug("Forbidden")
+		ape.RenderErr(w, problems.Forbidden())
+		return
+	case errors.Is(err, issuer.ErrMessageRecipientIsNotIssuer):
+		Log(r).WithField("reason", err).Debug("Bad request")
+		ape.RenderErr(w, problems.BadRequest(errors.Cause(err))...)
+		return
+	case errors.Is(err, issuer.ErrClaimIsNotExist):
+		Log(r).WithField("reason", err).Debug("Not found")
+		ape.RenderErr(w, problems.NotFound())
+		return
+	case err != nil:
+		Log(r).WithError(err).
+			WithField("credential-id", req.CredentialID).
+			Error("Failed to fetch credential")
+		ape.RenderErr(w, problems.InternalError())
+		return
+	}
+
+	renderCredentialIssuance(w, r, req.Response.MediaType, params, response)
+}
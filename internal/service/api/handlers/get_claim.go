@@ -0,0 +1,41 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/pkg/errors"
+	"gitlab.com/distributed_lab/ape"
+	"gitlab.com/distributed_lab/ape/problems"
+
+	"github.com/rarimo/issuer/internal/service/api/requests"
+	"github.com/rarimo/issuer/internal/service/core/issuer"
+)
+
+func GetClaim(w http.ResponseWriter, r *http.Request) {
+	req, err := requests.NewClaimByID(r)
+	if err != nil {
+		Log(r).WithField("reason", err).Debug("Bad request")
+		ape.RenderErr(w, problems.BadRequest(err)...)
+		return
+	}
+
+	if !authorizeClaimByID(w, r, req.ClaimID) {
+		return
+	}
+
+	credential, err := Issuer(r).GetCredential(r.Context(), req.ClaimID)
+	switch {
+	case errors.Is(err, issuer.ErrClaimIsNotExist):
+		Log(r).WithField("reason", err).Debug("Not found")
+		ape.RenderErr(w, problems.NotFound())
+		return
+	case err != nil:
+		Log(r).WithError(err).
+			WithField("credential-id", req.ClaimID).
+			Error("Failed to get credential")
+		ape.RenderErr(w, problems.InternalError())
+		return
+	}
+
+	ape.Render(w, credential)
+}
@@ -0,0 +1,16 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+const didDocumentMediaType = "application/did+ld+json"
+
+// GetDIDDocument renders the issuer DID document with the key the signed iden3comm messages are verified with.
+func GetDIDDocument(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", didDocumentMediaType)
+	if err := json.NewEncoder(w).Encode(Issuer(r).GetDIDDocument()); err != nil {
+		Log(r).WithError(err).Error("Failed to render did document")
+	}
+}
@@ -0,0 +1,152 @@
+package handlers
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/pkg/errors"
+	"gitlab.com/distributed_lab/ape"
+	"gitlab.com/distributed_lab/ape/problems"
+
+	"github.com/rarimo/issuer/internal/service/core/idempotency"
+)
+
+const (
+	IdempotencyKeyHeader     = "Idempotency-Key"
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+
+	maxIdempotencyKeyLen = 255
+	// maxIdempotentBodyLen bounds the body of the idempotent request, it is read whole to be hashed
+	maxIdempotentBodyLen = 1 << 20
+)
+
+// Idempotent handles the request with the Idempotency-Key header only once, the repeated request with the
+// same key gets the stored response of the first one. The key can't be reused for the different request,
+// and the responses with the server errors aren't stored, so such requests can be retried with the same key.
+func Idempotent(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(IdempotencyKeyHeader)
+		if key == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if len(key) > maxIdempotencyKeyLen {
+			Log(r).WithField("idempotency-key", key).Debug("Bad request")
+			ape.RenderErr(w, problems.BadRequest(validation.Errors{
+				"header/" + IdempotencyKeyHeader: validation.ErrLengthTooLong.SetParams(map[string]interface{}{
+					"max": maxIdempotencyKeyLen,
+				}),
+			})...)
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxIdempotentBodyLen))
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			Log(r).WithField("reason", err).Debug("Request entity too large")
+			ape.RenderErr(w, requestEntityTooLarge(errors.Errorf("request body exceeds %d bytes", maxBytesErr.Limit)))
+			return
+		}
+		if err != nil {
+			Log(r).WithField("reason", err).Debug("Bad request")
+			ape.RenderErr(w, problems.BadRequest(errors.Wrap(err, "failed to read request body"))...)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		// the keys of the different clients don't collide, since they are namespaced by the principal
+		key = principalIdempotencyKey(r, key)
+
+		store := IdempotencyStore(r)
+		stored, err := store.Begin(key, idempotency.RequestHash(r.Method, r.URL.RequestURI(), body))
+		switch {
+		case errors.Is(err, idempotency.ErrKeyIsReused):
+			Log(r).WithField("reason", err).Debug("Unprocessable entity")
+			ape.RenderErr(w, unprocessableEntity(err))
+			return
+		case errors.Is(err, idempotency.ErrKeyIsInProgress):
+			Log(r).WithField("reason", err).Debug("Conflict")
+			ape.RenderErr(w, conflict(err))
+			return
+		case err != nil:
+			Log(r).WithError(err).WithField("idempotency-key", key).Error("Failed to begin idempotent request")
+			ape.RenderErr(w, problems.InternalError())
+			return
+		case stored != nil:
+			if stored.ContentType != "" {
+				w.Header().Set("Content-Type", stored.ContentType)
+			}
+			w.Header().Set(IdempotentReplayedHeader, "true")
+			w.WriteHeader(*stored.StatusCode)
+			_, _ = w.Write(stored.Response)
+			return
+		}
+
+		isCompleted := false
+		defer func() {
+			// the key is released if the handler failed or panicked, so the request can be retried
+			if isCompleted {
+				return
+			}
+			if err := store.Release(key); err != nil {
+				Log(r).WithError(err).WithField("idempotency-key", key).Error("Failed to release idempotency key")
+			}
+		}()
+
+		recorder := newResponseRecorder(w)
+		next.ServeHTTP(recorder, r)
+
+		if recorder.statusCode >= http.StatusInternalServerError {
+			return
+		}
+
+		// the request is handled, so the key is left locked even if the response isn't stored,
+		// otherwise the retry would be handled again
+		isCompleted = true
+		err = store.Complete(key, recorder.statusCode, w.Header().Get("Content-Type"), recorder.body.Bytes())
+		if err != nil {
+			Log(r).WithError(err).WithField("idempotency-key", key).Error("Failed to store idempotent response")
+		}
+	})
+}
+
+func principalIdempotencyKey(r *http.Request, key string) string {
+	principal := Principal(r)
+	if principal == nil {
+		return key
+	}
+
+	return principal.Method + ":" + principal.ID + ":" + key
+}
+
+// responseRecorder writes the response through and keeps its status code and body.
+type responseRecorder struct {
+	http.ResponseWriter
+	statusCode  int
+	body        bytes.Buffer
+	wroteHeader bool
+}
+
+func newResponseRecorder(w http.ResponseWriter) *responseRecorder {
+	return &responseRecorder{
+		ResponseWriter: w,
+		statusCode:     http.StatusOK,
+	}
+}
+
+func (r *responseRecorder) WriteHeader(statusCode int) {
+	if !r.wroteHeader {
+		r.statusCode = statusCode
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	r.wroteHeader = true
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
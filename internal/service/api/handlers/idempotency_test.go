@@ -0,0 +1,236 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"gitlab.com/distributed_lab/logan/v3"
+
+	"github.com/rarimo/issuer/internal/config"
+	"github.com/rarimo/issuer/internal/data"
+	"github.com/rarimo/issuer/internal/service/core/apikeys"
+	"github.com/rarimo/issuer/internal/service/core/idempotency"
+)
+
+// testIdempotencyKeysQ keeps the keys in memory, the keys are never expired or abandoned.
+type testIdempotencyKeysQ struct {
+	data.IdempotencyKeysQ
+	keys map[string]data.IdempotencyKey
+}
+
+func (q *testIdempotencyKeysQ) Lock(key *data.IdempotencyKey, _, _ time.Time) (bool, error) {
+	if _, ok := q.keys[key.ID]; ok {
+		return false, nil
+	}
+
+	q.keys[key.ID] = *key
+	return true, nil
+}
+
+func (q *testIdempotencyKeysQ) Get(id string) (*data.IdempotencyKey, error) {
+	key, ok := q.keys[id]
+	if !ok {
+		return nil, nil
+	}
+
+	return &key, nil
+}
+
+func (q *testIdempotencyKeysQ) Complete(id string, statusCode int, contentType string, response []byte, completedAt time.Time) error {
+	key := q.keys[id]
+	key.StatusCode, key.ContentType, key.Response, key.CompletedAt = &statusCode, contentType, response, &completedAt
+	q.keys[id] = key
+
+	return nil
+}
+
+func (q *testIdempotencyKeysQ) Delete(id string) error {
+	delete(q.keys, id)
+	return nil
+}
+
+// testIdempotentHandler counts the handled requests and responds with the status.
+type testIdempotentHandler struct {
+	handled int
+	status  int
+}
+
+func (h *testIdempotentHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.handled++
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(h.status)
+	_, _ = w.Write([]byte(`{"handled":true}`))
+}
+
+func newTestIdempotentRequest(key, body string) *http.Request {
+	r := httptest.NewRequest(http.MethodPost, "/claims", strings.NewReader(body))
+	if key != "" {
+		r.Header.Set(IdempotencyKeyHeader, key)
+	}
+
+	return r
+}
+
+func serveIdempotent(store *idempotency.Store, handler http.Handler, r *http.Request) *httptest.ResponseRecorder {
+	ctx := CtxLog(logan.New())(r.Context())
+	ctx = CtxIdempotencyStore(store)(ctx)
+
+	w := httptest.NewRecorder()
+	Idempotent(handler).ServeHTTP(w, r.WithContext(ctx))
+
+	return w
+}
+
+func newTestIdempotencyStore() (*idempotency.Store, *testIdempotencyKeysQ) {
+	q := &testIdempotencyKeysQ{keys: map[string]data.IdempotencyKey{}}
+	return idempotency.NewStore(&config.IdempotencyConfig{TTL: time.Hour, LockTimeout: time.Minute}, q), q
+}
+
+func TestIdempotentReplaysResponse(t *testing.T) {
+	store, _ := newTestIdempotencyStore()
+	handler := &testIdempotentHandler{status: http.StatusCreated}
+
+	first := serveIdempotent(store, handler, newTestIdempotentRequest("key", `{}`))
+	if first.Code != http.StatusCreated || first.Header().Get(IdempotentReplayedHeader) != "" {
+		t.Fatalf("got first response %d replayed %q", first.Code, first.Header().Get(IdempotentReplayedHeader))
+	}
+
+	repeated := serveIdempotent(store, handler, newTestIdempotentRequest("key", `{}`))
+	if handler.handled != 1 {
+		t.Fatalf("request is handled %d times, want 1", handler.handled)
+	}
+	if repeated.Code != http.StatusCreated || repeated.Header().Get(IdempotentReplayedHeader) != "true" {
+		t.Fatalf("got repeated response %d replayed %q", repeated.Code, repeated.Header().Get(IdempotentReplayedHeader))
+	}
+	if repeated.Body.String() != first.Body.String() || repeated.Header().Get("Content-Type") != "application/json" {
+		t.Fatalf("got replayed response %s, want %s", repeated.Body, first.Body)
+	}
+
+	// the request without the key is handled every time
+	serveIdempotent(store, handler, newTestIdempotentRequest("", `{}`))
+	serveIdempotent(store, handler, newTestIdempotentRequest("", `{}`))
+	if handler.handled != 3 {
+		t.Fatalf("requests without the key are handled %d times, want 2", handler.handled-1)
+	}
+}
+
+func TestIdempotentRejectsReusedKey(t *testing.T) {
+	store, _ := newTestIdempotencyStore()
+	handler := &testIdempotentHandler{status: http.StatusCreated}
+
+	serveIdempotent(store, handler, newTestIdempotentRequest("key", `{"a":1}`))
+
+	w := serveIdempotent(store, handler, newTestIdempotentRequest("key", `{"a":2}`))
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("got status %d of the reused key, want %d", w.Code, http.StatusUnprocessableEntity)
+	}
+	if handler.handled != 1 {
+		t.Fatal("request with the reused key is handled")
+	}
+}
+
+func TestIdempotentRejectsKeyInProgress(t *testing.T) {
+	store, q := newTestIdempotencyStore()
+	handler := &testIdempotentHandler{status: http.StatusCreated}
+
+	r := newTestIdempotentRequest("key", `{}`)
+	q.keys["key"] = data.IdempotencyKey{
+		ID:          "key",
+		RequestHash: idempotency.RequestHash(r.Method, r.URL.RequestURI(), []byte(`{}`)),
+	}
+
+	w := serveIdempotent(store, handler, r)
+	if w.Code != http.StatusConflict {
+		t.Fatalf("got status %d of the key in progress, want %d", w.Code, http.StatusConflict)
+	}
+	if handler.handled != 0 {
+		t.Fatal("request with the key in progress is handled")
+	}
+}
+
+func TestIdempotentReleasesKeyOnServerError(t *testing.T) {
+	store, q := newTestIdempotencyStore()
+	handler := &testIdempotentHandler{status: http.StatusInternalServerError}
+
+	serveIdempotent(store, handler, newTestIdempotentRequest("key", `{}`))
+	if _, ok := q.keys["key"]; ok {
+		t.Fatal("key of the failed request isn't released")
+	}
+
+	handler.status = http.StatusCreated
+	w := serveIdempotent(store, handler, newTestIdempotentRequest("key", `{}`))
+	if w.Code != http.StatusCreated || handler.handled != 2 {
+		t.Fatal("failed request isn't handled again on retry")
+	}
+}
+
+func TestIdempotentReleasesKeyOnPanic(t *testing.T) {
+	store, q := newTestIdempotencyStore()
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("handler failed")
+	})
+
+	func() {
+		defer func() { _ = recover() }()
+		serveIdempotent(store, handler, newTestIdempotentRequest("key", `{}`))
+	}()
+
+	if _, ok := q.keys["key"]; ok {
+		t.Fatal("key of the panicked request isn't released")
+	}
+}
+
+func TestIdempotentBoundsRequest(t *testing.T) {
+	store, q := newTestIdempotencyStore()
+	handler := &testIdempotentHandler{status: http.StatusCreated}
+
+	cases := []struct {
+		name     string
+		key      string
+		body     string
+		expected int
+	}{
+		{"key is too long", strings.Repeat("k", maxIdempotencyKeyLen+1), `{}`, http.StatusBadRequest},
+		{"body is too large", "key", strings.Repeat(" ", maxIdempotentBodyLen+1), http.StatusRequestEntityTooLarge},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := serveIdempotent(store, handler, newTestIdempotentRequest(tc.key, tc.body))
+			if w.Code != tc.expected {
+				t.Fatalf("got status %d, want %d", w.Code, tc.expected)
+			}
+		})
+	}
+
+	if handler.handled != 0 || len(q.keys) != 0 {
+		t.Fatal("rejected request is handled")
+	}
+
+	// the body of the limit size is accepted
+	w := serveIdempotent(store, handler, newTestIdempotentRequest("key", strings.Repeat(" ", maxIdempotentBodyLen)))
+	if w.Code != http.StatusCreated {
+		t.Fatalf("got status %d of the body of the limit size, want %d", w.Code, http.StatusCreated)
+	}
+}
+
+func TestIdempotentScopesKeyByPrincipal(t *testing.T) {
+	store, q := newTestIdempotencyStore()
+	handler := &testIdempotentHandler{status: http.StatusCreated}
+
+	r := newTestIdempotentRequest("key", `{}`)
+	principal := &apikeys.Principal{ID: "client", Method: "api_key"}
+	r = r.WithContext(context.WithValue(r.Context(), principalCtxKey, principal))
+	serveIdempotent(store, handler, r)
+
+	if _, ok := q.keys["key"]; ok {
+		t.Fatal("key of the authenticated client isn't namespaced")
+	}
+	if _, ok := q.keys[principalIdempotencyKey(r, "key")]; !ok {
+		t.Fatal("namespaced key isn't stored")
+	}
+}
@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/iden3/iden3comm"
+	"github.com/pkg/errors"
+	"gitlab.com/distributed_lab/ape"
+	"gitlab.com/distributed_lab/ape/problems"
+
+	"github.com/rarimo/issuer/internal/service/core/issuer"
+)
+
+// newPackerParams returns the params of the envelope of the negotiated media type, they are taken before
+// the message is handled, so the recipient without the key to encrypt the response to gets the bad request
+// instead of losing the response. False is returned if the error is rendered.
+func newPackerParams(
+	w http.ResponseWriter,
+	r *http.Request,
+	mediaType iden3comm.MediaType,
+	recipient string,
+) (iden3comm.PackerParams, bool) {
+	params, err := Issuer(r).NewPackerParams(mediaType, recipient)
+	switch {
+	case errors.Is(err, issuer.ErrRecipientKeyIsNotFound):
+		Log(r).WithField("reason", err).Debug("Bad request")
+		ape.RenderErr(w, problems.BadRequest(errors.Cause(err))...)
+		return nil, false
+	case err != nil:
+		Log(r).WithError(err).
+			WithField("media-type", mediaType).
+			Error("Failed to get packer params")
+		ape.RenderErr(w, problems.InternalError())
+		return nil, false
+	}
+
+	return params, true
+}
+
+// renderPackedMessage renders the iden3comm message packed to the envelope of the negotiated media type.
+func renderPackedMessage(
+	w http.ResponseWriter,
+	r *http.Request,
+	mediaType iden3comm.MediaType,
+	message interface{},
+	params iden3comm.PackerParams,
+) {
+	envelope, err := Issuer(r).PackMessage(mediaType, message, params)
+	if err != nil {
+		Log(r).WithError(err).
+			WithField("media-type", mediaType).
+			Error("Failed to pack message")
+		ape.RenderErr(w, problems.InternalError())
+		return
+	}
+
+	w.Header().Set("Content-Type", string(mediaType))
+	if _, err := w.Write(envelope); err != nil {
+		Log(r).WithError(err).Error("Failed to write packed message")
+	}
+}
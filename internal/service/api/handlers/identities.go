@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/pkg/errors"
+	"gitlab.com/distributed_lab/ape"
+	"gitlab.com/distributed_lab/ape/problems"
+
+	"github.com/rarimo/issuer/internal/service/api/requests"
+	"github.com/rarimo/issuer/internal/service/api/responses"
+	"github.com/rarimo/issuer/internal/service/core/issuer"
+)
+
+func CreateIdentity(w http.ResponseWriter, r *http.Request) {
+	req, err := requests.NewCreateIdentity(r)
+	if err != nil {
+		Log(r).WithField("reason", err).Debug("Bad request")
+		ape.RenderErr(w, problems.BadRequest(err)...)
+		return
+	}
+
+	identity, err := Issuers(r).Create(r.Context(), req.Name)
+	switch {
+	case errors.Is(err, issuer.ErrIdentityIsAlreadyExist):
+		Log(r).WithField("reason", err).Debug("Conflict")
+		ape.RenderErr(w, conflict(err))
+		return
+	case errors.Is(err, issuer.ErrKeysEncryptionKeyIsNotSet):
+		Log(r).WithField("reason", err).Debug("Unprocessable entity")
+		ape.RenderErr(w, unprocessableEntity(err))
+		return
+	case err != nil:
+		Log(r).WithError(err).WithField("name", req.Name).Error("Failed to create identity")
+		ape.RenderErr(w, problems.InternalError())
+		return
+	}
+
+	ape.Render(w, responses.NewIdentity(identity))
+}
+
+func ListIdentities(w http.ResponseWriter, r *http.Request) {
+	identities, err := Issuers(r).List()
+	if err != nil {
+		Log(r).WithError(err).Error("Failed to list identities")
+		ape.RenderErr(w, problems.InternalError())
+		return
+	}
+
+	ape.Render(w, responses.NewIdentityList(identities))
+}
@@ -21,7 +21,20 @@ func IssueClaim(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	claimID, err := Issuer(r).IssueClaim(r.Context(), req.UserDID, req.Expiration, req.ClaimType, req.Credential)
+	if !authorizeSchemaTypes(w, r, string(req.ClaimType)) {
+		return
+	}
+
+	claimID, err := Issuer(r).IssueClaim(
+		r.Context(),
+		req.UserDID,
+		schemas.CompactClaimOptions{
+			Expiration: req.Expiration,
+			Updatable:  req.Updatable,
+		},
+		req.ClaimType,
+		req.Credential,
+	)
 	switch {
 	case errors.Is(err, schemas.ErrValidationData):
 		Log(r).WithField("reason", err).Debug("Bad request")
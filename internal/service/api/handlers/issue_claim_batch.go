@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"net/http"
+
+	"gitlab.com/distributed_lab/ape"
+	"gitlab.com/distributed_lab/ape/problems"
+
+	"github.com/rarimo/issuer/internal/service/api/requests"
+	"github.com/rarimo/issuer/internal/service/api/responses"
+	"github.com/rarimo/issuer/internal/service/core/claims/schemas"
+	"github.com/rarimo/issuer/internal/service/core/issuer"
+)
+
+func IssueClaimBatch(w http.ResponseWriter, r *http.Request) {
+	req, err := requests.NewIssueClaimBatch(r, Issuer(r).GetIdentifier())
+	if err != nil {
+		Log(r).WithField("reason", err).Debug("Bad request")
+		ape.RenderErr(w, problems.BadRequest(err)...)
+		return
+	}
+
+	schemaTypes := make([]string, 0, len(req.Items))
+	for _, item := range req.Items {
+		if item.Err == nil {
+			schemaTypes = append(schemaTypes, string(item.Claim.ClaimType))
+		}
+	}
+	if !authorizeSchemaTypes(w, r, schemaTypes...) {
+		return
+	}
+
+	batch := make([]issuer.BatchClaim, 0, len(req.Items))
+	for _, item := range req.Items {
+		if item.Err != nil {
+			batch = append(batch, issuer.BatchClaim{Err: item.Err})
+			continue
+		}
+
+		batch = append(batch, issuer.BatchClaim{
+			UserDID: item.Claim.UserDID,
+			Opts: schemas.CompactClaimOptions{
+				Expiration: item.Claim.Expiration,
+				Updatable:  item.Claim.Updatable,
+			},
+			ClaimType:  item.Claim.ClaimType,
+			Credential: item.Claim.Credential,
+		})
+	}
+
+	results, err := Issuer(r).IssueClaimsBatch(r.Context(), batch, req.Atomic)
+	if err != nil {
+		Log(r).WithError(err).
+			WithField("batch-size", len(batch)).
+			Error("Failed to issue claims batch")
+		ape.RenderErr(w, problems.InternalError())
+		return
+	}
+
+	ape.Render(w, responses.NewIssueClaimBatch(results))
+}
@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"gitlab.com/distributed_lab/ape"
+	"gitlab.com/distributed_lab/ape/problems"
+	"gitlab.com/distributed_lab/kit/pgdb"
+
+	"github.com/rarimo/issuer/internal/service/api/requests"
+	"github.com/rarimo/issuer/internal/service/api/responses"
+	"github.com/rarimo/issuer/resources"
+)
+
+func ListClaims(w http.ResponseWriter, r *http.Request) {
+	req, err := requests.NewListClaims(r)
+	if err != nil {
+		Log(r).WithField("reason", err).Debug("Bad request")
+		ape.RenderErr(w, problems.BadRequest(err)...)
+		return
+	}
+
+	// the principal limited to the schema types lists only the claims of them
+	if principal := Principal(r); principal != nil && principal.IsRestricted() && len(req.SchemaTypes) == 0 {
+		req.SchemaTypes = principal.SchemaTypes
+	}
+	if !authorizeSchemaTypes(w, r, req.SchemaTypes...) {
+		return
+	}
+
+	claimsList, err := Issuer(r).ListClaims(req)
+	if err != nil {
+		Log(r).WithError(err).Error("Failed to list claims")
+		ape.RenderErr(w, problems.InternalError())
+		return
+	}
+
+	ape.Render(w, responses.NewClaimList(claimsList, pageLinks(r, req.Page, len(claimsList))))
+}
+
+// pageLinks keeps the request filters in the links and only moves the page number,
+// the next link is omitted when the current page is not full.
+func pageLinks(r *http.Request, page pgdb.OffsetPageParams, count int) *resources.Links {
+	pageURL := func(pageNumber uint64) string {
+		query := r.URL.Query()
+		query.Set("page[limit]", strconv.FormatUint(page.Limit, 10))
+		query.Set("page[order]", page.Order)
+		query.Set("page[number]", strconv.FormatUint(pageNumber, 10))
+
+		return (&url.URL{Path: r.URL.Path, RawQuery: query.Encode()}).String()
+	}
+
+	links := &resources.Links{
+		Self: pageURL(page.PageNumber),
+	}
+	if uint64(count) == page.Limit {
+		links.Next = pageURL(page.PageNumber + 1)
+	}
+	if page.PageNumber > 0 {
+		links.Prev = pageURL(page.PageNumber - 1)
+	}
+
+	return links
+}
@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"net/http"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/pkg/errors"
+	"gitlab.com/distributed_lab/ape"
+	"gitlab.com/distributed_lab/ape/problems"
+
+	"github.com/rarimo/issuer/internal/service/api/requests"
+	"github.com/rarimo/issuer/internal/service/core/claims/schemas"
+	"github.com/rarimo/issuer/internal/service/core/issuer"
+)
+
+func MerklePaths(w http.ResponseWriter, r *http.Request) {
+	req, err := requests.NewMerklePaths(r)
+	if err != nil {
+		Log(r).WithField("reason", err).Debug("Bad request")
+		ape.RenderErr(w, problems.BadRequest(err)...)
+		return
+	}
+
+	if !authorizeClaimByID(w, r, req.CredentialID) {
+		return
+	}
+
+	response, err := Issuer(r).GetMerklePaths(r.Context(), req.CredentialID, req.Fields)
+	switch {
+	case errors.Is(err, issuer.ErrClaimIsNotExist), errors.Is(err, issuer.ErrClaimIsNotMerklized):
+		Log(r).WithField("reason", err).Debug("Not found")
+		ape.RenderErr(w, problems.NotFound())
+		return
+	case errors.Is(err, schemas.ErrFieldIsNotExist):
+		Log(r).WithField("reason", err).Debug("Bad request")
+		ape.RenderErr(w, problems.BadRequest(validation.Errors{
+			"query/field": errors.Cause(err),
+		})...)
+		return
+	case err != nil:
+		Log(r).WithError(err).
+			WithField("credential-id", req.CredentialID).
+			Error("Failed to get merkle paths")
+		ape.RenderErr(w, problems.InternalError())
+		return
+	}
+
+	ape.Render(w, response)
+}
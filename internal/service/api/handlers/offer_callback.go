@@ -3,6 +3,9 @@ package handlers
 import (
 	"net/http"
 
+	"github.com/iden3/iden3comm"
+	"github.com/iden3/iden3comm/packers"
+	"github.com/iden3/iden3comm/protocol"
 	"github.com/pkg/errors"
 	"gitlab.com/distributed_lab/ape"
 	"gitlab.com/distributed_lab/ape/problems"
@@ -19,18 +22,28 @@ func OfferCallback(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	params, ok := newPackerParams(w, r, req.Response.MediaType, req.Response.Recipient)
+	if !ok {
+		return
+	}
+
 	response, err := Issuer(r).OfferCallback(r.Context(), req)
 	switch {
 	case errors.Is(err, issuer.ErrClaimRetrieverIsNotClaimOwner),
 		errors.Is(err, issuer.ErrRepeatedCallbackRequest),
-		errors.Is(err, issuer.ErrProofVerifyFailed):
+		errors.Is(err, issuer.ErrProofVerifyFailed),
+		errors.Is(err, issuer.ErrTokenIsReplayed):
 		Log(r).WithField("reason", err).Debug("Forbidden")
 		ape.RenderErr(w, problems.Forbidden())
 		return
-	case errors.Is(err, issuer.ErrMessageRecipientIsNotIssuer):
+	case errors.Is(err, issuer.ErrMessageRecipientIsNotIssuer), errors.Is(err, issuer.ErrClaimIsNotOffered):
 		Log(r).WithField("reason", err).Debug("Bad request")
 		ape.RenderErr(w, problems.BadRequest(errors.Cause(err))...)
 		return
+	case errors.Is(err, issuer.ErrClaimOfferIsExpired), errors.Is(err, issuer.ErrClaimOfferIsCanceled):
+		Log(r).WithField("reason", err).Debug("Gone")
+		ape.RenderErr(w, gone(err))
+		return
 	case errors.Is(err, issuer.ErrClaimOfferIsNotExist), errors.Is(err, issuer.ErrClaimIsNotExist):
 		Log(r).WithField("reason", err).Debug("Not found")
 		ape.RenderErr(w, problems.NotFound())
@@ -41,5 +54,23 @@ func OfferCallback(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	ape.Render(w, response)
+	renderCredentialIssuance(w, r, req.Response.MediaType, params, response)
+}
+
+// renderCredentialIssuance renders the plain credential issuance message
+// as before, so the older wallets that don't negotiate the media type keep working.
+func renderCredentialIssuance(
+	w http.ResponseWriter,
+	r *http.Request,
+	mediaType iden3comm.MediaType,
+	params iden3comm.PackerParams,
+	message *protocol.CredentialIssuanceMessage,
+) {
+	if mediaType == packers.MediaTypePlainMessage {
+		ape.Render(w, message)
+		return
+	}
+
+	message.Typ = mediaType
+	renderPackedMessage(w, r, mediaType, message, params)
 }
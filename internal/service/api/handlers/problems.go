@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/google/jsonapi"
+	"github.com/pkg/errors"
+)
+
+// gone is the problem of the resource that existed but isn't available anymore, ape doesn't provide it.
+func gone(err error) *jsonapi.ErrorObject {
+	return newProblem(http.StatusGone, err)
+}
+
+// unauthorized is the unauthorized problem with the detailed reason, unlike the one of ape.
+func unauthorized(err error) *jsonapi.ErrorObject {
+	return newProblem(http.StatusUnauthorized, err)
+}
+
+// unprocessableEntity is the problem of the valid request that can't be handled, ape doesn't provide it.
+func unprocessableEntity(err error) *jsonapi.ErrorObject {
+	return newProblem(http.StatusUnprocessableEntity, err)
+}
+
+// conflict is the conflict problem with the detailed reason, unlike the one of ape.
+func conflict(err error) *jsonapi.ErrorObject {
+	return newProblem(http.StatusConflict, err)
+}
+
+// requestEntityTooLarge is the problem of the request which body exceeds the limit, ape doesn't provide it.
+func requestEntityTooLarge(err error) *jsonapi.ErrorObject {
+	return newProblem(http.StatusRequestEntityTooLarge, err)
+}
+
+func newProblem(status int, err error) *jsonapi.ErrorObject {
+	return &jsonapi.ErrorObject{
+		Title:  http.StatusText(status),
+		Status: fmt.Sprintf("%d", status),
+		Detail: errors.Cause(err).Error(),
+	}
+}
@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/iden3/go-merkletree-sql/v2"
+	"github.com/pkg/errors"
+	"gitlab.com/distributed_lab/ape"
+	"gitlab.com/distributed_lab/ape/problems"
+
+	"github.com/rarimo/issuer/internal/service/api/requests"
+	"github.com/rarimo/issuer/internal/service/api/responses"
+	"github.com/rarimo/issuer/internal/service/core/claims/schemas"
+	"github.com/rarimo/issuer/internal/service/core/issuer"
+)
+
+func ReissueClaim(w http.ResponseWriter, r *http.Request) {
+	req, err := requests.NewReissueClaim(r)
+	if err != nil {
+		Log(r).WithField("reason", err).Debug("Bad request")
+		ape.RenderErr(w, problems.BadRequest(err)...)
+		return
+	}
+
+	if !authorizeClaimByID(w, r, req.ClaimID) {
+		return
+	}
+
+	reissue, err := Issuer(r).ReissueClaim(r.Context(), req)
+	switch {
+	case errors.Is(err, schemas.ErrValidationData):
+		Log(r).WithField("reason", err).Debug("Bad request")
+		ape.RenderErr(w, problems.BadRequest(err)...)
+		return
+	case errors.Is(err, issuer.ErrClaimIsNotExist):
+		Log(r).WithField("reason", err).Debug("Not found")
+		ape.RenderErr(w, problems.NotFound())
+		return
+	case errors.Is(err, issuer.ErrClaimIsAlreadyRevoked),
+		errors.Is(err, schemas.ErrSchemaIsDeprecated),
+		errors.Is(err, merkletree.ErrEntryIndexAlreadyExists):
+		Log(r).WithField("reason", err).Debug("Conflict")
+		ape.RenderErr(w, problems.Conflict())
+		return
+	case errors.Is(err, issuer.ErrAuthClaimIsNotRevocable):
+		Log(r).WithField("reason", err).Debug("Unprocessable entity")
+		ape.RenderErr(w, unprocessableEntity(err))
+		return
+	case err != nil:
+		Log(r).WithError(err).
+			WithField("credential-id", req.ClaimID).
+			Error("Failed to reissue claim")
+		ape.RenderErr(w, problems.InternalError())
+		return
+	}
+
+	ape.Render(w, responses.NewClaimReissue(reissue))
+}
@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"net/http"
+	"unicode"
+
+	"github.com/google/uuid"
+
+	"github.com/rarimo/issuer/internal/service/core/audit"
+)
+
+const (
+	RequestIDHeader = "X-Request-ID"
+
+	maxRequestIDLength = 128
+)
+
+// RequestID identifies the request with the X-Request-ID header or the generated ID if the header is missing
+// or malformed. The ID is returned in the response header, added to the log fields and to the context, so the
+// audit events of the changes made by the request refer to it.
+func RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID := r.Header.Get(RequestIDHeader)
+		if !isValidRequestID(requestID) {
+			requestID = uuid.NewString()
+		}
+
+		w.Header().Set(RequestIDHeader, requestID)
+
+		ctx := audit.CtxRequestID(r.Context(), requestID)
+		ctx = CtxLog(Log(r).WithField("request_id", requestID))(ctx)
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+func isValidRequestID(requestID string) bool {
+	if requestID == "" || len(requestID) > maxRequestIDLength {
+		return false
+	}
+
+	for _, char := range requestID {
+		if char > unicode.MaxASCII || !unicode.IsPrint(char) {
+			return false
+		}
+	}
+
+	return true
+}
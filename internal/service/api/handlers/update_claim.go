@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/pkg/errors"
+	"gitlab.com/distributed_lab/ape"
+	"gitlab.com/distributed_lab/ape/problems"
+
+	"github.com/rarimo/issuer/internal/service/api/requests"
+	"github.com/rarimo/issuer/internal/service/api/responses"
+	"github.com/rarimo/issuer/internal/service/core/claims/schemas"
+	"github.com/rarimo/issuer/internal/service/core/issuer"
+)
+
+func UpdateClaim(w http.ResponseWriter, r *http.Request) {
+	req, err := requests.NewUpdateClaim(r)
+	if err != nil {
+		Log(r).WithField("reason", err).Debug("Bad request")
+		ape.RenderErr(w, problems.BadRequest(err)...)
+		return
+	}
+
+	if !authorizeClaimByID(w, r, req.ClaimID) {
+		return
+	}
+
+	claimID, err := Issuer(r).UpdateClaim(r.Context(), req)
+	switch {
+	case errors.Is(err, schemas.ErrValidationData), errors.Is(err, issuer.ErrClaimIndexChanged):
+		Log(r).WithField("reason", err).Debug("Bad request")
+		ape.RenderErr(w, problems.BadRequest(err)...)
+		return
+	case errors.Is(err, issuer.ErrClaimIsNotExist):
+		Log(r).WithField("reason", err).Debug("Not found")
+		ape.RenderErr(w, problems.NotFound())
+		return
+	case errors.Is(err, issuer.ErrClaimIsAlreadyRevoked),
+		errors.Is(err, issuer.ErrClaimIsExpired),
+		errors.Is(err, issuer.ErrClaimIsNotUpdatable):
+		Log(r).WithField("reason", err).Debug("Conflict")
+		ape.RenderErr(w, problems.Conflict())
+		return
+	case err != nil:
+		Log(r).WithError(err).
+			WithField("credential-id", req.ClaimID).
+			Error("Failed to update claim")
+		ape.RenderErr(w, problems.InternalError())
+		return
+	}
+
+	ape.Render(w, responses.NewIssueClaim(claimID))
+}
@@ -0,0 +1,53 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"gitlab.com/distributed_lab/ape"
+	"gitlab.com/distributed_lab/ape/problems"
+
+	"github.com/rarimo/issuer/internal/data"
+	"github.com/rarimo/issuer/internal/data/pg"
+	"github.com/rarimo/issuer/internal/service/api/handlers"
+	"github.com/rarimo/issuer/internal/service/core/apikeys"
+	"github.com/rarimo/issuer/internal/service/core/idempotency"
+)
+
+const issuerDIDParam = "issuer-did"
+
+// scopeIssuer adds the issuer of the DID from the URL to the request context along with the authenticator
+// and the idempotency store of its identity. The URLs without the DID are served by the default identity.
+func (s *service) scopeIssuer(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		issuer := s.issuers.Default()
+		if did := chi.URLParam(r, issuerDIDParam); did != "" {
+			issuer = s.issuers.Get(did)
+			if issuer == nil {
+				handlers.Log(r).WithField("did", did).Debug("Issuer is not hosted")
+				ape.RenderErr(w, problems.NotFound())
+				return
+			}
+		}
+
+		identityID := issuer.GetIdentityID()
+
+		var authenticator *apikeys.Authenticator
+		if !s.cfg.Auth().Disabled {
+			authenticator = apikeys.NewAuthenticator(
+				s.cfg.Auth(),
+				pg.NewAPIKeysQ(s.cfg.DB(), identityID),
+				issuer.GetDID(),
+				identityID == data.DefaultIdentityID,
+			)
+		}
+
+		ctx := handlers.CtxIssuer(issuer)(r.Context())
+		ctx = handlers.CtxAuthenticator(authenticator)(ctx)
+		ctx = handlers.CtxIdempotencyStore(
+			idempotency.NewStore(s.cfg.Idempotency(), pg.NewIdempotencyKeysQ(s.cfg.DB(), identityID)),
+		)(ctx)
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
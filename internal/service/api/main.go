@@ -17,20 +17,27 @@ type service struct {
 	log      *logan.Entry
 	listener net.Listener
 	copus    types.Copus
-	issuer   issuerPkg.Issuer
+	cfg      config.Config
+	issuers  *issuerPkg.Registry
 }
 
 func newService(ctx context.Context, cfg config.Config) (*service, error) {
-	issuer, err := issuerPkg.New(ctx, cfg)
+	issuers, err := issuerPkg.NewRegistry(ctx, cfg)
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to create new issuer")
+		return nil, errors.Wrap(err, "failed to create issuers registry")
+	}
+	go issuers.Run(ctx)
+
+	if cfg.Auth().Disabled {
+		cfg.Log().Warn("Private API authentication is disabled")
 	}
 
 	return &service{
 		log:      cfg.Log().WithField("service", "api"),
 		listener: cfg.Listener(),
 		copus:    cfg.Copus(),
-		issuer:   issuer,
+		cfg:      cfg,
+		issuers:  issuers,
 	}, nil
 }
 
@@ -0,0 +1,195 @@
+package requests
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/iden3/go-jwz"
+	"github.com/iden3/iden3comm"
+	"github.com/iden3/iden3comm/packers"
+	"github.com/iden3/iden3comm/protocol"
+	"gitlab.com/distributed_lab/logan/v3/errors"
+)
+
+const (
+	// CredentialRefreshMessageType is type of the request of the actual credential that replaced the given one
+	CredentialRefreshMessageType iden3comm.ProtocolMessage = iden3comm.Iden3Protocol + "credentials/1.0/refresh"
+	// ProblemReportMessageType is type of the message that reports the problem of the previous message
+	ProblemReportMessageType iden3comm.ProtocolMessage = iden3comm.Iden3Protocol + "report-problem/1.0/problem-report"
+)
+
+// CredentialRefreshMessage is the request of the actual credential, that is the credential itself
+// or its replacement if it was reissued.
+type CredentialRefreshMessage struct {
+	ID       string                       `json:"id"`
+	Typ      iden3comm.MediaType          `json:"typ,omitempty"`
+	Type     iden3comm.ProtocolMessage    `json:"type"`
+	ThreadID string                       `json:"thid,omitempty"`
+	Body     CredentialRefreshMessageBody `json:"body,omitempty"`
+
+	From string `json:"from,omitempty"`
+	To   string `json:"to,omitempty"`
+}
+
+type CredentialRefreshMessageBody struct {
+	ID     string `json:"id"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// ProblemReportMessage reports the problem of the message from the thread.
+type ProblemReportMessage struct {
+	ID       string                    `json:"id"`
+	Typ      iden3comm.MediaType       `json:"typ,omitempty"`
+	Type     iden3comm.ProtocolMessage `json:"type"`
+	ThreadID string                    `json:"thid,omitempty"`
+	Body     ProblemReportMessageBody  `json:"body,omitempty"`
+
+	From string `json:"from,omitempty"`
+	To   string `json:"to,omitempty"`
+}
+
+type ProblemReportMessageBody struct {
+	Code       string   `json:"code"`
+	Comment    string   `json:"comment,omitempty"`
+	Args       []string `json:"args,omitempty"`
+	EscalateTo string   `json:"escalate_to,omitempty"`
+}
+
+// AgentRequest is the iden3comm message sent to the agent endpoint, only the message of
+// its type is set. The Token is nil if the message is plain, so it is not authenticated.
+type AgentRequest struct {
+	Token    *jwz.Token
+	Message  *iden3comm.BasicMessage
+	Response *ResponseMediaType
+
+	FetchMessage            *protocol.CredentialFetchRequestMessage
+	RevocationStatusMessage *protocol.RevocationStatusRequestMessage
+	RefreshMessage          *CredentialRefreshMessage
+	ProblemReport           *ProblemReportMessage
+}
+
+func NewAgent(r *http.Request) (*AgentRequest, error) {
+	envelope, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, errors.New("is not a valid request envelope")
+	}
+
+	request, payload, err := unpackAgentEnvelope(bytes.TrimSpace(envelope))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := request.parseMessage(payload); err != nil {
+		return nil, err
+	}
+
+	if request.Token == nil {
+		// the recipient of the plain message is not proven, so it can't get the encrypted response
+		request.Response = &ResponseMediaType{
+			MediaType: parseAcceptedMediaType(r, packers.MediaTypePlainMessage, packers.MediaTypeSignedMessage),
+		}
+		return request, nil
+	}
+
+	request.Response, err = parseResponseMediaType(r, request.Token)
+	if err != nil {
+		return nil, err
+	}
+
+	return request, nil
+}
+
+// unpackAgentEnvelope returns the message from the plain envelope or from the JWZ token payload,
+// the plain message is recognized by the type field, that the full serialized JWZ doesn't have.
+func unpackAgentEnvelope(envelope []byte) (*AgentRequest, []byte, error) {
+	var message iden3comm.BasicMessage
+	if err := json.Unmarshal(envelope, &message); err == nil && message.Type != "" {
+		return &AgentRequest{Message: &message}, envelope, nil
+	}
+
+	token, err := jwz.Parse(string(envelope))
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to parse jwz")
+	}
+
+	if err := json.Unmarshal(token.GetPayload(), &message); err != nil {
+		return nil, nil, errors.Wrap(err, "failed to unmarshal")
+	}
+
+	return &AgentRequest{Token: token, Message: &message}, token.GetPayload(), nil
+}
+
+func (req *AgentRequest) parseMessage(payload []byte) error {
+	var (
+		message interface{}
+		rules   validation.Errors
+	)
+
+	switch req.Message.Type {
+	case protocol.CredentialFetchRequestMessageType:
+		req.FetchMessage = &protocol.CredentialFetchRequestMessage{}
+		message = req.FetchMessage
+	case protocol.RevocationStatusRequestMessageType:
+		req.RevocationStatusMessage = &protocol.RevocationStatusRequestMessage{}
+		message = req.RevocationStatusMessage
+	case CredentialRefreshMessageType:
+		req.RefreshMessage = &CredentialRefreshMessage{}
+		message = req.RefreshMessage
+	case ProblemReportMessageType:
+		req.ProblemReport = &ProblemReportMessage{}
+		message = req.ProblemReport
+	default:
+		return validation.Errors{
+			"message/type": errors.New("message type is not supported"),
+		}
+	}
+
+	if err := json.Unmarshal(payload, message); err != nil {
+		return errors.Wrap(err, "failed to unmarshal message")
+	}
+
+	switch {
+	case req.FetchMessage != nil:
+		rules = req.authenticatedMessageRules(req.FetchMessage.From, req.FetchMessage.To)
+		rules["message/body/id"] = validation.Validate(
+			req.FetchMessage.Body.ID, validation.Required, validation.By(MustBeValidUUID),
+		)
+	case req.RefreshMessage != nil:
+		rules = req.authenticatedMessageRules(req.RefreshMessage.From, req.RefreshMessage.To)
+		rules["message/body/id"] = validation.Validate(
+			req.RefreshMessage.Body.ID, validation.Required, validation.By(MustBeValidUUID),
+		)
+	case req.RevocationStatusMessage != nil:
+		rules = validation.Errors{
+			"message/from": validation.Validate(
+				req.RevocationStatusMessage.From,
+				validation.When(req.RevocationStatusMessage.From != "", validation.By(MustBeValidDID)),
+			),
+		}
+	case req.ProblemReport != nil:
+		rules = validation.Errors{
+			"message/body/code": validation.Validate(req.ProblemReport.Body.Code, validation.Required),
+		}
+	}
+
+	return rules.Filter()
+}
+
+// authenticatedMessageRules requires the message to be sent in the JWZ token, as it has to be proven
+// by the sender, and both the sender and the recipient to be set.
+func (req *AgentRequest) authenticatedMessageRules(from, to string) validation.Errors {
+	return validation.Errors{
+		"message/typ": validation.Validate(
+			req.Token, validation.NotNil.Error("message must be sent in the jwz token"),
+		),
+		"message/from": validation.Validate(
+			from, validation.Required, validation.By(MustBeValidDID),
+		),
+		"message/to": validation.Validate(
+			to, validation.Required, validation.By(MustBeValidDID),
+		),
+	}
+}
@@ -0,0 +1,43 @@
+package requests
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/google/uuid"
+)
+
+type claimByIDRequestRaw struct {
+	ClaimID string
+}
+
+type ClaimByIDRequest struct {
+	ClaimID uuid.UUID
+}
+
+func NewClaimByID(r *http.Request) (*ClaimByIDRequest, error) {
+	requestRaw := claimByIDRequestRaw{
+		ClaimID: chi.URLParam(r, credentialIDPathParam),
+	}
+
+	if err := requestRaw.validate(); err != nil {
+		return nil, err
+	}
+
+	return requestRaw.parse(), nil
+}
+
+func (req *claimByIDRequestRaw) validate() error {
+	return validation.Errors{
+		"path/{credential-id}": validation.Validate(
+			req.ClaimID, validation.Required, validation.By(MustBeValidUUID),
+		),
+	}.Filter()
+}
+
+func (req *claimByIDRequestRaw) parse() *ClaimByIDRequest {
+	return &ClaimByIDRequest{
+		ClaimID: uuid.MustParse(req.ClaimID),
+	}
+}
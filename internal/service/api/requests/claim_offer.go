@@ -1,11 +1,14 @@
 package requests
 
 import (
+	"context"
 	"net/http"
 
 	"github.com/go-chi/chi"
 	validation "github.com/go-ozzo/ozzo-validation/v4"
 	core "github.com/iden3/go-iden3-core"
+	"github.com/iden3/iden3comm"
+	"github.com/iden3/iden3comm/packers"
 	"gitlab.com/distributed_lab/logan/v3/errors"
 )
 
@@ -17,6 +20,7 @@ const (
 type ClaimOfferRequest struct {
 	UserDID   *core.DID
 	ClaimType string
+	MediaType iden3comm.MediaType
 }
 
 type claimOfferRequestRaw struct {
@@ -30,18 +34,27 @@ func NewClaimOffer(r *http.Request) (*ClaimOfferRequest, error) {
 		ClaimType: chi.URLParam(r, claimTypePathParam),
 	}
 
-	if err := requestBody.validate(); err != nil {
+	if err := requestBody.validate(r.Context()); err != nil {
 		return nil, err
 	}
 
-	return requestBody.parse(), nil
+	request := requestBody.parse()
+	request.MediaType = parseOfferMediaType(r)
+
+	return request, nil
+}
+
+// parseOfferMediaType negotiates the media type of the claim offer, it can't be encrypted
+// as the offer is requested without the token that could carry the recipient key.
+func parseOfferMediaType(r *http.Request) iden3comm.MediaType {
+	return parseAcceptedMediaType(r, packers.MediaTypePlainMessage, packers.MediaTypeSignedMessage)
 }
 
 // nolint
-func (req *claimOfferRequestRaw) validate() error {
+func (req *claimOfferRequestRaw) validate(ctx context.Context) error {
 	return validation.Errors{
 		"path/{claim-type}": validation.Validate(
-			req.ClaimType, validation.Required, validation.By(MustBeClaimType),
+			req.ClaimType, validation.Required, validation.By(MustBeClaimType(ctx)),
 		),
 		"path/{user-id}": validation.Validate(
 			req.UserID, validation.Required, validation.By(MustBeValidID),
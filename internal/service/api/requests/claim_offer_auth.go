@@ -0,0 +1,71 @@
+package requests
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/iden3/go-jwz"
+	"github.com/iden3/iden3comm/protocol"
+	"gitlab.com/distributed_lab/logan/v3/errors"
+)
+
+// maxOfferAuthThreadIDLen bounds the thread ID of the response, it carries the request signed by the issuer
+const maxOfferAuthThreadIDLen = 1024
+
+type ClaimOfferAuthCallbackRequest struct {
+	Token        *jwz.Token
+	AuthResponse *protocol.AuthorizationResponseMessage
+	Response     *ResponseMediaType
+}
+
+func NewClaimOfferAuthCallback(r *http.Request) (*ClaimOfferAuthCallbackRequest, error) {
+	tokenRaw, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, errors.New("is not a valid request token")
+	}
+
+	token, err := jwz.Parse(string(tokenRaw))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse jwz")
+	}
+
+	var authResponse protocol.AuthorizationResponseMessage
+	if err := json.Unmarshal(token.GetPayload(), &authResponse); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal")
+	}
+
+	request := ClaimOfferAuthCallbackRequest{
+		Token:        token,
+		AuthResponse: &authResponse,
+	}
+	if err := request.validate(); err != nil {
+		return nil, err
+	}
+
+	request.Response, err = parseResponseMediaType(r, token)
+	if err != nil {
+		return nil, err
+	}
+
+	return &request, nil
+}
+
+// nolint
+func (r *ClaimOfferAuthCallbackRequest) validate() error {
+	return validation.Errors{
+		"message/type": validation.Validate(
+			r.AuthResponse.Type, validation.Required, validation.In(protocol.AuthorizationResponseMessageType),
+		),
+		"message/thid": validation.Validate(
+			r.AuthResponse.ThreadID, validation.Required, validation.Length(0, maxOfferAuthThreadIDLen),
+		),
+		"message/from": validation.Validate(
+			r.AuthResponse.From, validation.Required, validation.By(MustBeValidDID),
+		),
+		"message/to": validation.Validate(
+			r.AuthResponse.To, validation.Required, validation.By(MustBeValidDID),
+		),
+	}.Filter()
+}
@@ -0,0 +1,60 @@
+package requests
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/google/uuid"
+	core "github.com/iden3/go-iden3-core"
+	"github.com/iden3/iden3comm"
+)
+
+type ClaimOfferByIDRequest struct {
+	UserDID   *core.DID
+	ClaimID   uuid.UUID
+	MediaType iden3comm.MediaType
+}
+
+type claimOfferByIDRequestRaw struct {
+	UserID  string
+	ClaimID string
+}
+
+func NewClaimOfferByID(r *http.Request) (*ClaimOfferByIDRequest, error) {
+	requestRaw := claimOfferByIDRequestRaw{
+		UserID:  chi.URLParam(r, UserIDPathParam),
+		ClaimID: chi.URLParam(r, credentialIDPathParam),
+	}
+
+	if err := requestRaw.validate(); err != nil {
+		return nil, err
+	}
+
+	request := requestRaw.parse()
+	request.MediaType = parseOfferMediaType(r)
+
+	return request, nil
+}
+
+func (req *claimOfferByIDRequestRaw) validate() error {
+	return validation.Errors{
+		"path/{user-id}": validation.Validate(
+			req.UserID, validation.Required, validation.By(MustBeValidID),
+		),
+		"path/{credential-id}": validation.Validate(
+			req.ClaimID, validation.Required, validation.By(MustBeValidUUID),
+		),
+	}.Filter()
+}
+
+func (req *claimOfferByIDRequestRaw) parse() *ClaimOfferByIDRequest {
+	userID := core.ID{}
+	_ = userID.UnmarshalText([]byte(req.UserID))
+	did, _ := core.ParseDIDFromID(userID)
+
+	return &ClaimOfferByIDRequest{
+		UserDID: did,
+		ClaimID: uuid.MustParse(req.ClaimID),
+	}
+}
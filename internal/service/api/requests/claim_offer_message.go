@@ -0,0 +1,111 @@
+package requests
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"gitlab.com/distributed_lab/logan/v3/errors"
+
+	"github.com/rarimo/issuer/internal/service/core/qrcode"
+)
+
+const (
+	offerIDPathParam = "offer-id"
+
+	formatQueryParam = "format"
+	sizeQueryParam   = "size"
+)
+
+type ClaimOfferIDRequest struct {
+	OfferID string
+}
+
+type ClaimOfferQRCodeRequest struct {
+	OfferID string
+	Format  string
+	Size    int
+}
+
+type claimOfferQRCodeRequestRaw struct {
+	OfferID string
+	Format  string
+	Size    string
+}
+
+func NewClaimOfferID(r *http.Request) (*ClaimOfferIDRequest, error) {
+	offerID := chi.URLParam(r, offerIDPathParam)
+
+	err := validation.Errors{
+		"path/{offer-id}": validation.Validate(
+			offerID, validation.Required, validation.By(MustBeValidUUID),
+		),
+	}.Filter()
+	if err != nil {
+		return nil, err
+	}
+
+	return &ClaimOfferIDRequest{
+		OfferID: offerID,
+	}, nil
+}
+
+func NewClaimOfferQRCode(r *http.Request) (*ClaimOfferQRCodeRequest, error) {
+	requestRaw := claimOfferQRCodeRequestRaw{
+		OfferID: chi.URLParam(r, offerIDPathParam),
+		Format:  r.URL.Query().Get(formatQueryParam),
+		Size:    r.URL.Query().Get(sizeQueryParam),
+	}
+
+	if err := requestRaw.validate(); err != nil {
+		return nil, err
+	}
+
+	return requestRaw.parse(), nil
+}
+
+func (req *claimOfferQRCodeRequestRaw) validate() error {
+	return validation.Errors{
+		"path/{offer-id}": validation.Validate(
+			req.OfferID, validation.Required, validation.By(MustBeValidUUID),
+		),
+		"query/format": validation.Validate(
+			req.Format, validation.In(qrcode.FormatPNG, qrcode.FormatSVG),
+		),
+		"query/size": validation.Validate(
+			req.Size, validation.When(req.Size != "", validation.By(mustBeQRCodeSize)),
+		),
+	}.Filter()
+}
+
+func (req *claimOfferQRCodeRequestRaw) parse() *ClaimOfferQRCodeRequest {
+	request := ClaimOfferQRCodeRequest{
+		OfferID: req.OfferID,
+		Format:  qrcode.FormatPNG,
+		Size:    qrcode.DefaultSize,
+	}
+
+	if req.Format != "" {
+		request.Format = req.Format
+	}
+	if req.Size != "" {
+		request.Size, _ = strconv.Atoi(req.Size)
+	}
+
+	return &request
+}
+
+func mustBeQRCodeSize(src interface{}) error {
+	sizeRaw, ok := src.(string)
+	if !ok {
+		return errors.New("it is not a string")
+	}
+
+	size, err := strconv.Atoi(sizeRaw)
+	if err != nil {
+		return errors.New("it is not an integer")
+	}
+
+	return validation.Validate(size, validation.Min(1), validation.Max(qrcode.MaxSize))
+}
@@ -1,18 +1,41 @@
 package requests
 
 import (
+	"context"
+	"encoding/json"
+	"io"
 	"net/http"
+	"strconv"
 
 	"github.com/go-chi/chi"
 	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/google/uuid"
 	core "github.com/iden3/go-iden3-core"
+	"gitlab.com/distributed_lab/logan/v3/errors"
 
+	"github.com/rarimo/issuer/internal/data"
 	"github.com/rarimo/issuer/internal/service/core/claims"
+	"github.com/rarimo/issuer/resources"
 )
 
+var revocationReasons = []interface{}{
+	data.RevocationReasonUnspecified,
+	data.RevocationReasonKeyCompromise,
+	data.RevocationReasonSuperseded,
+	data.RevocationReasonCessationOfOperation,
+	data.RevocationReasonPrivilegeWithdrawn,
+	data.RevocationReasonAffiliationChanged,
+}
+
+// RevocationDetails is the optional revocation request body, the reason is unspecified if it is omitted.
+type RevocationDetails struct {
+	Reason data.RevocationReason
+}
+
 type ClaimRevocationRequest struct {
 	UserID    *core.ID
 	ClaimType claims.ClaimSchemaType
+	Details   RevocationDetails
 }
 
 type claimRevocationRequestRaw struct {
@@ -26,21 +49,29 @@ func NewRevocationClaim(r *http.Request) (*ClaimRevocationRequest, error) {
 		ClaimType: chi.URLParam(r, claimTypePathParam),
 	}
 
-	if err := requestRaw.validate(); err != nil {
+	if err := requestRaw.validate(r.Context()); err != nil {
+		return nil, err
+	}
+
+	details, err := parseRevocationDetails(r)
+	if err != nil {
 		return nil, err
 	}
 
-	return requestRaw.parse(), nil
+	request := requestRaw.parse()
+	request.Details = *details
+
+	return request, nil
 }
 
 // nolint
-func (req *claimRevocationRequestRaw) validate() error {
+func (req *claimRevocationRequestRaw) validate(ctx context.Context) error {
 	return validation.Errors{
 		"path/{user-id}": validation.Validate(
 			req.UserID, validation.Required, validation.By(MustBeValidID),
 		),
 		"path/{claim-type}": validation.Validate(
-			req.ClaimType, validation.Required, validation.By(MustBeClaimType),
+			req.ClaimType, validation.Required, validation.By(MustBeClaimType(ctx)),
 		),
 	}.Filter()
 }
@@ -52,6 +83,99 @@ func (req *claimRevocationRequestRaw) parse() *ClaimRevocationRequest {
 
 	return &ClaimRevocationRequest{
 		UserID:    userID,
-		ClaimType: claims.ClaimSchemaTypeList[req.ClaimType],
+		ClaimType: claims.ClaimSchemaType(req.ClaimType),
+	}
+}
+
+type ClaimRevocationByIDRequest struct {
+	ClaimID uuid.UUID
+	Details RevocationDetails
+}
+
+func NewClaimRevocationByID(r *http.Request) (*ClaimRevocationByIDRequest, error) {
+	claimByID, err := NewClaimByID(r)
+	if err != nil {
+		return nil, err
+	}
+
+	details, err := parseRevocationDetails(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ClaimRevocationByIDRequest{
+		ClaimID: claimByID.ClaimID,
+		Details: *details,
+	}, nil
+}
+
+type RevocationByNonceRequest struct {
+	RevNonce uint64
+	Details  RevocationDetails
+}
+
+// NewRevocationByNonce parses the revocation nonce path param of the revocation lookup.
+func NewRevocationByNonce(r *http.Request) (*RevocationByNonceRequest, error) {
+	revNonceRaw := chi.URLParam(r, revocationIDPathParam)
+
+	err := validation.Errors{
+		"path/{rev-id}": validation.Validate(
+			revNonceRaw, validation.Required, validation.By(MustBeCorrectRevocationID),
+		),
+	}.Filter()
+	if err != nil {
+		return nil, err
+	}
+
+	revNonce, _ := strconv.ParseUint(revNonceRaw, 10, 64)
+
+	return &RevocationByNonceRequest{
+		RevNonce: revNonce,
+	}, nil
+}
+
+func NewClaimRevocationByNonce(r *http.Request) (*RevocationByNonceRequest, error) {
+	request, err := NewRevocationByNonce(r)
+	if err != nil {
+		return nil, err
+	}
+
+	details, err := parseRevocationDetails(r)
+	if err != nil {
+		return nil, err
+	}
+	request.Details = *details
+
+	return request, nil
+}
+
+func parseRevocationDetails(r *http.Request) (*RevocationDetails, error) {
+	requestBody := resources.RevokeClaimRequest{}
+
+	err := json.NewDecoder(r.Body).Decode(&requestBody)
+	if err != nil && err != io.EOF {
+		return nil, errors.Wrap(err, "failed to decode json request body")
 	}
+
+	return newRevocationDetails(requestBody.Data.Attributes.Reason)
+}
+
+func newRevocationDetails(reason *string) (*RevocationDetails, error) {
+	err := validation.Errors{
+		"data/attributes/reason": validation.Validate(
+			reason, validation.In(revocationReasons...),
+		),
+	}.Filter()
+	if err != nil {
+		return nil, err
+	}
+
+	details := RevocationDetails{
+		Reason: data.RevocationReasonUnspecified,
+	}
+	if reason != nil && *reason != "" {
+		details.Reason = data.RevocationReason(*reason)
+	}
+
+	return &details, nil
 }
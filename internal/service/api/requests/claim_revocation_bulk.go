@@ -0,0 +1,107 @@
+package requests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"gitlab.com/distributed_lab/logan/v3/errors"
+
+	"github.com/rarimo/issuer/resources"
+)
+
+const (
+	maxClaimRevocationBulkSize = 1000
+)
+
+type ClaimRevocationBulkRequest struct {
+	Items   []ClaimRevocationBulkItem
+	Details RevocationDetails
+}
+
+// ClaimRevocationBulkItem addresses the claim by ID or by the revocation nonce if the ID is empty.
+type ClaimRevocationBulkItem struct {
+	ClaimID  string
+	RevNonce uint64
+}
+
+func NewClaimRevocationBulk(r *http.Request) (*ClaimRevocationBulkRequest, error) {
+	requestBody := resources.RevokeClaimBulkRequest{}
+
+	if err := json.NewDecoder(r.Body).Decode(&requestBody); err != nil {
+		return nil, errors.Wrap(err, "failed to decode json request body")
+	}
+
+	attributes := requestBody.Data.Attributes
+	if err := (validation.Errors{
+		"data/attributes/items": validation.Validate(
+			attributes.Items, validation.Required, validation.Length(1, maxClaimRevocationBulkSize),
+		),
+	}).Filter(); err != nil {
+		return nil, err
+	}
+
+	details, err := newRevocationDetails(attributes.Reason)
+	if err != nil {
+		return nil, err
+	}
+
+	request := ClaimRevocationBulkRequest{
+		Items:   make([]ClaimRevocationBulkItem, 0, len(attributes.Items)),
+		Details: *details,
+	}
+
+	invalidItems := validation.Errors{}
+	for i, item := range attributes.Items {
+		parsed, err := parseClaimRevocationBulkItem(item)
+		if err != nil {
+			invalidItems[fmt.Sprintf("data/attributes/items/%d", i)] = err
+			continue
+		}
+
+		request.Items = append(request.Items, *parsed)
+	}
+
+	if len(invalidItems) > 0 {
+		return nil, invalidItems
+	}
+
+	return &request, nil
+}
+
+func parseClaimRevocationBulkItem(item resources.RevokeClaimBulkItem) (*ClaimRevocationBulkItem, error) {
+	if err := (validation.Errors{
+		"claim_id": validation.Validate(
+			item.ClaimId,
+			validation.When(item.RevNonce == nil, validation.Required),
+			validation.When(item.RevNonce != nil, validation.Nil.Error("must be blank if rev_nonce is set")),
+			validation.By(func(src interface{}) error {
+				if item.ClaimId == nil {
+					return nil
+				}
+				return MustBeValidUUID(*item.ClaimId)
+			}),
+		),
+		"rev_nonce": validation.Validate(
+			item.RevNonce,
+			validation.By(func(src interface{}) error {
+				if item.RevNonce == nil {
+					return nil
+				}
+				return MustBeCorrectRevocationID(*item.RevNonce)
+			}),
+		),
+	}).Filter(); err != nil {
+		return nil, err
+	}
+
+	if item.ClaimId != nil {
+		return &ClaimRevocationBulkItem{ClaimID: *item.ClaimId}, nil
+	}
+
+	revNonce, _ := strconv.ParseUint(*item.RevNonce, 10, 64)
+
+	return &ClaimRevocationBulkItem{RevNonce: revNonce}, nil
+}
@@ -0,0 +1,36 @@
+package requests
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+const (
+	schemaTypePathParam = "schema-type"
+)
+
+type ClaimSchemaRequest struct {
+	SchemaType string
+}
+
+func NewClaimSchema(r *http.Request) (*ClaimSchemaRequest, error) {
+	request := ClaimSchemaRequest{
+		SchemaType: chi.URLParam(r, schemaTypePathParam),
+	}
+
+	if err := request.validate(); err != nil {
+		return nil, err
+	}
+
+	return &request, nil
+}
+
+// validate doesn't check the schema type to exist, as the unknown schema type
+// is the not found resource rather than the bad request
+func (req *ClaimSchemaRequest) validate() error {
+	return validation.Errors{
+		"path/{schema-type}": validation.Validate(req.SchemaType, validation.Required),
+	}.Filter()
+}
@@ -0,0 +1,58 @@
+package requests
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/google/uuid"
+)
+
+const (
+	versionPathParam = "version"
+)
+
+type claimVersionRequestRaw struct {
+	ClaimID string
+	Version string
+}
+
+type ClaimVersionRequest struct {
+	ClaimID uuid.UUID
+	Version uint32
+}
+
+func NewClaimVersion(r *http.Request) (*ClaimVersionRequest, error) {
+	requestRaw := claimVersionRequestRaw{
+		ClaimID: chi.URLParam(r, credentialIDPathParam),
+		Version: chi.URLParam(r, versionPathParam),
+	}
+
+	if err := requestRaw.validate(); err != nil {
+		return nil, err
+	}
+
+	return requestRaw.parse(), nil
+}
+
+func (req *claimVersionRequestRaw) validate() error {
+	return validation.Errors{
+		"path/{credential-id}": validation.Validate(
+			req.ClaimID, validation.Required, validation.By(MustBeValidUUID),
+		),
+		"path/{version}": validation.Validate(
+			req.Version, validation.Required, validation.By(MustBeUintInRange(0, math.MaxUint32)),
+		),
+	}.Filter()
+}
+
+func (req *claimVersionRequestRaw) parse() *ClaimVersionRequest {
+	version, _ := strconv.ParseUint(req.Version, 10, 32)
+
+	return &ClaimVersionRequest{
+		ClaimID: uuid.MustParse(req.ClaimID),
+		Version: uint32(version),
+	}
+}
@@ -0,0 +1,79 @@
+package requests
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	core "github.com/iden3/go-iden3-core"
+	"github.com/iden3/iden3comm"
+)
+
+const (
+	claimIDQueryParam = "claim_id"
+
+	// MaxBundleOfferClaims is the max number of the claims offered in the single bundle offer
+	MaxBundleOfferClaims = 100
+)
+
+// ClaimsBundleOfferRequest offers the chosen claims of the user in the single offer,
+// the ClaimIDs are empty if all the pending claims of the user are offered.
+type ClaimsBundleOfferRequest struct {
+	UserDID   *core.DID
+	ClaimIDs  []string
+	MediaType iden3comm.MediaType
+}
+
+type claimsBundleOfferRequestRaw struct {
+	UserID   string
+	ClaimIDs []string
+}
+
+func NewClaimsBundleOffer(r *http.Request) (*ClaimsBundleOfferRequest, error) {
+	requestRaw := claimsBundleOfferRequestRaw{
+		UserID:   chi.URLParam(r, UserIDPathParam),
+		ClaimIDs: r.URL.Query()[claimIDQueryParam],
+	}
+
+	if err := requestRaw.validate(); err != nil {
+		return nil, err
+	}
+
+	request := requestRaw.parse()
+	request.MediaType = parseOfferMediaType(r)
+
+	return request, nil
+}
+
+func (req *claimsBundleOfferRequestRaw) validate() error {
+	return validation.Errors{
+		"path/{user-id}": validation.Validate(
+			req.UserID, validation.Required, validation.By(MustBeValidID),
+		),
+		"query/claim_id": validation.Validate(
+			req.ClaimIDs,
+			validation.Length(0, MaxBundleOfferClaims),
+			validation.Each(validation.Required, validation.By(MustBeValidUUID)),
+		),
+	}.Filter()
+}
+
+func (req *claimsBundleOfferRequestRaw) parse() *ClaimsBundleOfferRequest {
+	userID := core.ID{}
+	_ = userID.UnmarshalText([]byte(req.UserID))
+	did, _ := core.ParseDIDFromID(userID)
+
+	claimIDs := make([]string, 0, len(req.ClaimIDs))
+	isChosen := make(map[string]bool, len(req.ClaimIDs))
+	for _, claimID := range req.ClaimIDs {
+		if !isChosen[claimID] {
+			isChosen[claimID] = true
+			claimIDs = append(claimIDs, claimID)
+		}
+	}
+
+	return &ClaimsBundleOfferRequest{
+		UserDID:  did,
+		ClaimIDs: claimIDs,
+	}
+}
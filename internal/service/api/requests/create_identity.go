@@ -0,0 +1,46 @@
+package requests
+
+import (
+	"encoding/json"
+	"net/http"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"gitlab.com/distributed_lab/logan/v3/errors"
+
+	"github.com/rarimo/issuer/resources"
+)
+
+type CreateIdentityRequest struct {
+	Name string
+}
+
+type createIdentityRequestRaw struct {
+	Body resources.IdentityRequest
+}
+
+func NewCreateIdentity(r *http.Request) (*CreateIdentityRequest, error) {
+	requestRaw := createIdentityRequestRaw{}
+
+	if err := json.NewDecoder(r.Body).Decode(&requestRaw.Body); err != nil {
+		return nil, errors.Wrap(err, "failed to decode json request body")
+	}
+
+	if err := requestRaw.validate(); err != nil {
+		return nil, err
+	}
+
+	return &CreateIdentityRequest{
+		Name: requestRaw.Body.Data.Attributes.Name,
+	}, nil
+}
+
+func (req *createIdentityRequestRaw) validate() error {
+	return validation.Errors{
+		"data/type": validation.Validate(
+			req.Body.Data.Type, validation.Required, validation.In(resources.IDENTITY),
+		),
+		"data/attributes/name": validation.Validate(
+			req.Body.Data.Attributes.Name, validation.Required, validation.Length(1, 256),
+		),
+	}.Filter()
+}
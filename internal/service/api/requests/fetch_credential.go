@@ -0,0 +1,80 @@
+package requests
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/google/uuid"
+	"github.com/iden3/go-jwz"
+	"github.com/iden3/iden3comm/protocol"
+)
+
+// FetchCredentialRequest is the credential fetch message signed by the claim owner,
+// it allows the owner to get the issued credential again without the claim offer.
+type FetchCredentialRequest struct {
+	CredentialID uuid.UUID
+	Token        *jwz.Token
+	FetchMessage *protocol.CredentialFetchRequestMessage
+	Response     *ResponseMediaType
+}
+
+type fetchCredentialRequestRaw struct {
+	CredentialID string
+	Token        *jwz.Token
+	FetchMessage *protocol.CredentialFetchRequestMessage
+}
+
+func NewFetchCredential(r *http.Request) (*FetchCredentialRequest, error) {
+	token, fetchMessage, err := parseFetchMessageToken(r)
+	if err != nil {
+		return nil, err
+	}
+
+	requestRaw := fetchCredentialRequestRaw{
+		CredentialID: chi.URLParam(r, credentialIDPathParam),
+		Token:        token,
+		FetchMessage: fetchMessage,
+	}
+	if err := requestRaw.validate(); err != nil {
+		return nil, err
+	}
+
+	response, err := parseResponseMediaType(r, token)
+	if err != nil {
+		return nil, err
+	}
+
+	request := requestRaw.parse()
+	request.Response = response
+
+	return request, nil
+}
+
+func (req *fetchCredentialRequestRaw) validate() error {
+	return validation.Errors{
+		"path/{credential-id}": validation.Validate(
+			req.CredentialID, validation.Required, validation.By(MustBeValidUUID),
+		),
+		"message/type": validation.Validate(
+			req.FetchMessage.Type, validation.Required, validation.In(protocol.CredentialFetchRequestMessageType),
+		),
+		"message/from": validation.Validate(
+			req.FetchMessage.From, validation.Required, validation.By(MustBeValidDID),
+		),
+		"message/to": validation.Validate(
+			req.FetchMessage.To, validation.Required, validation.By(MustBeValidDID),
+		),
+		"message/body/id": validation.Validate(
+			req.FetchMessage.Body.ID, validation.Required, validation.In(req.CredentialID),
+		),
+	}.Filter()
+}
+
+func (req *fetchCredentialRequestRaw) parse() *FetchCredentialRequest {
+	return &FetchCredentialRequest{
+		CredentialID: uuid.MustParse(req.CredentialID),
+		Token:        req.Token,
+		FetchMessage: req.FetchMessage,
+	}
+}
@@ -1,6 +1,7 @@
 package requests
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"time"
@@ -19,6 +20,7 @@ type IssueClaimRequest struct {
 	ClaimType  claims.ClaimSchemaType
 	Expiration *time.Time
 	Credential []byte
+	Updatable  bool
 }
 
 type issueClaimRequestRaw struct {
@@ -40,40 +42,53 @@ func NewIssueClaim(r *http.Request, issuerID string) (*IssueClaimRequest, error)
 		Body:      requestBody,
 	}
 
-	if err := requestRaw.validate(issuerID); err != nil {
+	if err := requestRaw.validate(r.Context(), issuerID); err != nil {
 		return nil, err
 	}
 
-	schemaType := claims.ClaimSchemaTypeList[requestRaw.ClaimType]
-	if err := validation.Validate(
-		requestRaw.Body.Data.Attributes.CredentialSubject,
-		validation.By(
-			claims.ClaimSchemaList[schemaType].ClaimDataValidateFunc,
-		),
-	); err != nil {
-		return nil, errors.Wrap(err, "invalid schema data")
-	}
-
-	parseData, err := claims.ClaimSchemaList[schemaType].ClaimDataParseFunc(
-		requestRaw.Body.Data.Attributes.CredentialSubject,
+	credentialSubject, err := parseCredentialSubject(
+		r.Context(), requestRaw.ClaimType, requestRaw.Body.Data.Attributes.CredentialSubject,
 	)
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to parse kyc full name data")
+		return nil, err
 	}
-
-	requestRaw.Body.Data.Attributes.CredentialSubject = parseData
+	requestRaw.Body.Data.Attributes.CredentialSubject = credentialSubject
 
 	return requestRaw.parse(), nil
 }
 
+// parseCredentialSubject validates and coerces the credential subject with the claim schema functions.
+func parseCredentialSubject(ctx context.Context, claimType string, credentialSubject json.RawMessage) (json.RawMessage, error) {
+	claimData, _ := claims.GetClaimSchema(ctx, claims.ClaimSchemaType(claimType))
+	if claimData.ClaimDataValidateFunc != nil {
+		if err := validation.Validate(
+			credentialSubject,
+			validation.By(claimData.ClaimDataValidateFunc),
+		); err != nil {
+			return nil, errors.Wrap(err, "invalid schema data")
+		}
+	}
+
+	if claimData.ClaimDataParseFunc != nil {
+		parseData, err := claimData.ClaimDataParseFunc(credentialSubject)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to parse credential subject")
+		}
+
+		credentialSubject = parseData
+	}
+
+	return credentialSubject, nil
+}
+
 // nolint
-func (req *issueClaimRequestRaw) validate(issuerID string) error {
+func (req *issueClaimRequestRaw) validate(ctx context.Context, issuerID string) error {
 	return validation.Errors{
 		"path/{user-id}": validation.Validate(
 			req.UserID, validation.Required, validation.By(MustBeValidID), validation.NotIn(issuerID),
 		),
 		"path/{claim-type}": validation.Validate(
-			req.ClaimType, validation.Required, validation.By(MustBeClaimType),
+			req.ClaimType, validation.Required, validation.By(MustBeActiveClaimType(ctx)),
 		),
 		"data/attributes/credential_subject": validation.Validate(
 			req.Body.Data.Attributes.CredentialSubject, validation.Required,
@@ -88,17 +103,41 @@ func (req *issueClaimRequestRaw) validate(issuerID string) error {
 	}.Filter()
 }
 
-func MustBeClaimType(src interface{}) error {
-	schemaTypeRaw, ok := src.(string)
-	if !ok {
-		return errors.New("it is not a schema type")
-	}
+// MustBeClaimType checks that the schema type is registered, the schema missing in the registry
+// is read from the db within the ctx of the request.
+func MustBeClaimType(ctx context.Context) validation.RuleFunc {
+	return func(src interface{}) error {
+		schemaTypeRaw, ok := src.(string)
+		if !ok {
+			return errors.New("it is not a schema type")
+		}
 
-	if _, ok := claims.ClaimSchemaTypeList[schemaTypeRaw]; !ok {
-		return errors.New("schema type doesn't exist")
+		if _, ok := claims.GetClaimSchema(ctx, claims.ClaimSchemaType(schemaTypeRaw)); !ok {
+			return errors.New("schema type doesn't exist")
+		}
+
+		return nil
 	}
+}
 
-	return nil
+// MustBeActiveClaimType checks that the schema type is registered and isn't deprecated.
+func MustBeActiveClaimType(ctx context.Context) validation.RuleFunc {
+	return func(src interface{}) error {
+		schemaTypeRaw, ok := src.(string)
+		if !ok {
+			return errors.New("it is not a schema type")
+		}
+
+		claimData, ok := claims.GetClaimSchema(ctx, claims.ClaimSchemaType(schemaTypeRaw))
+		if !ok {
+			return errors.New("schema type doesn't exist")
+		}
+		if claimData.IsDeprecated {
+			return errors.New("schema type is deprecated")
+		}
+
+		return nil
+	}
 }
 
 func MustBeValidRFC3339(src interface{}) error {
@@ -132,11 +171,28 @@ func (req *issueClaimRequestRaw) parse() *IssueClaimRequest {
 	return &IssueClaimRequest{
 		Expiration: expiration,
 		UserDID:    did,
-		ClaimType:  claims.ClaimSchemaTypeList[req.ClaimType],
+		ClaimType:  claims.ClaimSchemaType(req.ClaimType),
 		Credential: schemaDataTrimmed,
+		Updatable:  req.Body.Data.Attributes.Updatable != nil && *req.Body.Data.Attributes.Updatable,
 	}
 }
 
+// parseTrimmedCredentialSubject is parseCredentialSubject for the requests that address the existing claim,
+// the schema type is taken from the claim, so the credential subject is parsed after the claim is loaded.
+func parseTrimmedCredentialSubject(ctx context.Context, claimType claims.ClaimSchemaType, credentialSubject json.RawMessage) ([]byte, error) {
+	credentialSubject, err := parseCredentialSubject(ctx, claimType.ToRaw(), credentialSubject)
+	if err != nil {
+		return nil, err
+	}
+
+	credentialSubjectTrimmed, err := jsonRawTrimSpaces(credentialSubject)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to trim credential subject")
+	}
+
+	return credentialSubjectTrimmed, nil
+}
+
 func jsonRawTrimSpaces(jsonRaw []byte) ([]byte, error) {
 	var jsonMap map[string]interface{}
 	err := json.Unmarshal(jsonRaw, &jsonMap)
@@ -0,0 +1,116 @@
+package requests
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"gitlab.com/distributed_lab/logan/v3/errors"
+
+	"github.com/rarimo/issuer/resources"
+)
+
+const (
+	maxIssueClaimBatchSize = 1000
+)
+
+type IssueClaimBatchRequest struct {
+	Atomic bool
+	Items  []IssueClaimBatchItem
+}
+
+// IssueClaimBatchItem is the parsed batch item, the Claim is nil if the item is not valid.
+type IssueClaimBatchItem struct {
+	Claim *IssueClaimRequest
+	Err   error
+}
+
+func NewIssueClaimBatch(r *http.Request, issuerID string) (*IssueClaimBatchRequest, error) {
+	requestBody := resources.IssueClaimBatchRequest{}
+
+	if err := json.NewDecoder(r.Body).Decode(&requestBody); err != nil {
+		return nil, errors.Wrap(err, "failed to decode json request body")
+	}
+
+	attributes := requestBody.Data.Attributes
+	if err := (validation.Errors{
+		"data/attributes/items": validation.Validate(
+			attributes.Items, validation.Required, validation.Length(1, maxIssueClaimBatchSize),
+		),
+	}).Filter(); err != nil {
+		return nil, err
+	}
+
+	request := IssueClaimBatchRequest{
+		Atomic: attributes.Atomic != nil && *attributes.Atomic,
+		Items:  make([]IssueClaimBatchItem, 0, len(attributes.Items)),
+	}
+
+	invalidItems := validation.Errors{}
+	for i, item := range attributes.Items {
+		claim, err := parseIssueClaimBatchItem(r.Context(), item, issuerID)
+		if err != nil {
+			invalidItems[fmt.Sprintf("data/attributes/items/%d", i)] = err
+		}
+
+		request.Items = append(request.Items, IssueClaimBatchItem{
+			Claim: claim,
+			Err:   err,
+		})
+	}
+
+	// all items must be valid to be issued atomically, so the
+	// request is rejected without the issuance of valid ones
+	if request.Atomic && len(invalidItems) > 0 {
+		return nil, invalidItems
+	}
+
+	return &request, nil
+}
+
+func parseIssueClaimBatchItem(ctx context.Context, item resources.IssueClaimBatchItem, issuerID string) (*IssueClaimRequest, error) {
+	requestRaw := issueClaimRequestRaw{
+		UserID:    item.UserId,
+		ClaimType: item.ClaimType,
+		Body: resources.IssueClaimRequest{
+			Data: resources.IssueClaim{
+				Attributes: resources.IssueClaimAttributes{
+					CredentialSubject: item.CredentialSubject,
+					Expiration:        item.Expiration,
+					Updatable:         item.Updatable,
+				},
+			},
+		},
+	}
+
+	if err := (validation.Errors{
+		"user_id": validation.Validate(
+			requestRaw.UserID, validation.Required, validation.By(MustBeValidID), validation.NotIn(issuerID),
+		),
+		"claim_type": validation.Validate(
+			requestRaw.ClaimType, validation.Required, validation.By(MustBeActiveClaimType(ctx)),
+		),
+		"credential_subject": validation.Validate(
+			item.CredentialSubject, validation.Required,
+		),
+		"expiration": validation.Validate(
+			item.Expiration,
+			validation.When(
+				!validation.IsEmpty(item.Expiration),
+				validation.By(MustBeValidRFC3339),
+			),
+		),
+	}).Filter(); err != nil {
+		return nil, err
+	}
+
+	credentialSubject, err := parseCredentialSubject(ctx, requestRaw.ClaimType, item.CredentialSubject)
+	if err != nil {
+		return nil, err
+	}
+	requestRaw.Body.Data.Attributes.CredentialSubject = credentialSubject
+
+	return requestRaw.parse(), nil
+}
@@ -0,0 +1,153 @@
+package requests
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"gitlab.com/distributed_lab/kit/pgdb"
+
+	"github.com/rarimo/issuer/internal/service/core/audit"
+)
+
+const (
+	eventTypeFilterParam        = "filter[event_type]"
+	actorIDFilterParam          = "filter[actor_id]"
+	requestIDFilterParam        = "filter[request_id]"
+	claimIDFilterParam          = "filter[claim_id]"
+	committedStateIDFilterParam = "filter[committed_state_id]"
+	createdFromFilterParam      = "filter[created_from]"
+	createdToFilterParam        = "filter[created_to]"
+)
+
+var auditEventTypes = []interface{}{
+	string(audit.EventClaimIssued),
+	string(audit.EventClaimRevoked),
+	string(audit.EventOfferReceived),
+	string(audit.EventStateCommitted),
+	string(audit.EventStateFailed),
+}
+
+type listAuditEventsRequestRaw struct {
+	EventTypes        []string
+	ActorIDs          []string
+	RequestIDs        []string
+	ClaimIDs          []string
+	CommittedStateIDs []string
+	CreatedFrom       string
+	CreatedTo         string
+	PageLimit         string
+	PageNumber        string
+	PageOrder         string
+}
+
+// ListAuditEventsRequest is the filter of the audit events, the page is ignored
+// by the export that streams all the matching events in the order of insertion.
+type ListAuditEventsRequest struct {
+	EventTypes        []string
+	ActorIDs          []string
+	RequestIDs        []string
+	ClaimIDs          []string
+	CommittedStateIDs []uint64
+	CreatedFrom       *time.Time
+	CreatedTo         *time.Time
+	Page              pgdb.OffsetPageParams
+}
+
+func NewListAuditEvents(r *http.Request) (*ListAuditEventsRequest, error) {
+	query := r.URL.Query()
+
+	requestRaw := listAuditEventsRequestRaw{
+		EventTypes:        query[eventTypeFilterParam],
+		ActorIDs:          query[actorIDFilterParam],
+		RequestIDs:        query[requestIDFilterParam],
+		ClaimIDs:          query[claimIDFilterParam],
+		CommittedStateIDs: query[committedStateIDFilterParam],
+		CreatedFrom:       query.Get(createdFromFilterParam),
+		CreatedTo:         query.Get(createdToFilterParam),
+		PageLimit:         query.Get(pageLimitParam),
+		PageNumber:        query.Get(pageNumberParam),
+		PageOrder:         query.Get(pageOrderParam),
+	}
+
+	if err := requestRaw.validate(); err != nil {
+		return nil, err
+	}
+
+	return requestRaw.parse(), nil
+}
+
+func (req *listAuditEventsRequestRaw) validate() error {
+	return validation.Errors{
+		"query/" + eventTypeFilterParam: validation.Validate(
+			req.EventTypes, validation.Each(validation.Required, validation.In(auditEventTypes...)),
+		),
+		"query/" + actorIDFilterParam: validation.Validate(
+			req.ActorIDs, validation.Each(validation.Required),
+		),
+		"query/" + requestIDFilterParam: validation.Validate(
+			req.RequestIDs, validation.Each(validation.Required),
+		),
+		"query/" + claimIDFilterParam: validation.Validate(
+			req.ClaimIDs, validation.Each(validation.Required, validation.By(MustBeValidUUID)),
+		),
+		"query/" + committedStateIDFilterParam: validation.Validate(
+			req.CommittedStateIDs, validation.Each(
+				validation.Required, validation.By(MustBeUintInRange(0, math.MaxUint64)),
+			),
+		),
+		"query/" + createdFromFilterParam: validation.Validate(
+			req.CreatedFrom, validation.Date(time.RFC3339),
+		),
+		"query/" + createdToFilterParam: validation.Validate(
+			req.CreatedTo, validation.Date(time.RFC3339),
+		),
+		"query/" + pageLimitParam: validation.Validate(
+			req.PageLimit, validation.When(
+				req.PageLimit != "", validation.By(MustBeUintInRange(1, maxPageLimit)),
+			),
+		),
+		"query/" + pageNumberParam: validation.Validate(
+			req.PageNumber, validation.When(
+				req.PageNumber != "", validation.By(MustBeUintInRange(0, math.MaxUint64)),
+			),
+		),
+		"query/" + pageOrderParam: validation.Validate(
+			req.PageOrder, validation.In(pgdb.OrderTypeAsc, pgdb.OrderTypeDesc),
+		),
+	}.Filter()
+}
+
+func (req *listAuditEventsRequestRaw) parse() *ListAuditEventsRequest {
+	request := &ListAuditEventsRequest{
+		EventTypes:  req.EventTypes,
+		ActorIDs:    req.ActorIDs,
+		RequestIDs:  req.RequestIDs,
+		ClaimIDs:    req.ClaimIDs,
+		CreatedFrom: parseOptionalTime(req.CreatedFrom),
+		CreatedTo:   parseOptionalTime(req.CreatedTo),
+		Page: pgdb.OffsetPageParams{
+			Limit: defaultPageLimit,
+			Order: pgdb.OrderTypeDesc,
+		},
+	}
+
+	for _, raw := range req.CommittedStateIDs {
+		committedStateID, _ := strconv.ParseUint(raw, 10, 64)
+		request.CommittedStateIDs = append(request.CommittedStateIDs, committedStateID)
+	}
+
+	if req.PageLimit != "" {
+		request.Page.Limit, _ = strconv.ParseUint(req.PageLimit, 10, 64)
+	}
+	if req.PageNumber != "" {
+		request.Page.PageNumber, _ = strconv.ParseUint(req.PageNumber, 10, 64)
+	}
+	if req.PageOrder != "" {
+		request.Page.Order = req.PageOrder
+	}
+
+	return request
+}
@@ -0,0 +1,200 @@
+package requests
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"gitlab.com/distributed_lab/kit/pgdb"
+	"gitlab.com/distributed_lab/logan/v3/errors"
+)
+
+const (
+	userIDFilterParam      = "filter[user_id]"
+	schemaTypeFilterParam  = "filter[schema_type]"
+	revokedFilterParam     = "filter[revoked]"
+	expiredFilterParam     = "filter[expired]"
+	issuedFromFilterParam  = "filter[issued_from]"
+	issuedToFilterParam    = "filter[issued_to]"
+	expiresFromFilterParam = "filter[expires_from]"
+	expiresToFilterParam   = "filter[expires_to]"
+
+	pageLimitParam  = "page[limit]"
+	pageNumberParam = "page[number]"
+	pageOrderParam  = "page[order]"
+
+	defaultPageLimit = 15
+	maxPageLimit     = 100
+)
+
+type listClaimsRequestRaw struct {
+	UserIDs     []string
+	SchemaTypes []string
+	Revoked     string
+	Expired     string
+	IssuedFrom  string
+	IssuedTo    string
+	ExpiresFrom string
+	ExpiresTo   string
+	PageLimit   string
+	PageNumber  string
+	PageOrder   string
+}
+
+type ListClaimsRequest struct {
+	UserIDs     []string
+	SchemaTypes []string
+	Revoked     *bool
+	Expired     *bool
+	IssuedFrom  *time.Time
+	IssuedTo    *time.Time
+	ExpiresFrom *time.Time
+	ExpiresTo   *time.Time
+	Page        pgdb.OffsetPageParams
+}
+
+func NewListClaims(r *http.Request) (*ListClaimsRequest, error) {
+	query := r.URL.Query()
+
+	requestRaw := listClaimsRequestRaw{
+		UserIDs:     query[userIDFilterParam],
+		SchemaTypes: query[schemaTypeFilterParam],
+		Revoked:     query.Get(revokedFilterParam),
+		Expired:     query.Get(expiredFilterParam),
+		IssuedFrom:  query.Get(issuedFromFilterParam),
+		IssuedTo:    query.Get(issuedToFilterParam),
+		ExpiresFrom: query.Get(expiresFromFilterParam),
+		ExpiresTo:   query.Get(expiresToFilterParam),
+		PageLimit:   query.Get(pageLimitParam),
+		PageNumber:  query.Get(pageNumberParam),
+		PageOrder:   query.Get(pageOrderParam),
+	}
+
+	if err := requestRaw.validate(); err != nil {
+		return nil, err
+	}
+
+	return requestRaw.parse(), nil
+}
+
+func (req *listClaimsRequestRaw) validate() error {
+	return validation.Errors{
+		"query/" + userIDFilterParam: validation.Validate(
+			req.UserIDs, validation.Each(validation.Required, validation.By(MustBeValidID)),
+		),
+		"query/" + schemaTypeFilterParam: validation.Validate(
+			req.SchemaTypes, validation.Each(validation.Required),
+		),
+		"query/" + revokedFilterParam: validation.Validate(
+			req.Revoked, validation.When(req.Revoked != "", validation.By(MustBeBool)),
+		),
+		"query/" + expiredFilterParam: validation.Validate(
+			req.Expired, validation.When(req.Expired != "", validation.By(MustBeBool)),
+		),
+		"query/" + issuedFromFilterParam: validation.Validate(
+			req.IssuedFrom, validation.Date(time.RFC3339),
+		),
+		"query/" + issuedToFilterParam: validation.Validate(
+			req.IssuedTo, validation.Date(time.RFC3339),
+		),
+		"query/" + expiresFromFilterParam: validation.Validate(
+			req.ExpiresFrom, validation.Date(time.RFC3339),
+		),
+		"query/" + expiresToFilterParam: validation.Validate(
+			req.ExpiresTo, validation.Date(time.RFC3339),
+		),
+		"query/" + pageLimitParam: validation.Validate(
+			req.PageLimit, validation.When(
+				req.PageLimit != "", validation.By(MustBeUintInRange(1, maxPageLimit)),
+			),
+		),
+		"query/" + pageNumberParam: validation.Validate(
+			req.PageNumber, validation.When(
+				req.PageNumber != "", validation.By(MustBeUintInRange(0, math.MaxUint64)),
+			),
+		),
+		"query/" + pageOrderParam: validation.Validate(
+			req.PageOrder, validation.In(pgdb.OrderTypeAsc, pgdb.OrderTypeDesc),
+		),
+	}.Filter()
+}
+
+func (req *listClaimsRequestRaw) parse() *ListClaimsRequest {
+	request := &ListClaimsRequest{
+		UserIDs:     req.UserIDs,
+		SchemaTypes: req.SchemaTypes,
+		IssuedFrom:  parseOptionalTime(req.IssuedFrom),
+		IssuedTo:    parseOptionalTime(req.IssuedTo),
+		ExpiresFrom: parseOptionalTime(req.ExpiresFrom),
+		ExpiresTo:   parseOptionalTime(req.ExpiresTo),
+		Page: pgdb.OffsetPageParams{
+			Limit: defaultPageLimit,
+			Order: pgdb.OrderTypeDesc,
+		},
+	}
+
+	if req.Revoked != "" {
+		revoked, _ := strconv.ParseBool(req.Revoked)
+		request.Revoked = &revoked
+	}
+	if req.Expired != "" {
+		expired, _ := strconv.ParseBool(req.Expired)
+		request.Expired = &expired
+	}
+	if req.PageLimit != "" {
+		request.Page.Limit, _ = strconv.ParseUint(req.PageLimit, 10, 64)
+	}
+	if req.PageNumber != "" {
+		request.Page.PageNumber, _ = strconv.ParseUint(req.PageNumber, 10, 64)
+	}
+	if req.PageOrder != "" {
+		request.Page.Order = req.PageOrder
+	}
+
+	return request
+}
+
+func parseOptionalTime(raw string) *time.Time {
+	if raw == "" {
+		return nil
+	}
+
+	parsed, _ := time.Parse(time.RFC3339, raw)
+	parsed = parsed.UTC()
+
+	return &parsed
+}
+
+func MustBeBool(src interface{}) error {
+	raw, ok := src.(string)
+	if !ok {
+		return errors.New("it is not a string")
+	}
+
+	if _, err := strconv.ParseBool(raw); err != nil {
+		return errors.New("it is not a valid boolean")
+	}
+
+	return nil
+}
+
+func MustBeUintInRange(min, max uint64) validation.RuleFunc {
+	return func(src interface{}) error {
+		raw, ok := src.(string)
+		if !ok {
+			return errors.New("it is not a string")
+		}
+
+		value, err := strconv.ParseUint(raw, 10, 64)
+		if err != nil {
+			return errors.New("it is not a valid unsigned integer")
+		}
+		if value < min || value > max {
+			return errors.Errorf("it must be between %d and %d", min, max)
+		}
+
+		return nil
+	}
+}
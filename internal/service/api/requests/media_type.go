@@ -0,0 +1,67 @@
+package requests
+
+import (
+	"encoding/json"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/iden3/go-jwz"
+	"github.com/iden3/iden3comm"
+	"github.com/iden3/iden3comm/packers"
+	"gitlab.com/distributed_lab/logan/v3/errors"
+)
+
+const acceptHeader = "Accept"
+
+// ResponseMediaType is the negotiated media type of the iden3comm response, the Recipient is the DID
+// the encrypted response is encrypted to, its key is taken from the key agreement of its DID document.
+type ResponseMediaType struct {
+	MediaType iden3comm.MediaType
+	Recipient string
+}
+
+// parseAcceptedMediaType returns the first supported iden3comm media type from the Accept header,
+// the plain one is returned if there are no supported media types to keep the older wallets working.
+func parseAcceptedMediaType(r *http.Request, supported ...iden3comm.MediaType) iden3comm.MediaType {
+	for _, accepted := range strings.Split(r.Header.Get(acceptHeader), ",") {
+		mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(accepted))
+		if err != nil {
+			continue
+		}
+
+		for _, supportedMediaType := range supported {
+			if iden3comm.MediaType(mediaType) == supportedMediaType {
+				return supportedMediaType
+			}
+		}
+	}
+
+	return packers.MediaTypePlainMessage
+}
+
+// parseResponseMediaType negotiates the media type of the credential issuance response,
+// the encrypted response is encrypted to the sender of the token message, that is proven by the token.
+func parseResponseMediaType(r *http.Request, token *jwz.Token) (*ResponseMediaType, error) {
+	response := &ResponseMediaType{
+		MediaType: parseAcceptedMediaType(r,
+			packers.MediaTypePlainMessage,
+			packers.MediaTypeSignedMessage,
+			packers.MediaTypeEncryptedMessage,
+		),
+	}
+	if response.MediaType != packers.MediaTypeEncryptedMessage {
+		return response, nil
+	}
+
+	var message iden3comm.BasicMessage
+	if err := json.Unmarshal(token.GetPayload(), &message); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal token message")
+	}
+	if message.From == "" {
+		return nil, errors.New("token message sender is required for the encrypted response")
+	}
+	response.Recipient = message.From
+
+	return response, nil
+}
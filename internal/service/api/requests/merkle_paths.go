@@ -0,0 +1,54 @@
+package requests
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/google/uuid"
+)
+
+const (
+	fieldQueryParam = "field"
+)
+
+type merklePathsRequest struct {
+	CredentialID string
+	Fields       []string
+}
+
+type MerklePathsRequest struct {
+	CredentialID uuid.UUID
+	Fields       []string
+}
+
+func NewMerklePaths(r *http.Request) (*MerklePathsRequest, error) {
+	requestRaw := merklePathsRequest{
+		CredentialID: chi.URLParam(r, credentialIDPathParam),
+		Fields:       r.URL.Query()[fieldQueryParam],
+	}
+
+	if err := requestRaw.validate(); err != nil {
+		return nil, err
+	}
+
+	return requestRaw.parse(), nil
+}
+
+func (r *merklePathsRequest) validate() error {
+	return validation.Errors{
+		"path/{credential-id}": validation.Validate(
+			r.CredentialID, validation.Required, validation.By(MustBeValidUUID),
+		),
+		"query/field": validation.Validate(
+			r.Fields, validation.Each(validation.Required),
+		),
+	}.Filter()
+}
+
+func (r *merklePathsRequest) parse() *MerklePathsRequest {
+	return &MerklePathsRequest{
+		CredentialID: uuid.MustParse(r.CredentialID),
+		Fields:       r.Fields,
+	}
+}
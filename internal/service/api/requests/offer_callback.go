@@ -15,33 +15,50 @@ import (
 type OfferCallbackRequest struct {
 	Token        *jwz.Token
 	FetchMessage *protocol.CredentialFetchRequestMessage
+	Response     *ResponseMediaType
 }
 
 func NewOfferCallback(r *http.Request) (*OfferCallbackRequest, error) {
+	token, fetchMessage, err := parseFetchMessageToken(r)
+	if err != nil {
+		return nil, err
+	}
+
+	requestBody := OfferCallbackRequest{
+		Token:        token,
+		FetchMessage: fetchMessage,
+	}
+	if err := requestBody.validate(); err != nil {
+		return nil, err
+	}
+
+	requestBody.Response, err = parseResponseMediaType(r, token)
+	if err != nil {
+		return nil, err
+	}
+
+	return &requestBody, nil
+}
+
+// parseFetchMessageToken reads the JWZ token from the request body
+// and unmarshals the credential fetch message from its payload.
+func parseFetchMessageToken(r *http.Request) (*jwz.Token, *protocol.CredentialFetchRequestMessage, error) {
 	tokenRaw, err := io.ReadAll(r.Body)
 	if err != nil {
-		return nil, errors.New("is not a valid request token")
+		return nil, nil, errors.New("is not a valid request token")
 	}
 
 	token, err := jwz.Parse(string(tokenRaw))
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to parse jwz")
+		return nil, nil, errors.Wrap(err, "failed to parse jwz")
 	}
 
 	var fetchMessage protocol.CredentialFetchRequestMessage
 	if err := json.Unmarshal(token.GetPayload(), &fetchMessage); err != nil {
-		return nil, errors.Wrap(err, "failed to unmarshal")
+		return nil, nil, errors.Wrap(err, "failed to unmarshal")
 	}
 
-	requestBody := OfferCallbackRequest{
-		Token:        token,
-		FetchMessage: &fetchMessage,
-	}
-	if err := requestBody.validate(); err != nil {
-		return nil, err
-	}
-
-	return &requestBody, nil
+	return token, &fetchMessage, nil
 }
 
 // nolint
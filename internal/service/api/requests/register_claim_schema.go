@@ -0,0 +1,82 @@
+package requests
+
+import (
+	"encoding/json"
+	"net/http"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"gitlab.com/distributed_lab/logan/v3/errors"
+
+	"github.com/rarimo/issuer/internal/data"
+	"github.com/rarimo/issuer/internal/service/core/claims"
+	"github.com/rarimo/issuer/resources"
+)
+
+type registerClaimSchemaRequestRaw struct {
+	Body resources.ClaimSchemaRequest
+}
+
+func NewRegisterClaimSchema(r *http.Request) (*data.ClaimSchema, error) {
+	requestRaw := registerClaimSchemaRequestRaw{}
+
+	if err := json.NewDecoder(r.Body).Decode(&requestRaw.Body); err != nil {
+		return nil, errors.Wrap(err, "failed to decode json request body")
+	}
+
+	if err := requestRaw.validate(); err != nil {
+		return nil, err
+	}
+
+	return requestRaw.parse(), nil
+}
+
+// nolint
+func (req *registerClaimSchemaRequestRaw) validate() error {
+	attributes := req.Body.Data.Attributes
+
+	return validation.Errors{
+		"data/type": validation.Validate(
+			req.Body.Data.Type, validation.Required, validation.In(resources.CLAIM_SCHEMA),
+		),
+		"data/id": validation.Validate(
+			req.Body.Data.ID, validation.Required, validation.Length(1, 256),
+		),
+		"data/attributes/schema_url": validation.Validate(
+			attributes.SchemaUrl, validation.Required,
+		),
+		"data/attributes/display_name": validation.Validate(
+			attributes.DisplayName, validation.Required, validation.Length(1, 256),
+		),
+		"data/attributes/merklized_root_position": validation.Validate(
+			attributes.MerklizedRootPosition, validation.NilOrNotEmpty, validation.In(
+				claims.MerklizedRootPositionIndex,
+				claims.MerklizedRootPositionValue,
+				claims.MerklizedRootPositionNone,
+			),
+		),
+	}.Filter()
+}
+
+func (req *registerClaimSchemaRequestRaw) parse() *data.ClaimSchema {
+	attributes := req.Body.Data.Attributes
+
+	result := data.ClaimSchema{
+		SchemaType:  req.Body.Data.ID,
+		SchemaURL:   attributes.SchemaUrl,
+		DisplayName: attributes.DisplayName,
+	}
+
+	if attributes.JsonLdContext != nil {
+		result.JSONLdContext = *attributes.JsonLdContext
+	}
+
+	if attributes.MerklizedRootPosition != nil {
+		result.MerklizedRootPosition = *attributes.MerklizedRootPosition
+	}
+
+	if attributes.RevokeOnExpiration != nil {
+		result.RevokeOnExpiration = *attributes.RevokeOnExpiration
+	}
+
+	return &result
+}
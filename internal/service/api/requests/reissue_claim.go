@@ -0,0 +1,102 @@
+package requests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/google/uuid"
+	"gitlab.com/distributed_lab/logan/v3/errors"
+
+	"github.com/rarimo/issuer/internal/data"
+	"github.com/rarimo/issuer/internal/service/core/claims"
+	"github.com/rarimo/issuer/resources"
+)
+
+type ReissueClaimRequest struct {
+	ClaimID    uuid.UUID
+	Expiration *time.Time
+	// Updatable is nil if the replacement keeps the updatable flag of the reissued claim
+	Updatable *bool
+	Details   RevocationDetails
+
+	credentialSubject json.RawMessage
+}
+
+type reissueClaimRequestRaw struct {
+	ClaimID string
+	Body    resources.ReissueClaimRequest
+}
+
+func NewReissueClaim(r *http.Request) (*ReissueClaimRequest, error) {
+	requestBody := resources.ReissueClaimRequest{}
+
+	if err := json.NewDecoder(r.Body).Decode(&requestBody); err != nil {
+		return nil, errors.Wrap(err, "failed to decode json request body")
+	}
+
+	requestRaw := reissueClaimRequestRaw{
+		ClaimID: chi.URLParam(r, credentialIDPathParam),
+		Body:    requestBody,
+	}
+
+	if err := requestRaw.validate(); err != nil {
+		return nil, err
+	}
+
+	details, err := newRevocationDetails(requestRaw.Body.Data.Attributes.Reason)
+	if err != nil {
+		return nil, err
+	}
+	// the reissued claim is replaced, so it is the default reason
+	if requestRaw.Body.Data.Attributes.Reason == nil || *requestRaw.Body.Data.Attributes.Reason == "" {
+		details.Reason = data.RevocationReasonSuperseded
+	}
+
+	request := requestRaw.parse()
+	request.Details = *details
+
+	return request, nil
+}
+
+func (req *reissueClaimRequestRaw) validate() error {
+	return validation.Errors{
+		"path/{credential-id}": validation.Validate(
+			req.ClaimID, validation.Required, validation.By(MustBeValidUUID),
+		),
+		"data/attributes/credential_subject": validation.Validate(
+			req.Body.Data.Attributes.CredentialSubject, validation.Required,
+		),
+		"data/attributes/expiration": validation.Validate(
+			req.Body.Data.Attributes.Expiration,
+			validation.When(
+				!validation.IsEmpty(req.Body.Data.Attributes.Expiration),
+				validation.By(MustBeValidRFC3339),
+			),
+		),
+	}.Filter()
+}
+
+func (req *reissueClaimRequestRaw) parse() *ReissueClaimRequest {
+	var expiration *time.Time
+	if req.Body.Data.Attributes.Expiration != "" {
+		parsedExpiration, _ := time.Parse(time.RFC3339, req.Body.Data.Attributes.Expiration)
+		expiration = &parsedExpiration
+	}
+
+	return &ReissueClaimRequest{
+		ClaimID:           uuid.MustParse(req.ClaimID),
+		Expiration:        expiration,
+		Updatable:         req.Body.Data.Attributes.Updatable,
+		credentialSubject: req.Body.Data.Attributes.CredentialSubject,
+	}
+}
+
+// CredentialSubject validates and coerces the credential subject with the functions of the claim schema,
+// the schema type is taken from the reissued claim, so it is known only after the claim is loaded.
+func (req *ReissueClaimRequest) CredentialSubject(ctx context.Context, claimType claims.ClaimSchemaType) ([]byte, error) {
+	return parseTrimmedCredentialSubject(ctx, claimType, req.credentialSubject)
+}
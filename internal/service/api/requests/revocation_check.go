@@ -7,7 +7,6 @@ import (
 
 	"github.com/go-chi/chi"
 	validation "github.com/go-ozzo/ozzo-validation/v4"
-	"github.com/iden3/iden3comm/protocol"
 	"gitlab.com/distributed_lab/logan/v3/errors"
 )
 
@@ -16,13 +15,11 @@ const (
 )
 
 type revocationCheckRequest struct {
-	RevocationID      string
-	RevocationRequest *protocol.RevocationStatusRequestMessage
+	RevocationID string
 }
 
 type RevocationCheckRequest struct {
-	RevocationID      *big.Int
-	RevocationRequest *protocol.RevocationStatusRequestMessage
+	RevocationID *big.Int
 }
 
 func NewRevocationCheck(r *http.Request) (*RevocationCheckRequest, error) {
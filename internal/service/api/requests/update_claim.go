@@ -0,0 +1,87 @@
+package requests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/google/uuid"
+	"gitlab.com/distributed_lab/logan/v3/errors"
+
+	"github.com/rarimo/issuer/internal/service/core/claims"
+	"github.com/rarimo/issuer/resources"
+)
+
+// UpdateClaimRequest has the same body as the issuance request, but the
+// updatable flag is ignored as the updated claim is always updatable.
+type UpdateClaimRequest struct {
+	ClaimID    uuid.UUID
+	Expiration *time.Time
+
+	credentialSubject json.RawMessage
+}
+
+type updateClaimRequestRaw struct {
+	ClaimID string
+	Body    resources.IssueClaimRequest
+}
+
+func NewUpdateClaim(r *http.Request) (*UpdateClaimRequest, error) {
+	requestBody := resources.IssueClaimRequest{}
+
+	if err := json.NewDecoder(r.Body).Decode(&requestBody); err != nil {
+		return nil, errors.Wrap(err, "failed to decode json request body")
+	}
+
+	requestRaw := updateClaimRequestRaw{
+		ClaimID: chi.URLParam(r, credentialIDPathParam),
+		Body:    requestBody,
+	}
+
+	if err := requestRaw.validate(); err != nil {
+		return nil, err
+	}
+
+	return requestRaw.parse(), nil
+}
+
+func (req *updateClaimRequestRaw) validate() error {
+	return validation.Errors{
+		"path/{credential-id}": validation.Validate(
+			req.ClaimID, validation.Required, validation.By(MustBeValidUUID),
+		),
+		"data/attributes/credential_subject": validation.Validate(
+			req.Body.Data.Attributes.CredentialSubject, validation.Required,
+		),
+		"data/attributes/expiration": validation.Validate(
+			req.Body.Data.Attributes.Expiration,
+			validation.When(
+				!validation.IsEmpty(req.Body.Data.Attributes.Expiration),
+				validation.By(MustBeValidRFC3339),
+			),
+		),
+	}.Filter()
+}
+
+func (req *updateClaimRequestRaw) parse() *UpdateClaimRequest {
+	var expiration *time.Time
+	if req.Body.Data.Attributes.Expiration != "" {
+		parsedExpiration, _ := time.Parse(time.RFC3339, req.Body.Data.Attributes.Expiration)
+		expiration = &parsedExpiration
+	}
+
+	return &UpdateClaimRequest{
+		ClaimID:           uuid.MustParse(req.ClaimID),
+		Expiration:        expiration,
+		credentialSubject: req.Body.Data.Attributes.CredentialSubject,
+	}
+}
+
+// CredentialSubject validates and coerces the credential subject with the functions of the claim schema,
+// the schema type is taken from the updated claim, so it is known only after the claim is loaded.
+func (req *UpdateClaimRequest) CredentialSubject(ctx context.Context, claimType claims.ClaimSchemaType) ([]byte, error) {
+	return parseTrimmedCredentialSubject(ctx, claimType, req.credentialSubject)
+}
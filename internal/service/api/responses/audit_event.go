@@ -0,0 +1,49 @@
+package responses
+
+import (
+	"strconv"
+
+	"github.com/rarimo/issuer/internal/data"
+	"github.com/rarimo/issuer/resources"
+)
+
+func NewAuditEventList(events []data.AuditEvent, links *resources.Links) *resources.AuditEventListResponse {
+	response := &resources.AuditEventListResponse{
+		Data:     make([]resources.AuditEvent, 0, len(events)),
+		Included: resources.Included{},
+		Links:    links,
+	}
+
+	for i := range events {
+		response.Data = append(response.Data, NewAuditEventData(&events[i]))
+	}
+
+	return response
+}
+
+// NewAuditEventData is the audit event resource, it is also the line of the audit events export.
+func NewAuditEventData(event *data.AuditEvent) resources.AuditEvent {
+	auditEvent := resources.AuditEvent{
+		Key: resources.Key{
+			ID:   strconv.FormatUint(event.ID, 10),
+			Type: resources.AUDIT_EVENT,
+		},
+		Attributes: resources.AuditEventAttributes{
+			ActorId:          event.ActorID,
+			ActorType:        event.ActorType,
+			ClaimId:          event.ClaimID,
+			CommittedStateId: event.CommittedStateID,
+			CreatedAt:        event.CreatedAt,
+			EventType:        event.EventType,
+			OfferId:          event.OfferID,
+			RequestId:        event.RequestID,
+		},
+	}
+
+	if event.RevNonce != nil {
+		revNonce := uint64(*event.RevNonce)
+		auditEvent.Attributes.RevNonce = &revNonce
+	}
+
+	return auditEvent
+}
@@ -0,0 +1,39 @@
+package responses
+
+import (
+	"github.com/rarimo/issuer/internal/data"
+	"github.com/rarimo/issuer/resources"
+)
+
+func NewClaimList(claims []data.Claim, links *resources.Links) *resources.ClaimListResponse {
+	response := &resources.ClaimListResponse{
+		Data:     make([]resources.Claim, 0, len(claims)),
+		Included: resources.Included{},
+		Links:    links,
+	}
+
+	for i := range claims {
+		response.Data = append(response.Data, newClaimData(&claims[i]))
+	}
+
+	return response
+}
+
+func newClaimData(claim *data.Claim) resources.Claim {
+	return resources.Claim{
+		Key: resources.Key{
+			ID:   claim.ID,
+			Type: resources.CLAIM,
+		},
+		Attributes: resources.ClaimAttributes{
+			CreatedAt:       claim.CreatedAt,
+			Credential:      claim.Credential,
+			Expiration:      claim.Expiration,
+			Expired:         claim.Expired,
+			PreviousClaimId: claim.PreviousClaimID,
+			Revoked:         claim.Revoked,
+			SchemaType:      claim.ClaimType,
+			UserId:          claim.UserID,
+		},
+	}
+}
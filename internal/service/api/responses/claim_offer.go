@@ -2,10 +2,25 @@ package responses
 
 import (
 	"github.com/iden3/iden3comm/protocol"
+
+	"github.com/rarimo/issuer/internal/service/core/issuer"
 	"github.com/rarimo/issuer/resources"
 )
 
-func NewClaimOffer(claimOffer *protocol.CredentialsOfferMessage) *resources.ClaimOfferResponse {
+func NewClaimOffer(
+	claimOffer *protocol.CredentialsOfferMessage,
+	links *issuer.ClaimOfferLinks,
+) *resources.ClaimOfferResponse {
+	return &resources.ClaimOfferResponse{
+		Data:     newClaimOfferData(claimOffer, links),
+		Included: resources.Included{},
+	}
+}
+
+func newClaimOfferData(
+	claimOffer *protocol.CredentialsOfferMessage,
+	links *issuer.ClaimOfferLinks,
+) resources.ClaimOffer {
 	credentials := make([]resources.ClaimOfferBodyCredentials, 0, len(claimOffer.Body.Credentials))
 	for _, credential := range claimOffer.Body.Credentials {
 		credentials = append(credentials, resources.ClaimOfferBodyCredentials{
@@ -14,24 +29,32 @@ func NewClaimOffer(claimOffer *protocol.CredentialsOfferMessage) *resources.Clai
 		})
 	}
 
-	return &resources.ClaimOfferResponse{
-		Data: resources.ClaimOffer{
-			Key: resources.Key{
-				Type: resources.CLAIM_OFFER,
-			},
-			Attributes: resources.ClaimOfferAttributes{
-				Body: resources.ClaimOfferBody{
-					Credentials: credentials,
-					Url:         claimOffer.Body.URL,
-				},
-				From: claimOffer.From,
-				Id:   claimOffer.ID,
-				Thid: claimOffer.ThreadID,
-				To:   claimOffer.To,
-				Typ:  string(claimOffer.Typ),
-				Type: string(claimOffer.Type),
+	var offerLinks *resources.ClaimOfferLinks
+	if links != nil {
+		offerLinks = &resources.ClaimOfferLinks{
+			DeepLink: links.DeepLink,
+			Message:  links.MessageURL,
+			QrPng:    links.QRCodePNG,
+			QrSvg:    links.QRCodeSVG,
+		}
+	}
+
+	return resources.ClaimOffer{
+		Key: resources.Key{
+			Type: resources.CLAIM_OFFER,
+		},
+		Attributes: resources.ClaimOfferAttributes{
+			Body: resources.ClaimOfferBody{
+				Credentials: credentials,
+				Url:         claimOffer.Body.URL,
 			},
+			From:  claimOffer.From,
+			Id:    claimOffer.ID,
+			Links: offerLinks,
+			Thid:  claimOffer.ThreadID,
+			To:    claimOffer.To,
+			Typ:   string(claimOffer.Typ),
+			Type:  string(claimOffer.Type),
 		},
-		Included: resources.Included{},
 	}
 }
@@ -0,0 +1,38 @@
+package responses
+
+import (
+	"github.com/rarimo/issuer/internal/service/core/issuer"
+	"github.com/rarimo/issuer/resources"
+)
+
+func NewClaimReissue(reissue *issuer.ClaimReissue) *resources.ClaimReissueResponse {
+	revocation := newClaimRevocationData(reissue.Revocation)
+
+	// the offer is included by its message identifier, as it has no other one
+	offer := newClaimOfferData(reissue.Offer, reissue.OfferLinks)
+	offer.ID = reissue.Offer.ID
+
+	response := &resources.ClaimReissueResponse{
+		Data: resources.ClaimReissue{
+			Key: resources.Key{
+				ID:   reissue.Claim.ID,
+				Type: resources.CLAIM_REISSUE_RESULT,
+			},
+			Attributes: resources.ClaimReissueAttributes{
+				PreviousClaimId: *reissue.Claim.PreviousClaimID,
+			},
+			Relationships: resources.ClaimReissueRelationships{
+				Offer: resources.Relation{
+					Data: &offer.Key,
+				},
+				Revocation: resources.Relation{
+					Data: &revocation.Key,
+				},
+			},
+		},
+		Included: resources.Included{},
+	}
+	response.Included.Add(&revocation, &offer)
+
+	return response
+}
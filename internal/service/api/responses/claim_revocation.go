@@ -0,0 +1,83 @@
+package responses
+
+import (
+	"strconv"
+
+	"github.com/rarimo/issuer/internal/service/core/issuer"
+	"github.com/rarimo/issuer/resources"
+)
+
+func NewClaimRevocation(revocation *issuer.ClaimRevocation) *resources.ClaimRevocationResponse {
+	return &resources.ClaimRevocationResponse{
+		Data:     newClaimRevocationData(revocation),
+		Included: resources.Included{},
+	}
+}
+
+func newClaimRevocationData(revocation *issuer.ClaimRevocation) resources.ClaimRevocation {
+	revNonce := strconv.FormatUint(uint64(revocation.RevNonce), 10)
+
+	return resources.ClaimRevocation{
+		Key: resources.Key{
+			ID:   revNonce,
+			Type: resources.CLAIM_REVOCATION,
+		},
+		Attributes: resources.ClaimRevocationAttributes{
+			ClaimId:   revocation.ClaimID,
+			Operator:  revocation.Operator,
+			Reason:    string(revocation.Reason),
+			RevNonce:  revNonce,
+			RevokedAt: revocation.RevokedAt,
+			State:     newClaimRevocationState(revocation.State),
+		},
+	}
+}
+
+func NewClaimRevocationBulk(results []issuer.BulkRevocationResult) *resources.RevokeClaimBulkResultListResponse {
+	data := make([]resources.RevokeClaimBulkResult, 0, len(results))
+	for i, result := range results {
+		item := resources.RevokeClaimBulkResult{
+			Key: resources.Key{
+				Type: resources.CLAIM_REVOKE_RESULT,
+			},
+			Attributes: resources.RevokeClaimBulkResultAttributes{
+				ClaimId: result.ClaimID,
+				Index:   i,
+				Status:  string(result.Status),
+			},
+		}
+
+		// the nonce is unknown if the claim is not found by ID
+		if result.Status == issuer.BulkRevocationStatusNotFound {
+			item.ID = *result.ClaimID
+		} else {
+			revNonce := strconv.FormatUint(result.RevNonce, 10)
+			item.ID = revNonce
+			item.Attributes.RevNonce = &revNonce
+		}
+		if result.Revocation != nil {
+			state := newClaimRevocationState(result.Revocation.State)
+			item.Attributes.RevokedAt = &result.Revocation.RevokedAt
+			item.Attributes.State = &state
+		}
+
+		data = append(data, item)
+	}
+
+	return &resources.RevokeClaimBulkResultListResponse{
+		Data:     data,
+		Included: resources.Included{},
+	}
+}
+
+func newClaimRevocationState(state issuer.RevocationState) resources.ClaimRevocationState {
+	result := resources.ClaimRevocationState{
+		CommittedStateId: state.CommittedStateID,
+		Status:           string(state.Status),
+	}
+	if state.TxID != "" {
+		result.TxId = &state.TxID
+	}
+
+	return result
+}
@@ -0,0 +1,43 @@
+package responses
+
+import (
+	"github.com/rarimo/issuer/internal/data"
+	"github.com/rarimo/issuer/resources"
+)
+
+func NewClaimSchema(claimSchema *data.ClaimSchema) *resources.ClaimSchemaResponse {
+	return &resources.ClaimSchemaResponse{
+		Data:     newClaimSchemaData(claimSchema),
+		Included: resources.Included{},
+	}
+}
+
+func NewClaimSchemaList(claimSchemas []data.ClaimSchema) *resources.ClaimSchemaListResponse {
+	result := make([]resources.ClaimSchema, 0, len(claimSchemas))
+	for i := range claimSchemas {
+		result = append(result, newClaimSchemaData(&claimSchemas[i]))
+	}
+
+	return &resources.ClaimSchemaListResponse{
+		Data:     result,
+		Included: resources.Included{},
+	}
+}
+
+func newClaimSchemaData(claimSchema *data.ClaimSchema) resources.ClaimSchema {
+	return resources.ClaimSchema{
+		Key: resources.Key{
+			ID:   claimSchema.SchemaType,
+			Type: resources.CLAIM_SCHEMA,
+		},
+		Attributes: resources.ClaimSchemaAttributes{
+			CreatedAt:             &claimSchema.CreatedAt,
+			DisplayName:           claimSchema.DisplayName,
+			IsDeprecated:          &claimSchema.IsDeprecated,
+			JsonLdContext:         &claimSchema.JSONLdContext,
+			MerklizedRootPosition: &claimSchema.MerklizedRootPosition,
+			RevokeOnExpiration:    &claimSchema.RevokeOnExpiration,
+			SchemaUrl:             claimSchema.SchemaURL,
+		},
+	}
+}
@@ -0,0 +1,100 @@
+package responses
+
+import (
+	"encoding/json"
+
+	"github.com/rarimo/issuer/internal/service/core/issuer"
+	"github.com/rarimo/issuer/resources"
+)
+
+const (
+	exampleExpiration = "2030-01-01T00:00:00Z"
+)
+
+func NewClaimType(claimType *issuer.ClaimType) (*resources.ClaimTypeResponse, error) {
+	claimTypeData, err := newClaimTypeData(claimType)
+	if err != nil {
+		return nil, err
+	}
+
+	return &resources.ClaimTypeResponse{
+		Data:     *claimTypeData,
+		Included: resources.Included{},
+	}, nil
+}
+
+func NewClaimTypeList(claimTypes []issuer.ClaimType) (*resources.ClaimTypeListResponse, error) {
+	result := make([]resources.ClaimType, 0, len(claimTypes))
+	for i := range claimTypes {
+		claimTypeData, err := newClaimTypeData(&claimTypes[i])
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, *claimTypeData)
+	}
+
+	return &resources.ClaimTypeListResponse{
+		Data:     result,
+		Included: resources.Included{},
+	}, nil
+}
+
+func newClaimTypeData(claimType *issuer.ClaimType) (*resources.ClaimType, error) {
+	exampleRequest, err := newExampleIssueClaimRequest(claimType)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := make([]resources.ClaimTypeField, 0, len(claimType.RequestFields))
+	for _, field := range claimType.RequestFields {
+		claimTypeField := resources.ClaimTypeField{
+			Default:  field.Default,
+			Enum:     field.Enum,
+			Name:     field.Name,
+			Nullable: field.Nullable,
+			Required: field.Required,
+			Type:     field.Type,
+		}
+		if field.Format != "" {
+			format := field.Format
+			claimTypeField.Format = &format
+		}
+
+		fields = append(fields, claimTypeField)
+	}
+
+	return &resources.ClaimType{
+		Key: resources.Key{
+			ID:   claimType.SchemaType.ToRaw(),
+			Type: resources.CLAIM_TYPE,
+		},
+		Attributes: resources.ClaimTypeAttributes{
+			DisplayName:    claimType.DisplayName,
+			ExampleRequest: exampleRequest,
+			IsDeprecated:   claimType.IsDeprecated,
+			JsonLdContext:  claimType.JSONLdContext,
+			RequestFields:  fields,
+			Schema:         claimType.Schema,
+			SchemaUrl:      claimType.SchemaURL,
+		},
+	}, nil
+}
+
+func newExampleIssueClaimRequest(claimType *issuer.ClaimType) (json.RawMessage, error) {
+	credentialSubject, err := json.Marshal(claimType.ExampleCredentialSubject)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(resources.IssueClaimRequest{
+		Data: resources.IssueClaim{
+			Key: resources.Key{
+				Type: resources.CLAIM_ISSUE,
+			},
+			Attributes: resources.IssueClaimAttributes{
+				CredentialSubject: credentialSubject,
+				Expiration:        exampleExpiration,
+			},
+		},
+	})
+}
@@ -0,0 +1,42 @@
+package responses
+
+import (
+	"strconv"
+
+	"github.com/rarimo/issuer/internal/service/core/issuer"
+	"github.com/rarimo/issuer/resources"
+)
+
+func NewClaimVersionList(versions []issuer.ClaimVersion) *resources.ClaimVersionListResponse {
+	response := &resources.ClaimVersionListResponse{
+		Data:     make([]resources.ClaimVersion, 0, len(versions)),
+		Included: resources.Included{},
+	}
+
+	for i := range versions {
+		response.Data = append(response.Data, newClaimVersionData(&versions[i]))
+	}
+
+	return response
+}
+
+func NewClaimVersion(version *issuer.ClaimVersion) *resources.ClaimVersionResponse {
+	return &resources.ClaimVersionResponse{
+		Data:     newClaimVersionData(version),
+		Included: resources.Included{},
+	}
+}
+
+func newClaimVersionData(version *issuer.ClaimVersion) resources.ClaimVersion {
+	return resources.ClaimVersion{
+		Key: resources.Key{
+			ID:   strconv.FormatUint(uint64(version.Version), 10),
+			Type: resources.CLAIM_VERSION,
+		},
+		Attributes: resources.ClaimVersionAttributes{
+			ClaimId:    version.ClaimID,
+			Credential: version.Credential,
+			ReplacedAt: version.ReplacedAt,
+		},
+	}
+}
@@ -0,0 +1,42 @@
+package responses
+
+import (
+	"strconv"
+
+	"github.com/rarimo/issuer/internal/data"
+	"github.com/rarimo/issuer/resources"
+)
+
+func NewIdentity(identity *data.Identity) *resources.IdentityResponse {
+	return &resources.IdentityResponse{
+		Data:     newIdentityData(identity),
+		Included: resources.Included{},
+	}
+}
+
+func NewIdentityList(identities []data.Identity) *resources.IdentityListResponse {
+	result := make([]resources.Identity, 0, len(identities))
+	for i := range identities {
+		result = append(result, newIdentityData(&identities[i]))
+	}
+
+	return &resources.IdentityListResponse{
+		Data:     result,
+		Included: resources.Included{},
+	}
+}
+
+// newIdentityData omits the keys, the sealed keys of the identity never leave the service.
+func newIdentityData(identity *data.Identity) resources.Identity {
+	return resources.Identity{
+		Key: resources.Key{
+			ID:   strconv.FormatUint(identity.ID, 10),
+			Type: resources.IDENTITY,
+		},
+		Attributes: resources.IdentityAttributes{
+			CreatedAt: &identity.CreatedAt,
+			Did:       identity.DID,
+			Name:      identity.Name,
+		},
+	}
+}
@@ -0,0 +1,33 @@
+package responses
+
+import (
+	"github.com/rarimo/issuer/internal/service/core/issuer"
+	"github.com/rarimo/issuer/resources"
+)
+
+func NewIssueClaimBatch(results []issuer.BatchClaimResult) *resources.IssueClaimBatchResultListResponse {
+	data := make([]resources.IssueClaimBatchResult, 0, len(results))
+	for i, result := range results {
+		item := resources.IssueClaimBatchResult{
+			Key: resources.Key{
+				ID:   result.ClaimID,
+				Type: resources.CLAIM_ISSUE_RESULT,
+			},
+			Attributes: resources.IssueClaimBatchResultAttributes{
+				Index:  i,
+				Status: string(result.Status),
+			},
+		}
+		if result.Err != nil {
+			reason := result.Err.Error()
+			item.Attributes.Error = &reason
+		}
+
+		data = append(data, item)
+	}
+
+	return &resources.IssueClaimBatchResultListResponse{
+		Data:     data,
+		Included: resources.Included{},
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"gitlab.com/distributed_lab/ape"
 
 	"github.com/rarimo/issuer/internal/service/api/handlers"
+	"github.com/rarimo/issuer/internal/service/core/apikeys"
 )
 
 func (s *service) router() chi.Router {
@@ -16,42 +17,123 @@ func (s *service) router() chi.Router {
 		ape.ContentType("application/vnd.api+json"),
 		ape.CtxMiddleware(
 			handlers.CtxLog(s.log),
-			handlers.CtxIssuer(s.issuer),
+			handlers.CtxIssuers(s.issuers),
 		),
+		handlers.RequestID,
 	)
 
-	r.Route("/integrations/issuer", func(r chi.Router) {
-		r.Route("/v1", func(r chi.Router) {
-			r.Route("/public", func(r chi.Router) {
-				r.Route("/claims", func(r chi.Router) {
-					r.Route("/offers", func(r chi.Router) {
-						r.Get("/{user-id}/{claim-type}", handlers.ClaimOffer)
-						r.Post("/callback", handlers.OfferCallback)
-					})
-
-					r.Route("/revocations", func(r chi.Router) {
-						r.Get("/check/{rev-id}", handlers.RevocationCheck)
-					})
-
-					r.Get("/mtp-update/{credential-id}", handlers.InclusionMTPUpdate)
-				})
-
-				r.Route("/identity", func(r chi.Router) {
-					r.Get("/identifier", handlers.GetIdentifier)
-				})
+	r.Route("/integrations/issuer/v1", func(r chi.Router) {
+		// the URLs without the issuer DID are served by the default identity, the credentials
+		// issued before the identities were scoped by the DID refer to them
+		r.Group(func(r chi.Router) {
+			r.Use(s.scopeIssuer)
+			issuerRoutes(r)
+		})
+
+		r.Route("/identities/{"+issuerDIDParam+"}", func(r chi.Router) {
+			r.Use(s.scopeIssuer)
+			issuerRoutes(r)
+		})
+
+		r.Route("/admin", func(r chi.Router) {
+			r.Use(s.scopeIssuer, handlers.Authenticate, handlers.RequireScope(apikeys.ScopeIdentitiesManage))
+
+			r.Route("/identities", func(r chi.Router) {
+				r.Get("/", handlers.ListIdentities)
+				r.Post("/", handlers.CreateIdentity)
 			})
 
-			r.Route("/private", func(r chi.Router) {
-				r.Route("/claims", func(r chi.Router) {
-					r.Route("/revocations", func(r chi.Router) {
-						r.Post("/{user-id}/{claim-type}", handlers.ClaimRevocation)
-					})
+			// the claim schemas are shared by all the identities, so only the operator manages them
+			r.Route("/schemas", func(r chi.Router) {
+				r.Use(handlers.RequireScope(apikeys.ScopeSchemasManage))
 
-					r.Post("/issue/{user-id}/{claim-type}", handlers.IssueClaim)
-				})
+				r.Post("/", handlers.RegisterClaimSchema)
+				r.Post("/{schema-type}/deprecate", handlers.DeprecateClaimSchema)
 			})
 		})
 	})
 
 	return r
 }
+
+// issuerRoutes are the routes of the issuer the request is scoped by.
+func issuerRoutes(r chi.Router) {
+	r.Route("/public", func(r chi.Router) {
+		r.Post("/agent", handlers.Agent)
+
+		r.Route("/claims", func(r chi.Router) {
+			r.Route("/offers", func(r chi.Router) {
+				r.Get("/{user-id}/{claim-type}", handlers.ClaimOffer)
+				r.Get("/by-id/{user-id}/{credential-id}", handlers.ClaimOfferByID)
+				r.Get("/bundle/{user-id}", handlers.ClaimsBundleOffer)
+				r.Post("/callback", handlers.OfferCallback)
+				r.Get("/messages/{offer-id}", handlers.ClaimOfferMessage)
+				r.Get("/messages/{offer-id}/qr", handlers.ClaimOfferQRCode)
+				r.Get("/auth/{user-id}/{claim-type}", handlers.ClaimOfferAuthRequest)
+				r.Post("/auth/callback", handlers.ClaimOfferAuthCallback)
+			})
+
+			r.Route("/revocations", func(r chi.Router) {
+				r.Get("/check/{rev-id}", handlers.RevocationCheck)
+			})
+
+			r.Get("/mtp-update/{credential-id}", handlers.InclusionMTPUpdate)
+			r.Post("/{credential-id}", handlers.FetchCredential)
+		})
+
+		r.Route("/identity", func(r chi.Router) {
+			r.Get("/identifier", handlers.GetIdentifier)
+			r.Get("/did-document", handlers.GetDIDDocument)
+		})
+
+		r.Route("/schemas", func(r chi.Router) {
+			r.Get("/", handlers.GetClaimTypes)
+			r.Get("/{schema-type}", handlers.GetClaimType)
+		})
+	})
+
+	r.Route("/private", func(r chi.Router) {
+		r.Use(handlers.Authenticate)
+
+		read := handlers.RequireScope(apikeys.ScopeClaimsRead)
+		issue := handlers.RequireScope(apikeys.ScopeClaimsIssue)
+		revoke := handlers.RequireScope(apikeys.ScopeClaimsRevoke)
+
+		r.Route("/claims", func(r chi.Router) {
+			r.Route("/revocations", func(r chi.Router) {
+				r.With(revoke, handlers.Idempotent).Post("/{user-id}/{claim-type}", handlers.ClaimRevocation)
+				r.With(revoke, handlers.Idempotent).Post("/by-id/{credential-id}", handlers.ClaimRevocationByID)
+				r.With(revoke, handlers.Idempotent).Post("/by-nonce/{rev-id}", handlers.ClaimRevocationByNonce)
+				r.With(read).Get("/by-nonce/{rev-id}", handlers.GetClaimRevocation)
+				r.With(revoke, handlers.Idempotent).Post("/bulk", handlers.ClaimRevocationBulk)
+			})
+
+			r.With(read).Get("/", handlers.ListClaims)
+			r.With(read).Get("/{credential-id}", handlers.GetClaim)
+			r.With(read).Get("/{credential-id}/merkle-paths", handlers.MerklePaths)
+			r.With(read).Get("/{credential-id}/versions", handlers.ListClaimVersions)
+			r.With(read).Get("/{credential-id}/versions/{version}", handlers.GetClaimVersion)
+
+			r.With(issue, handlers.Idempotent).Post("/issue/batch", handlers.IssueClaimBatch)
+			r.With(issue, handlers.Idempotent).Post("/issue/{user-id}/{claim-type}", handlers.IssueClaim)
+			r.With(issue, handlers.Idempotent).Post("/update/{credential-id}", handlers.UpdateClaim)
+			r.With(issue, revoke, handlers.Idempotent).Post("/reissue/{credential-id}", handlers.ReissueClaim)
+			r.With(handlers.RequireScope(apikeys.ScopeOffersManage)).
+				Post("/offers/{offer-id}/cancel", handlers.CancelClaimOffer)
+		})
+
+		r.Route("/schemas", func(r chi.Router) {
+			r.Use(handlers.RequireScope(apikeys.ScopeSchemasRead))
+
+			r.Get("/", handlers.GetClaimSchemas)
+			r.Get("/{schema-type}", handlers.GetClaimSchema)
+		})
+
+		r.Route("/audit", func(r chi.Router) {
+			r.Use(handlers.RequireScope(apikeys.ScopeAuditRead))
+
+			r.Get("/events", handlers.ListAuditEvents)
+			r.Get("/events/export", handlers.ExportAuditEvents)
+		})
+	})
+}
@@ -0,0 +1,92 @@
+package apikeys
+
+import (
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	jose "gopkg.in/square/go-jose.v2"
+	"gopkg.in/square/go-jose.v2/jwt"
+)
+
+// jwtLeeway is the allowed clock skew of the token time claims
+const jwtLeeway = time.Minute
+
+// JWTVerifier verifies the HS256 JWTs signed with the shared secret. The token has to be issued to the subject
+// and expire, the scopes are the space-delimited scope claim, the schema types are the schema_types claim.
+// The audience has to contain the DID of the issuer the token is verified for, the tokens without the audience
+// are accepted only if the audience is optional, that is for the default identity.
+type JWTVerifier struct {
+	secret           []byte
+	issuer           string
+	audience         string
+	audienceOptional bool
+}
+
+type jwtClaims struct {
+	jwt.Claims
+	Scope       string   `json:"scope"`
+	SchemaTypes []string `json:"schema_types,omitempty"`
+}
+
+func NewJWTVerifier(secret, issuer, audience string, audienceOptional bool) *JWTVerifier {
+	return &JWTVerifier{
+		secret:           []byte(secret),
+		issuer:           issuer,
+		audience:         audience,
+		audienceOptional: audienceOptional,
+	}
+}
+
+func (v *JWTVerifier) Verify(token string) (*Principal, error) {
+	parsed, err := jwt.ParseSigned(token)
+	if err != nil {
+		return nil, ErrInvalidCredentials
+	}
+
+	if len(parsed.Headers) != 1 || parsed.Headers[0].Algorithm != string(jose.HS256) {
+		return nil, ErrInvalidCredentials
+	}
+
+	var claims jwtClaims
+	if err := parsed.Claims(v.secret, &claims); err != nil {
+		return nil, ErrInvalidCredentials
+	}
+
+	if claims.Subject == "" || claims.Expiry == nil {
+		return nil, ErrInvalidCredentials
+	}
+
+	err = claims.ValidateWithLeeway(jwt.Expected{
+		Issuer: v.issuer,
+		Time:   time.Now(),
+	}, jwtLeeway)
+	if err != nil {
+		return nil, ErrInvalidCredentials
+	}
+
+	if !v.allowsAudience(claims.Audience) {
+		return nil, ErrInvalidCredentials
+	}
+
+	scopes := strings.Fields(claims.Scope)
+	if err := ValidateScopes(scopes); err != nil {
+		return nil, errors.Wrap(ErrInvalidCredentials, err.Error())
+	}
+
+	return &Principal{
+		ID:          claims.Subject,
+		Name:        claims.Subject,
+		Method:      MethodJWT,
+		Scopes:      scopes,
+		SchemaTypes: claims.SchemaTypes,
+	}, nil
+}
+
+func (v *JWTVerifier) allowsAudience(audience jwt.Audience) bool {
+	if len(audience) == 0 {
+		return v.audienceOptional
+	}
+
+	return audience.Contains(v.audience)
+}
@@ -0,0 +1,242 @@
+package apikeys
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	jose "gopkg.in/square/go-jose.v2"
+	"gopkg.in/square/go-jose.v2/jwt"
+)
+
+const (
+	testJWTSecret = "0123456789abcdef0123456789abcdef"
+	testJWTIssuer = "auth"
+	testIssuerDID = "did:iden3:readonly:issuer"
+)
+
+func signTestJWT(t *testing.T, secret string, alg jose.SignatureAlgorithm, claims interface{}) string {
+	t.Helper()
+
+	signer, err := jose.NewSigner(jose.SigningKey{Algorithm: alg, Key: []byte(secret)}, nil)
+	if err != nil {
+		t.Fatalf("failed to create signer: %v", err)
+	}
+
+	token, err := jwt.Signed(signer).Claims(claims).CompactSerialize()
+	if err != nil {
+		t.Fatalf("failed to sign token: %v", err)
+	}
+
+	return token
+}
+
+func newTestJWTClaims(change func(claims *jwtClaims)) *jwtClaims {
+	now := time.Now()
+	claims := &jwtClaims{
+		Claims: jwt.Claims{
+			Subject:  "client",
+			Issuer:   testJWTIssuer,
+			Audience: jwt.Audience{testIssuerDID},
+			IssuedAt: jwt.NewNumericDate(now),
+			Expiry:   jwt.NewNumericDate(now.Add(time.Hour)),
+		},
+		Scope: "claims:read claims:issue",
+	}
+	if change != nil {
+		change(claims)
+	}
+
+	return claims
+}
+
+func TestJWTVerifierVerify(t *testing.T) {
+	cases := []struct {
+		name             string
+		token            func(t *testing.T) string
+		audienceOptional bool
+		isValid          bool
+	}{
+		{
+			name: "valid token",
+			token: func(t *testing.T) string {
+				return signTestJWT(t, testJWTSecret, jose.HS256, newTestJWTClaims(nil))
+			},
+			isValid: true,
+		},
+		{
+			name: "audience contains the issuer among the others",
+			token: func(t *testing.T) string {
+				return signTestJWT(t, testJWTSecret, jose.HS256, newTestJWTClaims(func(claims *jwtClaims) {
+					claims.Audience = jwt.Audience{"did:iden3:readonly:other", testIssuerDID}
+				}))
+			},
+			isValid: true,
+		},
+		{
+			name: "audience of the other issuer",
+			token: func(t *testing.T) string {
+				return signTestJWT(t, testJWTSecret, jose.HS256, newTestJWTClaims(func(claims *jwtClaims) {
+					claims.Audience = jwt.Audience{"did:iden3:readonly:other"}
+				}))
+			},
+			audienceOptional: true,
+		},
+		{
+			name: "no audience for the default issuer",
+			token: func(t *testing.T) string {
+				return signTestJWT(t, testJWTSecret, jose.HS256, newTestJWTClaims(func(claims *jwtClaims) {
+					claims.Audience = nil
+				}))
+			},
+			audienceOptional: true,
+			isValid:          true,
+		},
+		{
+			name: "no audience for the hosted issuer",
+			token: func(t *testing.T) string {
+				return signTestJWT(t, testJWTSecret, jose.HS256, newTestJWTClaims(func(claims *jwtClaims) {
+					claims.Audience = nil
+				}))
+			},
+		},
+		{
+			name: "other secret",
+			token: func(t *testing.T) string {
+				return signTestJWT(t, "fedcba9876543210fedcba9876543210", jose.HS256, newTestJWTClaims(nil))
+			},
+		},
+		{
+			name: "other algorithm",
+			token: func(t *testing.T) string {
+				return signTestJWT(t, testJWTSecret+testJWTSecret, jose.HS512, newTestJWTClaims(nil))
+			},
+		},
+		{
+			name: "expired",
+			token: func(t *testing.T) string {
+				return signTestJWT(t, testJWTSecret, jose.HS256, newTestJWTClaims(func(claims *jwtClaims) {
+					claims.Expiry = jwt.NewNumericDate(time.Now().Add(-2 * jwtLeeway))
+				}))
+			},
+		},
+		{
+			name: "expired within the leeway",
+			token: func(t *testing.T) string {
+				return signTestJWT(t, testJWTSecret, jose.HS256, newTestJWTClaims(func(claims *jwtClaims) {
+					claims.Expiry = jwt.NewNumericDate(time.Now().Add(-jwtLeeway / 2))
+				}))
+			},
+			isValid: true,
+		},
+		{
+			name: "not valid yet",
+			token: func(t *testing.T) string {
+				return signTestJWT(t, testJWTSecret, jose.HS256, newTestJWTClaims(func(claims *jwtClaims) {
+					claims.NotBefore = jwt.NewNumericDate(time.Now().Add(2 * jwtLeeway))
+				}))
+			},
+		},
+		{
+			name: "no expiry",
+			token: func(t *testing.T) string {
+				return signTestJWT(t, testJWTSecret, jose.HS256, newTestJWTClaims(func(claims *jwtClaims) {
+					claims.Expiry = nil
+				}))
+			},
+		},
+		{
+			name: "no subject",
+			token: func(t *testing.T) string {
+				return signTestJWT(t, testJWTSecret, jose.HS256, newTestJWTClaims(func(claims *jwtClaims) {
+					claims.Subject = ""
+				}))
+			},
+		},
+		{
+			name: "other token issuer",
+			token: func(t *testing.T) string {
+				return signTestJWT(t, testJWTSecret, jose.HS256, newTestJWTClaims(func(claims *jwtClaims) {
+					claims.Issuer = "other"
+				}))
+			},
+		},
+		{
+			name: "unknown scope",
+			token: func(t *testing.T) string {
+				return signTestJWT(t, testJWTSecret, jose.HS256, newTestJWTClaims(func(claims *jwtClaims) {
+					claims.Scope = "claims:read claims:delete"
+				}))
+			},
+		},
+		{
+			name: "no scope",
+			token: func(t *testing.T) string {
+				return signTestJWT(t, testJWTSecret, jose.HS256, newTestJWTClaims(func(claims *jwtClaims) {
+					claims.Scope = ""
+				}))
+			},
+		},
+		{
+			name: "malformed token",
+			token: func(t *testing.T) string {
+				return "a.b.c"
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			verifier := NewJWTVerifier(testJWTSecret, testJWTIssuer, testIssuerDID, tc.audienceOptional)
+
+			principal, err := verifier.Verify(tc.token(t))
+			if !tc.isValid {
+				if !errors.Is(err, ErrInvalidCredentials) {
+					t.Fatalf("got error %v, want %v", err, ErrInvalidCredentials)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("failed to verify token: %v", err)
+			}
+			if principal.ID != "client" || principal.Method != MethodJWT {
+				t.Fatalf("unexpected principal %+v", principal)
+			}
+		})
+	}
+}
+
+func TestJWTVerifierPrincipal(t *testing.T) {
+	token := signTestJWT(t, testJWTSecret, jose.HS256, newTestJWTClaims(func(claims *jwtClaims) {
+		claims.Scope = " claims:read  claims:revoke "
+		claims.SchemaTypes = []string{"KYCAgeCredential"}
+	}))
+
+	principal, err := NewJWTVerifier(testJWTSecret, testJWTIssuer, testIssuerDID, false).Verify(token)
+	if err != nil {
+		t.Fatalf("failed to verify token: %v", err)
+	}
+
+	if !reflect.DeepEqual(principal.Scopes, []string{"claims:read", "claims:revoke"}) {
+		t.Fatalf("got scopes %v", principal.Scopes)
+	}
+	if !principal.HasScope(ScopeClaimsRevoke) || principal.HasScope(ScopeClaimsIssue) {
+		t.Fatal("principal scopes don't match the token scope")
+	}
+	if !principal.AllowsSchemaType("KYCAgeCredential") || principal.AllowsSchemaType("Other") {
+		t.Fatal("principal isn't limited to the token schema types")
+	}
+}
+
+func TestJWTVerifierWithoutIssuer(t *testing.T) {
+	// the iss claim isn't checked if the issuer isn't configured
+	token := signTestJWT(t, testJWTSecret, jose.HS256, newTestJWTClaims(func(claims *jwtClaims) {
+		claims.Issuer = "any"
+	}))
+
+	if _, err := NewJWTVerifier(testJWTSecret, "", testIssuerDID, false).Verify(token); err != nil {
+		t.Fatalf("failed to verify token: %v", err)
+	}
+}
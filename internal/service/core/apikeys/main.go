@@ -0,0 +1,177 @@
+package apikeys
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+
+	"github.com/rarimo/issuer/internal/config"
+	"github.com/rarimo/issuer/internal/data"
+)
+
+const (
+	// keyPrefix marks the issuer API keys, so they are recognizable in the configs and the leaked secrets scans
+	keyPrefix    = "isk_"
+	keyBytesLen  = 32
+	keyPrefixLen = len(keyPrefix) + 8
+	// usagePeriod is the precision of the key last usage time
+	usagePeriod = time.Minute
+)
+
+var (
+	ErrInvalidCredentials     = errors.New("invalid credentials")
+	ErrAPIKeyIsNotExist       = errors.New("api key is not exist")
+	ErrAPIKeyIsAlreadyRevoked = errors.New("api key is already revoked")
+)
+
+// Authenticator authenticates the private API clients of the issuer by the API keys of its identity
+// or the JWTs issued to its DID if they are enabled.
+type Authenticator struct {
+	keys *Manager
+	jwt  *JWTVerifier
+}
+
+// NewAuthenticator creates the authenticator of the issuer with the DID, the apiKeysQ has to be scoped by
+// its identity. The JWTs without the audience are accepted only if the issuer is the default one.
+func NewAuthenticator(cfg *config.AuthConfig, apiKeysQ data.APIKeysQ, did string, isDefault bool) *Authenticator {
+	authenticator := &Authenticator{
+		keys: NewManager(apiKeysQ),
+	}
+	if cfg.JWTSecret != "" {
+		authenticator.jwt = NewJWTVerifier(cfg.JWTSecret, cfg.JWTIssuer, did, isDefault)
+	}
+
+	return authenticator
+}
+
+// Authenticate returns the principal of the API key or the JWT, the JWT is recognized by its compact form.
+func (a *Authenticator) Authenticate(credentials string) (*Principal, error) {
+	if a.jwt != nil && strings.Count(credentials, ".") == 2 {
+		return a.jwt.Verify(credentials)
+	}
+
+	return a.keys.Authenticate(credentials)
+}
+
+// Manager creates and revokes the API keys, the raw key is returned only on creation.
+type Manager struct {
+	apiKeysQ data.APIKeysQ
+}
+
+func NewManager(apiKeysQ data.APIKeysQ) *Manager {
+	return &Manager{
+		apiKeysQ: apiKeysQ,
+	}
+}
+
+// Create generates the new API key with the scopes, limited to the schema types if any and expiring at the
+// expiresAt if it is set. The raw key is returned along with the stored key and can't be recovered later.
+func (m *Manager) Create(
+	name string,
+	scopes, schemaTypes []string,
+	expiresAt *time.Time,
+) (string, *data.APIKey, error) {
+	if name == "" {
+		return "", nil, errors.New("api key name is required")
+	}
+	if err := ValidateScopes(scopes); err != nil {
+		return "", nil, errors.Wrap(err, "invalid scopes")
+	}
+
+	secret := make([]byte, keyBytesLen)
+	if _, err := rand.Read(secret); err != nil {
+		return "", nil, errors.Wrap(err, "failed to generate api key")
+	}
+	rawKey := keyPrefix + base64.RawURLEncoding.EncodeToString(secret)
+
+	apiKey := &data.APIKey{
+		ID:          uuid.NewString(),
+		Name:        name,
+		KeyPrefix:   rawKey[:keyPrefixLen],
+		KeyHash:     hashKey(rawKey),
+		Scopes:      scopes,
+		SchemaTypes: schemaTypes,
+		CreatedAt:   time.Now().UTC(),
+		ExpiresAt:   expiresAt,
+	}
+	if apiKey.SchemaTypes == nil {
+		apiKey.SchemaTypes = []string{}
+	}
+
+	err := m.apiKeysQ.Insert(apiKey)
+	if err != nil {
+		return "", nil, errors.Wrap(err, "failed to insert api key")
+	}
+
+	return rawKey, apiKey, nil
+}
+
+func (m *Manager) List() ([]data.APIKey, error) {
+	apiKeys, err := m.apiKeysQ.Select()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to select api keys")
+	}
+
+	return apiKeys, nil
+}
+
+func (m *Manager) Revoke(id string) error {
+	apiKey, err := m.apiKeysQ.Get(id)
+	if err != nil {
+		return errors.Wrap(err, "failed to get api key")
+	}
+	if apiKey == nil {
+		return ErrAPIKeyIsNotExist
+	}
+
+	isRevoked, err := m.apiKeysQ.Revoke(id, time.Now().UTC())
+	if err != nil {
+		return errors.Wrap(err, "failed to revoke api key")
+	}
+	if !isRevoked {
+		return ErrAPIKeyIsAlreadyRevoked
+	}
+
+	return nil
+}
+
+// Authenticate returns the principal of the API key that is neither revoked nor expired.
+func (m *Manager) Authenticate(rawKey string) (*Principal, error) {
+	if !strings.HasPrefix(rawKey, keyPrefix) {
+		return nil, ErrInvalidCredentials
+	}
+
+	apiKey, err := m.apiKeysQ.GetByHash(hashKey(rawKey))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get api key")
+	}
+
+	now := time.Now().UTC()
+	if apiKey == nil || apiKey.RevokedAt != nil || (apiKey.ExpiresAt != nil && !now.Before(*apiKey.ExpiresAt)) {
+		return nil, ErrInvalidCredentials
+	}
+
+	err = m.apiKeysQ.MarkUsed(apiKey.ID, now, now.Add(-usagePeriod))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to mark api key as used")
+	}
+
+	return &Principal{
+		ID:          apiKey.ID,
+		Name:        apiKey.Name,
+		Method:      MethodAPIKey,
+		Scopes:      apiKey.Scopes,
+		SchemaTypes: apiKey.SchemaTypes,
+	}, nil
+}
+
+// hashKey is the SHA-256 of the key, the keys are random, so the slow hash isn't needed.
+func hashKey(rawKey string) []byte {
+	hash := sha256.Sum256([]byte(rawKey))
+	return hash[:]
+}
@@ -0,0 +1,144 @@
+package apikeys
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	jose "gopkg.in/square/go-jose.v2"
+
+	"github.com/rarimo/issuer/internal/config"
+	"github.com/rarimo/issuer/internal/data"
+)
+
+// testAPIKeysQ keeps the keys in memory and records their usage.
+type testAPIKeysQ struct {
+	data.APIKeysQ
+	keys []*data.APIKey
+	used []string
+}
+
+func (q *testAPIKeysQ) Insert(apiKey *data.APIKey) error {
+	q.keys = append(q.keys, apiKey)
+	return nil
+}
+
+func (q *testAPIKeysQ) GetByHash(keyHash []byte) (*data.APIKey, error) {
+	for _, apiKey := range q.keys {
+		if string(apiKey.KeyHash) == string(keyHash) {
+			return apiKey, nil
+		}
+	}
+
+	return nil, nil
+}
+
+func (q *testAPIKeysQ) MarkUsed(id string, usedAt, usedBefore time.Time) error {
+	q.used = append(q.used, id)
+	return nil
+}
+
+func TestManagerAuthenticate(t *testing.T) {
+	q := &testAPIKeysQ{}
+	manager := NewManager(q)
+
+	rawKey, apiKey, err := manager.Create("client", []string{string(ScopeClaimsRead)}, []string{"KYCAgeCredential"}, nil)
+	if err != nil {
+		t.Fatalf("failed to create api key: %v", err)
+	}
+	if !strings.HasPrefix(rawKey, keyPrefix) || !strings.HasPrefix(rawKey, apiKey.KeyPrefix) {
+		t.Fatalf("key %s isn't prefixed", rawKey)
+	}
+	if strings.Contains(string(apiKey.KeyHash), rawKey) {
+		t.Fatal("raw key is stored")
+	}
+
+	principal, err := manager.Authenticate(rawKey)
+	if err != nil {
+		t.Fatalf("failed to authenticate: %v", err)
+	}
+	if principal.ID != apiKey.ID || principal.Method != MethodAPIKey || !principal.HasScope(ScopeClaimsRead) {
+		t.Fatalf("unexpected principal %+v", principal)
+	}
+	if !principal.IsRestricted() || principal.AllowsSchemaType("Other") {
+		t.Fatal("principal isn't limited to the key schema types")
+	}
+	if len(q.used) != 1 || q.used[0] != apiKey.ID {
+		t.Fatal("key usage isn't recorded")
+	}
+
+	past := time.Now().UTC().Add(-time.Second)
+	cases := []struct {
+		name   string
+		rawKey string
+		change func(apiKey *data.APIKey)
+	}{
+		{"unknown key", keyPrefix + "unknown", nil},
+		{"key without prefix", strings.TrimPrefix(rawKey, keyPrefix), nil},
+		{"revoked key", rawKey, func(apiKey *data.APIKey) { apiKey.RevokedAt = &past }},
+		{"expired key", rawKey, func(apiKey *data.APIKey) { apiKey.ExpiresAt = &past }},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			stored := *apiKey
+			if tc.change != nil {
+				tc.change(&stored)
+			}
+			q.keys = []*data.APIKey{&stored}
+
+			if _, err := manager.Authenticate(tc.rawKey); !errors.Is(err, ErrInvalidCredentials) {
+				t.Fatalf("got error %v, want %v", err, ErrInvalidCredentials)
+			}
+		})
+	}
+}
+
+func TestManagerCreateValidatesScopes(t *testing.T) {
+	manager := NewManager(&testAPIKeysQ{})
+
+	cases := []struct {
+		name   string
+		scopes []string
+	}{
+		{"no scopes", nil},
+		{"unknown scope", []string{string(ScopeClaimsRead), "claims:delete"}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, _, err := manager.Create("client", tc.scopes, nil, nil); err == nil {
+				t.Fatal("api key with invalid scopes is created")
+			}
+		})
+	}
+}
+
+func TestAuthenticator(t *testing.T) {
+	q := &testAPIKeysQ{}
+	rawKey, _, err := NewManager(q).Create("client", []string{string(ScopeClaimsRead)}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create api key: %v", err)
+	}
+	token := signTestJWT(t, testJWTSecret, jose.HS256, newTestJWTClaims(nil))
+
+	withJWT := NewAuthenticator(&config.AuthConfig{JWTSecret: testJWTSecret, JWTIssuer: testJWTIssuer}, q, testIssuerDID, false)
+	withoutJWT := NewAuthenticator(&config.AuthConfig{}, q, testIssuerDID, false)
+
+	if principal, err := withJWT.Authenticate(rawKey); err != nil || principal.Method != MethodAPIKey {
+		t.Fatalf("api key isn't authenticated along with the jwts: %v", err)
+	}
+	if principal, err := withJWT.Authenticate(token); err != nil || principal.Method != MethodJWT {
+		t.Fatalf("jwt isn't authenticated: %v", err)
+	}
+	if _, err := withoutJWT.Authenticate(token); !errors.Is(err, ErrInvalidCredentials) {
+		t.Fatalf("jwt is authenticated while the jwts are disabled: %v", err)
+	}
+
+	// the token of the other hosted issuer isn't accepted
+	otherIssuer := NewAuthenticator(&config.AuthConfig{JWTSecret: testJWTSecret, JWTIssuer: testJWTIssuer}, q, "did:iden3:readonly:other", true)
+	if _, err := otherIssuer.Authenticate(token); !errors.Is(err, ErrInvalidCredentials) {
+		t.Fatalf("jwt of the other issuer is authenticated: %v", err)
+	}
+}
@@ -0,0 +1,45 @@
+package apikeys
+
+const (
+	MethodAPIKey = "api_key"
+	MethodJWT    = "jwt"
+)
+
+// Principal is the authenticated client of the private API, that is the API key or the JWT subject.
+// The principal limited to the SchemaTypes has access only to the claims of these types.
+type Principal struct {
+	ID          string
+	Name        string
+	Method      string
+	Scopes      []string
+	SchemaTypes []string
+}
+
+func (p *Principal) HasScope(scope Scope) bool {
+	for _, granted := range p.Scopes {
+		if Scope(granted) == scope {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IsRestricted is true if the principal is limited to the schema types.
+func (p *Principal) IsRestricted() bool {
+	return len(p.SchemaTypes) > 0
+}
+
+func (p *Principal) AllowsSchemaType(schemaType string) bool {
+	if !p.IsRestricted() {
+		return true
+	}
+
+	for _, allowed := range p.SchemaTypes {
+		if allowed == schemaType {
+			return true
+		}
+	}
+
+	return false
+}
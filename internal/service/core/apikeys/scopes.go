@@ -0,0 +1,55 @@
+package apikeys
+
+import (
+	"github.com/pkg/errors"
+)
+
+type Scope string
+
+const (
+	ScopeClaimsRead       Scope = "claims:read"
+	ScopeClaimsIssue      Scope = "claims:issue"
+	ScopeClaimsRevoke     Scope = "claims:revoke"
+	ScopeOffersManage     Scope = "offers:manage"
+	ScopeSchemasRead      Scope = "schemas:read"
+	ScopeSchemasManage    Scope = "schemas:manage"
+	ScopeAuditRead        Scope = "audit:read"
+	ScopeIdentitiesManage Scope = "identities:manage"
+)
+
+// Scopes are all the scopes of the private API.
+var Scopes = []Scope{
+	ScopeClaimsRead,
+	ScopeClaimsIssue,
+	ScopeClaimsRevoke,
+	ScopeOffersManage,
+	ScopeSchemasRead,
+	ScopeSchemasManage,
+	ScopeAuditRead,
+	ScopeIdentitiesManage,
+}
+
+// ValidateScopes checks that the scopes are known and there is at least one of them.
+func ValidateScopes(scopes []string) error {
+	if len(scopes) == 0 {
+		return errors.New("at least one scope is required")
+	}
+
+	for _, scope := range scopes {
+		if !isKnownScope(Scope(scope)) {
+			return errors.Errorf("unknown scope %s", scope)
+		}
+	}
+
+	return nil
+}
+
+func isKnownScope(scope Scope) bool {
+	for _, known := range Scopes {
+		if known == scope {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,39 @@
+package audit
+
+import "context"
+
+type ctxKey int
+
+const (
+	actorCtxKey ctxKey = iota
+	requestIDCtxKey
+)
+
+// CtxActor adds the actor the changes made with the context are attributed to.
+func CtxActor(ctx context.Context, actor Actor) context.Context {
+	return context.WithValue(ctx, actorCtxKey, actor)
+}
+
+// ActorFromCtx returns the actor of the context, it is the anonymous system if none is added.
+func ActorFromCtx(ctx context.Context) Actor {
+	actor, ok := ctx.Value(actorCtxKey).(Actor)
+	if !ok {
+		return Actor{Type: ActorTypeSystem}
+	}
+
+	return actor
+}
+
+func CtxRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDCtxKey, requestID)
+}
+
+// RequestIDFromCtx returns the ID of the API request of the context, nil is returned outside the requests.
+func RequestIDFromCtx(ctx context.Context) *string {
+	requestID, ok := ctx.Value(requestIDCtxKey).(string)
+	if !ok {
+		return nil
+	}
+
+	return &requestID
+}
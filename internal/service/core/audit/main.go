@@ -0,0 +1,93 @@
+package audit
+
+import (
+	"context"
+	"time"
+
+	"github.com/rarimo/issuer/internal/data"
+)
+
+type EventType string
+
+const (
+	EventClaimIssued    EventType = "claim.issued"
+	EventClaimRevoked   EventType = "claim.revoked"
+	EventOfferReceived  EventType = "offer.received"
+	EventStateCommitted EventType = "state.committed"
+	EventStateFailed    EventType = "state.failed"
+)
+
+type ActorType string
+
+const (
+	// ActorTypeAPIKey and ActorTypeJWT are the private API clients, the actor ID is the principal ID
+	ActorTypeAPIKey ActorType = "api_key"
+	ActorTypeJWT    ActorType = "jwt"
+	// ActorTypeDID is the holder that made the change with the iden3comm message, the actor ID is its DID
+	ActorTypeDID ActorType = "did"
+	// ActorTypeAnonymous is the private API client when the authentication is disabled
+	ActorTypeAnonymous ActorType = "anonymous"
+	// ActorTypeSystem is the service component, the actor ID is its name
+	ActorTypeSystem ActorType = "system"
+)
+
+type Actor struct {
+	Type ActorType
+	ID   string
+}
+
+func NewSystemActor(component string) Actor {
+	return Actor{Type: ActorTypeSystem, ID: component}
+}
+
+func NewDIDActor(did string) Actor {
+	return Actor{Type: ActorTypeDID, ID: did}
+}
+
+// Subject is what the event is about, the fields that don't relate to the change are left empty.
+type Subject struct {
+	ClaimID          *string
+	OfferID          *string
+	RevNonce         *data.RevocationNonce
+	CommittedStateID *uint64
+}
+
+func NewClaimSubject(claim *data.Claim) Subject {
+	return Subject{ClaimID: &claim.ID}
+}
+
+func NewRevocationSubject(revocation *data.ClaimRevocation) Subject {
+	return Subject{ClaimID: revocation.ClaimID, RevNonce: &revocation.RevNonce}
+}
+
+func NewOfferSubject(claimOffer *data.ClaimOffer, claim *data.Claim) Subject {
+	return Subject{ClaimID: &claim.ID, OfferID: &claimOffer.ID}
+}
+
+func NewStateSubject(committedState *data.CommittedState) Subject {
+	return Subject{CommittedStateID: &committedState.ID}
+}
+
+// NewEvent creates the event of the change made by the actor of the context in the request of the context,
+// the changes made outside the API requests are attributed to the system. The event has to be inserted
+// in the transaction of the change, so it is stored only if the change is committed.
+func NewEvent(ctx context.Context, eventType EventType, subject Subject) data.AuditEvent {
+	actor := ActorFromCtx(ctx)
+
+	return data.AuditEvent{
+		EventType:        string(eventType),
+		ActorType:        string(actor.Type),
+		ActorID:          actor.ID,
+		RequestID:        RequestIDFromCtx(ctx),
+		ClaimID:          subject.ClaimID,
+		OfferID:          subject.OfferID,
+		RevNonce:         subject.RevNonce,
+		CommittedStateID: subject.CommittedStateID,
+		CreatedAt:        time.Now().UTC(),
+	}
+}
+
+// NewSystemEvent creates the event of the change made by the service component on its own.
+func NewSystemEvent(component string, eventType EventType, subject Subject) data.AuditEvent {
+	return NewEvent(CtxActor(context.Background(), NewSystemActor(component)), eventType, subject)
+}
@@ -32,12 +32,15 @@ func SignClaimEntry(claim *core.Claim, signFunc func(*big.Int) ([]byte, error))
 
 func DefineMerklizedRootPosition(metadata *jsonSuite.SchemaMetadata, position string) string {
 	if metadata != nil && metadata.Serialization != nil {
-		return ""
+		return utils.MerklizedRootPositionNone
 	}
 
-	if position != "" {
+	switch position {
+	case "":
+		return utils.MerklizedRootPositionIndex
+	case MerklizedRootPositionNone:
+		return utils.MerklizedRootPositionNone
+	default:
 		return position
 	}
-
-	return utils.MerklizedRootPositionIndex
 }
@@ -7,8 +7,9 @@ import (
 )
 
 const (
-	CredentialStatusCheckURL = "/integrations/issuer/v1/public/claims/revocations/check/"
-	MTPUpdateURL             = "/integrations/issuer/v1/public/claims/mtp-update/"
+	// the URLs are relative to the URL of the issuer scoped by its DID
+	CredentialStatusCheckURL = "/public/claims/revocations/check/"
+	MTPUpdateURL             = "/public/claims/mtp-update/"
 
 	AuthBJJCredentialClaimType = "AuthBJJCredential" //nolint
 )
@@ -34,20 +35,28 @@ const (
 	IdentityProvidersSchemaPath = "/json/IdentityProviders.json"
 )
 
+const (
+	MerklizedRootPositionIndex = "index"
+	MerklizedRootPositionValue = "value"
+	MerklizedRootPositionNone  = "none"
+)
+
 type ClaimDataParseFunc = func([]byte) ([]byte, error)
 
 type ClaimData struct {
 	ClaimSchemaURL        string
 	ClaimSchemaName       string
+	JSONLdContext         string
+	MerklizedRootPosition string
+	IsDeprecated          bool
+	// RevokeOnExpiration makes the expiration sweeper revoke the expired claims
+	RevokeOnExpiration    bool
 	ClaimDataValidateFunc validation.RuleFunc
 	ClaimDataParseFunc    ClaimDataParseFunc
 }
 
-var ClaimSchemaTypeList = map[string]ClaimSchemaType{
-	NaturalPersonSchemaType.ToRaw():     NaturalPersonSchemaType,
-	IdentityProvidersSchemaType.ToRaw(): IdentityProvidersSchemaType,
-}
-
+// ClaimSchemaList contains the built-in claim schemas, they are seeded into the
+// claim schemas registry on the first start and keep their validation functions.
 var ClaimSchemaList = map[ClaimSchemaType]ClaimData{
 	NaturalPersonSchemaType: {
 		ClaimSchemaURL:        NaturalPersonSchemaPath,
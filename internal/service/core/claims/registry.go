@@ -0,0 +1,137 @@
+package claims
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// claimSchemaRefreshInterval is how long the registered schema is used before it is read from
+// the source again, so the deprecation made by the other instances is applied without the restart.
+// The schema type that is missing in the source isn't read again for the same interval.
+const claimSchemaRefreshInterval = time.Minute
+
+// maxClaimSchemaMisses bounds the missing schema types that are remembered, so the lookups of
+// the arbitrary schema types don't grow the registry, the misses are forgotten once it is reached.
+const maxClaimSchemaMisses = 1024
+
+// ClaimSchemaSource reads the claim schema from the claim_schemas table, nil is returned if it isn't registered.
+type ClaimSchemaSource func(context.Context, ClaimSchemaType) (*ClaimData, error)
+
+// registry holds the claim schemas that are available in runtime. It is filled from the
+// claim_schemas table by the schemas builder on the start and extended by the registration API,
+// the schemas registered by the other instances are read through the source on a miss.
+var registry = newClaimSchemaRegistry()
+
+type claimSchemaRegistry struct {
+	schemas map[ClaimSchemaType]registeredClaimSchema
+	misses  map[ClaimSchemaType]time.Time
+	source  ClaimSchemaSource
+	mu      sync.RWMutex
+}
+
+type registeredClaimSchema struct {
+	ClaimData
+	registeredAt time.Time
+}
+
+func newClaimSchemaRegistry() *claimSchemaRegistry {
+	return &claimSchemaRegistry{
+		schemas: map[ClaimSchemaType]registeredClaimSchema{},
+		misses:  map[ClaimSchemaType]time.Time{},
+	}
+}
+
+// SetClaimSchemaSource sets the source the schemas missing in the registry are read from.
+func SetClaimSchemaSource(source ClaimSchemaSource) {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+
+	registry.source = source
+}
+
+func RegisterClaimSchema(schemaType ClaimSchemaType, claimData ClaimData) {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+
+	registry.register(schemaType, claimData)
+}
+
+func (r *claimSchemaRegistry) register(schemaType ClaimSchemaType, claimData ClaimData) {
+	// the handwritten functions of the built-in schemas override the generic ones
+	if builtIn, ok := ClaimSchemaList[schemaType]; ok {
+		if builtIn.ClaimDataValidateFunc != nil {
+			claimData.ClaimDataValidateFunc = builtIn.ClaimDataValidateFunc
+		}
+		if builtIn.ClaimDataParseFunc != nil {
+			claimData.ClaimDataParseFunc = builtIn.ClaimDataParseFunc
+		}
+	}
+
+	r.schemas[schemaType] = registeredClaimSchema{
+		ClaimData:    claimData,
+		registeredAt: time.Now().UTC(),
+	}
+	delete(r.misses, schemaType)
+}
+
+func (r *claimSchemaRegistry) miss(schemaType ClaimSchemaType) {
+	if len(r.misses) >= maxClaimSchemaMisses {
+		r.misses = map[ClaimSchemaType]time.Time{}
+	}
+
+	delete(r.schemas, schemaType)
+	r.misses[schemaType] = time.Now().UTC()
+}
+
+func DeprecateClaimSchema(schemaType ClaimSchemaType) {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+
+	registered, ok := registry.schemas[schemaType]
+	if !ok {
+		return
+	}
+
+	registered.IsDeprecated = true
+	registry.schemas[schemaType] = registered
+}
+
+// GetClaimSchema returns the registered claim schema, the schema that is missing or was registered
+// more than the refresh interval ago is read from the source within the ctx. The registered schema is
+// returned as it is if the source fails, false is returned if the schema isn't known to the registry or
+// the source, the source isn't asked for the missing schema again until the refresh interval passes.
+func GetClaimSchema(ctx context.Context, schemaType ClaimSchemaType) (ClaimData, bool) {
+	return registry.get(ctx, schemaType)
+}
+
+func (r *claimSchemaRegistry) get(ctx context.Context, schemaType ClaimSchemaType) (ClaimData, bool) {
+	r.mu.RLock()
+	registered, ok := r.schemas[schemaType]
+	missedAt, missed := r.misses[schemaType]
+	source := r.source
+	r.mu.RUnlock()
+
+	if source == nil || (ok && time.Since(registered.registeredAt) < claimSchemaRefreshInterval) {
+		return registered.ClaimData, ok
+	}
+	if missed && time.Since(missedAt) < claimSchemaRefreshInterval {
+		return ClaimData{}, false
+	}
+
+	claimData, err := source(ctx, schemaType)
+	if err != nil {
+		return registered.ClaimData, ok
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if claimData == nil {
+		r.miss(schemaType)
+		return ClaimData{}, false
+	}
+
+	r.register(schemaType, *claimData)
+	return r.schemas[schemaType].ClaimData, true
+}
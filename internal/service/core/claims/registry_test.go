@@ -0,0 +1,112 @@
+package claims
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+type ctxKey struct{}
+
+func TestClaimSchemaRegistryCachesMisses(t *testing.T) {
+	var reads int
+	r := newClaimSchemaRegistry()
+	r.source = func(ctx context.Context, schemaType ClaimSchemaType) (*ClaimData, error) {
+		reads++
+		return nil, nil
+	}
+
+	for i := 0; i < 3; i++ {
+		if _, ok := r.get(context.Background(), "Unknown"); ok {
+			t.Fatal("unknown schema is found")
+		}
+	}
+	if reads != 1 {
+		t.Fatalf("source is read %d times, want 1", reads)
+	}
+
+	// the miss is forgotten after the refresh interval, so the schema registered meanwhile is found
+	r.misses["Unknown"] = time.Now().UTC().Add(-claimSchemaRefreshInterval)
+	r.source = func(ctx context.Context, schemaType ClaimSchemaType) (*ClaimData, error) {
+		reads++
+		return &ClaimData{ClaimSchemaName: "Unknown"}, nil
+	}
+
+	claimData, ok := r.get(context.Background(), "Unknown")
+	if !ok || claimData.ClaimSchemaName != "Unknown" {
+		t.Fatal("schema registered after the miss isn't found")
+	}
+	if _, missed := r.misses["Unknown"]; missed {
+		t.Fatal("registered schema is still remembered as missing")
+	}
+}
+
+func TestClaimSchemaRegistryRegisterForgetsMiss(t *testing.T) {
+	r := newClaimSchemaRegistry()
+	r.source = func(ctx context.Context, schemaType ClaimSchemaType) (*ClaimData, error) {
+		return nil, nil
+	}
+
+	if _, ok := r.get(context.Background(), "Registered"); ok {
+		t.Fatal("unknown schema is found")
+	}
+
+	r.register("Registered", ClaimData{ClaimSchemaName: "Registered"})
+
+	if _, ok := r.get(context.Background(), "Registered"); !ok {
+		t.Fatal("schema registered by the instance isn't found until the miss expires")
+	}
+}
+
+func TestClaimSchemaRegistryBoundsMisses(t *testing.T) {
+	r := newClaimSchemaRegistry()
+	r.source = func(ctx context.Context, schemaType ClaimSchemaType) (*ClaimData, error) {
+		return nil, nil
+	}
+
+	for i := 0; i < maxClaimSchemaMisses*2; i++ {
+		r.get(context.Background(), ClaimSchemaType(fmt.Sprint("Unknown", i)))
+	}
+	if len(r.misses) > maxClaimSchemaMisses {
+		t.Fatalf("%d misses are remembered, want at most %d", len(r.misses), maxClaimSchemaMisses)
+	}
+}
+
+func TestClaimSchemaRegistryPassesCtx(t *testing.T) {
+	r := newClaimSchemaRegistry()
+	r.source = func(ctx context.Context, schemaType ClaimSchemaType) (*ClaimData, error) {
+		if ctx.Value(ctxKey{}) != "request" {
+			t.Fatal("source is called without the request ctx")
+		}
+		return nil, ctx.Err()
+	}
+
+	ctx, cancel := context.WithCancel(context.WithValue(context.Background(), ctxKey{}, "request"))
+	cancel()
+
+	if _, ok := r.get(ctx, "Canceled"); ok {
+		t.Fatal("schema is found by the canceled request")
+	}
+	// the failed read isn't a miss, so the schema is read again by the next request
+	if _, missed := r.misses["Canceled"]; missed {
+		t.Fatal("failed read is remembered as missing")
+	}
+}
+
+func TestClaimSchemaRegistryKeepsSchemaOnSourceFailure(t *testing.T) {
+	r := newClaimSchemaRegistry()
+	r.source = func(ctx context.Context, schemaType ClaimSchemaType) (*ClaimData, error) {
+		return nil, errors.New("db is down")
+	}
+	r.schemas["Stale"] = registeredClaimSchema{
+		ClaimData:    ClaimData{ClaimSchemaName: "Stale"},
+		registeredAt: time.Now().UTC().Add(-claimSchemaRefreshInterval),
+	}
+
+	claimData, ok := r.get(context.Background(), "Stale")
+	if !ok || claimData.ClaimSchemaName != "Stale" {
+		t.Fatal("registered schema isn't returned when the source fails")
+	}
+}
@@ -0,0 +1,252 @@
+package schemas
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/piprate/json-gold/ld"
+	"github.com/pkg/errors"
+	"gitlab.com/distributed_lab/logan/v3"
+
+	"github.com/rarimo/issuer/internal/assets"
+	"github.com/rarimo/issuer/internal/data"
+)
+
+const (
+	embeddedSchemasRoot = "schemas"
+	httpLoadTimeout     = 30 * time.Second
+)
+
+// documentSource provides the JSON schema or JSON-LD context by its URL,
+// it returns nil without an error if the document is absent in the source.
+type documentSource interface {
+	load(ctx context.Context, documentURL string) ([]byte, error)
+}
+
+// DocumentLoader loads the JSON schemas and JSON-LD contexts from the local directory,
+// embedded assets, network and db cache in that order. The first loaded version of the
+// document is saved to the db cache and pins its content hash, so the issuer is able to
+// start without the network and fails to load the document if it is changed since then.
+// It implements ld.DocumentLoader, so the claims processing never reaches the network
+// for the documents that were already loaded.
+type DocumentLoader struct {
+	log       *logan.Entry
+	sources   []documentSource
+	documents data.SchemaDocumentsQ
+	offline   bool
+
+	cache map[string][]byte
+	mu    sync.RWMutex
+}
+
+func NewDocumentLoader(
+	log *logan.Entry,
+	baseURL, localDir string,
+	offline bool,
+	documents data.SchemaDocumentsQ,
+) *DocumentLoader {
+	sources := make([]documentSource, 0, 2)
+	if localDir != "" {
+		sources = append(sources, &fsSource{fs: os.DirFS(localDir), baseURL: baseURL})
+	}
+
+	embedded, _ := fs.Sub(assets.Schemas, embeddedSchemasRoot)
+	sources = append(sources, &fsSource{fs: embedded, baseURL: baseURL})
+
+	return &DocumentLoader{
+		log:       log,
+		sources:   sources,
+		documents: documents,
+		offline:   offline,
+		cache:     map[string][]byte{},
+	}
+}
+
+func (l *DocumentLoader) Load(ctx context.Context, documentURL string) ([]byte, error) {
+	l.mu.RLock()
+	document, ok := l.cache[documentURL]
+	l.mu.RUnlock()
+	if ok {
+		return document, nil
+	}
+
+	document, err := l.load(ctx, documentURL)
+	if err != nil {
+		return nil, err
+	}
+
+	l.mu.Lock()
+	l.cache[documentURL] = document
+	l.mu.Unlock()
+
+	return document, nil
+}
+
+func (l *DocumentLoader) load(ctx context.Context, documentURL string) ([]byte, error) {
+	cached, err := l.documents.Get(documentURL)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get document from the db cache")
+	}
+
+	document, err := l.loadFromSources(ctx, documentURL)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to load document from the sources")
+	}
+
+	if document == nil {
+		if cached == nil {
+			return nil, errors.Wrapf(ErrDocumentIsNotAvailable, "url %s", documentURL)
+		}
+		return cached.Body, nil
+	}
+
+	hash := DocumentHash(document)
+	if cached != nil && cached.Hash != hash {
+		return nil, errors.Wrapf(ErrDocumentHashMismatch, "url %s, pinned %s, loaded %s", documentURL, cached.Hash, hash)
+	}
+
+	if err = l.saveToCache(documentURL, document, hash); err != nil {
+		return nil, errors.Wrap(err, "failed to save document to the db cache")
+	}
+
+	return document, nil
+}
+
+// loadFromSources loads the document from the local sources or the network,
+// nil is returned if it is absent locally and can't be fetched.
+func (l *DocumentLoader) loadFromSources(ctx context.Context, documentURL string) ([]byte, error) {
+	for _, source := range l.sources {
+		document, err := source.load(ctx, documentURL)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to load document from the local source")
+		}
+		if document != nil {
+			return document, nil
+		}
+	}
+
+	if l.offline {
+		return nil, nil
+	}
+
+	document, err := loadHTTP(ctx, documentURL)
+	if err != nil {
+		l.log.WithError(err).WithField("url", documentURL).Warn("Failed to fetch document, using db cache")
+		return nil, nil
+	}
+
+	return document, nil
+}
+
+func (l *DocumentLoader) saveToCache(documentURL string, document []byte, hash string) error {
+	return l.documents.Upsert(&data.SchemaDocument{
+		URL:       documentURL,
+		Body:      document,
+		Hash:      hash,
+		FetchedAt: time.Now(),
+	})
+}
+
+// LoadDocument implements ld.DocumentLoader
+func (l *DocumentLoader) LoadDocument(documentURL string) (*ld.RemoteDocument, error) {
+	document, err := l.Load(context.Background(), documentURL)
+	if err != nil {
+		return nil, ld.NewJsonLdError(ld.LoadingDocumentFailed, err)
+	}
+
+	parsed, err := ld.DocumentFromReader(bytes.NewReader(document))
+	if err != nil {
+		return nil, ld.NewJsonLdError(ld.LoadingDocumentFailed, err)
+	}
+
+	return &ld.RemoteDocument{
+		DocumentURL: documentURL,
+		Document:    parsed,
+	}, nil
+}
+
+func DocumentHash(document []byte) string {
+	hash := sha256.Sum256(document)
+	return hex.EncodeToString(hash[:])
+}
+
+type fsSource struct {
+	fs      fs.FS
+	baseURL string
+}
+
+func (s *fsSource) load(_ context.Context, documentURL string) ([]byte, error) {
+	documentPath, ok := urlToPath(s.baseURL, documentURL)
+	if !ok {
+		return nil, nil
+	}
+
+	document, err := fs.ReadFile(s.fs, documentPath)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, errors.Wrapf(err, "failed to read file %s", documentPath)
+	}
+
+	return document, nil
+}
+
+// urlToPath maps the document URL to the relative file path: the path itself for the URLs
+// relative to the schemas base URL and host and path for the other absolute URLs.
+func urlToPath(baseURL, documentURL string) (string, bool) {
+	if baseURL != "" && strings.HasPrefix(documentURL, baseURL) {
+		documentURL = strings.TrimPrefix(documentURL, baseURL)
+	}
+
+	parsedURL, err := url.Parse(documentURL)
+	if err != nil {
+		return "", false
+	}
+
+	documentPath := path.Clean(strings.TrimPrefix(path.Join(parsedURL.Host, parsedURL.Path), "/"))
+	if documentPath == "." || !fs.ValidPath(documentPath) {
+		return "", false
+	}
+
+	return filepath.ToSlash(documentPath), true
+}
+
+func loadHTTP(ctx context.Context, documentURL string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, httpLoadTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, documentURL, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create request")
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to do request")
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("unexpected response status %d", resp.StatusCode)
+	}
+
+	document, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read response body")
+	}
+
+	return document, nil
+}
@@ -0,0 +1,126 @@
+package schemas
+
+import (
+	"context"
+	"testing"
+	"testing/fstest"
+
+	"github.com/pkg/errors"
+	"gitlab.com/distributed_lab/logan/v3"
+
+	"github.com/rarimo/issuer/internal/data"
+)
+
+type schemaDocumentsQMock struct {
+	documents map[string]data.SchemaDocument
+}
+
+func (q *schemaDocumentsQMock) New() data.SchemaDocumentsQ {
+	return q
+}
+
+func (q *schemaDocumentsQMock) Get(url string) (*data.SchemaDocument, error) {
+	document, ok := q.documents[url]
+	if !ok {
+		return nil, nil
+	}
+
+	return &document, nil
+}
+
+func (q *schemaDocumentsQMock) Upsert(document *data.SchemaDocument) error {
+	q.documents[document.URL] = *document
+	return nil
+}
+
+func TestURLToPath(t *testing.T) {
+	const baseURL = "https://schemas.example.com/issuer"
+
+	cases := []struct {
+		name        string
+		documentURL string
+		path        string
+		ok          bool
+	}{
+		{"relative", "/json/NaturalPerson.json", "json/NaturalPerson.json", true},
+		{"resolved against base url", baseURL + "/json/NaturalPerson.json", "json/NaturalPerson.json", true},
+		{"absolute", "https://www.w3.org/2018/credentials/v1", "www.w3.org/2018/credentials/v1", true},
+		{"path traversal", "/../../etc/passwd", "etc/passwd", true},
+		{"empty", "", "", false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			path, ok := urlToPath(baseURL, tc.documentURL)
+			if ok != tc.ok || path != tc.path {
+				t.Fatalf("expected (%q, %v), got (%q, %v)", tc.path, tc.ok, path, ok)
+			}
+		})
+	}
+}
+
+func TestDocumentLoaderPinning(t *testing.T) {
+	const documentURL = "https://www.w3.org/2018/credentials/v1"
+
+	original := []byte(`{"@context": {}}`)
+	changed := []byte(`{"@context": {"changed": true}}`)
+
+	cases := []struct {
+		name     string
+		local    []byte
+		cached   []byte
+		expected []byte
+		err      error
+	}{
+		{name: "first load pins the document", local: original, expected: original},
+		{name: "pinned document is loaded", local: original, cached: original, expected: original},
+		{name: "changed document is rejected", local: changed, cached: original, err: ErrDocumentHashMismatch},
+		{name: "cached document is loaded offline", cached: original, expected: original},
+		{name: "missing document is not available", err: ErrDocumentIsNotAvailable},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			documentsQ := &schemaDocumentsQMock{documents: map[string]data.SchemaDocument{}}
+			if tc.cached != nil {
+				documentsQ.documents[documentURL] = data.SchemaDocument{
+					URL:  documentURL,
+					Body: tc.cached,
+					Hash: DocumentHash(tc.cached),
+				}
+			}
+
+			local := fstest.MapFS{}
+			if tc.local != nil {
+				local["www.w3.org/2018/credentials/v1"] = &fstest.MapFile{Data: tc.local}
+			}
+
+			loader := &DocumentLoader{
+				log:       logan.New(),
+				sources:   []documentSource{&fsSource{fs: local}},
+				documents: documentsQ,
+				offline:   true,
+				cache:     map[string][]byte{},
+			}
+
+			document, err := loader.Load(context.Background(), documentURL)
+			if tc.err != nil {
+				if !errors.Is(err, tc.err) {
+					t.Fatalf("expected error %v, got %v", tc.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if string(document) != string(tc.expected) {
+				t.Fatalf("expected document %s, got %s", tc.expected, document)
+			}
+
+			pinned, ok := documentsQ.documents[documentURL]
+			if !ok || pinned.Hash != DocumentHash(tc.expected) {
+				t.Fatalf("expected document to be pinned with hash %s", DocumentHash(tc.expected))
+			}
+		})
+	}
+}
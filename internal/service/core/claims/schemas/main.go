@@ -4,74 +4,389 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"math/big"
+	"net/url"
+	"time"
 
 	core "github.com/iden3/go-iden3-core"
 	jsonSuite "github.com/iden3/go-schema-processor/json"
-	"github.com/iden3/go-schema-processor/loaders"
 	"github.com/iden3/go-schema-processor/processor"
 	"github.com/iden3/go-schema-processor/utils"
 	"github.com/iden3/go-schema-processor/verifiable"
 	"github.com/pkg/errors"
 
+	"github.com/rarimo/issuer/internal/data"
+	"github.com/rarimo/issuer/internal/data/pg"
 	"github.com/rarimo/issuer/internal/service/core/claims"
+	"github.com/rarimo/issuer/internal/service/core/claims/validation"
 )
 
-func NewBuilder(ctx context.Context, schemasBaseUrl string) (*Builder, error) {
+func NewBuilder(ctx context.Context, cfg Config) (*Builder, error) {
 	builder := &Builder{
-		SchemasBaseURL: schemasBaseUrl,
-		CachedSchemas:  map[string]Schema{},
+		SchemasBaseURL: cfg.SchemasBaseURL,
+		log:            cfg.Log,
+		schemasQ:       pg.NewClaimSchemasQ(cfg.DB),
+		loader: NewDocumentLoader(
+			cfg.Log, cfg.SchemasBaseURL, cfg.LocalDir, cfg.Offline, pg.NewSchemaDocumentsQ(cfg.DB),
+		),
+		cachedSchemas: map[string]Schema{},
+	}
+
+	if err := builder.seedSchemas(); err != nil {
+		return nil, errors.Wrap(err, "failed to seed built-in schemas")
 	}
 
-	if err := builder.loadSchemas(ctx, schemasBaseUrl); err != nil {
+	if err := builder.loadSchemas(ctx); err != nil {
 		return nil, errors.Wrap(err, "failed to load schemas")
 	}
 
+	if err := builder.loadContexts(ctx); err != nil {
+		return nil, errors.Wrap(err, "failed to load json-ld contexts")
+	}
+
+	claims.SetClaimSchemaSource(builder.resolveClaimSchema)
+
 	return builder, nil
 }
 
-func (b *Builder) loadSchemas(ctx context.Context, schemasBaseUrl string) error {
-	for schemaType, schema := range claims.ClaimSchemaList {
-		schemaBytes, _, err := (&loaders.HTTP{
-			URL: fmt.Sprint(schemasBaseUrl, schema.ClaimSchemaURL),
-		}).Load(ctx)
+// seedSchemas puts the built-in claim schemas into the registry if they are not there yet.
+func (b *Builder) seedSchemas() error {
+	for schemaType, claimData := range claims.ClaimSchemaList {
+		schemaRaw, err := b.schemasQ.Get(schemaType.ToRaw())
 		if err != nil {
-			return errors.Wrap(err, "failed to load schema")
+			return errors.Wrap(err, "failed to get claim schema from db")
+		}
+		if schemaRaw != nil {
+			continue
 		}
 
-		var parsedSchema jsonSuite.Schema
-		if err = json.Unmarshal(schemaBytes, &parsedSchema); err != nil {
-			return errors.Wrap(err, "failed to parse schema")
+		// the schema seeded by the concurrently started instance is loaded as it is
+		_, err = b.schemasQ.Insert(&data.ClaimSchema{
+			SchemaType:            schemaType.ToRaw(),
+			SchemaURL:             claimData.ClaimSchemaURL,
+			JSONLdContext:         claimData.JSONLdContext,
+			DisplayName:           claimData.ClaimSchemaName,
+			MerklizedRootPosition: claimData.MerklizedRootPosition,
+			CreatedAt:             time.Now(),
+		})
+		if err != nil {
+			return errors.Wrap(err, "failed to insert claim schema to db")
+		}
+	}
+
+	return nil
+}
+
+func (b *Builder) loadSchemas(ctx context.Context) error {
+	schemasRaw, err := b.schemasQ.Select()
+	if err != nil {
+		return errors.Wrap(err, "failed to select claim schemas from db")
+	}
+
+	for i := range schemasRaw {
+		schemaRaw := &schemasRaw[i]
+
+		schema, err := b.loadSchema(ctx, schemaRaw)
+		if err != nil {
+			return errors.Wrapf(err, "failed to load schema %s", schemaRaw.SchemaType)
 		}
 
-		jsonLdContext, ok := parsedSchema.Metadata.Uris["jsonLdContext"].(string)
-		if !ok {
-			return errors.New("failed to get jsonLdContext from schema")
+		if err = b.pinSchema(schemaRaw, schema); err != nil {
+			return errors.Wrapf(err, "failed to pin schema %s", schemaRaw.SchemaType)
 		}
 
-		b.CachedSchemas[schemaType.ToRaw()] = Schema{
-			Raw:           schemaBytes,
-			Body:          parsedSchema,
-			JSONLdContext: jsonLdContext,
+		b.cacheSchema(schemaRaw, schema)
+	}
+
+	return nil
+}
+
+// pinSchema saves the schema content hash on the first load and
+// forbids the start if the schema was changed since then.
+func (b *Builder) pinSchema(schemaRaw *data.ClaimSchema, schema *Schema) error {
+	if schemaRaw.SchemaHash == schema.Hash {
+		return nil
+	}
+
+	if schemaRaw.SchemaHash != "" {
+		return errors.Wrapf(ErrSchemaHashMismatch, "pinned %s, loaded %s", schemaRaw.SchemaHash, schema.Hash)
+	}
+
+	schemaRaw.SchemaHash = schema.Hash
+	if err := b.schemasQ.Update(schemaRaw); err != nil {
+		return errors.Wrap(err, "failed to update claim schema in db")
+	}
+
+	return nil
+}
+
+// loadContexts loads the JSON-LD contexts used by the issued credentials, so
+// they are cached before the first claim is processed.
+func (b *Builder) loadContexts(ctx context.Context) error {
+	contexts := []string{
+		verifiable.JSONLDSchemaW3CCredential2018,
+		verifiable.JSONLDSchemaIden3Credential,
+	}
+
+	b.mu.RLock()
+	for _, schema := range b.cachedSchemas {
+		contexts = append(contexts, schema.JSONLdContext)
+	}
+	b.mu.RUnlock()
+
+	for _, contextURL := range contexts {
+		if _, err := b.loader.Load(ctx, contextURL); err != nil {
+			return errors.Wrapf(err, "failed to load context %s", contextURL)
 		}
 	}
 
 	return nil
 }
 
+func (b *Builder) loadSchema(ctx context.Context, schemaRaw *data.ClaimSchema) (*Schema, error) {
+	schemaBytes, err := b.loader.Load(ctx, b.ResolveSchemaURL(schemaRaw.SchemaURL))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to load schema")
+	}
+
+	var parsedSchema jsonSuite.Schema
+	if err = json.Unmarshal(schemaBytes, &parsedSchema); err != nil {
+		return nil, errors.Wrap(err, "failed to parse schema")
+	}
+
+	jsonLdContext := schemaRaw.JSONLdContext
+	if jsonLdContext == "" && parsedSchema.Metadata != nil {
+		jsonLdContext, _ = parsedSchema.Metadata.Uris[jsonLdContextURIKey].(string)
+	}
+	if jsonLdContext == "" {
+		return nil, errors.New("failed to get jsonLdContext from schema")
+	}
+
+	// the schemas without the credential subject definition are validated only by the processor
+	credentialSubject, _ := validation.NewCredentialSubjectSchema(schemaBytes)
+
+	return &Schema{
+		Raw:                   schemaBytes,
+		Hash:                  DocumentHash(schemaBytes),
+		Body:                  parsedSchema,
+		JSONLdContext:         jsonLdContext,
+		MerklizedRootPosition: schemaRaw.MerklizedRootPosition,
+		CredentialSubject:     credentialSubject,
+	}, nil
+}
+
+// resolveClaimSchema is the claims registry source, it reads the schema from the db, so the schemas
+// registered or deprecated by the other instances are available without the restart.
+func (b *Builder) resolveClaimSchema(ctx context.Context, schemaType claims.ClaimSchemaType) (*claims.ClaimData, error) {
+	schemaRaw, schema, err := b.readSchema(ctx, schemaType)
+	if err != nil {
+		b.log.WithError(err).WithField("schema_type", schemaType).Error("Failed to read claim schema")
+		return nil, err
+	}
+	if schemaRaw == nil {
+		return nil, nil
+	}
+
+	claimData := newClaimData(schemaRaw, schema)
+	return &claimData, nil
+}
+
+// readSchema reads the schema from the db and loads its document if it isn't cached yet, nil is returned
+// if the schema isn't registered. The document is cached by the schema type, it is pinned, so it doesn't change.
+func (b *Builder) readSchema(ctx context.Context, schemaType claims.ClaimSchemaType) (*data.ClaimSchema, *Schema, error) {
+	schemaRaw, err := b.schemasQ.New().Get(schemaType.ToRaw())
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to get claim schema from db")
+	}
+	if schemaRaw == nil {
+		return nil, nil, nil
+	}
+
+	b.mu.RLock()
+	schema, ok := b.cachedSchemas[schemaRaw.SchemaType]
+	b.mu.RUnlock()
+	if ok {
+		return schemaRaw, &schema, nil
+	}
+
+	loaded, err := b.loadSchema(ctx, schemaRaw)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "failed to load schema %s", schemaRaw.SchemaType)
+	}
+
+	if err = b.pinSchema(schemaRaw, loaded); err != nil {
+		return nil, nil, errors.Wrapf(err, "failed to pin schema %s", schemaRaw.SchemaType)
+	}
+
+	if _, err = b.loader.Load(ctx, loaded.JSONLdContext); err != nil {
+		return nil, nil, errors.Wrap(err, "failed to load json-ld context")
+	}
+
+	b.mu.Lock()
+	b.cachedSchemas[schemaRaw.SchemaType] = *loaded
+	b.mu.Unlock()
+
+	return schemaRaw, loaded, nil
+}
+
+func (b *Builder) cacheSchema(schemaRaw *data.ClaimSchema, schema *Schema) {
+	b.mu.Lock()
+	b.cachedSchemas[schemaRaw.SchemaType] = *schema
+	b.mu.Unlock()
+
+	claims.RegisterClaimSchema(claims.ClaimSchemaType(schemaRaw.SchemaType), newClaimData(schemaRaw, schema))
+}
+
+func newClaimData(schemaRaw *data.ClaimSchema, schema *Schema) claims.ClaimData {
+	claimData := claims.ClaimData{
+		ClaimSchemaURL:        schemaRaw.SchemaURL,
+		ClaimSchemaName:       schemaRaw.DisplayName,
+		JSONLdContext:         schema.JSONLdContext,
+		MerklizedRootPosition: schemaRaw.MerklizedRootPosition,
+		IsDeprecated:          schemaRaw.IsDeprecated,
+		RevokeOnExpiration:    schemaRaw.RevokeOnExpiration,
+	}
+
+	if schema.CredentialSubject != nil {
+		claimData.ClaimDataValidateFunc = schema.CredentialSubject.Validate
+		claimData.ClaimDataParseFunc = schema.CredentialSubject.Parse
+	}
+
+	return claimData
+}
+
+// RegisterSchema loads the schema by its URL, stores it in the registry and makes it
+// available for the issuance without the service restart.
+func (b *Builder) RegisterSchema(ctx context.Context, schemaRaw *data.ClaimSchema) error {
+	existing, err := b.schemasQ.Get(schemaRaw.SchemaType)
+	if err != nil {
+		return errors.Wrap(err, "failed to get claim schema from db")
+	}
+	if existing != nil {
+		return ErrSchemaAlreadyExists
+	}
+
+	schema, err := b.loadSchema(ctx, schemaRaw)
+	if err != nil {
+		return errors.Wrap(err, "failed to load schema")
+	}
+
+	if _, err = b.loader.Load(ctx, schema.JSONLdContext); err != nil {
+		return errors.Wrap(err, "failed to load json-ld context")
+	}
+
+	schemaRaw.JSONLdContext = schema.JSONLdContext
+	schemaRaw.SchemaHash = schema.Hash
+	schemaRaw.IsDeprecated = false
+	schemaRaw.CreatedAt = time.Now()
+
+	// the schema of the same type could be registered by the concurrent request after the check
+	inserted, err := b.schemasQ.Insert(schemaRaw)
+	if err != nil {
+		return errors.Wrap(err, "failed to insert claim schema to db")
+	}
+	if !inserted {
+		return ErrSchemaAlreadyExists
+	}
+
+	b.cacheSchema(schemaRaw, schema)
+
+	return nil
+}
+
+// DeprecateSchema forbids the issuance of the new claims with the schema,
+// already issued claims are still can be offered and revoked.
+func (b *Builder) DeprecateSchema(schemaType string) error {
+	schemaRaw, err := b.schemasQ.Get(schemaType)
+	if err != nil {
+		return errors.Wrap(err, "failed to get claim schema from db")
+	}
+	if schemaRaw == nil {
+		return ErrSchemaIsNotExist
+	}
+	if schemaRaw.IsDeprecated {
+		return ErrSchemaIsDeprecated
+	}
+
+	schemaRaw.IsDeprecated = true
+	if err = b.schemasQ.Update(schemaRaw); err != nil {
+		return errors.Wrap(err, "failed to update claim schema in db")
+	}
+
+	claims.DeprecateClaimSchema(claims.ClaimSchemaType(schemaType))
+
+	return nil
+}
+
+// GetSchema returns the schema document, the schema registered by the other instance is read from the db
+// and its document is loaded within the ctx.
+func (b *Builder) GetSchema(ctx context.Context, schemaType claims.ClaimSchemaType) (Schema, bool) {
+	b.mu.RLock()
+	schema, ok := b.cachedSchemas[schemaType.ToRaw()]
+	b.mu.RUnlock()
+	if ok || b.schemasQ == nil {
+		return schema, ok
+	}
+
+	_, loaded, err := b.readSchema(ctx, schemaType)
+	if err != nil {
+		b.log.WithError(err).WithField("schema_type", schemaType).Error("Failed to read claim schema")
+		return Schema{}, false
+	}
+	if loaded == nil {
+		return Schema{}, false
+	}
+
+	return *loaded, true
+}
+
+// ResolveSchemaURL returns the absolute schema URL, relative ones are resolved against the schemas base URL.
+func (b *Builder) ResolveSchemaURL(schemaURL string) string {
+	return resolveSchemaURL(b.SchemasBaseURL, schemaURL)
+}
+
+func resolveSchemaURL(baseURL, schemaURL string) string {
+	parsedURL, err := url.Parse(schemaURL)
+	if err == nil && parsedURL.IsAbs() {
+		return schemaURL
+	}
+
+	return fmt.Sprint(baseURL, schemaURL)
+}
+
 func (b *Builder) CreateCoreClaim(
 	ctx context.Context,
 	schemaType claims.ClaimSchemaType,
 	credential *verifiable.W3CCredential,
 	revNonce uint64,
-) (*core.Claim, error) {
+	opts CompactClaimOptions,
+) (*core.Claim, *MerklizedCredential, error) {
+	schema, ok := b.GetSchema(ctx, schemaType)
+	if !ok {
+		return nil, nil, ErrSchemaIsNotExist
+	}
+
+	merklizedRootPosition := schema.MerklizedRootPosition
+	if merklizedRootPosition == "" {
+		merklizedRootPosition = utils.MerklizedRootPositionValue
+	}
+
+	merklizedRootPosition = claims.DefineMerklizedRootPosition(schema.Body.Metadata, merklizedRootPosition)
+
+	// the index of the updatable claim must stay the same between
+	// versions, so the changing merklized root is kept in the value
+	if opts.Updatable && merklizedRootPosition == utils.MerklizedRootPositionIndex {
+		merklizedRootPosition = utils.MerklizedRootPositionValue
+	}
+
+	// the merklized root is set after the parsing, so the processor
+	// doesn't load the JSON-LD contexts with its own loader
 	parseOptions := &processor.CoreClaimOptions{
-		RevNonce:        revNonce,
-		Updatable:       false,
-		SubjectPosition: utils.SubjectPositionIndex,
-		MerklizedRootPosition: claims.DefineMerklizedRootPosition(
-			b.CachedSchemas[schemaType.ToRaw()].Body.Metadata,
-			utils.MerklizedRootPositionValue,
-		),
+		RevNonce:              revNonce,
+		Version:               opts.Version,
+		Updatable:             opts.Updatable,
+		SubjectPosition:       utils.SubjectPositionIndex,
+		MerklizedRootPosition: utils.MerklizedRootPositionNone,
 	}
 
 	claimsProcessor := processor.InitProcessorOptions(
@@ -82,24 +397,76 @@ func (b *Builder) CreateCoreClaim(
 
 	jsonCredential, err := json.Marshal(credential)
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to marshal verifiable credential")
+		return nil, nil, errors.Wrap(err, "failed to marshal verifiable credential")
 	}
 
-	err = claimsProcessor.ValidateData(jsonCredential, b.CachedSchemas[schemaType.ToRaw()].Raw)
+	err = claimsProcessor.ValidateData(jsonCredential, schema.Raw)
 	if err != nil {
-		return nil, errors.Wrap(ErrValidationData, err.Error())
+		return nil, nil, errors.Wrap(ErrValidationData, err.Error())
 	}
 
 	coreClaim, err := claimsProcessor.ParseClaim(
 		ctx,
 		*credential,
-		fmt.Sprintf("%s#%s", b.CachedSchemas[schemaType.ToRaw()].JSONLdContext, schemaType.ToRaw()),
-		b.CachedSchemas[schemaType.ToRaw()].Raw,
+		fmt.Sprintf("%s#%s", schema.JSONLdContext, schemaType.ToRaw()),
+		schema.Raw,
 		parseOptions,
 	)
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to parse the schema slots")
+		return nil, nil, errors.Wrap(err, "failed to parse the schema slots")
 	}
 
-	return coreClaim, nil
+	if merklizedRootPosition == utils.MerklizedRootPositionNone {
+		if err = setIndexNonce(coreClaim, revNonce, schema.Body.Metadata, opts); err != nil {
+			return nil, nil, errors.Wrap(err, "failed to set revocation nonce to the core claim index")
+		}
+
+		return coreClaim, nil, nil
+	}
+
+	merklized, err := b.MerklizeCredential(ctx, credential)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to merklize credential")
+	}
+
+	switch merklizedRootPosition {
+	case utils.MerklizedRootPositionIndex:
+		err = coreClaim.SetIndexMerklizedRoot(merklized.Root.BigInt())
+	case utils.MerklizedRootPositionValue:
+		err = coreClaim.SetValueMerklizedRoot(merklized.Root.BigInt())
+	default:
+		return nil, nil, errors.New("unknown merklized root position")
+	}
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to set merklized root to the core claim")
+	}
+
+	if err = setIndexNonce(coreClaim, revNonce, schema.Body.Metadata, opts); err != nil {
+		return nil, nil, errors.Wrap(err, "failed to set revocation nonce to the core claim index")
+	}
+
+	return coreClaim, merklized, nil
+}
+
+// setIndexNonce puts the revocation nonce to the index slot B if the schema doesn't map any field to it.
+// The index consists of the schema and the subject otherwise, so the claims of the same type issued to
+// the same subject would have the same index. The claim keeps the nonce between versions, so does the index.
+func setIndexNonce(
+	coreClaim *core.Claim,
+	revNonce uint64,
+	metadata *jsonSuite.SchemaMetadata,
+	opts CompactClaimOptions,
+) error {
+	if opts.WithoutIndexNonce || mapsIndexSlotB(metadata) {
+		return nil
+	}
+
+	index, _ := coreClaim.RawSlots()
+	return coreClaim.SetIndexDataInts(index[2].ToInt(), new(big.Int).SetUint64(revNonce))
+}
+
+// mapsIndexSlotB reports whether the schema serialization puts any credential field to the index slot B,
+// the field value may be zero, so the slot is decided by the schema rather than by the claim.
+func mapsIndexSlotB(metadata *jsonSuite.SchemaMetadata) bool {
+	return metadata != nil && metadata.Serialization != nil && metadata.Serialization.IndexDataSlotB != ""
 }
@@ -0,0 +1,202 @@
+package schemas
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	core "github.com/iden3/go-iden3-core"
+	"github.com/iden3/go-merkletree-sql/v2"
+	"github.com/iden3/go-merkletree-sql/v2/db/memory"
+	jsonSuite "github.com/iden3/go-schema-processor/json"
+	"github.com/iden3/go-schema-processor/verifiable"
+
+	"github.com/rarimo/issuer/internal/service/core/claims"
+)
+
+const testSchemaType = "TestCredential"
+
+var testSchema = []byte(`{
+	"$schema": "http://json-schema.org/draft-07/schema#",
+	"type": "object",
+	"required": ["credentialSubject"],
+	"properties": {
+		"credentialSubject": {
+			"type": "object",
+			"required": ["id", "value"],
+			"properties": {
+				"id": {"type": "string"},
+				"value": {"type": "integer"}
+			}
+		}
+	}
+}`)
+
+func newTestBuilder() *Builder {
+	return &Builder{
+		cachedSchemas: map[string]Schema{
+			testSchemaType: {
+				Raw:                   testSchema,
+				JSONLdContext:         "https://example.com/test-credential.jsonld",
+				MerklizedRootPosition: claims.MerklizedRootPositionNone,
+			},
+		},
+	}
+}
+
+func newTestCredential(t *testing.T, subject *core.DID, value int) *verifiable.W3CCredential {
+	t.Helper()
+
+	return &verifiable.W3CCredential{
+		Context: []string{verifiable.JSONLDSchemaW3CCredential2018},
+		Type:    []string{verifiable.TypeW3CVerifiableCredential, testSchemaType},
+		CredentialSubject: map[string]interface{}{
+			"id":    subject.String(),
+			"value": value,
+		},
+	}
+}
+
+func newTestDID(t *testing.T, state int64) *core.DID {
+	t.Helper()
+
+	didType, err := core.BuildDIDType(core.DIDMethodIden3, core.NoChain, core.NoNetwork)
+	if err != nil {
+		t.Fatalf("failed to build did type: %v", err)
+	}
+
+	did, err := core.DIDGenesisFromIdenState(didType, big.NewInt(state))
+	if err != nil {
+		t.Fatalf("failed to create did: %v", err)
+	}
+
+	return did
+}
+
+func TestCreateCoreClaimIndex(t *testing.T) {
+	ctx := context.Background()
+	builder := newTestBuilder()
+	subject := newTestDID(t, 1)
+
+	type issuance struct {
+		revNonce uint64
+		value    int
+		opts     CompactClaimOptions
+	}
+
+	cases := []struct {
+		name      string
+		first     issuance
+		second    issuance
+		sameIndex bool
+	}{
+		{
+			name:   "two claims of one type to one subject",
+			first:  issuance{revNonce: 1, value: 1},
+			second: issuance{revNonce: 2, value: 1},
+		},
+		{
+			name:      "updatable claim with the changed data",
+			first:     issuance{revNonce: 1, value: 1, opts: CompactClaimOptions{Updatable: true}},
+			second:    issuance{revNonce: 1, value: 2, opts: CompactClaimOptions{Updatable: true}},
+			sameIndex: true,
+		},
+		{
+			name:      "claim issued without the index nonce with the changed data",
+			first:     issuance{revNonce: 1, value: 1, opts: CompactClaimOptions{WithoutIndexNonce: true}},
+			second:    issuance{revNonce: 2, value: 2, opts: CompactClaimOptions{WithoutIndexNonce: true}},
+			sameIndex: true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			tree, err := merkletree.NewMerkleTree(ctx, memory.NewMemoryStorage(), 40)
+			if err != nil {
+				t.Fatalf("failed to create merkle tree: %v", err)
+			}
+
+			indexes := make([]*big.Int, 0, 2)
+			for _, item := range []issuance{tc.first, tc.second} {
+				coreClaim, _, err := builder.CreateCoreClaim(
+					ctx, testSchemaType, newTestCredential(t, subject, item.value), item.revNonce, item.opts,
+				)
+				if err != nil {
+					t.Fatalf("failed to create core claim: %v", err)
+				}
+
+				hi, hv, err := coreClaim.HiHv()
+				if err != nil {
+					t.Fatalf("failed to get claim index and value hash: %v", err)
+				}
+				indexes = append(indexes, hi)
+
+				if tc.sameIndex {
+					continue
+				}
+
+				if err = tree.Add(ctx, hi, hv); err != nil {
+					t.Fatalf("failed to add claim to the claims tree: %v", err)
+				}
+			}
+
+			if isSame := indexes[0].Cmp(indexes[1]) == 0; isSame != tc.sameIndex {
+				t.Fatalf("expected the same index to be %v, got %v", tc.sameIndex, isSame)
+			}
+		})
+	}
+}
+
+func TestCreateCoreClaimIndexSlotB(t *testing.T) {
+	const slotBSchemaType = "TestSlotBCredential"
+
+	ctx := context.Background()
+	builder := newTestBuilder()
+	subject := newTestDID(t, 1)
+
+	var body jsonSuite.Schema
+	slotBSchema := []byte(`{
+	"$metadata": {"serialization": {"indexDataSlotB": "value"}},
+	"$schema": "http://json-schema.org/draft-07/schema#",
+	"type": "object",
+	"required": ["credentialSubject"],
+	"properties": {
+		"credentialSubject": {
+			"type": "object",
+			"required": ["id", "value"],
+			"properties": {
+				"id": {"type": "string"},
+				"value": {"type": "integer"}
+			}
+		}
+	}
+}`)
+	if err := json.Unmarshal(slotBSchema, &body); err != nil {
+		t.Fatalf("failed to unmarshal schema: %v", err)
+	}
+
+	builder.cachedSchemas[slotBSchemaType] = Schema{
+		Raw:                   slotBSchema,
+		Body:                  body,
+		JSONLdContext:         "https://example.com/test-credential.jsonld",
+		MerklizedRootPosition: claims.MerklizedRootPositionNone,
+	}
+
+	for _, value := range []int{0, 7} {
+		credential := newTestCredential(t, subject, value)
+		credential.Type = []string{verifiable.TypeW3CVerifiableCredential, slotBSchemaType}
+		// the credential subject is decoded from JSON, so the numbers are floats
+		credential.CredentialSubject["value"] = float64(value)
+
+		coreClaim, _, err := builder.CreateCoreClaim(ctx, slotBSchemaType, credential, 42, CompactClaimOptions{})
+		if err != nil {
+			t.Fatalf("failed to create core claim: %v", err)
+		}
+
+		index, _ := coreClaim.RawSlots()
+		if slotB := index[3].ToInt(); slotB.Cmp(big.NewInt(int64(value))) != 0 {
+			t.Fatalf("expected the index slot B to hold the field value %d, got %s", value, slotB)
+		}
+	}
+}
@@ -0,0 +1,188 @@
+package schemas
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"math/big"
+
+	"github.com/iden3/go-merkletree-sql/v2"
+	"github.com/iden3/go-merkletree-sql/v2/db/memory"
+	"github.com/iden3/go-schema-processor/merklize"
+	"github.com/iden3/go-schema-processor/verifiable"
+	"github.com/piprate/json-gold/ld"
+	"github.com/pkg/errors"
+
+	"github.com/rarimo/issuer/internal/service/core/claims"
+)
+
+const (
+	merklizedTreeDepth = 40
+	credentialProofKey = "proof"
+
+	credentialSubjectIRI = "https://www.w3.org/2018/credentials#credentialSubject"
+)
+
+// MerklizedCredential is the credential merklized over the whole JSON-LD document,
+// the Document is the canonical N-Quads form the tree is built from.
+type MerklizedCredential struct {
+	Root     *merkletree.Hash
+	Document []byte
+}
+
+// MerklePath is the proof of the credential subject field inclusion into the merklized credential.
+type MerklePath struct {
+	Field string            `json:"field"`
+	Path  []interface{}     `json:"path"`
+	Key   string            `json:"key"`
+	Value string            `json:"value,omitempty"`
+	Proof *merkletree.Proof `json:"proof"`
+}
+
+// MerklizeCredential builds the merkle tree of the credential JSON-LD document the same way as
+// verifiable.W3CCredential.Merklize does, but resolves the contexts with the issuer document loader.
+func (b *Builder) MerklizeCredential(
+	ctx context.Context,
+	credential *verifiable.W3CCredential,
+) (*MerklizedCredential, error) {
+	credentialRaw, err := json.Marshal(credential)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal credential")
+	}
+
+	var credentialDocument map[string]interface{}
+	if err = json.Unmarshal(credentialRaw, &credentialDocument); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal credential")
+	}
+	delete(credentialDocument, credentialProofKey)
+
+	options := ld.NewJsonLdOptions("")
+	options.Algorithm = ld.AlgorithmURDNA2015
+	options.SafeMode = true
+	options.DocumentLoader = b.loader
+
+	normalized, err := ld.NewJsonLdProcessor().Normalize(credentialDocument, options)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to normalize credential")
+	}
+
+	dataset, ok := normalized.(*ld.RDFDataset)
+	if !ok {
+		return nil, errors.New("normalized credential is not a rdf dataset")
+	}
+
+	var document bytes.Buffer
+	if err = (&ld.NQuadRDFSerializer{}).SerializeTo(&document, dataset); err != nil {
+		return nil, errors.Wrap(err, "failed to serialize rdf dataset")
+	}
+
+	mt, err := merkleTreeFromDataset(ctx, dataset)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to build merkle tree")
+	}
+
+	return &MerklizedCredential{
+		Root:     mt.Root(),
+		Document: document.Bytes(),
+	}, nil
+}
+
+// MerklePaths returns the merkle proofs of the credential subject fields against the stored
+// merklized document. Fields are the credential subject JSON-LD terms, nested ones are
+// separated with the dot, e.g. `address.city`.
+func (b *Builder) MerklePaths(
+	ctx context.Context,
+	schemaType claims.ClaimSchemaType,
+	document []byte,
+	fields []string,
+) (*merkletree.Hash, []MerklePath, error) {
+	schema, ok := b.GetSchema(ctx, schemaType)
+	if !ok {
+		return nil, nil, ErrSchemaIsNotExist
+	}
+
+	jsonLdContext, err := b.loader.Load(ctx, schema.JSONLdContext)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to load json-ld context")
+	}
+
+	dataset, err := ld.ParseNQuads(string(document))
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to parse merklized document")
+	}
+
+	mt, err := merkleTreeFromDataset(ctx, dataset)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to build merkle tree")
+	}
+
+	paths := make([]MerklePath, 0, len(fields))
+	for _, field := range fields {
+		path, err := merklize.NewFieldPathFromContext(jsonLdContext, schemaType.ToRaw(), field)
+		if err != nil {
+			return nil, nil, errors.Wrapf(ErrFieldIsNotExist, "field %s: %s", field, err.Error())
+		}
+
+		if err = path.Prepend(credentialSubjectIRI); err != nil {
+			return nil, nil, errors.Wrap(err, "failed to prepend credential subject to the path")
+		}
+
+		key, err := path.MtEntry()
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "failed to get merkle tree entry of the field %s", field)
+		}
+
+		value, proof, err := generateProof(ctx, mt, key)
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "failed to generate proof of the field %s", field)
+		}
+
+		merklePath := MerklePath{
+			Field: field,
+			Path:  path.Parts(),
+			Key:   key.String(),
+			Proof: proof,
+		}
+		if value != nil {
+			merklePath.Value = value.String()
+		}
+
+		paths = append(paths, merklePath)
+	}
+
+	return mt.Root(), paths, nil
+}
+
+func generateProof(
+	ctx context.Context,
+	mt *merkletree.MerkleTree,
+	key *big.Int,
+) (*big.Int, *merkletree.Proof, error) {
+	proof, value, err := mt.GenerateProof(ctx, key, nil)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to generate merkle tree proof")
+	}
+	if !proof.Existence {
+		return nil, proof, nil
+	}
+
+	return value, proof, nil
+}
+
+func merkleTreeFromDataset(ctx context.Context, dataset *ld.RDFDataset) (*merkletree.MerkleTree, error) {
+	entries, err := merklize.EntriesFromRDFWithHasher(dataset, merklize.PoseidonHasher{})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get entries from rdf dataset")
+	}
+
+	mt, err := merkletree.NewMerkleTree(ctx, memory.NewMemoryStorage(), merklizedTreeDepth)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create merkle tree")
+	}
+
+	if err = merklize.AddEntriesToMerkleTree(ctx, merklize.MerkleTreeSQLAdapter(mt), entries); err != nil {
+		return nil, errors.Wrap(err, "failed to add entries to merkle tree")
+	}
+
+	return mt, nil
+}
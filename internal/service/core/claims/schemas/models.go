@@ -1,28 +1,66 @@
 package schemas
 
 import (
+	"sync"
 	"time"
 
 	jsonSuite "github.com/iden3/go-schema-processor/json"
+	"gitlab.com/distributed_lab/kit/pgdb"
+	"gitlab.com/distributed_lab/logan/v3"
 	"gitlab.com/distributed_lab/logan/v3/errors"
+
+	"github.com/rarimo/issuer/internal/data"
+	"github.com/rarimo/issuer/internal/service/core/claims/validation"
+)
+
+const (
+	jsonLdContextURIKey = "jsonLdContext"
 )
 
 var (
-	ErrValidationData = errors.New("data is not valid for requested schema")
+	ErrValidationData      = errors.New("data is not valid for requested schema")
+	ErrSchemaIsNotExist    = errors.New("claim schema is not exist")
+	ErrSchemaAlreadyExists = errors.New("claim schema already exists")
+	ErrSchemaIsDeprecated  = errors.New("claim schema is deprecated")
+	ErrSchemaHashMismatch  = errors.New("claim schema content was changed")
+	ErrFieldIsNotExist     = errors.New("field is not exist in the claim schema context")
+
+	ErrDocumentIsNotAvailable = errors.New("document is not available offline")
+	ErrDocumentHashMismatch   = errors.New("document content was changed")
 )
 
 type Builder struct {
 	SchemasBaseURL string
-	CachedSchemas  map[string]Schema
+
+	log           *logan.Entry
+	schemasQ      data.ClaimSchemasQ
+	loader        *DocumentLoader
+	cachedSchemas map[string]Schema
+	mu            sync.RWMutex
+}
+
+type Config struct {
+	Log            *logan.Entry
+	DB             *pgdb.DB
+	SchemasBaseURL string
+	LocalDir       string
+	Offline        bool
 }
 
 type Schema struct {
-	Raw           []byte
-	Body          jsonSuite.Schema
-	JSONLdContext string
+	Raw                   []byte
+	Hash                  string
+	Body                  jsonSuite.Schema
+	JSONLdContext         string
+	MerklizedRootPosition string
+	CredentialSubject     *validation.CredentialSubjectSchema
 }
 
 type CompactClaimOptions struct {
 	Expiration *time.Time
 	Version    uint32
+	Updatable  bool
+	// WithoutIndexNonce keeps the empty index slot B empty, so the new versions of the claims
+	// issued before the revocation nonce was put to the index keep the same index
+	WithoutIndexNonce bool
 }
@@ -0,0 +1,99 @@
+package schemas
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+
+	jsonSuite "github.com/iden3/go-schema-processor/json"
+	"github.com/iden3/go-schema-processor/verifiable"
+	"github.com/pkg/errors"
+
+	"github.com/rarimo/issuer/internal/service/core/claims"
+)
+
+// VendoredDocument is the document written by VendorDocuments with its content hash.
+type VendoredDocument struct {
+	URL  string
+	Path string
+	Hash string
+}
+
+// VendorDocuments fetches the built-in schemas and the JSON-LD contexts of the issued credentials into
+// the directory with the layout of the embedded assets, so they can be compiled into the binary.
+func VendorDocuments(ctx context.Context, baseURL, dir string) ([]VendoredDocument, error) {
+	documentURLs := []string{
+		verifiable.JSONLDSchemaW3CCredential2018,
+		verifiable.JSONLDSchemaIden3Credential,
+	}
+
+	documents := make(map[string][]byte, len(documentURLs))
+	for _, schemaType := range builtInSchemaTypes() {
+		schemaURL := resolveSchemaURL(baseURL, claims.ClaimSchemaList[schemaType].ClaimSchemaURL)
+
+		schemaBytes, err := loadHTTP(ctx, schemaURL)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to fetch schema %s", schemaURL)
+		}
+		documents[schemaURL] = schemaBytes
+		documentURLs = append(documentURLs, schemaURL)
+
+		var parsedSchema jsonSuite.Schema
+		if err = json.Unmarshal(schemaBytes, &parsedSchema); err != nil {
+			return nil, errors.Wrapf(err, "failed to parse schema %s", schemaURL)
+		}
+
+		if parsedSchema.Metadata != nil {
+			if jsonLdContext, _ := parsedSchema.Metadata.Uris[jsonLdContextURIKey].(string); jsonLdContext != "" {
+				documentURLs = append(documentURLs, jsonLdContext)
+			}
+		}
+	}
+
+	vendored := make([]VendoredDocument, 0, len(documentURLs))
+	for _, documentURL := range documentURLs {
+		documentPath, ok := urlToPath(baseURL, documentURL)
+		if !ok {
+			return nil, errors.Errorf("failed to map url %s to the file path", documentURL)
+		}
+
+		document, ok := documents[documentURL]
+		if !ok {
+			var err error
+			if document, err = loadHTTP(ctx, documentURL); err != nil {
+				return nil, errors.Wrapf(err, "failed to fetch document %s", documentURL)
+			}
+		}
+
+		filePath := filepath.Join(dir, filepath.FromSlash(documentPath))
+		if err := os.MkdirAll(filepath.Dir(filePath), 0o755); err != nil {
+			return nil, errors.Wrapf(err, "failed to create directory for %s", filePath)
+		}
+		if err := os.WriteFile(filePath, document, 0o644); err != nil {
+			return nil, errors.Wrapf(err, "failed to write document %s", filePath)
+		}
+
+		vendored = append(vendored, VendoredDocument{
+			URL:  documentURL,
+			Path: filePath,
+			Hash: DocumentHash(document),
+		})
+	}
+
+	return vendored, nil
+}
+
+func builtInSchemaTypes() []claims.ClaimSchemaType {
+	schemaTypes := make([]claims.ClaimSchemaType, 0, len(claims.ClaimSchemaList))
+	for schemaType := range claims.ClaimSchemaList {
+		schemaTypes = append(schemaTypes, schemaType)
+	}
+
+	sort.Slice(schemaTypes, func(i, j int) bool {
+		return schemaTypes[i] < schemaTypes[j]
+	})
+
+	return schemaTypes
+}
@@ -0,0 +1,368 @@
+package validation
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/iancoleman/strcase"
+	"gitlab.com/distributed_lab/logan/v3/errors"
+)
+
+const (
+	credentialSubjectErrorPath = "data/attributes/credential_subject"
+
+	typeInteger = "integer"
+	typeNumber  = "number"
+	typeBoolean = "boolean"
+	typeString  = "string"
+	typeNull    = "null"
+
+	formatDate     = "date"
+	formatDateTime = "date-time"
+
+	dateLayout = "2006-01-02"
+)
+
+// reservedFields are filled by the issuer itself and must not be passed in the request.
+var reservedFields = map[string]struct{}{
+	"id":   {},
+	"type": {},
+}
+
+// CredentialSubjectSchema validates and coerces the credential subject according to the
+// credentialSubject definition of the claim JSON schema, so the schemas without
+// the handwritten validation functions can be issued as well.
+type CredentialSubjectSchema struct {
+	fields   map[string]fieldSchema
+	required map[string]struct{}
+}
+
+type fieldSchema struct {
+	Type    interface{}   `json:"type"`
+	Format  string        `json:"format"`
+	Enum    []interface{} `json:"enum"`
+	Default interface{}   `json:"default"`
+}
+
+type jsonSchema struct {
+	Properties struct {
+		CredentialSubject struct {
+			Properties map[string]fieldSchema `json:"properties"`
+			Required   []string               `json:"required"`
+		} `json:"credentialSubject"`
+	} `json:"properties"`
+}
+
+func NewCredentialSubjectSchema(schemaRaw []byte) (*CredentialSubjectSchema, error) {
+	var schema jsonSchema
+	if err := json.Unmarshal(schemaRaw, &schema); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal json schema")
+	}
+
+	if len(schema.Properties.CredentialSubject.Properties) == 0 {
+		return nil, errors.New("json schema has no credential subject properties")
+	}
+
+	result := CredentialSubjectSchema{
+		fields:   map[string]fieldSchema{},
+		required: map[string]struct{}{},
+	}
+
+	for name, field := range schema.Properties.CredentialSubject.Properties {
+		if _, ok := reservedFields[name]; ok {
+			continue
+		}
+		result.fields[name] = field
+	}
+
+	for _, name := range schema.Properties.CredentialSubject.Required {
+		if _, ok := reservedFields[name]; ok {
+			continue
+		}
+		result.required[name] = struct{}{}
+	}
+
+	return &result, nil
+}
+
+// Validate is a validation.RuleFunc that checks whether the credential subject can be coerced.
+func (s *CredentialSubjectSchema) Validate(credentialSubject interface{}) error {
+	rawData, ok := credentialSubject.(json.RawMessage)
+	if !ok {
+		return errors.New("it is not a valid credential subject")
+	}
+
+	_, err := s.coerce(rawData)
+	return err
+}
+
+// Parse converts every credential subject field to the type declared in the schema.
+func (s *CredentialSubjectSchema) Parse(rawData []byte) ([]byte, error) {
+	coerced, err := s.coerce(rawData)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to coerce credential subject")
+	}
+
+	result, err := json.Marshal(coerced)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal credential subject")
+	}
+
+	return result, nil
+}
+
+func (s *CredentialSubjectSchema) coerce(rawData []byte) (map[string]interface{}, error) {
+	var credentialSubject map[string]interface{}
+
+	decoder := json.NewDecoder(bytes.NewReader(rawData))
+	decoder.UseNumber()
+	if err := decoder.Decode(&credentialSubject); err != nil {
+		return nil, validation.Errors{
+			credentialSubjectErrorPath: errors.New("it is not a valid json object"),
+		}
+	}
+
+	// the keys are sorted, so the key that is reported as passed twice doesn't depend on the map order
+	keys := make([]string, 0, len(credentialSubject))
+	for key := range credentialSubject {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	result := make(map[string]interface{}, len(s.fields))
+	errs := validation.Errors{}
+	present := map[string]struct{}{}
+
+	for _, key := range keys {
+		fieldPath := fmt.Sprintf("%s/%s", credentialSubjectErrorPath, key)
+		name := fieldName(key)
+
+		field, ok := s.fields[name]
+		if !ok {
+			errs[fieldPath] = errors.New("field is not defined in the schema")
+			continue
+		}
+
+		if _, ok := present[name]; ok {
+			errs[fieldPath] = errors.New("field is passed more than once")
+			continue
+		}
+		present[name] = struct{}{}
+
+		coerced, err := field.coerce(credentialSubject[key])
+		if err != nil {
+			errs[fieldPath] = err
+			continue
+		}
+
+		result[requestKey(name)] = coerced
+	}
+
+	for name := range s.required {
+		if _, ok := present[name]; !ok {
+			errs[fmt.Sprintf("%s/%s", credentialSubjectErrorPath, requestKey(name))] = validation.ErrRequired
+		}
+	}
+
+	for name, field := range s.fields {
+		if _, ok := present[name]; ok || field.Default == nil {
+			continue
+		}
+		result[requestKey(name)] = field.Default
+	}
+
+	if len(errs) > 0 {
+		return nil, errs
+	}
+
+	return result, nil
+}
+
+// fieldName returns the name of the schema field the credential subject key is passed for, the
+// keys are passed in the snake case, but the camel case ones are accepted as well.
+func fieldName(key string) string {
+	return strcase.ToLowerCamel(key)
+}
+
+// requestKey returns the credential subject key of the schema field, the coerced credential subject
+// is keyed in the snake case only, so the field passed in the camel case doesn't appear twice.
+func requestKey(name string) string {
+	return strcase.ToSnake(name)
+}
+
+func (f fieldSchema) coerce(value interface{}) (interface{}, error) {
+	fieldType := f.fieldType()
+	if value == nil {
+		if fieldType == typeNull || f.isNullable() {
+			return nil, nil
+		}
+		return nil, errors.New("cannot be null")
+	}
+
+	var (
+		result interface{}
+		err    error
+	)
+
+	switch fieldType {
+	case typeInteger:
+		result, err = toInteger(value)
+	case typeNumber:
+		result, err = toNumber(value)
+	case typeBoolean:
+		result, err = toBoolean(value)
+	case typeString:
+		result, err = f.toString(value)
+	default:
+		result = value
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if len(f.Enum) > 0 && !inEnum(result, f.Enum) {
+		return nil, errors.New("must be a valid value")
+	}
+
+	return result, nil
+}
+
+// fieldType returns the first non-null type, the type can be declared as a string or an array.
+func (f fieldSchema) fieldType() string {
+	switch fieldType := f.Type.(type) {
+	case string:
+		return fieldType
+	case []interface{}:
+		for _, item := range fieldType {
+			if itemType, ok := item.(string); ok && itemType != typeNull {
+				return itemType
+			}
+		}
+	}
+
+	return ""
+}
+
+func (f fieldSchema) isNullable() bool {
+	types, ok := f.Type.([]interface{})
+	if !ok {
+		return false
+	}
+
+	for _, item := range types {
+		if item == typeNull {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (f fieldSchema) toString(value interface{}) (string, error) {
+	var result string
+	switch typed := value.(type) {
+	case string:
+		result = typed
+	case json.Number:
+		result = typed.String()
+	case bool:
+		result = strconv.FormatBool(typed)
+	default:
+		return "", errors.New("it is not a string")
+	}
+
+	switch f.Format {
+	case formatDate:
+		if _, err := time.Parse(dateLayout, result); err != nil {
+			return "", errors.New("it is not a valid date in YYYY-MM-DD format")
+		}
+	case formatDateTime:
+		parsed, err := time.Parse(time.RFC3339, result)
+		if err != nil {
+			return "", errors.New("it is not a valid RFC3339 time format")
+		}
+		result = parsed.UTC().Format(time.RFC3339)
+	}
+
+	return result, nil
+}
+
+func toInteger(value interface{}) (int64, error) {
+	switch typed := value.(type) {
+	case json.Number:
+		if result, err := typed.Int64(); err == nil {
+			return result, nil
+		}
+
+		floatValue, err := typed.Float64()
+		if err != nil || floatValue != math.Trunc(floatValue) {
+			return 0, errors.New("it is not an integer")
+		}
+		return int64(floatValue), nil
+	case string:
+		result, err := strconv.ParseInt(strings.TrimSpace(typed), 10, 64)
+		if err != nil {
+			return 0, errors.New("it is not an integer")
+		}
+		return result, nil
+	default:
+		return 0, errors.New("it is not an integer")
+	}
+}
+
+func toNumber(value interface{}) (float64, error) {
+	switch typed := value.(type) {
+	case json.Number:
+		result, err := typed.Float64()
+		if err != nil {
+			return 0, errors.New("it is not a number")
+		}
+		return result, nil
+	case string:
+		result, err := strconv.ParseFloat(strings.TrimSpace(typed), 64)
+		if err != nil {
+			return 0, errors.New("it is not a number")
+		}
+		return result, nil
+	default:
+		return 0, errors.New("it is not a number")
+	}
+}
+
+func toBoolean(value interface{}) (bool, error) {
+	switch typed := value.(type) {
+	case bool:
+		return typed, nil
+	case json.Number:
+		switch typed.String() {
+		case "0":
+			return false, nil
+		case "1":
+			return true, nil
+		}
+	case string:
+		result, err := strconv.ParseBool(strings.TrimSpace(typed))
+		if err == nil {
+			return result, nil
+		}
+	}
+
+	return false, errors.New("it is not a boolean")
+}
+
+func inEnum(value interface{}, enum []interface{}) bool {
+	for _, item := range enum {
+		if fmt.Sprint(item) == fmt.Sprint(value) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,262 @@
+package validation
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+var testSchema = []byte(`{
+	"$schema": "http://json-schema.org/draft-07/schema#",
+	"type": "object",
+	"properties": {
+		"credentialSubject": {
+			"type": "object",
+			"required": ["id", "documentNumber", "age"],
+			"properties": {
+				"id": {"type": "string"},
+				"documentNumber": {"type": "string"},
+				"age": {"type": "integer"},
+				"score": {"type": "number"},
+				"isAdult": {"type": "boolean"},
+				"birthDate": {"type": "string", "format": "date"},
+				"issuedAt": {"type": "string", "format": "date-time"},
+				"level": {"type": "integer", "enum": [1, 2, 3]},
+				"nickname": {"type": ["string", "null"]},
+				"country": {"type": "string", "default": "UA"}
+			}
+		}
+	}
+}`)
+
+func TestCredentialSubjectSchemaParse(t *testing.T) {
+	schema, err := NewCredentialSubjectSchema(testSchema)
+	if err != nil {
+		t.Fatalf("failed to create schema: %v", err)
+	}
+
+	cases := []struct {
+		name     string
+		subject  string
+		expected map[string]interface{}
+		errPaths []string
+	}{
+		{
+			name:    "values of the declared types",
+			subject: `{"document_number": "AB123", "age": 30, "score": 1.5, "is_adult": true, "country": "PL"}`,
+			expected: map[string]interface{}{
+				"document_number": "AB123", "age": 30.0, "score": 1.5, "is_adult": true, "country": "PL",
+			},
+		},
+		{
+			name:    "strings are coerced to the declared types",
+			subject: `{"document_number": 123, "age": " 30 ", "score": "1.5", "is_adult": "false"}`,
+			expected: map[string]interface{}{
+				"document_number": "123", "age": 30.0, "score": 1.5, "is_adult": false, "country": "UA",
+			},
+		},
+		{
+			name:    "integral number is an integer",
+			subject: `{"document_number": "AB123", "age": 30.0}`,
+			expected: map[string]interface{}{
+				"document_number": "AB123", "age": 30.0, "country": "UA",
+			},
+		},
+		{
+			name:    "date and date time formats",
+			subject: `{"document_number": "AB123", "age": 30, "birth_date": "2000-01-31", "issued_at": "2023-01-01T12:00:00+02:00"}`,
+			expected: map[string]interface{}{
+				"document_number": "AB123", "age": 30.0, "birth_date": "2000-01-31",
+				"issued_at": "2023-01-01T10:00:00Z", "country": "UA",
+			},
+		},
+		{
+			name:    "enum value",
+			subject: `{"document_number": "AB123", "age": 30, "level": "2"}`,
+			expected: map[string]interface{}{
+				"document_number": "AB123", "age": 30.0, "level": 2.0, "country": "UA",
+			},
+		},
+		{
+			name:    "nullable field",
+			subject: `{"document_number": "AB123", "age": 30, "nickname": null}`,
+			expected: map[string]interface{}{
+				"document_number": "AB123", "age": 30.0, "nickname": nil, "country": "UA",
+			},
+		},
+		{
+			name:    "camel case keys are normalised to the snake case",
+			subject: `{"documentNumber": "AB123", "age": 30, "isAdult": true}`,
+			expected: map[string]interface{}{
+				"document_number": "AB123", "age": 30.0, "is_adult": true, "country": "UA",
+			},
+		},
+		{
+			name:    "default is replaced by the camel case key",
+			subject: `{"document_number": "AB123", "age": 30, "Country": "PL"}`,
+			expected: map[string]interface{}{
+				"document_number": "AB123", "age": 30.0, "country": "PL",
+			},
+		},
+		{
+			name:     "field passed in both cases",
+			subject:  `{"document_number": "AB123", "documentNumber": "CD456", "age": 30}`,
+			errPaths: []string{"document_number"},
+		},
+		{
+			name:     "required fields are missing",
+			subject:  `{"score": 1.5}`,
+			errPaths: []string{"document_number", "age"},
+		},
+		{
+			name:     "invalid required field is not reported as missing",
+			subject:  `{"document_number": "AB123", "age": "thirty"}`,
+			errPaths: []string{"age"},
+		},
+		{
+			name:     "boolean is not an integer",
+			subject:  `{"document_number": "AB123", "age": true}`,
+			errPaths: []string{"age"},
+		},
+		{
+			name:     "fractional number is not an integer",
+			subject:  `{"document_number": "AB123", "age": 30.5}`,
+			errPaths: []string{"age"},
+		},
+		{
+			name:     "boolean is not a number",
+			subject:  `{"document_number": "AB123", "age": 30, "score": false}`,
+			errPaths: []string{"score"},
+		},
+		{
+			name:     "invalid boolean",
+			subject:  `{"document_number": "AB123", "age": 30, "is_adult": "yes"}`,
+			errPaths: []string{"is_adult"},
+		},
+		{
+			name:     "object is not a string",
+			subject:  `{"document_number": {"number": "AB123"}, "age": 30}`,
+			errPaths: []string{"document_number"},
+		},
+		{
+			name:     "invalid date",
+			subject:  `{"document_number": "AB123", "age": 30, "birth_date": "31.01.2000"}`,
+			errPaths: []string{"birth_date"},
+		},
+		{
+			name:     "invalid date time",
+			subject:  `{"document_number": "AB123", "age": 30, "issued_at": "2023-01-01"}`,
+			errPaths: []string{"issued_at"},
+		},
+		{
+			name:     "value out of enum",
+			subject:  `{"document_number": "AB123", "age": 30, "level": 4}`,
+			errPaths: []string{"level"},
+		},
+		{
+			name:     "not nullable field is null",
+			subject:  `{"document_number": null, "age": 30}`,
+			errPaths: []string{"document_number"},
+		},
+		{
+			name:     "undefined field",
+			subject:  `{"document_number": "AB123", "age": 30, "unknown": 1}`,
+			errPaths: []string{"unknown"},
+		},
+		{
+			name:     "reserved field",
+			subject:  `{"document_number": "AB123", "age": 30, "id": "did:iden3:test"}`,
+			errPaths: []string{"id"},
+		},
+		{
+			name:     "not an object",
+			subject:  `["AB123", 30]`,
+			errPaths: []string{""},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			parsed, err := schema.Parse([]byte(tc.subject))
+			validateErr := schema.Validate(json.RawMessage(tc.subject))
+
+			if len(tc.errPaths) > 0 {
+				if err == nil || validateErr == nil {
+					t.Fatalf("credential subject is accepted: parsed %s", parsed)
+				}
+
+				errs, ok := validateErr.(validation.Errors)
+				if !ok {
+					t.Fatalf("unexpected error type %T", validateErr)
+				}
+				if len(errs) != len(tc.errPaths) {
+					t.Fatalf("got errors %v, want errors of %v", errs, tc.errPaths)
+				}
+				for _, path := range tc.errPaths {
+					errPath := credentialSubjectErrorPath
+					if path != "" {
+						errPath += "/" + path
+					}
+					if _, ok := errs[errPath]; !ok {
+						t.Fatalf("got errors %v, want error of %s", errs, errPath)
+					}
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("failed to parse credential subject: %v", err)
+			}
+			if validateErr != nil {
+				t.Fatalf("failed to validate credential subject: %v", validateErr)
+			}
+
+			var result map[string]interface{}
+			if err := json.Unmarshal(parsed, &result); err != nil {
+				t.Fatalf("failed to unmarshal parsed credential subject: %v", err)
+			}
+			if !reflect.DeepEqual(result, tc.expected) {
+				t.Fatalf("got %v, want %v", result, tc.expected)
+			}
+		})
+	}
+}
+
+func TestCredentialSubjectSchemaFields(t *testing.T) {
+	schema, err := NewCredentialSubjectSchema(testSchema)
+	if err != nil {
+		t.Fatalf("failed to create schema: %v", err)
+	}
+
+	fields := schema.Fields()
+	names := make([]string, 0, len(fields))
+	for _, field := range fields {
+		names = append(names, field.Name)
+	}
+
+	// the reserved id field is filled by the issuer, so it isn't requested
+	expected := []string{
+		"age", "birth_date", "country", "document_number", "is_adult", "issued_at", "level", "nickname", "score",
+	}
+	if !reflect.DeepEqual(names, expected) {
+		t.Fatalf("got fields %v, want %v", names, expected)
+	}
+
+	// the example passes the validation
+	example, err := json.Marshal(schema.Example())
+	if err != nil {
+		t.Fatalf("failed to marshal example: %v", err)
+	}
+	if err := schema.Validate(json.RawMessage(example)); err != nil {
+		t.Fatalf("example is not valid: %v", err)
+	}
+}
+
+func TestNewCredentialSubjectSchemaWithoutSubject(t *testing.T) {
+	_, err := NewCredentialSubjectSchema([]byte(`{"type": "object", "properties": {}}`))
+	if err == nil {
+		t.Fatal("schema without credential subject properties is accepted")
+	}
+}
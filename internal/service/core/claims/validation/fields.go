@@ -0,0 +1,87 @@
+package validation
+
+import "sort"
+
+const (
+	exampleInteger  = 1
+	exampleNumber   = 1.5
+	exampleString   = "string"
+	exampleDate     = "2000-01-01"
+	exampleDateTime = "2000-01-01T00:00:00Z"
+)
+
+// Field describes the credential subject field the way it is passed in the issuance request.
+type Field struct {
+	Name     string
+	Type     string
+	Format   string
+	Enum     []interface{}
+	Default  interface{}
+	Required bool
+	Nullable bool
+}
+
+// Fields returns the credential subject fields sorted by name, the names are in the
+// snake case as the issuance request expects them.
+func (s *CredentialSubjectSchema) Fields() []Field {
+	result := make([]Field, 0, len(s.fields))
+	for name, field := range s.fields {
+		_, required := s.required[name]
+
+		result = append(result, Field{
+			Name:     requestKey(name),
+			Type:     field.fieldType(),
+			Format:   field.Format,
+			Enum:     field.Enum,
+			Default:  field.Default,
+			Required: required,
+			Nullable: field.isNullable(),
+		})
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+
+	return result
+}
+
+// Example returns the credential subject that passes the validation, every field is filled
+// with its default value, the first enum value or the placeholder of its type.
+func (s *CredentialSubjectSchema) Example() map[string]interface{} {
+	result := make(map[string]interface{}, len(s.fields))
+	for name, field := range s.fields {
+		result[requestKey(name)] = field.example()
+	}
+
+	return result
+}
+
+func (f fieldSchema) example() interface{} {
+	if f.Default != nil {
+		return f.Default
+	}
+
+	if len(f.Enum) > 0 {
+		return f.Enum[0]
+	}
+
+	switch f.fieldType() {
+	case typeInteger:
+		return exampleInteger
+	case typeNumber:
+		return exampleNumber
+	case typeBoolean:
+		return true
+	case typeString:
+		switch f.Format {
+		case formatDate:
+			return exampleDate
+		case formatDateTime:
+			return exampleDateTime
+		}
+		return exampleString
+	}
+
+	return nil
+}
@@ -0,0 +1,68 @@
+package didresolver
+
+import (
+	"crypto/ecdsa"
+	"encoding/json"
+
+	"github.com/iden3/go-schema-processor/verifiable"
+	"github.com/pkg/errors"
+	jose "gopkg.in/go-jose/go-jose.v2"
+)
+
+var ErrKeyAgreementIsNotFound = errors.New("did document has no key agreement ec key")
+
+// KeyAgreementKey returns the first public EC JWK of the DID document key agreement, the anoncrypt messages
+// are encrypted to it. The key agreement is either the reference to the verification method or the embedded
+// one, the key ID is the ID of the verification method, so the recipient knows the key to decrypt with.
+func KeyAgreementKey(document *verifiable.DIDDocument) (*jose.JSONWebKey, error) {
+	for _, keyAgreement := range document.KeyAgreement {
+		method, ok := keyAgreementMethod(document, keyAgreement)
+		if !ok || len(method.PublicKeyJwk) == 0 {
+			continue
+		}
+
+		keyRaw, err := json.Marshal(method.PublicKeyJwk)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to marshal public jwk")
+		}
+
+		var key jose.JSONWebKey
+		if err := key.UnmarshalJSON(keyRaw); err != nil {
+			continue
+		}
+		if _, ok := key.Key.(*ecdsa.PublicKey); !ok {
+			continue
+		}
+
+		key.KeyID = method.ID
+		return &key, nil
+	}
+
+	return nil, ErrKeyAgreementIsNotFound
+}
+
+func keyAgreementMethod(
+	document *verifiable.DIDDocument,
+	keyAgreement interface{},
+) (*verifiable.CommonVerificationMethod, bool) {
+	if methodID, ok := keyAgreement.(string); ok {
+		for i := range document.VerificationMethod {
+			if document.VerificationMethod[i].ID == methodID {
+				return &document.VerificationMethod[i], true
+			}
+		}
+		return nil, false
+	}
+
+	methodRaw, err := json.Marshal(keyAgreement)
+	if err != nil {
+		return nil, false
+	}
+
+	var method verifiable.CommonVerificationMethod
+	if err := json.Unmarshal(methodRaw, &method); err != nil || method.ID == "" {
+		return nil, false
+	}
+
+	return &method, true
+}
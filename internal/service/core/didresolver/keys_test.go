@@ -0,0 +1,94 @@
+package didresolver
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/iden3/go-schema-processor/verifiable"
+)
+
+const testDID = "did:iden3:polygon:mumbai:x4jcHP4XHTK3vX58AHZPyHE8kYjneyE6FZRfz7K29"
+
+var (
+	testECKey = map[string]interface{}{
+		"kty": "EC",
+		"crv": "P-256",
+		"x":   "f83OJ3D2xF1Bg8vub9tLe1gHMzV76e8Tus9uPHvRVEU",
+		"y":   "x_FEzRu9m36HLN_tue659LNpXW6pCyStikYjKIWI5a0",
+	}
+	testOKPKey = map[string]interface{}{
+		"kty": "OKP",
+		"crv": "X25519",
+		"x":   "hSDwCYkwp1R0i33ctD73Wg2_Og0mOBr066SpjqqbTmo",
+	}
+)
+
+func TestKeyAgreementKey(t *testing.T) {
+	cases := []struct {
+		name     string
+		document *verifiable.DIDDocument
+		keyID    string
+	}{
+		{
+			name: "referenced verification method",
+			document: &verifiable.DIDDocument{
+				ID: testDID,
+				VerificationMethod: []verifiable.CommonVerificationMethod{
+					{ID: testDID + "#key-1", PublicKeyJwk: testECKey},
+				},
+				KeyAgreement: []interface{}{testDID + "#key-1"},
+			},
+			keyID: testDID + "#key-1",
+		},
+		{
+			name: "embedded verification method",
+			document: &verifiable.DIDDocument{
+				ID: testDID,
+				KeyAgreement: []interface{}{
+					map[string]interface{}{"id": testDID + "#key-2", "publicKeyJwk": testECKey},
+				},
+			},
+			keyID: testDID + "#key-2",
+		},
+		{
+			name: "unsupported key is skipped",
+			document: &verifiable.DIDDocument{
+				ID: testDID,
+				VerificationMethod: []verifiable.CommonVerificationMethod{
+					{ID: testDID + "#key-1", PublicKeyJwk: testOKPKey},
+					{ID: testDID + "#key-2", PublicKeyJwk: testECKey},
+				},
+				KeyAgreement: []interface{}{testDID + "#key-1", testDID + "#key-2"},
+			},
+			keyID: testDID + "#key-2",
+		},
+		{
+			name: "authentication key is not used",
+			document: &verifiable.DIDDocument{
+				ID: testDID,
+				VerificationMethod: []verifiable.CommonVerificationMethod{
+					{ID: testDID + "#key-1", PublicKeyJwk: testECKey},
+				},
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			key, err := KeyAgreementKey(tc.document)
+			if tc.keyID == "" {
+				if !errors.Is(err, ErrKeyAgreementIsNotFound) {
+					t.Fatalf("expected key agreement not found error, got %v", err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("failed to get key agreement key: %v", err)
+			}
+			if key.KeyID != tc.keyID {
+				t.Fatalf("expected key %s, got %s", tc.keyID, key.KeyID)
+			}
+		})
+	}
+}
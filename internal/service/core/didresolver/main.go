@@ -0,0 +1,60 @@
+package didresolver
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/iden3/go-schema-processor/verifiable"
+	"github.com/pkg/errors"
+)
+
+var ErrDIDIsNotFound = errors.New("did is not found")
+
+// Resolver resolves the DID documents with the universal resolver HTTP API.
+type Resolver struct {
+	baseURL string
+	client  *http.Client
+}
+
+// didResolution is the DID resolution result of the universal resolver
+type didResolution struct {
+	DIDDocument *verifiable.DIDDocument `json:"didDocument"`
+}
+
+func New(baseURL string, timeout time.Duration) *Resolver {
+	return &Resolver{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		client:  &http.Client{Timeout: timeout},
+	}
+}
+
+// Resolve returns the DID document of the DID, ErrDIDIsNotFound is returned if the resolver doesn't know it.
+func (r *Resolver) Resolve(did string) (*verifiable.DIDDocument, error) {
+	resp, err := r.client.Get(fmt.Sprintf("%s/%s", r.baseURL, url.PathEscape(did)))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to do request")
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil, ErrDIDIsNotFound
+	default:
+		return nil, errors.Errorf("unexpected response status %d", resp.StatusCode)
+	}
+
+	var resolution didResolution
+	if err := json.NewDecoder(resp.Body).Decode(&resolution); err != nil {
+		return nil, errors.Wrap(err, "failed to decode did resolution")
+	}
+	if resolution.DIDDocument == nil || resolution.DIDDocument.ID != did {
+		return nil, errors.New("did resolution has no document of the did")
+	}
+
+	return resolution.DIDDocument, nil
+}
@@ -0,0 +1,64 @@
+package idempotency
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"gitlab.com/distributed_lab/logan/v3"
+	"gitlab.com/distributed_lab/running"
+
+	"github.com/rarimo/issuer/internal/config"
+	"github.com/rarimo/issuer/internal/data"
+)
+
+const CleanerRunnerName = "idempotency_keys_cleaner"
+
+// Cleaner deletes the keys that are expired longer than the TTL ago.
+type Cleaner struct {
+	log              *logan.Entry
+	idempotencyKeysQ data.IdempotencyKeysQ
+	period           time.Duration
+	ttl              time.Duration
+}
+
+func NewCleaner(log *logan.Entry, cfg *config.IdempotencyConfig, idempotencyKeysQ data.IdempotencyKeysQ) *Cleaner {
+	return &Cleaner{
+		log:              log,
+		idempotencyKeysQ: idempotencyKeysQ,
+		period:           cfg.CleanupPeriod,
+		ttl:              cfg.TTL,
+	}
+}
+
+func (c *Cleaner) Run(ctx context.Context) {
+	ticker := time.NewTicker(c.period)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			running.UntilSuccess(ctx, c.log, CleanerRunnerName,
+				func(ctx context.Context) (bool, error) {
+					return true, c.clean()
+				}, c.period, c.period,
+			)
+
+			ticker.Reset(c.period)
+		}
+	}
+}
+
+func (c *Cleaner) clean() error {
+	deleted, err := c.idempotencyKeysQ.DeleteCreatedBefore(time.Now().UTC().Add(-c.ttl))
+	if err != nil {
+		return errors.Wrap(err, "failed to delete expired idempotency keys")
+	}
+
+	if deleted > 0 {
+		c.log.WithField("deleted", deleted).Info("Expired idempotency keys are deleted")
+	}
+
+	return nil
+}
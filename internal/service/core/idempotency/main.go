@@ -0,0 +1,103 @@
+package idempotency
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/rarimo/issuer/internal/config"
+	"github.com/rarimo/issuer/internal/data"
+)
+
+var (
+	ErrKeyIsReused     = errors.New("idempotency key is already used for the different request")
+	ErrKeyIsInProgress = errors.New("request with the idempotency key is in progress")
+)
+
+// Store keeps the responses of the requests by their idempotency keys, so the repeated
+// request gets the original response instead of being handled again.
+type Store struct {
+	idempotencyKeysQ data.IdempotencyKeysQ
+	ttl              time.Duration
+	lockTimeout      time.Duration
+}
+
+func NewStore(cfg *config.IdempotencyConfig, idempotencyKeysQ data.IdempotencyKeysQ) *Store {
+	return &Store{
+		idempotencyKeysQ: idempotencyKeysQ,
+		ttl:              cfg.TTL,
+		lockTimeout:      cfg.LockTimeout,
+	}
+}
+
+// Begin locks the key for the request with the hash. If the key was already used for the same request,
+// the stored response is returned and the request mustn't be handled, otherwise the response of the
+// handled request has to be stored with Complete or the key released with Release.
+func (s *Store) Begin(key string, requestHash []byte) (*data.IdempotencyKey, error) {
+	now := time.Now().UTC()
+
+	isLocked, err := s.idempotencyKeysQ.Lock(&data.IdempotencyKey{
+		ID:          key,
+		RequestHash: requestHash,
+		CreatedAt:   now,
+	}, now.Add(-s.ttl), now.Add(-s.lockTimeout))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to lock idempotency key")
+	}
+	if isLocked {
+		return nil, nil
+	}
+
+	idempotencyKey, err := s.idempotencyKeysQ.Get(key)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get idempotency key")
+	}
+	if idempotencyKey == nil {
+		// the key was released by the concurrent request after the lock attempt
+		return nil, ErrKeyIsInProgress
+	}
+
+	if !bytes.Equal(idempotencyKey.RequestHash, requestHash) {
+		return nil, ErrKeyIsReused
+	}
+	if idempotencyKey.StatusCode == nil {
+		return nil, ErrKeyIsInProgress
+	}
+
+	return idempotencyKey, nil
+}
+
+// Complete stores the response of the request locked with the key.
+func (s *Store) Complete(key string, statusCode int, contentType string, response []byte) error {
+	err := s.idempotencyKeysQ.Complete(key, statusCode, contentType, response, time.Now().UTC())
+	if err != nil {
+		return errors.Wrap(err, "failed to complete idempotency key")
+	}
+
+	return nil
+}
+
+// Release deletes the key of the request that wasn't handled, so it can be retried with the same key.
+func (s *Store) Release(key string) error {
+	err := s.idempotencyKeysQ.Delete(key)
+	if err != nil {
+		return errors.Wrap(err, "failed to delete idempotency key")
+	}
+
+	return nil
+}
+
+// RequestHash is the hash of the request method, URI and body, the same key can't be used
+// for the requests with the different hashes.
+func RequestHash(method, requestURI string, body []byte) []byte {
+	hash := sha256.New()
+	hash.Write([]byte(method))
+	hash.Write([]byte{0})
+	hash.Write([]byte(requestURI))
+	hash.Write([]byte{0})
+	hash.Write(body)
+
+	return hash.Sum(nil)
+}
@@ -0,0 +1,126 @@
+package idempotency
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/rarimo/issuer/internal/config"
+	"github.com/rarimo/issuer/internal/data"
+)
+
+// testIdempotencyKeysQ answers the lock attempt as set and serves the stored key.
+type testIdempotencyKeysQ struct {
+	data.IdempotencyKeysQ
+	isLocked        bool
+	stored          *data.IdempotencyKey
+	expiredBefore   time.Time
+	abandonedBefore time.Time
+}
+
+func (q *testIdempotencyKeysQ) Lock(key *data.IdempotencyKey, expiredBefore, abandonedBefore time.Time) (bool, error) {
+	q.expiredBefore, q.abandonedBefore = expiredBefore, abandonedBefore
+	return q.isLocked, nil
+}
+
+func (q *testIdempotencyKeysQ) Get(id string) (*data.IdempotencyKey, error) {
+	return q.stored, nil
+}
+
+func TestStoreBegin(t *testing.T) {
+	requestHash := RequestHash("POST", "/claims", []byte(`{}`))
+	statusCode := 201
+
+	cases := []struct {
+		name        string
+		isLocked    bool
+		stored      *data.IdempotencyKey
+		expectedErr error
+		isReplayed  bool
+	}{
+		{
+			name:     "new or taken over key",
+			isLocked: true,
+		},
+		{
+			name:        "key is in progress",
+			stored:      &data.IdempotencyKey{RequestHash: requestHash},
+			expectedErr: ErrKeyIsInProgress,
+		},
+		{
+			name:        "key is released after the lock attempt",
+			expectedErr: ErrKeyIsInProgress,
+		},
+		{
+			name:        "key is reused for the different request",
+			stored:      &data.IdempotencyKey{RequestHash: RequestHash("POST", "/claims", []byte(`{"a":1}`))},
+			expectedErr: ErrKeyIsReused,
+		},
+		{
+			name:        "key of the different request is reused while it is in progress",
+			stored:      &data.IdempotencyKey{RequestHash: RequestHash("PUT", "/claims", []byte(`{}`))},
+			expectedErr: ErrKeyIsReused,
+		},
+		{
+			name:       "request is completed",
+			stored:     &data.IdempotencyKey{RequestHash: requestHash, StatusCode: &statusCode, Response: []byte(`{}`)},
+			isReplayed: true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			q := &testIdempotencyKeysQ{isLocked: tc.isLocked, stored: tc.stored}
+			store := NewStore(&config.IdempotencyConfig{TTL: time.Hour, LockTimeout: time.Minute}, q)
+
+			stored, err := store.Begin("key", requestHash)
+			if !errors.Is(err, tc.expectedErr) {
+				t.Fatalf("got error %v, want %v", err, tc.expectedErr)
+			}
+			if (stored != nil) != tc.isReplayed {
+				t.Fatalf("got stored response %v, want replayed %t", stored, tc.isReplayed)
+			}
+		})
+	}
+}
+
+func TestStoreBeginTakeover(t *testing.T) {
+	q := &testIdempotencyKeysQ{isLocked: true}
+	store := NewStore(&config.IdempotencyConfig{TTL: time.Hour, LockTimeout: time.Minute}, q)
+
+	before := time.Now().UTC()
+	if _, err := store.Begin("key", nil); err != nil {
+		t.Fatalf("failed to begin: %v", err)
+	}
+	after := time.Now().UTC()
+
+	// the keys older than the TTL and the ones not completed during the lock timeout are taken over
+	if q.expiredBefore.Before(before.Add(-time.Hour)) || q.expiredBefore.After(after.Add(-time.Hour)) {
+		t.Fatalf("keys expired before %s are taken over, want %s ago", q.expiredBefore, time.Hour)
+	}
+	if q.abandonedBefore.Before(before.Add(-time.Minute)) || q.abandonedBefore.After(after.Add(-time.Minute)) {
+		t.Fatalf("keys abandoned before %s are taken over, want %s ago", q.abandonedBefore, time.Minute)
+	}
+}
+
+func TestRequestHash(t *testing.T) {
+	hash := RequestHash("POST", "/claims", []byte(`{}`))
+
+	others := [][]byte{
+		RequestHash("PUT", "/claims", []byte(`{}`)),
+		RequestHash("POST", "/claims?a=1", []byte(`{}`)),
+		RequestHash("POST", "/claims", []byte(`{ }`)),
+		// the parts are separated, so they can't be shifted between each other
+		RequestHash("POST/claims", "", []byte(`{}`)),
+	}
+	for i, other := range others {
+		if bytes.Equal(hash, other) {
+			t.Fatalf("hash %d of the different request is the same", i)
+		}
+	}
+
+	if !bytes.Equal(hash, RequestHash("POST", "/claims", []byte(`{}`))) {
+		t.Fatal("hash of the same request differs")
+	}
+}
@@ -13,40 +13,41 @@ import (
 	"github.com/rarimo/issuer/internal/service/core/identity/state"
 )
 
-func (iden *Identity) generateNewIdentity() error {
+func (iden *Identity) generateNewIdentity(ctx context.Context, db data.MasterQ) error {
 	iden.log.Info("Generating the new Identity")
 
 	if iden.babyJubJubPrivateKey == nil {
 		return errors.New("error generating new identity, babyJubJubPrivateKey is nil")
 	}
 
-	did, authClaim, err := iden.State.SetupGenesis(iden.babyJubJubPrivateKey.Public())
+	did, authClaim, trees, err := iden.State.SetupGenesis(ctx, db, iden.babyJubJubPrivateKey.Public())
 	if err != nil {
 		return errors.Wrap(err, "failed to setup genesis state")
 	}
 
 	iden.Identifier = did
-	iden.log.
-		WithField("did", did.String()).
-		Infof("The new Identity successfully generated")
 
-	if err := iden.saveAuthClaimModel(authClaim); err != nil {
+	if err := iden.saveAuthClaimModel(db, authClaim); err != nil {
 		return errors.Wrap(err, "failed to save auth claim to db")
 	}
 
-	err = iden.State.DB.CommittedStatesQ().Insert((&state.CommittedState{
+	err = db.CommittedStatesQ().Insert((&state.CommittedState{
 		Status:              data.StatusCompleted,
 		CommitInfo:          nil,
-		CreatedAt:           time.Now(),
+		CreatedAt:           time.Now().UTC(),
 		IsGenesis:           true,
-		RootsTreeRoot:       iden.State.RootsTree.Root(),
-		ClaimsTreeRoot:      iden.State.ClaimsTree.Root(),
-		RevocationsTreeRoot: iden.State.RevocationsTree.Root(),
+		RootsTreeRoot:       trees.Roots.Root(),
+		ClaimsTreeRoot:      trees.Claims.Root(),
+		RevocationsTreeRoot: trees.Revocations.Root(),
 	}).ToRaw())
 	if err != nil {
 		return errors.Wrap(err, "failed to insert genesis state to committed states")
 	}
 
+	iden.log.
+		WithField("did", iden.Identifier.String()).
+		Infof("The new Identity successfully generated")
+
 	return nil
 }
 
@@ -88,7 +89,7 @@ func (iden *Identity) parseIdentity(
 	return nil
 }
 
-func (iden *Identity) saveAuthClaimModel(coreAuthClaim *core.Claim) error {
+func (iden *Identity) saveAuthClaimModel(db data.MasterQ, coreAuthClaim *core.Claim) error {
 	if iden.babyJubJubPrivateKey == nil {
 		return errors.New("error generating new identity, babyJubJubPrivateKey is nil")
 	}
@@ -103,9 +104,10 @@ func (iden *Identity) saveAuthClaimModel(coreAuthClaim *core.Claim) error {
 		CoreClaim:  data.NewCoreClaim(coreAuthClaim),
 		ClaimType:  claims.AuthBJJCredentialClaimType,
 		Credential: authClaimData,
+		RevNonce:   data.RevocationNonce(coreAuthClaim.GetRevocationNonce()),
 	}
 
-	err = iden.State.DB.ClaimsQ().Insert(authClaim)
+	err = db.ClaimsQ().Insert(authClaim)
 	if err != nil {
 		return errors.Wrap(err, "failed to insert the auth claim to the db")
 	}
@@ -114,3 +116,23 @@ func (iden *Identity) saveAuthClaimModel(coreAuthClaim *core.Claim) error {
 
 	return nil
 }
+
+// saveDID stores the DID derived from the genesis state, the identity is addressed by it.
+func (iden *Identity) saveDID(db data.MasterQ) error {
+	model, err := db.IdentitiesQ().Get(iden.State.IdentityID)
+	if err != nil {
+		return errors.Wrap(err, "failed to get identity")
+	}
+
+	did := iden.Identifier.String()
+	if model.DID != nil && *model.DID == did {
+		return nil
+	}
+
+	err = db.IdentitiesQ().SetDID(iden.State.IdentityID, did)
+	if err != nil {
+		return errors.Wrap(err, "failed to set identity did")
+	}
+
+	return nil
+}
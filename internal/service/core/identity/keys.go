@@ -0,0 +1,149 @@
+package identity
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdsa"
+	"crypto/rand"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/iden3/go-iden3-crypto/babyjub"
+	"github.com/pkg/errors"
+
+	"github.com/rarimo/issuer/internal/config"
+	"github.com/rarimo/issuer/internal/data"
+)
+
+const (
+	babyJubJubKeyName = "babyjubjub_private_key"
+	signingKeyName    = "signing_key"
+)
+
+// keys are the keys the identity signs with, the BabyJubJub one signs the claims and the state transitions,
+// the secp256k1 one signs the iden3comm messages.
+type keys struct {
+	babyJubJubPrivateKey *babyjub.PrivateKey
+	signingKey           *ecdsa.PrivateKey
+}
+
+// loadKeys returns the configured keys for the default identity and opens the sealed ones of the other ones.
+func loadKeys(cfg config.Config, model *data.Identity) (*keys, error) {
+	if model.ID == data.DefaultIdentityID {
+		return &keys{
+			babyJubJubPrivateKey: cfg.Identity().BabyJubJubPrivateKey,
+			signingKey:           cfg.Iden3Comm().SigningKey,
+		}, nil
+	}
+
+	encryptionKey := cfg.Identity().KeysEncryptionKey
+	if encryptionKey == nil {
+		return nil, ErrKeysEncryptionKeyIsNotSet
+	}
+
+	babyJubJubKey, err := openKey(encryptionKey, model.BabyJubJubPrivateKey, keyAAD(model.Name, babyJubJubKeyName))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to open babyjubjub private key")
+	}
+
+	signingKeyRaw, err := openKey(encryptionKey, model.SigningKey, keyAAD(model.Name, signingKeyName))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to open signing key")
+	}
+
+	signingKey, err := crypto.ToECDSA(signingKeyRaw)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse signing key")
+	}
+
+	var babyJubJubPrivateKey babyjub.PrivateKey
+	copy(babyJubJubPrivateKey[:], babyJubJubKey)
+
+	return &keys{
+		babyJubJubPrivateKey: &babyJubJubPrivateKey,
+		signingKey:           signingKey,
+	}, nil
+}
+
+// newModel returns the identity named the name with the generated keys sealed with the keys encryption key.
+func newModel(cfg *config.IdentityConfig, name string) (*data.Identity, error) {
+	if cfg.KeysEncryptionKey == nil {
+		return nil, ErrKeysEncryptionKeyIsNotSet
+	}
+
+	babyJubJubPrivateKey := babyjub.NewRandPrivKey()
+	babyJubJubKey, err := sealKey(cfg.KeysEncryptionKey, babyJubJubPrivateKey[:], keyAAD(name, babyJubJubKeyName))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to seal babyjubjub private key")
+	}
+
+	signingKey, err := crypto.GenerateKey()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to generate signing key")
+	}
+
+	signingKeyRaw, err := sealKey(cfg.KeysEncryptionKey, crypto.FromECDSA(signingKey), keyAAD(name, signingKeyName))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to seal signing key")
+	}
+
+	return &data.Identity{
+		Name:                 name,
+		BabyJubJubPrivateKey: babyJubJubKey,
+		SigningKey:           signingKeyRaw,
+	}, nil
+}
+
+// sealKey encrypts the key with the AES-256-GCM, the random nonce is prepended to the sealed key.
+// The additional data binds the sealed key to the identity and the key, so it can't be opened
+// once it is copied to the other identity or swapped with the other key of the identity.
+func sealKey(encryptionKey, key, additionalData []byte) ([]byte, error) {
+	aead, err := newAEAD(encryptionKey)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, errors.Wrap(err, "failed to generate nonce")
+	}
+
+	return aead.Seal(nonce, nonce, key, additionalData), nil
+}
+
+func openKey(encryptionKey, sealed, additionalData []byte) ([]byte, error) {
+	aead, err := newAEAD(encryptionKey)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(sealed) < aead.NonceSize() {
+		return nil, errors.New("sealed key is too short")
+	}
+
+	key, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], additionalData)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decrypt key")
+	}
+
+	return key, nil
+}
+
+// keyAAD is the additional data the key of the identity is sealed with, the identity name is unique
+// and isn't changed, unlike the DID it is known before the genesis state is generated.
+func keyAAD(identityName, keyName string) []byte {
+	return []byte(identityName + "/" + keyName)
+}
+
+func newAEAD(encryptionKey []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(encryptionKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create cipher")
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create gcm")
+	}
+
+	return aead, nil
+}
@@ -0,0 +1,72 @@
+package identity
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/rarimo/issuer/internal/config"
+)
+
+func TestSealKey(t *testing.T) {
+	encryptionKey := bytes.Repeat([]byte{1}, 32)
+	key := []byte("key")
+
+	sealed, err := sealKey(encryptionKey, key, keyAAD("first", signingKeyName))
+	if err != nil {
+		t.Fatalf("failed to seal key: %v", err)
+	}
+
+	opened, err := openKey(encryptionKey, sealed, keyAAD("first", signingKeyName))
+	if err != nil {
+		t.Fatalf("failed to open key: %v", err)
+	}
+	if !bytes.Equal(opened, key) {
+		t.Fatalf("got key %x, want %x", opened, key)
+	}
+
+	cases := []struct {
+		name           string
+		encryptionKey  []byte
+		sealed         []byte
+		additionalData []byte
+	}{
+		{"other encryption key", bytes.Repeat([]byte{2}, 32), sealed, keyAAD("first", signingKeyName)},
+		{"key of the other identity", encryptionKey, sealed, keyAAD("second", signingKeyName)},
+		{"other key of the identity", encryptionKey, sealed, keyAAD("first", babyJubJubKeyName)},
+		{"no additional data", encryptionKey, sealed, nil},
+		{"truncated key", encryptionKey, sealed[:4], keyAAD("first", signingKeyName)},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := openKey(tc.encryptionKey, tc.sealed, tc.additionalData); err == nil {
+				t.Fatal("sealed key is opened")
+			}
+		})
+	}
+}
+
+func TestNewModel(t *testing.T) {
+	identityConfig := &config.IdentityConfig{KeysEncryptionKey: bytes.Repeat([]byte{1}, 32)}
+
+	first, err := newModel(identityConfig, "first")
+	if err != nil {
+		t.Fatalf("failed to create identity model: %v", err)
+	}
+	second, err := newModel(identityConfig, "second")
+	if err != nil {
+		t.Fatalf("failed to create identity model: %v", err)
+	}
+
+	if _, err := openKey(identityConfig.KeysEncryptionKey, first.SigningKey, keyAAD("first", signingKeyName)); err != nil {
+		t.Fatalf("failed to open signing key: %v", err)
+	}
+	if _, err := openKey(identityConfig.KeysEncryptionKey, first.BabyJubJubPrivateKey, keyAAD("first", babyJubJubKeyName)); err != nil {
+		t.Fatalf("failed to open babyjubjub private key: %v", err)
+	}
+
+	// the keys copied from the other identity aren't opened
+	if _, err := openKey(identityConfig.KeysEncryptionKey, second.SigningKey, keyAAD("first", signingKeyName)); err == nil {
+		t.Fatal("signing key of the other identity is opened")
+	}
+}
@@ -2,38 +2,97 @@ package identity
 
 import (
 	"context"
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/sha256"
 	"math/big"
+	"time"
 
 	"github.com/iden3/go-iden3-crypto/utils"
 	"github.com/pkg/errors"
+	"gitlab.com/distributed_lab/logan/v3"
 
 	"github.com/rarimo/issuer/internal/config"
+	"github.com/rarimo/issuer/internal/data"
+	"github.com/rarimo/issuer/internal/data/pg"
 	statePkg "github.com/rarimo/issuer/internal/service/core/identity/state"
 	statepublisher "github.com/rarimo/issuer/internal/service/core/identity/state_publisher"
+	"github.com/rarimo/issuer/internal/service/core/webhooks"
 )
 
-func New(ctx context.Context, cfg config.Config) (*Identity, error) {
-	state, err := statePkg.NewIdentityState(ctx, statePkg.Config{
-		DB:             cfg.DB(),
-		IdentityConfig: cfg.Identity(),
-	})
+// New hosts the identity, its genesis state is generated if it doesn't have one yet, and its states
+// are published by the publisher of its own. The webhooks outbox is shared by all the hosted identities,
+// the events of the publisher are attributed to the identity.
+func New(ctx context.Context, cfg config.Config, model *data.Identity, webhooksOutbox *webhooks.Outbox) (*Identity, error) {
+	identity, err := newIdentity(ctx, cfg, model)
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to initialize the identity state")
+		return nil, err
 	}
 
 	statePublisher, err := statepublisher.New(&statepublisher.Config{
-		Log:            cfg.Log(),
+		Log:            cfg.Log().WithField("did", identity.Identifier.String()),
 		EthConfig:      cfg.EthClient(),
 		StatePublisher: cfg.StatePublisher(),
-	}, state)
+		Webhooks:       webhooksOutbox.WithIssuer(identity.Identifier.String()),
+	}, identity.State)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to initialize the state publisher")
 	}
 
+	go statePublisher.Run(ctx)
+
+	return identity, nil
+}
+
+// Create creates the identity named the name with the generated keys and generates its genesis state,
+// so the DID of the returned identity is set. The identity is hosted by the registries of the instances.
+func Create(ctx context.Context, cfg config.Config, name string) (*data.Identity, error) {
+	model, err := newModel(cfg.Identity(), name)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create identity model")
+	}
+	model.CreatedAt = time.Now().UTC()
+
+	isInserted, err := pg.NewIdentitiesQ(cfg.DB()).Insert(model)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to insert identity")
+	}
+	if !isInserted {
+		return nil, ErrIdentityIsAlreadyExist
+	}
+
+	// the genesis state that failed to be generated here is generated once the identity is hosted
+	identity, err := newIdentity(ctx, cfg, model)
+	if err != nil {
+		return nil, err
+	}
+
+	did := identity.Identifier.String()
+	model.DID = &did
+
+	return model, nil
+}
+
+func newIdentity(ctx context.Context, cfg config.Config, model *data.Identity) (*Identity, error) {
+	identityKeys, err := loadKeys(cfg, model)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to load the identity keys")
+	}
+
+	state, err := statePkg.NewIdentityState(statePkg.Config{
+		DB:             cfg.DB(),
+		IdentityConfig: cfg.Identity(),
+		IdentityID:     model.ID,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to initialize the identity state")
+	}
+
 	identity := &Identity{
-		log:                  cfg.Log().WithField("service", "Identity"),
+		log:                  cfg.Log().WithFields(logan.F{"service": "Identity", "identity": model.Name}),
 		State:                state,
-		babyJubJubPrivateKey: cfg.Identity().BabyJubJubPrivateKey,
+		babyJubJubPrivateKey: identityKeys.babyJubJubPrivateKey,
+		signingKey:           identityKeys.signingKey,
 		circuitsPath:         cfg.Identity().CircuitsPath,
 	}
 
@@ -48,40 +107,50 @@ func New(ctx context.Context, cfg config.Config) (*Identity, error) {
 		AuthClaim:            identity.AuthClaim.CoreClaim.Claim,
 	})
 
-	go statePublisher.Run(ctx)
-
 	return identity, nil
 }
 
+// Init loads the identity or generates its genesis state if it doesn't exist. The identity is locked,
+// so the instances initializing it concurrently don't both generate the genesis state.
 func (iden *Identity) Init(ctx context.Context) error {
-	genesisStateRaw, err := iden.State.DB.CommittedStatesQ().GetGenesis()
-	if err != nil {
-		return errors.Wrap(err, "failed to get genesis state")
-	}
-
-	authClaim, err := iden.State.DB.ClaimsQ().GetAuthClaim()
-	if err != nil {
-		return errors.Wrap(err, "failed to get auth claim")
-	}
+	db := iden.State.DB.New()
+	err := db.Transaction(func() error {
+		if err := db.IdentitiesQ().Lock(iden.State.IdentityID); err != nil {
+			return errors.Wrap(err, "failed to lock identity")
+		}
 
-	if (genesisStateRaw == nil && authClaim != nil) || (genesisStateRaw != nil && authClaim == nil) {
-		return errors.New("only one of the genesis state or auth claim is exist")
-	}
+		genesisStateRaw, err := db.CommittedStatesQ().GetGenesis()
+		if err != nil {
+			return errors.Wrap(err, "failed to get genesis state")
+		}
 
-	if genesisStateRaw == nil || authClaim == nil {
-		iden.log.Info("Identity not found")
-		err = iden.generateNewIdentity()
+		authClaim, err := db.ClaimsQ().GetAuthClaim()
 		if err != nil {
-			return errors.Wrap(err, "failed to generate new Identity")
+			return errors.Wrap(err, "failed to get auth claim")
 		}
 
-		return nil
-	}
+		if (genesisStateRaw == nil && authClaim != nil) || (genesisStateRaw != nil && authClaim == nil) {
+			return errors.New("only one of the genesis state or auth claim is exist")
+		}
+
+		if genesisStateRaw == nil || authClaim == nil {
+			iden.log.Info("Identity not found")
+			err = iden.generateNewIdentity(ctx, db)
+			if err != nil {
+				return errors.Wrap(err, "failed to generate new Identity")
+			}
+		} else {
+			iden.log.Info("Identity found")
+			err = iden.parseIdentity(ctx, authClaim, genesisStateRaw)
+			if err != nil {
+				return errors.Wrap(err, "failed to parse Identity")
+			}
+		}
 
-	iden.log.Info("Identity found")
-	err = iden.parseIdentity(ctx, authClaim, genesisStateRaw)
+		return iden.saveDID(db)
+	})
 	if err != nil {
-		return errors.Wrap(err, "failed to parse Identity")
+		return errors.Wrap(err, "failed to execute db transaction")
 	}
 
 	iden.State.SetIdentityInfo(&statePkg.IdentityInfo{
@@ -101,3 +170,17 @@ func (iden *Identity) Sign(singMessage *big.Int) ([]byte, error) {
 	sig := iden.babyJubJubPrivateKey.SignPoseidon(singMessage).Compress()
 	return sig[:], nil
 }
+
+// SigningKey returns the secp256k1 key the iden3comm messages of the identity are signed with.
+func (iden *Identity) SigningKey() *ecdsa.PrivateKey {
+	return iden.signingKey
+}
+
+// SecretKey derives the secret key of the purpose from the BabyJubJub private key, so the secrets
+// the identity authenticates its own data with are neither stored nor shared with the other identities.
+func (iden *Identity) SecretKey(purpose string) []byte {
+	mac := hmac.New(sha256.New, iden.babyJubJubPrivateKey[:])
+	mac.Write([]byte(purpose))
+
+	return mac.Sum(nil)
+}
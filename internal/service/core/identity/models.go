@@ -1,6 +1,8 @@
 package identity
 
 import (
+	"crypto/ecdsa"
+
 	core "github.com/iden3/go-iden3-core"
 	"github.com/iden3/go-iden3-crypto/babyjub"
 	"gitlab.com/distributed_lab/logan/v3"
@@ -12,10 +14,15 @@ import (
 
 var (
 	ErrClaimWasNotPublishedYet = errors.New("claim was not published yet")
+	ErrIdentityIsAlreadyExist  = errors.New("identity with the name is already exist")
+	// ErrKeysEncryptionKeyIsNotSet is returned for the identities other than the default one, their keys
+	// are sealed with the keys encryption key
+	ErrKeysEncryptionKeyIsNotSet = errors.New("keys encryption key is not set")
 )
 
 type Identity struct {
 	babyJubJubPrivateKey *babyjub.PrivateKey
+	signingKey           *ecdsa.PrivateKey
 	Identifier           *core.DID
 	AuthClaim            *data.Claim
 	circuitsPath         string
@@ -23,7 +23,7 @@ func (iden *Identity) GenerateIncMTProof(
 		return nil, errors.New("failed to generate proof, claim is nil")
 	}
 
-	lastCommittedState, _, err := iden.GetLatestState()
+	lastCommittedState, _, err := iden.GetLatestState(ctx)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to get latest committed state")
 	}
@@ -76,7 +76,7 @@ func (iden *Identity) GenerateIncSigProof(
 }
 
 func (iden *Identity) CompactIssuerData(ctx context.Context, checkRevLink string) (*verifiable.IssuerData, error) {
-	lastCommittedState, lastCommittedStateRaw, err := iden.GetLatestState()
+	lastCommittedState, lastCommittedStateRaw, err := iden.GetLatestState(ctx)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to get latest committed state")
 	}
@@ -131,7 +131,7 @@ func (iden *Identity) CompactIssuerData(ctx context.Context, checkRevLink string
 	return &issuerData, nil
 }
 
-func (iden *Identity) GetLatestState() (*state.CommittedState, *data.CommittedState, error) {
+func (iden *Identity) GetLatestState(ctx context.Context) (*state.CommittedState, *data.CommittedState, error) {
 	lastCommittedStateRaw, err := iden.State.DB.CommittedStatesQ().WhereStatus(data.StatusCompleted).GetLatest()
 	if err != nil {
 		return nil, nil, errors.Wrap(err, "failed to get last committed state from db")
@@ -146,10 +146,15 @@ func (iden *Identity) GetLatestState() (*state.CommittedState, *data.CommittedSt
 		return lastCommittedState, lastCommittedStateRaw, nil
 	}
 
+	trees, err := iden.State.LoadTrees(ctx, iden.State.DB)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to load identity trees")
+	}
+
 	return &state.CommittedState{
-		ClaimsTreeRoot:      iden.State.ClaimsTree.Root(),
-		RevocationsTreeRoot: iden.State.RevocationsTree.Root(),
-		RootsTreeRoot:       iden.State.RootsTree.Root(),
+		ClaimsTreeRoot:      trees.Claims.Root(),
+		RevocationsTreeRoot: trees.Revocations.Root(),
+		RootsTreeRoot:       trees.Roots.Root(),
 	}, nil, nil
 }
 
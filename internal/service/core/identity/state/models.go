@@ -1,7 +1,6 @@
 package state
 
 import (
-	"sync"
 	"time"
 
 	core "github.com/iden3/go-iden3-core"
@@ -28,20 +27,27 @@ var (
 type IdentityState struct {
 	*IdentityInfo
 
-	DB              data.MasterQ
-	ClaimsTree      *merkletree.MerkleTree
-	RevocationsTree *merkletree.MerkleTree
-	RootsTree       *merkletree.MerkleTree
+	IdentityID uint64
+	DB         data.MasterQ
 
 	circuits     map[string][]byte
 	circuitsPath string
+	treeDepth    int
+}
 
-	*sync.Mutex
+// Trees are the merkle trees of the identity loaded from the db, the changes of the
+// trees loaded from the transaction db are committed or rolled back along with it.
+type Trees struct {
+	Claims      *merkletree.MerkleTree
+	Revocations *merkletree.MerkleTree
+	Roots       *merkletree.MerkleTree
 }
 
 type Config struct {
 	DB             *pgdb.DB
 	IdentityConfig *config.IdentityConfig
+	// IdentityID scopes the trees and the committed states of the identity in the db
+	IdentityID uint64
 }
 
 type CommittedState struct {
@@ -20,44 +20,80 @@ import (
 	"github.com/rarimo/issuer/internal/service/core/zkp"
 )
 
+// GenerateStateCommitment stores the new state in the processing status along with adding the
+// latest committed claims tree root to the roots tree. The trees are locked only while the roots of
+// the new state are taken, the transition proof is generated after the commit from those roots, so
+// the issuance isn't blocked by the prover. The new state is returned along with the error if the
+// proof generation fails, so the caller can mark it as failed.
 func (is *IdentityState) GenerateStateCommitment(
 	ctx context.Context,
 ) (*StateTransitionInfo, *dataPkg.CommittedState, error) {
-	is.Lock()
-	defer is.Unlock()
+	var (
+		oldState       *CommittedState
+		newState       *CommittedState
+		newStateCommit *dataPkg.CommittedState
+	)
 
-	processingStates, err := is.DB.CommittedStatesQ().WhereStatus(dataPkg.StatusProcessing).Select()
+	db := is.DB.New()
+	err := db.Transaction(func() error {
+		trees, err := is.LockTrees(ctx, db)
+		if err != nil {
+			return errors.Wrap(err, "failed to lock identity trees")
+		}
+
+		processingStates, err := db.CommittedStatesQ().WhereStatus(dataPkg.StatusProcessing).Select()
+		if err != nil {
+			return errors.Wrap(err, "failed to select committed states")
+		}
+		if len(processingStates) > 0 {
+			return nil
+		}
+
+		oldState, err = is.prepareNewState(ctx, db, trees)
+		if err != nil {
+			return errors.Wrap(err, "failed to prepare new state")
+		}
+
+		newState = &CommittedState{
+			Status:              dataPkg.StatusProcessing,
+			CreatedAt:           time.Now().UTC(),
+			IsGenesis:           false,
+			RootsTreeRoot:       trees.Roots.Root(),
+			ClaimsTreeRoot:      trees.Claims.Root(),
+			RevocationsTreeRoot: trees.Revocations.Root(),
+		}
+		newStateCommit = newState.ToRaw()
+
+		err = db.CommittedStatesQ().Insert(newStateCommit)
+		if err != nil {
+			return errors.Wrap(err, "failed to insert committed state into db")
+		}
+
+		return nil
+	})
 	if err != nil {
-		return nil, nil, errors.Wrap(err, "failed to select committed states")
+		return nil, nil, err
 	}
-	if len(processingStates) > 0 {
+	if newStateCommit == nil {
 		return nil, nil, nil
 	}
 
-	transitionInfo, err := is.PrepareTransitionInfo(ctx)
-	if err != nil {
-		return nil, nil, errors.Wrap(err, "failed to prepare state transition info")
-	}
-
-	newStateCommit := (&CommittedState{
-		Status:              dataPkg.StatusProcessing,
-		CreatedAt:           time.Now(),
-		IsGenesis:           false,
-		RootsTreeRoot:       is.RootsTree.Root(),
-		ClaimsTreeRoot:      is.ClaimsTree.Root(),
-		RevocationsTreeRoot: is.RevocationsTree.Root(),
-	}).ToRaw()
-
-	err = is.DB.CommittedStatesQ().Insert(newStateCommit)
+	transitionInfo, err := is.prepareTransitionInfo(ctx, oldState, newState)
 	if err != nil {
-		return nil, nil, errors.Wrap(err, "failed to insert committed state into db")
+		return nil, newStateCommit, errors.Wrap(err, "failed to prepare state transition info")
 	}
 
 	return transitionInfo, newStateCommit, nil
 }
 
-func (is *IdentityState) PrepareTransitionInfo(ctx context.Context) (*StateTransitionInfo, error) {
-	oldStateRaw, err := is.DB.CommittedStatesQ().New().WhereStatus(dataPkg.StatusCompleted).GetLatest()
+// prepareNewState adds the latest committed claims tree root to the roots tree and checks that the
+// trees were changed since the latest committed state, which is returned.
+func (is *IdentityState) prepareNewState(
+	ctx context.Context,
+	db dataPkg.MasterQ,
+	trees *Trees,
+) (*CommittedState, error) {
+	oldStateRaw, err := db.CommittedStatesQ().WhereStatus(dataPkg.StatusCompleted).GetLatest()
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to get the last committed state from db")
 	}
@@ -75,23 +111,47 @@ func (is *IdentityState) PrepareTransitionInfo(ctx context.Context) (*StateTrans
 		return nil, errors.Wrap(err, "failed to get latest committed state hash")
 	}
 
-	err = is.RootsTree.Add(ctx, oldState.ClaimsTreeRoot.BigInt(), merkletree.HashZero.BigInt())
+	err = trees.Roots.Add(ctx, oldState.ClaimsTreeRoot.BigInt(), merkletree.HashZero.BigInt())
 	if err != nil && !errors.Is(err, merkletree.ErrEntryIndexAlreadyExists) {
 		return nil, errors.Wrap(err, "failed to add new claim tree root to the roots tree")
 	}
 
-	newStateHash, err := is.GetCurrentStateHash()
+	newStateHash, err := trees.StateHash()
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to get the new state hash")
 	}
 
 	if oldStateHash.Equals(newStateHash) ||
-		(oldState.ClaimsTreeRoot.Equals(is.ClaimsTree.Root()) &&
-			oldState.RevocationsTreeRoot.Equals(is.RevocationsTree.Root())) {
+		(oldState.ClaimsTreeRoot.Equals(trees.Claims.Root()) &&
+			oldState.RevocationsTreeRoot.Equals(trees.Revocations.Root())) {
 		return nil, ErrStateWasntChanged
 	}
 
-	transitionInputs, err := is.prepareTransitionInputs(ctx, oldState, newStateHash)
+	return oldState, nil
+}
+
+// prepareTransitionInfo generates the proof of the transition between the states, the trees nodes
+// are never deleted, so the proofs are generated against the roots of the states without the lock.
+func (is *IdentityState) prepareTransitionInfo(
+	ctx context.Context,
+	oldState, newState *CommittedState,
+) (*StateTransitionInfo, error) {
+	oldStateHash, err := oldState.StateHash()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get latest committed state hash")
+	}
+
+	newStateHash, err := newState.StateHash()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get the new state hash")
+	}
+
+	trees, err := is.LoadTrees(ctx, is.DB)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to load identity trees")
+	}
+
+	transitionInputs, err := is.prepareTransitionInputs(ctx, trees, oldState, newState, newStateHash)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to prepare state transition inputs")
 	}
@@ -112,7 +172,8 @@ func (is *IdentityState) PrepareTransitionInfo(ctx context.Context) (*StateTrans
 
 func (is *IdentityState) prepareTransitionInputs(
 	ctx context.Context,
-	oldState *CommittedState,
+	trees *Trees,
+	oldState, newState *CommittedState,
 	newStateHash *merkletree.Hash,
 ) ([]byte, error) {
 	oldStateCircuits, err := circuitsState(oldState)
@@ -122,22 +183,22 @@ func (is *IdentityState) prepareTransitionInputs(
 
 	newStateCircuits := circuits.TreeState{
 		State:          newStateHash,
-		ClaimsRoot:     is.ClaimsTree.Root(),
-		RevocationRoot: is.RevocationsTree.Root(),
-		RootOfRoots:    is.RootsTree.Root(),
+		ClaimsRoot:     newState.ClaimsTreeRoot,
+		RevocationRoot: newState.RevocationsTreeRoot,
+		RootOfRoots:    newState.RootsTreeRoot,
 	}
 
-	authInclusionProof, err := is.GetInclusionProof(ctx, is.AuthClaim, oldState.ClaimsTreeRoot)
+	authInclusionProof, err := getInclusionProof(ctx, trees.Claims, is.AuthClaim, oldState.ClaimsTreeRoot)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to get the auth claim inclusion proof")
 	}
 
-	authNewInclusionProof, err := is.GetInclusionProof(ctx, is.AuthClaim, newStateCircuits.ClaimsRoot)
+	authNewInclusionProof, err := getInclusionProof(ctx, trees.Claims, is.AuthClaim, newStateCircuits.ClaimsRoot)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to get the auth claim new inclusion proof")
 	}
 
-	authNonRevocationProof, _, err := is.RevocationsTree.GenerateProof(
+	authNonRevocationProof, _, err := trees.Revocations.GenerateProof(
 		ctx,
 		big.NewInt(int64(is.AuthClaim.GetRevocationNonce())),
 		oldState.RevocationsTreeRoot,
@@ -169,17 +230,32 @@ func (is *IdentityState) prepareTransitionInputs(
 	return transitionInputs, nil
 }
 
+// GetInclusionProof generates the proof of the claim inclusion to the claims tree with the given root.
 func (is *IdentityState) GetInclusionProof(
 	ctx context.Context,
 	claim *core.Claim,
 	claimTreeRoot *merkletree.Hash,
+) (*merkletree.Proof, error) {
+	trees, err := is.LoadTrees(ctx, is.DB)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to load identity trees")
+	}
+
+	return getInclusionProof(ctx, trees.Claims, claim, claimTreeRoot)
+}
+
+func getInclusionProof(
+	ctx context.Context,
+	claimsTree *merkletree.MerkleTree,
+	claim *core.Claim,
+	claimTreeRoot *merkletree.Hash,
 ) (*merkletree.Proof, error) {
 	hi, err := claim.HIndex()
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to get hash of index and a value of the claim")
 	}
 
-	proof, _, err := is.ClaimsTree.GenerateProof(ctx, hi, claimTreeRoot)
+	proof, _, err := claimsTree.GenerateProof(ctx, hi, claimTreeRoot)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to generate a proof")
 	}
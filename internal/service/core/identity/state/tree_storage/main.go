@@ -4,11 +4,9 @@ import (
 	"context"
 
 	"github.com/iden3/go-merkletree-sql/v2"
-	"gitlab.com/distributed_lab/kit/pgdb"
 	errPkg "gitlab.com/distributed_lab/logan/v3/errors"
 
 	"github.com/rarimo/issuer/internal/data"
-	"github.com/rarimo/issuer/internal/data/pg"
 )
 
 // treeStorage implement merkletree.Storage
@@ -17,9 +15,9 @@ type treeStorage struct {
 	db          data.TreeStorageQ
 }
 
-func NewTreeStorage(db *pgdb.DB, treeName string) merkletree.Storage {
+func NewTreeStorage(db data.TreeStorageQ) merkletree.Storage {
 	return &treeStorage{
-		db: pg.NewTreeStorageQ(db, treeName),
+		db: db,
 	}
 }
 
@@ -0,0 +1,63 @@
+package state
+
+import (
+	"context"
+
+	"github.com/iden3/go-merkletree-sql/v2"
+	"github.com/pkg/errors"
+
+	"github.com/rarimo/issuer/internal/data"
+	treestorage "github.com/rarimo/issuer/internal/service/core/identity/state/tree_storage"
+)
+
+// LoadTrees loads the trees with the roots the db has at the moment, the trees
+// have to be loaded with LockTrees to be changed.
+func (is *IdentityState) LoadTrees(ctx context.Context, db data.MasterQ) (*Trees, error) {
+	claimsTree, err := is.loadTree(ctx, db, treestorage.ClaimsTreeName)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to load claims merkle tree")
+	}
+
+	revocationsTree, err := is.loadTree(ctx, db, treestorage.RevocationTreeName)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to load revocations merkle tree")
+	}
+
+	rootsTree, err := is.loadTree(ctx, db, treestorage.RootsTreeName)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to load roots merkle tree")
+	}
+
+	return &Trees{
+		Claims:      claimsTree,
+		Revocations: revocationsTree,
+		Roots:       rootsTree,
+	}, nil
+}
+
+// LockTrees locks the identity till the end of the db transaction and loads the
+// trees from it, so the concurrent transactions change the trees one by one.
+func (is *IdentityState) LockTrees(ctx context.Context, db data.MasterQ) (*Trees, error) {
+	if err := db.IdentitiesQ().Lock(is.IdentityID); err != nil {
+		return nil, errors.Wrap(err, "failed to lock identity")
+	}
+
+	return is.LoadTrees(ctx, db)
+}
+
+func (is *IdentityState) loadTree(ctx context.Context, db data.MasterQ, treeName string) (*merkletree.MerkleTree, error) {
+	return merkletree.NewMerkleTree(ctx, treestorage.NewTreeStorage(db.TreeStorageQ(treeName)), is.treeDepth)
+}
+
+func (t *Trees) StateHash() (*merkletree.Hash, error) {
+	hash, err := merkletree.HashElems(
+		t.Claims.Root().BigInt(),
+		t.Revocations.Root().BigInt(),
+		t.Roots.Root().BigInt(),
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get hash merkle trees roots")
+	}
+
+	return hash, nil
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"math/big"
+	"sync"
 
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/accounts/abi"
@@ -32,10 +33,17 @@ func (p *publisher) waitMined(ctx context.Context, tx *types.Transaction) (*type
 	return receipt, nil
 }
 
+// accountLock serializes the transactions of the publishers of all the hosted identities, they are sent
+// from the same account, so the concurrent ones would be signed with the same pending nonce.
+var accountLock sync.Mutex
+
 func (p *publisher) retryChainCall(
 	ctx context.Context,
 	contractCall func(signer *bind.TransactOpts) error,
 ) error {
+	accountLock.Lock()
+	defer accountLock.Unlock()
+
 	signer, err := p.newSigner(ctx)
 	if err != nil {
 		return errors.Wrap(err, "failed to get new signer")
@@ -12,8 +12,10 @@ import (
 	"github.com/pkg/errors"
 
 	"github.com/rarimo/issuer/internal/data"
+	"github.com/rarimo/issuer/internal/service/core/audit"
 	"github.com/rarimo/issuer/internal/service/core/identity/state"
 	"github.com/rarimo/issuer/internal/service/core/identity/state_publisher/contracts"
+	"github.com/rarimo/issuer/internal/service/core/webhooks"
 	"github.com/rarimo/issuer/internal/service/core/zkp"
 )
 
@@ -36,6 +38,7 @@ func New(cfg *Config, state *state.IdentityState) (Publisher, error) {
 		log:                cfg.Log,
 		stateStoreContract: stateStorageContract,
 		state:              state,
+		webhooks:           cfg.Webhooks,
 		publishPeriod:      cfg.StatePublisher.PublishPeriod,
 		retryPeriod:        cfg.StatePublisher.RetryPeriod,
 
@@ -186,7 +189,7 @@ func (p *publisher) processPreviousSessionCommit() error {
 		}
 
 		unprocessedState.Status = data.StatusCompleted
-		err = p.state.DB.CommittedStatesQ().Update(&unprocessedState)
+		err = p.updateCommittedState(&unprocessedState, webhooks.EventStateCommitted, audit.EventStateCommitted)
 		if err != nil {
 			return errors.Wrap(err, "failed to update unprocessed state status to complete")
 		}
@@ -16,6 +16,7 @@ import (
 	"github.com/rarimo/issuer/internal/data"
 	"github.com/rarimo/issuer/internal/service/core/identity/state"
 	"github.com/rarimo/issuer/internal/service/core/identity/state_publisher/contracts"
+	"github.com/rarimo/issuer/internal/service/core/webhooks"
 )
 
 const (
@@ -42,6 +43,7 @@ type publisher struct {
 	address            common.Address
 	chainID            *big.Int
 	state              *state.IdentityState
+	webhooks           *webhooks.Outbox
 
 	publishPeriod time.Duration
 
@@ -53,6 +55,7 @@ type Config struct {
 	Log            *logan.Entry
 	EthConfig      *config.EthClientConfig
 	StatePublisher *config.StatePublisherConfig
+	Webhooks       *webhooks.Outbox
 }
 
 type contractReadableZKP struct {
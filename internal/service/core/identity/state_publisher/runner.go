@@ -9,7 +9,9 @@ import (
 	"gitlab.com/distributed_lab/running"
 
 	"github.com/rarimo/issuer/internal/data"
+	"github.com/rarimo/issuer/internal/service/core/audit"
 	"github.com/rarimo/issuer/internal/service/core/identity/state"
+	"github.com/rarimo/issuer/internal/service/core/webhooks"
 )
 
 func (p *publisher) Run(ctx context.Context) {
@@ -38,8 +40,19 @@ func (p *publisher) runner(ctx context.Context) error {
 			return nil
 		}
 
+		// the new state is stored, but its transition proof failed to be generated
+		if stateCommit != nil {
+			if err := p.setStatusFailed("", err.Error(), stateCommit); err != nil {
+				return errors.Wrap(err, "failed to set status failed in db")
+			}
+		}
+
 		return errors.Wrap(err, "failed to generate state commitment")
 	}
+	if stateCommit == nil {
+		// the previous state is still being processed
+		return nil
+	}
 
 	tx, err := p.sendTransaction(ctx, stateTransitionInfo, stateCommit)
 	if err != nil {
@@ -55,16 +68,23 @@ func (p *publisher) runner(ctx context.Context) error {
 }
 
 func (p *publisher) setStatusFailed(txHash, reason string, committedState *data.CommittedState) error {
+	// the failed contract calls are retried, so the state failure is reported only once
+	isFailed := committedState.Status == data.StatusFailed
+
 	committedState.TxID = txHash
 	committedState.Status = data.StatusFailed
 	committedState.Message = reason
 
-	err := p.state.DB.CommittedStatesQ().Update(committedState)
-	if err != nil {
-		return errors.Wrap(err, "failed to update committed state in db")
+	if isFailed {
+		err := p.state.DB.CommittedStatesQ().Update(committedState)
+		if err != nil {
+			return errors.Wrap(err, "failed to update committed state in db")
+		}
+
+		return nil
 	}
 
-	return nil
+	return p.updateCommittedState(committedState, webhooks.EventStateFailed, audit.EventStateFailed)
 }
 
 func (p *publisher) setStatusCompleted(
@@ -82,9 +102,44 @@ func (p *publisher) setStatusCompleted(
 	committedState.BlockTimestamp = block.Time()
 	committedState.BlockNumber = block.NumberU64()
 
-	err = p.state.DB.CommittedStatesQ().Update(committedState)
+	return p.updateCommittedState(committedState, webhooks.EventStateCommitted, audit.EventStateCommitted)
+}
+
+// updateCommittedState updates the committed state along with storing the webhook and the audit events of its
+// new status.
+func (p *publisher) updateCommittedState(
+	committedState *data.CommittedState,
+	eventType webhooks.EventType,
+	auditEventType audit.EventType,
+) error {
+	eventData, err := webhooks.NewStateData(committedState)
+	if err != nil {
+		return errors.Wrap(err, "failed to create state event data")
+	}
+
+	db := p.state.DB.New()
+	err = db.Transaction(func() error {
+		err := db.CommittedStatesQ().Update(committedState)
+		if err != nil {
+			return errors.Wrap(err, "failed to update committed state in db")
+		}
+
+		err = p.webhooks.Enqueue(db.WebhookEventsQ(), eventType, eventData)
+		if err != nil {
+			return errors.Wrap(err, "failed to enqueue state event")
+		}
+
+		err = db.AuditEventsQ().Insert(
+			audit.NewSystemEvent(statePublisherRunnerName, auditEventType, audit.NewStateSubject(committedState)),
+		)
+		if err != nil {
+			return errors.Wrap(err, "failed to insert state audit event")
+		}
+
+		return nil
+	})
 	if err != nil {
-		return errors.Wrap(err, "failed to update committed state in db")
+		return errors.Wrap(err, "failed to execute db transaction")
 	}
 
 	return nil
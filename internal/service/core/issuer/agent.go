@@ -0,0 +1,198 @@
+package issuer
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/google/uuid"
+	"github.com/iden3/iden3comm/protocol"
+	"github.com/pkg/errors"
+
+	"github.com/rarimo/issuer/internal/data"
+	"github.com/rarimo/issuer/internal/service/api/requests"
+	"github.com/rarimo/issuer/internal/service/core/audit"
+)
+
+// HandleAgentMessage dispatches the iden3comm message of the agent request by its type and returns
+// the response message with the negotiated media type. The problem reports don't have the response,
+// so the nil message is returned for them.
+func (isr *issuer) HandleAgentMessage(ctx context.Context, request *requests.AgentRequest) (interface{}, error) {
+	switch {
+	case request.FetchMessage != nil:
+		message, err := isr.agentFetchCredential(ctx, request)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to fetch credential")
+		}
+		message.Typ = request.Response.MediaType
+		return message, nil
+	case request.RevocationStatusMessage != nil:
+		message, err := isr.agentRevocationStatus(ctx, request.RevocationStatusMessage)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get revocation status")
+		}
+		message.Typ = request.Response.MediaType
+		return message, nil
+	case request.RefreshMessage != nil:
+		message, err := isr.agentRefreshCredential(ctx, request)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to refresh credential")
+		}
+		message.Typ = request.Response.MediaType
+		return message, nil
+	default:
+		return nil, nil
+	}
+}
+
+// agentFetchCredential issues the credential as FetchCredential does, if the message belongs to the
+// offer thread the offer is marked as received, the repeated fetch isn't rejected unlike the callback.
+func (isr *issuer) agentFetchCredential(
+	ctx context.Context,
+	request *requests.AgentRequest,
+) (*protocol.CredentialIssuanceMessage, error) {
+	message, err := isr.FetchCredential(ctx, &requests.FetchCredentialRequest{
+		CredentialID: uuid.MustParse(request.FetchMessage.Body.ID),
+		Token:        request.Token,
+		FetchMessage: request.FetchMessage,
+		Response:     request.Response,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if request.FetchMessage.ThreadID == "" {
+		return message, nil
+	}
+
+	claimOffer, err := isr.claimsOffersQ.Get(request.FetchMessage.ThreadID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get claim offer")
+	}
+	if claimOffer == nil || claimOffer.IsReceived || claimOffer.CanceledAt != nil {
+		return message, nil
+	}
+
+	claim, err := isr.State.DB.ClaimsQ().Get(request.FetchMessage.Body.ID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get claim from db")
+	}
+	if claim == nil {
+		return message, nil
+	}
+
+	// the credential that isn't offered in the thread or is already received is left as is,
+	// FetchCredential checks the sender to be the claim recipient, so the delivery is attributed to it
+	ctx = audit.CtxActor(ctx, audit.NewDIDActor(request.FetchMessage.From))
+	// the token is already spent by the FetchCredential, the offer canceled or expired meanwhile is left as is
+	_, err = isr.markOfferCredentialReceived(ctx, claimOffer, claim, nil)
+	if err != nil && !errors.Is(err, ErrClaimOfferIsCanceled) {
+		return nil, errors.Wrap(err, "failed to mark claim offer credential as received")
+	}
+
+	return message, nil
+}
+
+func (isr *issuer) agentRevocationStatus(
+	ctx context.Context,
+	request *protocol.RevocationStatusRequestMessage,
+) (*protocol.RevocationStatusResponseMessage, error) {
+	if request.To != "" && request.To != isr.Identifier.String() {
+		return nil, ErrMessageRecipientIsNotIssuer
+	}
+
+	status, err := isr.GetRevocationStatus(ctx, new(big.Int).SetUint64(request.Body.RevocationNonce))
+	if err != nil {
+		return nil, err
+	}
+
+	threadID := request.ThreadID
+	if threadID == "" {
+		threadID = request.ID
+	}
+
+	return &protocol.RevocationStatusResponseMessage{
+		ID:       uuid.NewString(),
+		Type:     protocol.RevocationStatusResponseMessageType,
+		ThreadID: threadID,
+		Body: protocol.RevocationStatusResponseMessageBody{
+			RevocationStatus: status.RevocationStatus,
+		},
+		From: isr.Identifier.String(),
+		To:   request.From,
+	}, nil
+}
+
+// agentRefreshCredential responds with the actual credential, that is the requested one if it is
+// not revoked, or the latest replacement of it from the reissue chain.
+func (isr *issuer) agentRefreshCredential(
+	ctx context.Context,
+	request *requests.AgentRequest,
+) (*protocol.CredentialIssuanceMessage, error) {
+	refreshMessage := request.RefreshMessage
+
+	claim, err := isr.State.DB.ClaimsQ().Get(refreshMessage.Body.ID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get claim from db")
+	}
+	if claim == nil {
+		return nil, ErrClaimIsNotExist
+	}
+
+	err = isr.checkMessageSender(ctx, claim, request.Token, refreshMessage.From, refreshMessage.To)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid refresh request")
+	}
+
+	actualClaim, err := isr.getActualClaim(claim)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get actual claim")
+	}
+	if actualClaim == nil {
+		return nil, ErrClaimIsAlreadyRevoked
+	}
+
+	if err := isr.generateProofs(ctx, actualClaim); err != nil {
+		return nil, errors.Wrap(err, "failed to generate mtp")
+	}
+
+	cred, err := ClaimModelToW3Credential(actualClaim)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create iden3 credential from claim model")
+	}
+
+	err = isr.markTokenUsed(isr.State.DB, request.Token, refreshMessage.From)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to spend refresh request token")
+	}
+
+	threadID := refreshMessage.ThreadID
+	if threadID == "" {
+		threadID = refreshMessage.ID
+	}
+
+	return &protocol.CredentialIssuanceMessage{
+		ID:       uuid.NewString(),
+		Type:     protocol.CredentialIssuanceResponseMessageType,
+		ThreadID: threadID,
+		Body:     protocol.IssuanceMessageBody{Credential: *cred},
+		From:     refreshMessage.To,
+		To:       refreshMessage.From,
+	}, nil
+}
+
+// getActualClaim follows the reissue chain from the claim to the first not revoked one,
+// nil is returned if the chain ends with the revoked claim that wasn't replaced.
+func (isr *issuer) getActualClaim(claim *data.Claim) (*data.Claim, error) {
+	claimsQ := isr.State.DB.ClaimsQ()
+
+	for claim != nil && claim.Revoked {
+		replacement, err := claimsQ.GetByPreviousClaimID(claim.ID)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get replacement claim from db")
+		}
+
+		claim = replacement
+	}
+
+	return claim, nil
+}
@@ -0,0 +1,70 @@
+package issuer
+
+import (
+	"github.com/pkg/errors"
+
+	"github.com/rarimo/issuer/internal/data"
+	"github.com/rarimo/issuer/internal/service/api/requests"
+)
+
+const auditExportBatchSize = 500
+
+func (isr *issuer) ListAuditEvents(req *requests.ListAuditEventsRequest) ([]data.AuditEvent, error) {
+	events, err := filterAuditEvents(isr.State.DB.AuditEventsQ(), req).Page(req.Page).Select()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to select audit events from db")
+	}
+
+	return events, nil
+}
+
+// ExportAuditEvents passes all the audit events matching the request filters to the write function
+// by batches in the order of insertion, so the export doesn't hold all the events in memory.
+func (isr *issuer) ExportAuditEvents(
+	req *requests.ListAuditEventsRequest,
+	write func(events []data.AuditEvent) error,
+) error {
+	var lastID uint64
+	for {
+		events, err := filterAuditEvents(isr.State.DB.AuditEventsQ(), req).
+			After(lastID, auditExportBatchSize).
+			Select()
+		if err != nil {
+			return errors.Wrap(err, "failed to select audit events from db")
+		}
+		if len(events) == 0 {
+			return nil
+		}
+
+		if err := write(events); err != nil {
+			return errors.Wrap(err, "failed to write audit events")
+		}
+
+		if len(events) < auditExportBatchSize {
+			return nil
+		}
+		lastID = events[len(events)-1].ID
+	}
+}
+
+func filterAuditEvents(auditEventsQ data.AuditEventsQ, req *requests.ListAuditEventsRequest) data.AuditEventsQ {
+	auditEventsQ = auditEventsQ.FilterByCreatedAt(req.CreatedFrom, req.CreatedTo)
+
+	if len(req.EventTypes) > 0 {
+		auditEventsQ = auditEventsQ.FilterByEventType(req.EventTypes...)
+	}
+	if len(req.ActorIDs) > 0 {
+		auditEventsQ = auditEventsQ.FilterByActorID(req.ActorIDs...)
+	}
+	if len(req.RequestIDs) > 0 {
+		auditEventsQ = auditEventsQ.FilterByRequestID(req.RequestIDs...)
+	}
+	if len(req.ClaimIDs) > 0 {
+		auditEventsQ = auditEventsQ.FilterByClaimID(req.ClaimIDs...)
+	}
+	if len(req.CommittedStateIDs) > 0 {
+		auditEventsQ = auditEventsQ.FilterByCommittedStateID(req.CommittedStateIDs...)
+	}
+
+	return auditEventsQ
+}
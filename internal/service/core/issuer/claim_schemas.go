@@ -0,0 +1,47 @@
+package issuer
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	"github.com/rarimo/issuer/internal/data"
+	"github.com/rarimo/issuer/internal/service/core/claims/schemas"
+)
+
+func (isr *issuer) RegisterClaimSchema(ctx context.Context, claimSchema *data.ClaimSchema) error {
+	if err := isr.schemaBuilder.RegisterSchema(ctx, claimSchema); err != nil {
+		return errors.Wrap(err, "failed to register claim schema")
+	}
+
+	return nil
+}
+
+func (isr *issuer) GetClaimSchemas() ([]data.ClaimSchema, error) {
+	claimSchemas, err := isr.State.DB.ClaimSchemasQ().Select()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to select claim schemas from db")
+	}
+
+	return claimSchemas, nil
+}
+
+func (isr *issuer) GetClaimSchema(schemaType string) (*data.ClaimSchema, error) {
+	claimSchema, err := isr.State.DB.ClaimSchemasQ().Get(schemaType)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get claim schema from db")
+	}
+	if claimSchema == nil {
+		return nil, schemas.ErrSchemaIsNotExist
+	}
+
+	return claimSchema, nil
+}
+
+func (isr *issuer) DeprecateClaimSchema(schemaType string) error {
+	if err := isr.schemaBuilder.DeprecateSchema(schemaType); err != nil {
+		return errors.Wrap(err, "failed to deprecate claim schema")
+	}
+
+	return nil
+}
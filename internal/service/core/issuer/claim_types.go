@@ -0,0 +1,68 @@
+package issuer
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	"github.com/rarimo/issuer/internal/service/core/claims"
+	"github.com/rarimo/issuer/internal/service/core/claims/schemas"
+)
+
+// GetClaimTypes describes the schemas of the registry table, so the schemas
+// registered by the other instances are listed as well.
+func (isr *issuer) GetClaimTypes(ctx context.Context) ([]ClaimType, error) {
+	claimSchemas, err := isr.State.DB.ClaimSchemasQ().Select()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to select claim schemas from db")
+	}
+
+	result := make([]ClaimType, 0, len(claimSchemas))
+	for _, claimSchema := range claimSchemas {
+		claimType, ok := isr.describeClaimType(ctx, claims.ClaimSchemaType(claimSchema.SchemaType))
+		if !ok {
+			continue
+		}
+		result = append(result, *claimType)
+	}
+
+	return result, nil
+}
+
+func (isr *issuer) GetClaimType(ctx context.Context, schemaType claims.ClaimSchemaType) (*ClaimType, error) {
+	claimType, ok := isr.describeClaimType(ctx, schemaType)
+	if !ok {
+		return nil, schemas.ErrSchemaIsNotExist
+	}
+
+	return claimType, nil
+}
+
+func (isr *issuer) describeClaimType(ctx context.Context, schemaType claims.ClaimSchemaType) (*ClaimType, bool) {
+	claimData, ok := claims.GetClaimSchema(ctx, schemaType)
+	if !ok {
+		return nil, false
+	}
+
+	schema, ok := isr.schemaBuilder.GetSchema(ctx, schemaType)
+	if !ok {
+		return nil, false
+	}
+
+	claimType := ClaimType{
+		SchemaType:               schemaType,
+		DisplayName:              claimData.ClaimSchemaName,
+		SchemaURL:                isr.schemaBuilder.ResolveSchemaURL(claimData.ClaimSchemaURL),
+		JSONLdContext:            schema.JSONLdContext,
+		IsDeprecated:             claimData.IsDeprecated,
+		Schema:                   schema.Raw,
+		ExampleCredentialSubject: map[string]interface{}{},
+	}
+
+	if schema.CredentialSubject != nil {
+		claimType.RequestFields = schema.CredentialSubject.Fields()
+		claimType.ExampleCredentialSubject = schema.CredentialSubject.Example()
+	}
+
+	return &claimType, true
+}
@@ -0,0 +1,75 @@
+package issuer
+
+import (
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+
+	"github.com/rarimo/issuer/internal/data"
+)
+
+// ListClaimVersions returns the replaced versions of the claim followed by the current one.
+func (isr *issuer) ListClaimVersions(claimID uuid.UUID) ([]ClaimVersion, error) {
+	claim, err := isr.State.DB.ClaimsQ().Get(claimID.String())
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get claim from db")
+	}
+	if claim == nil {
+		return nil, ErrClaimIsNotExist
+	}
+
+	replaced, err := isr.State.DB.ClaimVersionsQ().SelectByClaimID(claim.ID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to select claim versions from db")
+	}
+
+	versions := make([]ClaimVersion, 0, len(replaced)+1)
+	for i := range replaced {
+		versions = append(versions, newReplacedClaimVersion(&replaced[i]))
+	}
+
+	return append(versions, newCurrentClaimVersion(claim)), nil
+}
+
+func (isr *issuer) GetClaimVersion(claimID uuid.UUID, version uint32) (*ClaimVersion, error) {
+	claim, err := isr.State.DB.ClaimsQ().Get(claimID.String())
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get claim from db")
+	}
+	if claim == nil {
+		return nil, ErrClaimIsNotExist
+	}
+
+	if claim.CoreClaim.GetVersion() == version {
+		current := newCurrentClaimVersion(claim)
+		return &current, nil
+	}
+
+	replaced, err := isr.State.DB.ClaimVersionsQ().Get(claim.ID, version)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get claim version from db")
+	}
+	if replaced == nil {
+		return nil, ErrClaimVersionIsNotExist
+	}
+
+	claimVersion := newReplacedClaimVersion(replaced)
+	return &claimVersion, nil
+}
+
+func newCurrentClaimVersion(claim *data.Claim) ClaimVersion {
+	return ClaimVersion{
+		ClaimID:    claim.ID,
+		Version:    claim.CoreClaim.GetVersion(),
+		Credential: claim.Credential,
+	}
+}
+
+func newReplacedClaimVersion(claimVersion *data.ClaimVersion) ClaimVersion {
+	replacedAt := claimVersion.ReplacedAt
+	return ClaimVersion{
+		ClaimID:    claimVersion.ClaimID,
+		Version:    claimVersion.Version,
+		Credential: claimVersion.Credential,
+		ReplacedAt: &replacedAt,
+	}
+}
@@ -15,12 +15,13 @@ import (
 
 	"github.com/rarimo/issuer/internal/data"
 	"github.com/rarimo/issuer/internal/service/core/claims"
+	"github.com/rarimo/issuer/internal/service/core/claims/schemas"
 )
 
 func (isr *issuer) compactClaim(
 	ctx context.Context,
 	userDID *core.DID,
-	expiration *time.Time,
+	opts schemas.CompactClaimOptions,
 	claimType claims.ClaimSchemaType,
 	credentialSubjectRaw []byte,
 ) (*data.Claim, error) {
@@ -29,14 +30,27 @@ func (isr *issuer) compactClaim(
 		return nil, errors.Wrap(err, "failed to generate random uint64")
 	}
 
+	return isr.buildClaim(ctx, uuid.NewString(), revNonce, userDID, opts, claimType, credentialSubjectRaw)
+}
+
+// buildClaim creates the credential and the core claim with the given id and revocation nonce,
+// the existing claim keeps them when it is updated to the new version.
+func (isr *issuer) buildClaim(
+	ctx context.Context,
+	claimID string,
+	revNonce uint64,
+	userDID *core.DID,
+	opts schemas.CompactClaimOptions,
+	claimType claims.ClaimSchemaType,
+	credentialSubjectRaw []byte,
+) (*data.Claim, error) {
 	credentialsStatus := verifiable.CredentialStatus{
 		ID:              fmt.Sprint(isr.baseURL, claims.CredentialStatusCheckURL, revNonce),
 		Type:            verifiable.SparseMerkleTreeProof,
 		RevocationNonce: revNonce,
 	}
 
-	claimID := uuid.NewString()
-	credential, err := isr.newW3CCredential(claimID, userDID, credentialSubjectRaw, expiration, claimType, credentialsStatus)
+	credential, err := isr.newW3CCredential(ctx, claimID, userDID, credentialSubjectRaw, opts.Expiration, claimType, credentialsStatus)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to create new w3c credential")
 	}
@@ -46,26 +60,56 @@ func (isr *issuer) compactClaim(
 		return nil, errors.Wrap(err, "failed to marshal credential")
 	}
 
-	coreClaim, err := isr.schemaBuilder.CreateCoreClaim(
+	coreClaim, merklized, err := isr.schemaBuilder.CreateCoreClaim(
 		ctx,
 		claimType,
 		credential,
 		revNonce,
+		opts,
 	)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to process schema")
 	}
 
-	return &data.Claim{
+	claim := &data.Claim{
 		ID:         claimID,
 		ClaimType:  claimType.ToRaw(),
 		CoreClaim:  data.NewCoreClaim(coreClaim),
 		Credential: credentialRaw,
 		UserID:     userDID.ID.String(),
-	}, nil
+		CreatedAt:  time.Now(),
+		RevNonce:   data.RevocationNonce(revNonce),
+	}
+	if merklized != nil {
+		claim.MerklizedDocument = merklized.Document
+	}
+	// the expiration is stored without the time zone, so it is compared in UTC
+	if opts.Expiration != nil {
+		expiration := opts.Expiration.UTC()
+		claim.Expiration = &expiration
+	}
+
+	return claim, nil
+}
+
+// claimRecipientDID returns the DID of the claim recipient, it is
+// needed to build the new version or the replacement of the claim.
+func claimRecipientDID(claim *data.Claim) (*core.DID, error) {
+	userID, err := core.IDFromString(claim.UserID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse claim recipient id")
+	}
+
+	userDID, err := core.ParseDIDFromID(userID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse claim recipient did")
+	}
+
+	return userDID, nil
 }
 
 func (isr *issuer) newW3CCredential(
+	ctx context.Context,
 	claimID string,
 	userDID *core.DID,
 	credentialSubjectRaw []byte,
@@ -73,6 +117,13 @@ func (isr *issuer) newW3CCredential(
 	claimType claims.ClaimSchemaType,
 	credentialStatus verifiable.CredentialStatus,
 ) (*verifiable.W3CCredential, error) {
+	schema, ok := isr.schemaBuilder.GetSchema(ctx, claimType)
+	if !ok {
+		return nil, schemas.ErrSchemaIsNotExist
+	}
+
+	claimData, _ := claims.GetClaimSchema(ctx, claimType)
+
 	issuanceDate := time.Now()
 	credential, err := claims.ParseCredentialFromSnakeCase(credentialSubjectRaw)
 	if err != nil {
@@ -87,39 +138,65 @@ func (isr *issuer) newW3CCredential(
 	credential.Issuer = isr.Identifier.String()
 	credential.CredentialStatus = credentialStatus
 	credential.CredentialSchema = verifiable.CredentialSchema{
-		ID:   fmt.Sprint(isr.schemaBuilder.SchemasBaseURL, claims.ClaimSchemaList[claimType].ClaimSchemaURL),
+		ID:   isr.schemaBuilder.ResolveSchemaURL(claimData.ClaimSchemaURL),
 		Type: verifiable.JSONSchemaValidator2018,
 	}
 	credential.Context = []string{
 		verifiable.JSONLDSchemaW3CCredential2018,
 		verifiable.JSONLDSchemaIden3Credential,
-		isr.schemaBuilder.CachedSchemas[string(claimType)].JSONLdContext,
+		schema.JSONLdContext,
 	}
 	credential.Type = []string{verifiable.TypeW3CVerifiableCredential, claimType.ToRaw()}
 
 	return credential, nil
 }
 
-func NewClaimOffer(callBackURL string, from, to *core.DID, claim *data.Claim) *protocol.CredentialsOfferMessage {
+func NewClaimOffer(
+	ctx context.Context,
+	callBackURL string,
+	from, to *core.DID,
+	offeredClaims ...*data.Claim,
+) *protocol.CredentialsOfferMessage {
+	credentials := make([]protocol.CredentialOffer, 0, len(offeredClaims))
+	for _, claim := range offeredClaims {
+		claimData, _ := claims.GetClaimSchema(ctx, claims.ClaimSchemaType(claim.ClaimType))
+
+		credentials = append(credentials, protocol.CredentialOffer{
+			ID:          claim.ID,
+			Description: claimData.ClaimSchemaName,
+		})
+	}
+
 	return &protocol.CredentialsOfferMessage{
 		ID:       uuid.NewString(),
 		Typ:      packers.MediaTypePlainMessage,
 		Type:     protocol.CredentialOfferMessageType,
 		ThreadID: uuid.NewString(),
 		Body: protocol.CredentialsOfferMessageBody{
-			URL: callBackURL,
-			Credentials: []protocol.CredentialOffer{
-				protocol.CredentialOffer{
-					ID:          claim.ID,
-					Description: claims.ClaimSchemaList[claims.ClaimSchemaTypeList[claim.ClaimType]].ClaimSchemaName,
-				},
-			},
+			URL:         callBackURL,
+			Credentials: credentials,
 		},
 		From: from.String(),
 		To:   to.String(),
 	}
 }
 
+// NewCredentialIssuanceMessage responds to the fetch message with the credential.
+func NewCredentialIssuanceMessage(
+	fetchMessage *protocol.CredentialFetchRequestMessage,
+	credential *verifiable.W3CCredential,
+) *protocol.CredentialIssuanceMessage {
+	return &protocol.CredentialIssuanceMessage{
+		ID:       uuid.NewString(),
+		Typ:      packers.MediaTypePlainMessage,
+		Type:     protocol.CredentialIssuanceResponseMessageType,
+		ThreadID: fetchMessage.ThreadID,
+		Body:     protocol.IssuanceMessageBody{Credential: *credential},
+		From:     fetchMessage.To,
+		To:       fetchMessage.From,
+	}
+}
+
 func ClaimOfferToRaw(
 	claimOffer *protocol.CredentialsOfferMessage,
 	createdAt time.Time,
@@ -0,0 +1,198 @@
+package issuer
+
+import (
+	"context"
+	"math/big"
+
+	core "github.com/iden3/go-iden3-core"
+	"github.com/iden3/go-merkletree-sql/v2"
+	"github.com/pkg/errors"
+
+	"github.com/rarimo/issuer/internal/data"
+	"github.com/rarimo/issuer/internal/service/core/audit"
+	"github.com/rarimo/issuer/internal/service/core/claims"
+	"github.com/rarimo/issuer/internal/service/core/claims/schemas"
+	"github.com/rarimo/issuer/internal/service/core/identity/state"
+	"github.com/rarimo/issuer/internal/service/core/webhooks"
+)
+
+type BatchClaimStatus string
+
+const (
+	BatchClaimStatusIssued  BatchClaimStatus = "issued"
+	BatchClaimStatusFailed  BatchClaimStatus = "failed"
+	BatchClaimStatusSkipped BatchClaimStatus = "skipped"
+)
+
+// BatchClaim is the batch issuance item, the Err is set if the item failed the request validation.
+type BatchClaim struct {
+	UserDID    *core.DID
+	Opts       schemas.CompactClaimOptions
+	ClaimType  claims.ClaimSchemaType
+	Credential []byte
+	Err        error
+}
+
+type BatchClaimResult struct {
+	ClaimID string
+	Status  BatchClaimStatus
+	Err     error
+}
+
+type batchLeaf struct {
+	claim  *data.Claim
+	hi, hv *big.Int
+}
+
+// IssueClaimsBatch issues the claims in the single db transaction. The failed items are reported in
+// the results, the rest of them are issued unless the atomic mode is set, in that case nothing is
+// issued and the items that didn't fail are reported as skipped.
+func (isr *issuer) IssueClaimsBatch(
+	ctx context.Context,
+	batch []BatchClaim,
+	atomic bool,
+) ([]BatchClaimResult, error) {
+	results := make([]BatchClaimResult, len(batch))
+	leaves := make([]*batchLeaf, len(batch))
+	batchIndexes := make(map[string]struct{}, len(batch))
+
+	failed := false
+	for i, item := range batch {
+		if item.Err != nil {
+			results[i] = BatchClaimResult{Status: BatchClaimStatusFailed, Err: item.Err}
+			failed = true
+			continue
+		}
+
+		leaf, err := isr.compactBatchLeaf(ctx, item, batchIndexes)
+		switch {
+		case errors.Is(err, schemas.ErrValidationData), errors.Is(err, merkletree.ErrEntryIndexAlreadyExists):
+			results[i] = BatchClaimResult{Status: BatchClaimStatusFailed, Err: err}
+			failed = true
+			continue
+		case err != nil:
+			return nil, errors.Wrapf(err, "failed to compact batch item %d", i)
+		}
+
+		leaves[i] = leaf
+	}
+
+	db := isr.State.DB.New()
+	err := db.Transaction(func() error {
+		trees, err := isr.State.LockTrees(ctx, db)
+		if err != nil {
+			return errors.Wrap(err, "failed to lock identity trees")
+		}
+
+		// the indexes are checked against the locked tree, so they can't be taken concurrently
+		for i, leaf := range leaves {
+			if leaf == nil {
+				continue
+			}
+
+			err = checkIndexIsFree(ctx, trees, leaf.hi)
+			switch {
+			case errors.Is(err, merkletree.ErrEntryIndexAlreadyExists):
+				results[i] = BatchClaimResult{Status: BatchClaimStatusFailed, Err: err}
+				leaves[i] = nil
+				failed = true
+			case err != nil:
+				return errors.Wrapf(err, "failed to check batch item %d index", i)
+			}
+		}
+
+		if failed && atomic {
+			for i := range results {
+				if results[i].Status != BatchClaimStatusFailed {
+					results[i].Status = BatchClaimStatusSkipped
+				}
+				leaves[i] = nil
+			}
+			return nil
+		}
+
+		for _, leaf := range leaves {
+			if leaf == nil {
+				continue
+			}
+
+			if err := db.ClaimsQ().Insert(leaf.claim); err != nil {
+				return errors.Wrap(err, "failed to insert claim into db")
+			}
+
+			if err := trees.Claims.Add(ctx, leaf.hi, leaf.hv); err != nil {
+				return errors.Wrap(err, "failed to add claim to the claims merkle tree")
+			}
+
+			err := isr.webhooks.Enqueue(
+				db.WebhookEventsQ(), webhooks.EventClaimIssued, webhooks.NewClaimIssuedData(leaf.claim),
+			)
+			if err != nil {
+				return errors.Wrap(err, "failed to enqueue claim issued event")
+			}
+
+			err = db.AuditEventsQ().Insert(
+				audit.NewEvent(ctx, audit.EventClaimIssued, audit.NewClaimSubject(leaf.claim)),
+			)
+			if err != nil {
+				return errors.Wrap(err, "failed to insert claim issued audit event")
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to execute db transaction")
+	}
+
+	for i, leaf := range leaves {
+		if leaf == nil {
+			continue
+		}
+		results[i] = BatchClaimResult{ClaimID: leaf.claim.ID, Status: BatchClaimStatusIssued}
+	}
+
+	return results, nil
+}
+
+// compactBatchLeaf creates the claim and checks that its index
+// is not taken by the previous items of the batch.
+func (isr *issuer) compactBatchLeaf(
+	ctx context.Context,
+	item BatchClaim,
+	batchIndexes map[string]struct{},
+) (*batchLeaf, error) {
+	claim, err := isr.compactClaim(ctx, item.UserDID, item.Opts, item.ClaimType, item.Credential)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to compact the requested claim")
+	}
+
+	hi, hv, err := claim.CoreClaim.HiHv()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get claim index and value hash")
+	}
+
+	if _, ok := batchIndexes[hi.String()]; ok {
+		return nil, merkletree.ErrEntryIndexAlreadyExists
+	}
+	batchIndexes[hi.String()] = struct{}{}
+
+	return &batchLeaf{
+		claim: claim,
+		hi:    hi,
+		hv:    hv,
+	}, nil
+}
+
+// checkIndexIsFree returns merkletree.ErrEntryIndexAlreadyExists if the claims tree has the index.
+func checkIndexIsFree(ctx context.Context, trees *state.Trees, hi *big.Int) error {
+	_, _, _, err := trees.Claims.Get(ctx, hi)
+	switch {
+	case err == nil:
+		return merkletree.ErrEntryIndexAlreadyExists
+	case !errors.Is(err, merkletree.ErrKeyNotFound):
+		return errors.Wrap(err, "failed to get claim from the claims merkle tree")
+	}
+
+	return nil
+}
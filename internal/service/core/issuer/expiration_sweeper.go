@@ -0,0 +1,162 @@
+package issuer
+
+import (
+	"context"
+	"math/big"
+	"time"
+
+	"github.com/iden3/go-merkletree-sql/v2"
+	"github.com/pkg/errors"
+	"gitlab.com/distributed_lab/kit/pgdb"
+	"gitlab.com/distributed_lab/logan/v3"
+	"gitlab.com/distributed_lab/running"
+
+	"github.com/rarimo/issuer/internal/config"
+	"github.com/rarimo/issuer/internal/data"
+	"github.com/rarimo/issuer/internal/service/core/audit"
+	"github.com/rarimo/issuer/internal/service/core/claims"
+	"github.com/rarimo/issuer/internal/service/core/identity/state"
+)
+
+const (
+	expirationSweeperRunnerName = "expiration_sweeper"
+	expirationSweeperOperator   = "expiration_sweeper"
+)
+
+// expirationSweeper marks the expired claims and revokes the ones
+// whose schema has the revoke on expiration policy.
+type expirationSweeper struct {
+	log       *logan.Entry
+	issuer    *issuer
+	period    time.Duration
+	batchSize uint64
+}
+
+func newExpirationSweeper(log *logan.Entry, cfg *config.ExpirationSweeperConfig, isr *issuer) *expirationSweeper {
+	return &expirationSweeper{
+		log:       log,
+		issuer:    isr,
+		period:    cfg.Period,
+		batchSize: cfg.BatchSize,
+	}
+}
+
+func (s *expirationSweeper) Run(ctx context.Context) {
+	// the revocations of the expired claims are attributed to the sweeper
+	ctx = audit.CtxActor(ctx, audit.NewSystemActor(expirationSweeperOperator))
+
+	ticker := time.NewTicker(s.period)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			running.UntilSuccess(ctx, s.log, expirationSweeperRunnerName,
+				func(ctx context.Context) (bool, error) {
+					return true, s.sweep(ctx)
+				}, s.period, s.period,
+			)
+
+			ticker.Reset(s.period)
+		}
+	}
+}
+
+func (s *expirationSweeper) sweep(ctx context.Context) error {
+	now := time.Now().UTC()
+
+	for {
+		// the swept claims are marked as expired, so the first page is always taken
+		expiredClaims, err := s.issuer.State.DB.ClaimsQ().
+			FilterByExpired(false).
+			FilterByExpiration(nil, &now).
+			Page(pgdb.OffsetPageParams{
+				Limit: s.batchSize,
+				Order: pgdb.OrderTypeAsc,
+			}).
+			Select()
+		if err != nil {
+			return errors.Wrap(err, "failed to select expired claims")
+		}
+
+		for i := range expiredClaims {
+			if err := s.issuer.expireClaim(ctx, expiredClaims[i].ID); err != nil {
+				return errors.Wrapf(err, "failed to expire claim %s", expiredClaims[i].ID)
+			}
+		}
+
+		if len(expiredClaims) > 0 {
+			s.log.WithField("count", len(expiredClaims)).Info("Expired claims are swept")
+		}
+
+		if uint64(len(expiredClaims)) < s.batchSize {
+			return nil
+		}
+	}
+}
+
+// expireClaim marks the claim as expired and revokes it if the claim schema requires, the nonce
+// that was already revoked by the operator is not revoked twice. The claim is read again after the
+// trees are locked, so the concurrent update or revocation of it is neither overwritten nor repeated.
+func (isr *issuer) expireClaim(ctx context.Context, claimID string) error {
+	db := isr.State.DB.New()
+	err := db.Transaction(func() error {
+		trees, err := isr.State.LockTrees(ctx, db)
+		if err != nil {
+			return errors.Wrap(err, "failed to lock identity trees")
+		}
+
+		claim, err := db.ClaimsQ().Get(claimID)
+		if err != nil {
+			return errors.Wrap(err, "failed to get claim from db")
+		}
+		// the claim could be already swept by the other instance or prolonged by the update
+		if claim == nil || claim.Expired || claim.Expiration == nil || claim.Expiration.After(time.Now().UTC()) {
+			return nil
+		}
+
+		claimData, _ := claims.GetClaimSchema(ctx, claims.ClaimSchemaType(claim.ClaimType))
+
+		err = db.ClaimsQ().MarkExpired(claim.ID, claimData.RevokeOnExpiration)
+		if err != nil {
+			return errors.Wrap(err, "failed to mark claim as expired in db")
+		}
+
+		if !claimData.RevokeOnExpiration || claim.Revoked {
+			return nil
+		}
+
+		revoked, err := isNonceRevoked(ctx, trees, claim.CoreClaim.GetRevocationNonce())
+		if err != nil {
+			return errors.Wrap(err, "failed to check revocation nonce")
+		}
+		if revoked {
+			return nil
+		}
+
+		return isr.revokeNonce(ctx, db, trees, newClaimRevocation(
+			ctx,
+			claim.CoreClaim.GetRevocationNonce(),
+			&claim.ID,
+			RevocationDetails{Reason: data.RevocationReasonExpired},
+		))
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to execute db transaction")
+	}
+
+	return nil
+}
+
+func isNonceRevoked(ctx context.Context, trees *state.Trees, revNonce uint64) (bool, error) {
+	_, _, _, err := trees.Revocations.Get(ctx, new(big.Int).SetUint64(revNonce))
+	switch {
+	case err == nil:
+		return true, nil
+	case errors.Is(err, merkletree.ErrKeyNotFound):
+		return false, nil
+	default:
+		return false, errors.Wrap(err, "failed to get nonce from the revocations merkle tree")
+	}
+}
@@ -2,27 +2,34 @@ package issuer
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"math/big"
 	"time"
 
 	"github.com/google/uuid"
 	core "github.com/iden3/go-iden3-core"
-	"github.com/iden3/go-merkletree-sql/v2"
+	"github.com/iden3/go-jwz"
 	"github.com/iden3/go-schema-processor/verifiable"
-	"github.com/iden3/iden3comm/packers"
 	"github.com/iden3/iden3comm/protocol"
 	"github.com/pkg/errors"
 
 	"github.com/rarimo/issuer/internal/data"
 	"github.com/rarimo/issuer/internal/service/api/requests"
+	"github.com/rarimo/issuer/internal/service/core/audit"
 	"github.com/rarimo/issuer/internal/service/core/claims"
-	"github.com/rarimo/issuer/internal/service/core/identity/state"
+	"github.com/rarimo/issuer/internal/service/core/claims/schemas"
+	"github.com/rarimo/issuer/internal/service/core/webhooks"
 )
 
 func (isr *issuer) CreateClaimOffer(
-	userDID *core.DID, claimID string,
+	ctx context.Context, userDID *core.DID, claimID string,
 ) (*protocol.CredentialsOfferMessage, error) {
+	// the claim isn't looked up, so the response doesn't reveal whether the user has it
+	if isr.isOfferAuthRequired(claimID) {
+		return nil, ErrOfferAuthIsRequired
+	}
+
 	claim, err := isr.Identity.State.DB.ClaimsQ().GetBySchemaType(claimID, userDID.ID.String())
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to get claim from db")
@@ -31,36 +38,101 @@ func (isr *issuer) CreateClaimOffer(
 		return nil, ErrClaimIsNotExist
 	}
 
-	recipient, err := claim.CoreClaim.GetID()
+	return isr.offerClaims(ctx, userDID, claim)
+}
+
+func (isr *issuer) CreateClaimOfferByID(
+	ctx context.Context, userDID *core.DID, claimID uuid.UUID,
+) (*protocol.CredentialsOfferMessage, error) {
+	claim, err := isr.Identity.State.DB.ClaimsQ().Get(claimID.String())
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to get the claim recipient id")
+		return nil, errors.Wrap(err, "failed to get claim from db")
+	}
+	if claim == nil {
+		return nil, ErrClaimIsNotExist
 	}
-	if !userDID.ID.Equals(&recipient) {
-		return nil, ErrClaimRetrieverIsNotClaimOwner
+
+	if err := isr.checkOfferAuthIsNotRequired(claim); err != nil {
+		return nil, err
 	}
 
+	return isr.offerClaims(ctx, userDID, claim)
+}
+
+// offerClaims offers the claims of the user in the single offer, the delivery
+// of every offered credential is tracked separately under the offer thread.
+func (isr *issuer) offerClaims(ctx context.Context, userDID *core.DID, offeredClaims ...*data.Claim) (*protocol.CredentialsOfferMessage, error) {
+	for _, claim := range offeredClaims {
+		recipient, err := claim.CoreClaim.GetID()
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get the claim recipient id")
+		}
+		if !userDID.ID.Equals(&recipient) {
+			return nil, ErrClaimRetrieverIsNotClaimOwner
+		}
+	}
+
+	var claimOffer *protocol.CredentialsOfferMessage
+
+	db := isr.State.DB.New()
+	err := db.Transaction(func() (err error) {
+		claimOffer, err = isr.insertClaimOffer(ctx, db, userDID, offeredClaims...)
+		return err
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to execute db transaction")
+	}
+
+	return claimOffer, nil
+}
+
+func (isr *issuer) insertClaimOffer(
+	ctx context.Context,
+	db data.MasterQ,
+	userDID *core.DID,
+	offeredClaims ...*data.Claim,
+) (*protocol.CredentialsOfferMessage, error) {
 	claimOffer := NewClaimOffer(
-		fmt.Sprint(isr.baseURL, ClaimIssueCallBackPath), isr.Identifier, userDID, claim,
+		ctx, fmt.Sprint(isr.baseURL, ClaimIssueCallBackPath), isr.Identifier, userDID, offeredClaims...,
 	)
 
-	err = isr.claimsOffersQ.Insert(
-		ClaimOfferToRaw(claimOffer, time.Now(), isr.Identifier.ID, userDID.ID),
-	)
+	claimOfferRaw := ClaimOfferToRaw(claimOffer, time.Now().UTC(), isr.Identifier.ID, userDID.ID)
+
+	message, err := json.Marshal(claimOffer)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal claim offer message")
+	}
+	claimOfferRaw.Message = message
+
+	err = db.ClaimsOffersQ().Insert(claimOfferRaw)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to insert claim offer to db")
 	}
 
+	credentials := make([]data.ClaimOfferCredential, 0, len(offeredClaims))
+	for _, claim := range offeredClaims {
+		credentials = append(credentials, data.ClaimOfferCredential{
+			OfferID: claimOfferRaw.ID,
+			ClaimID: claim.ID,
+		})
+	}
+
+	err = db.ClaimOfferCredentialsQ().Insert(credentials...)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to insert claim offer credentials to db")
+	}
+
 	return claimOffer, nil
 }
 
 func (isr *issuer) IssueClaim(
 	ctx context.Context,
 	userDID *core.DID,
-	expiration *time.Time,
+	opts schemas.CompactClaimOptions,
 	claimType claims.ClaimSchemaType,
 	credentialRaw []byte,
 ) (string, error) {
-	claim, err := isr.compactClaim(ctx, userDID, expiration, claimType, credentialRaw)
+	claim, err := isr.compactClaim(ctx, userDID, opts, claimType, credentialRaw)
 	if err != nil {
 		return "", errors.Wrap(err, "failed to compact the requested claim")
 	}
@@ -70,21 +142,33 @@ func (isr *issuer) IssueClaim(
 		return "", errors.Wrap(err, "failed to get claim index and value hash")
 	}
 
-	// we clone it to save the atomicity of the TX and to
-	// avoid this issue https://github.com/lib/pq/issues/635
-	// merkletree.Add() contains update statement after the select
 	db := isr.State.DB.New()
 	err = db.Transaction(func() error {
+		trees, err := isr.State.LockTrees(ctx, db)
+		if err != nil {
+			return errors.Wrap(err, "failed to lock identity trees")
+		}
+
 		err = db.ClaimsQ().Insert(claim)
 		if err != nil {
 			return errors.Wrap(err, "failed to insert claim into db")
 		}
 
-		err = isr.State.ClaimsTree.Add(ctx, hi, hv)
+		err = trees.Claims.Add(ctx, hi, hv)
 		if err != nil {
 			return errors.Wrap(err, "failed to add claim to the claims merkle tree")
 		}
 
+		err = isr.webhooks.Enqueue(db.WebhookEventsQ(), webhooks.EventClaimIssued, webhooks.NewClaimIssuedData(claim))
+		if err != nil {
+			return errors.Wrap(err, "failed to enqueue claim issued event")
+		}
+
+		err = db.AuditEventsQ().Insert(audit.NewEvent(ctx, audit.EventClaimIssued, audit.NewClaimSubject(claim)))
+		if err != nil {
+			return errors.Wrap(err, "failed to insert claim issued audit event")
+		}
+
 		return nil
 	})
 	if err != nil {
@@ -114,7 +198,7 @@ func (isr *issuer) OfferCallback(
 		return nil, ErrClaimIsNotExist
 	}
 
-	err = isr.checkCallbackRequest(claim, claimOffer, request)
+	err = isr.checkCallbackRequest(ctx, claim, claimOffer, request)
 	if err != nil {
 		return nil, errors.Wrap(err, "invalid callback request")
 	}
@@ -128,38 +212,37 @@ func (isr *issuer) OfferCallback(
 		return nil, errors.Wrap(err, "failed to create iden3 credential from claim model")
 	}
 
-	claimOffer.IsReceived = true
-	err = isr.claimsOffersQ.Update(claimOffer)
+	// the sender is checked to be the claim recipient, so the delivery is attributed to it
+	ctx = audit.CtxActor(ctx, audit.NewDIDActor(request.FetchMessage.From))
+
+	isMarked, err := isr.markOfferCredentialReceived(ctx, claimOffer, claim, request)
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to update claim offer in db")
+		return nil, errors.Wrap(err, "failed to mark claim offer credential as received")
+	}
+	if !isMarked {
+		// the credential was delivered by the concurrent request after it had been checked
+		return nil, ErrRepeatedCallbackRequest
 	}
 
-	return &protocol.CredentialIssuanceMessage{
-		ID:       uuid.NewString(),
-		Typ:      packers.MediaTypePlainMessage,
-		Type:     protocol.CredentialIssuanceResponseMessageType,
-		ThreadID: request.FetchMessage.ThreadID,
-		Body:     protocol.IssuanceMessageBody{Credential: *cred},
-		From:     request.FetchMessage.To,
-		To:       request.FetchMessage.From,
-	}, nil
+	return NewCredentialIssuanceMessage(request.FetchMessage, cred), nil
 }
 
 func (isr *issuer) GetRevocationStatus(
 	ctx context.Context,
 	revID *big.Int,
-) (*verifiable.RevocationStatus, error) {
-	lastCommittedStateRaw, err := isr.State.DB.CommittedStatesQ().WhereStatus(data.StatusCompleted).GetLatest()
+) (*RevocationStatus, error) {
+	// the state may be not committed yet, then the proof refers to the trees roots as the credential proofs do
+	lastCommittedState, _, err := isr.GetLatestState(ctx)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to get last committed state")
 	}
 
-	lastCommittedState, err := state.CommittedStateFromRaw(lastCommittedStateRaw)
+	trees, err := isr.State.LoadTrees(ctx, isr.State.DB)
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to get last committed state")
+		return nil, errors.Wrap(err, "failed to load identity trees")
 	}
 
-	mtp, _, err := isr.State.RevocationsTree.GenerateProof(ctx, revID, lastCommittedState.RevocationsTreeRoot)
+	mtp, _, err := trees.Revocations.GenerateProof(ctx, revID, lastCommittedState.RevocationsTreeRoot)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to generate non revocation proof")
 	}
@@ -169,47 +252,53 @@ func (isr *issuer) GetRevocationStatus(
 		return nil, errors.Wrap(err, "failed to generate state hash")
 	}
 
-	return &verifiable.RevocationStatus{
-		MTP: *mtp,
-		Issuer: struct {
-			State              *string `json:"state,omitempty"`
-			RootOfRoots        *string `json:"rootOfRoots,omitempty"`
-			ClaimsTreeRoot     *string `json:"claimsTreeRoot,omitempty"`
-			RevocationTreeRoot *string `json:"revocationTreeRoot,omitempty"`
-		}{
-			State:              strptr(stateHash.Hex()),
-			RevocationTreeRoot: strptr(lastCommittedState.RevocationsTreeRoot.Hex()),
-			RootOfRoots:        strptr(lastCommittedState.RootsTreeRoot.Hex()),
-			ClaimsTreeRoot:     strptr(lastCommittedState.ClaimsTreeRoot.Hex()),
+	claim, err := isr.State.DB.ClaimsQ().GetByRevNonce(revID.Uint64())
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get claim by revocation nonce")
+	}
+
+	status := &RevocationStatus{
+		RevocationStatus: verifiable.RevocationStatus{
+			MTP: *mtp,
+			Issuer: struct {
+				State              *string `json:"state,omitempty"`
+				RootOfRoots        *string `json:"rootOfRoots,omitempty"`
+				ClaimsTreeRoot     *string `json:"claimsTreeRoot,omitempty"`
+				RevocationTreeRoot *string `json:"revocationTreeRoot,omitempty"`
+			}{
+				State:              strptr(stateHash.Hex()),
+				RevocationTreeRoot: strptr(lastCommittedState.RevocationsTreeRoot.Hex()),
+				RootOfRoots:        strptr(lastCommittedState.RootsTreeRoot.Hex()),
+				ClaimsTreeRoot:     strptr(lastCommittedState.ClaimsTreeRoot.Hex()),
+			},
 		},
-	}, nil
+	}
+	if claim != nil {
+		status.Expired = claim.Expired
+		status.Expiration = claim.Expiration
+	}
+
+	return status, nil
 }
 
 func (isr *issuer) GetInclusionMTP(
 	ctx context.Context,
 	claimID uuid.UUID,
 ) (*ClaimInclusionMTP, error) {
-	lastCommittedStateRaw, err := isr.State.DB.CommittedStatesQ().WhereStatus(data.StatusCompleted).GetLatest()
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to get last committed state")
-	}
-
-	lastCommittedState, err := state.CommittedStateFromRaw(lastCommittedStateRaw)
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to get last committed state")
-	}
-
 	claim, err := isr.State.DB.ClaimsQ().Get(claimID.String())
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to get claim from db")
 	}
+	if claim == nil {
+		return nil, ErrClaimIsNotExist
+	}
 
-	hi, err := claim.CoreClaim.HIndex()
+	lastCommittedState, _, err := isr.GetLatestState(ctx)
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to get claim index hash")
+		return nil, errors.Wrap(err, "failed to get last committed state")
 	}
 
-	mtp, _, err := isr.State.ClaimsTree.GenerateProof(ctx, hi, lastCommittedState.ClaimsTreeRoot)
+	mtp, err := isr.State.GetInclusionProof(ctx, claim.CoreClaim.Claim, lastCommittedState.ClaimsTreeRoot)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to generate claim inclusion proof")
 	}
@@ -235,80 +324,409 @@ func (isr *issuer) GetInclusionMTP(
 	}, nil
 }
 
+func (isr *issuer) GetCredential(ctx context.Context, claimID uuid.UUID) (*verifiable.W3CCredential, error) {
+	claim, err := isr.State.DB.ClaimsQ().Get(claimID.String())
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get claim from db")
+	}
+	if claim == nil {
+		return nil, ErrClaimIsNotExist
+	}
+
+	if err := isr.generateProofs(ctx, claim); err != nil {
+		return nil, errors.Wrap(err, "failed to generate proofs")
+	}
+
+	cred, err := ClaimModelToW3Credential(claim)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create iden3 credential from claim model")
+	}
+
+	return cred, nil
+}
+
+// GetClaimSchemaType returns the schema type of the claim, so the access to it can be authorized before it is used.
+func (isr *issuer) GetClaimSchemaType(claimID uuid.UUID) (string, error) {
+	claim, err := isr.State.DB.ClaimsQ().Get(claimID.String())
+	if err != nil {
+		return "", errors.Wrap(err, "failed to get claim from db")
+	}
+	if claim == nil {
+		return "", ErrClaimIsNotExist
+	}
+
+	return claim.ClaimType, nil
+}
+
+// GetClaimSchemaTypeByNonce returns the schema type of the claim with the revocation nonce.
+func (isr *issuer) GetClaimSchemaTypeByNonce(revNonce uint64) (string, error) {
+	claim, err := isr.State.DB.ClaimsQ().GetByRevNonce(revNonce)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to get claim by revocation nonce")
+	}
+	if claim == nil {
+		return "", ErrClaimIsNotExist
+	}
+
+	return claim.ClaimType, nil
+}
+
+func (isr *issuer) FetchCredential(
+	ctx context.Context,
+	request *requests.FetchCredentialRequest,
+) (*protocol.CredentialIssuanceMessage, error) {
+	claim, err := isr.State.DB.ClaimsQ().Get(request.CredentialID.String())
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get claim from db")
+	}
+	if claim == nil {
+		return nil, ErrClaimIsNotExist
+	}
+
+	err = isr.checkFetchRequest(ctx, claim, request.Token, request.FetchMessage)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid fetch request")
+	}
+
+	if err := isr.generateProofs(ctx, claim); err != nil {
+		return nil, errors.Wrap(err, "failed to generate mtp")
+	}
+
+	cred, err := ClaimModelToW3Credential(claim)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create iden3 credential from claim model")
+	}
+
+	err = isr.markTokenUsed(isr.State.DB, request.Token, request.FetchMessage.From)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to spend fetch request token")
+	}
+
+	return NewCredentialIssuanceMessage(request.FetchMessage, cred), nil
+}
+
+func (isr *issuer) ListClaims(req *requests.ListClaimsRequest) ([]data.Claim, error) {
+	claimsQ := isr.State.DB.ClaimsQ().
+		FilterByCreatedAt(req.IssuedFrom, req.IssuedTo).
+		FilterByExpiration(req.ExpiresFrom, req.ExpiresTo).
+		Page(req.Page)
+
+	if len(req.UserIDs) > 0 {
+		claimsQ = claimsQ.FilterByUserID(req.UserIDs...)
+	}
+	if len(req.SchemaTypes) > 0 {
+		claimsQ = claimsQ.FilterBySchemaType(req.SchemaTypes...)
+	}
+	if req.Revoked != nil {
+		claimsQ = claimsQ.FilterByRevoked(*req.Revoked)
+	}
+	if req.Expired != nil {
+		claimsQ = claimsQ.FilterByExpired(*req.Expired)
+	}
+
+	claimsList, err := claimsQ.Select()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to select claims from db")
+	}
+
+	return claimsList, nil
+}
+
 func (isr *issuer) GetIdentifier() string {
 	return isr.Identifier.ID.String()
 }
 
+// GetDID returns the DID of the issuer, its URLs are scoped by it.
+func (isr *issuer) GetDID() string {
+	return isr.Identifier.String()
+}
+
+// GetIdentityID returns the ID of the hosted identity, the data of the issuer is scoped by it.
+func (isr *issuer) GetIdentityID() uint64 {
+	return isr.State.IdentityID
+}
+
 func (isr *issuer) RevokeClaim(
 	ctx context.Context,
 	userID *core.ID,
 	schemaType claims.ClaimSchemaType,
-) error {
+	details RevocationDetails,
+) (*ClaimRevocation, error) {
+	if schemaType == claims.AuthBJJCredentialClaimType {
+		return nil, ErrAuthClaimIsNotRevocable
+	}
+
 	claim, err := isr.State.DB.ClaimsQ().GetBySchemaType(schemaType.ToRaw(), userID.String())
 	if err != nil {
-		return errors.Wrap(err, "failed to get claim from db")
+		return nil, errors.Wrap(err, "failed to get claim from db")
 	}
 	if claim == nil {
-		return ErrClaimIsNotExist
+		return nil, ErrClaimIsNotExist
+	}
+
+	return isr.revokeClaim(ctx, claim, details)
+}
+
+func (isr *issuer) RevokeClaimByID(
+	ctx context.Context,
+	claimID uuid.UUID,
+	details RevocationDetails,
+) (*ClaimRevocation, error) {
+	if claimID.String() == isr.AuthClaim.ID {
+		return nil, ErrAuthClaimIsNotRevocable
+	}
+
+	claim, err := isr.State.DB.ClaimsQ().Get(claimID.String())
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get claim from db")
+	}
+	if claim == nil {
+		return nil, ErrClaimIsNotExist
+	}
+
+	return isr.revokeClaim(ctx, claim, details)
+}
+
+func (isr *issuer) revokeClaim(
+	ctx context.Context,
+	claim *data.Claim,
+	details RevocationDetails,
+) (*ClaimRevocation, error) {
+	if err := isr.checkClaimIsRevocable(claim); err != nil {
+		return nil, err
 	}
 
 	if claim.Revoked {
-		return ErrClaimIsAlreadyRevoked
+		return nil, ErrClaimIsAlreadyRevoked
+	}
+
+	revocation := newClaimRevocation(ctx, claim.CoreClaim.GetRevocationNonce(), &claim.ID, details)
+
+	db := isr.State.DB.New()
+	err := db.Transaction(func() error {
+		trees, err := isr.State.LockTrees(ctx, db)
+		if err != nil {
+			return errors.Wrap(err, "failed to lock identity trees")
+		}
+
+		// only the flag is set, so the version the claim was updated to concurrently is kept,
+		// the concurrent revocation of the claim is rejected by the revocation of its nonce
+		err = db.ClaimsQ().MarkRevoked(claim.ID)
+		if err != nil {
+			return errors.Wrap(err, "failed to mark claim as revoked in db")
+		}
+
+		return isr.revokeNonce(ctx, db, trees, revocation)
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to execute db transaction")
 	}
 
-	claim.Revoked = true
+	return isr.withRevocationState(ctx, revocation)
+}
+
+// UpdateClaim replaces the updatable claim with the new version that has the same index data,
+// the replaced version is kept in the claim versions history. The claim is read again and checked after
+// the trees are locked, so the revocation committed concurrently isn't undone by the update.
+func (isr *issuer) UpdateClaim(ctx context.Context, req *requests.UpdateClaimRequest) (string, error) {
+	claim, err := isr.State.DB.ClaimsQ().Get(req.ClaimID.String())
+	if err != nil {
+		return "", errors.Wrap(err, "failed to get claim from db")
+	}
+	if claim == nil {
+		return "", ErrClaimIsNotExist
+	}
+
+	// the schema type of the claim doesn't change, so the credential subject is validated before the lock
+	claimType := claims.ClaimSchemaType(claim.ClaimType)
+	credentialRaw, err := req.CredentialSubject(ctx, claimType)
+	if err != nil {
+		return "", errors.Wrap(schemas.ErrValidationData, err.Error())
+	}
 
-	// we clone it to save the atomicity of the TX and to
-	// avoid this issue https://github.com/lib/pq/issues/635
-	// merkletree.Add() contains update statement after the select
 	db := isr.State.DB.New()
 	err = db.Transaction(func() error {
-		err = db.ClaimsQ().Update(claim)
+		trees, err := isr.State.LockTrees(ctx, db)
+		if err != nil {
+			return errors.Wrap(err, "failed to lock identity trees")
+		}
+
+		claim, err := db.ClaimsQ().Get(req.ClaimID.String())
+		if err != nil {
+			return errors.Wrap(err, "failed to get claim from db")
+		}
+		if claim == nil {
+			return ErrClaimIsNotExist
+		}
+
+		updated, err := isr.buildClaimVersion(ctx, claim, credentialRaw, req.Expiration)
+		if err != nil {
+			return err
+		}
+
+		replacedHi, err := claim.CoreClaim.HIndex()
+		if err != nil {
+			return errors.Wrap(err, "failed to get replaced claim index hash")
+		}
+
+		hi, hv, err := updated.CoreClaim.HiHv()
+		if err != nil {
+			return errors.Wrap(err, "failed to get claim index and value hash")
+		}
+
+		err = db.ClaimVersionsQ().Insert(&data.ClaimVersion{
+			ClaimID:           claim.ID,
+			Version:           claim.CoreClaim.GetVersion(),
+			Credential:        claim.Credential,
+			CoreClaim:         claim.CoreClaim,
+			MerklizedDocument: claim.MerklizedDocument,
+			ReplacedAt:        time.Now(),
+		})
+		if err != nil {
+			return errors.Wrap(err, "failed to insert claim version into db")
+		}
+
+		err = db.ClaimsQ().Update(updated)
 		if err != nil {
 			return errors.Wrap(err, "failed to update claim in db")
 		}
 
-		err = isr.State.RevocationsTree.Add(ctx, new(big.Int).SetUint64(
-			claim.CoreClaim.GetRevocationNonce()), merkletree.HashZero.BigInt(),
-		)
+		err = trees.Claims.Delete(ctx, replacedHi)
 		if err != nil {
-			return errors.Wrap(err, "failed to add revocation nonce to the revocations merkle tree")
+			return errors.Wrap(err, "failed to delete replaced claim from the claims merkle tree")
+		}
+
+		err = trees.Claims.Add(ctx, hi, hv)
+		if err != nil {
+			return errors.Wrap(err, "failed to add claim to the claims merkle tree")
 		}
 
 		return nil
 	})
 	if err != nil {
-		return errors.Wrap(err, "failed to execute db transaction")
+		return "", errors.Wrap(err, "failed to execute db transaction")
 	}
 
-	return nil
+	return claim.ID, nil
+}
+
+// buildClaimVersion checks that the claim can be updated and builds its next version with
+// the credential subject, the index data of the claim has to stay the same.
+func (isr *issuer) buildClaimVersion(
+	ctx context.Context,
+	claim *data.Claim,
+	credentialRaw []byte,
+	expiration *time.Time,
+) (*data.Claim, error) {
+	if claim.Revoked {
+		return nil, ErrClaimIsAlreadyRevoked
+	}
+
+	if claim.Expired {
+		return nil, ErrClaimIsExpired
+	}
+
+	if !claim.CoreClaim.GetFlagUpdatable() {
+		return nil, ErrClaimIsNotUpdatable
+	}
+
+	userDID, err := claimRecipientDID(claim)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get claim recipient did")
+	}
+
+	if expiration == nil {
+		if claimExpiration, ok := claim.CoreClaim.GetExpirationDate(); ok {
+			expiration = &claimExpiration
+		}
+	}
+
+	updated, err := isr.buildClaim(
+		ctx,
+		claim.ID,
+		claim.CoreClaim.GetRevocationNonce(),
+		userDID,
+		schemas.CompactClaimOptions{
+			Expiration:        expiration,
+			Version:           claim.CoreClaim.GetVersion() + 1,
+			Updatable:         true,
+			WithoutIndexNonce: !hasIndexSlotB(claim.CoreClaim.Claim),
+		},
+		claims.ClaimSchemaType(claim.ClaimType),
+		credentialRaw,
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to compact the updated claim")
+	}
+	updated.CreatedAt = claim.CreatedAt
+
+	if !isSameIndexData(claim.CoreClaim.Claim, updated.CoreClaim.Claim) {
+		return nil, ErrClaimIndexChanged
+	}
+
+	return updated, nil
 }
 
 func (isr *issuer) checkCallbackRequest(
+	ctx context.Context,
 	claim *data.Claim,
 	claimOffer *data.ClaimOffer,
 	request *requests.OfferCallbackRequest,
 ) error {
-	userDID, err := core.ParseDID(request.FetchMessage.From)
+	if err := isr.checkFetchRequest(ctx, claim, request.Token, request.FetchMessage); err != nil {
+		return err
+	}
+
+	if claimOffer.IsReceived {
+		return ErrRepeatedCallbackRequest
+	}
+
+	credentials, err := isr.State.DB.ClaimOfferCredentialsQ().SelectByOfferID(claimOffer.ID)
+	if err != nil {
+		return errors.Wrap(err, "failed to select claim offer credentials")
+	}
+
+	credential := findOfferCredential(credentials, claim.ID)
+	if credential == nil {
+		return ErrClaimIsNotOffered
+	}
+	if credential.IsReceived {
+		return ErrRepeatedCallbackRequest
+	}
+
+	return isr.checkClaimOfferIsActive(claimOffer)
+}
+
+// checkFetchRequest checks that the fetch message is addressed to the issuer
+// and signed by the claim owner.
+func (isr *issuer) checkFetchRequest(
+	ctx context.Context,
+	claim *data.Claim,
+	token *jwz.Token,
+	fetchMessage *protocol.CredentialFetchRequestMessage,
+) error {
+	return isr.checkMessageSender(ctx, claim, token, fetchMessage.From, fetchMessage.To)
+}
+
+// checkMessageSender checks that the message from the sender to the recipient
+// is addressed to the issuer and signed by the claim owner.
+func (isr *issuer) checkMessageSender(ctx context.Context, claim *data.Claim, token *jwz.Token, from, to string) error {
+	userDID, err := core.ParseDID(from)
 	if err != nil {
 		return errors.Wrap(err, "failed to parse user did")
 	}
 
-	ok, err := isr.checkClaimRetriever(claim, userDID.ID.String(), request.Token)
+	claimRecipientID, err := claim.CoreClaim.GetID()
 	if err != nil {
-		return errors.Wrap(err, "failed to check claim retriever")
+		return errors.Wrap(err, "failed to get claim recipient identifier")
 	}
-	if !ok {
+	if !claimRecipientID.Equals(&userDID.ID) {
 		return ErrClaimRetrieverIsNotClaimOwner
 	}
 
-	if isr.Identifier.String() != request.FetchMessage.To {
+	if isr.Identifier.String() != to {
 		return ErrMessageRecipientIsNotIssuer
 	}
 
-	if claimOffer.IsReceived {
-		return ErrRepeatedCallbackRequest
-	}
-
-	return nil
+	return isr.verifyToken(ctx, token, &userDID.ID)
 }
@@ -3,54 +3,148 @@ package issuer
 import (
 	"context"
 	"math/big"
-	"time"
+	"os"
+	"path/filepath"
 
 	"github.com/google/uuid"
+	"github.com/iden3/go-circuits"
 	core "github.com/iden3/go-iden3-core"
 	"github.com/iden3/go-schema-processor/verifiable"
+	"github.com/iden3/iden3comm"
 	"github.com/iden3/iden3comm/protocol"
 	"gitlab.com/distributed_lab/logan/v3/errors"
 
 	"github.com/rarimo/issuer/internal/config"
+	"github.com/rarimo/issuer/internal/data"
 	"github.com/rarimo/issuer/internal/data/pg"
 	"github.com/rarimo/issuer/internal/service/api/requests"
 	"github.com/rarimo/issuer/internal/service/core/claims"
 	"github.com/rarimo/issuer/internal/service/core/claims/schemas"
+	"github.com/rarimo/issuer/internal/service/core/idempotency"
 	identityPkg "github.com/rarimo/issuer/internal/service/core/identity"
 	statePkg "github.com/rarimo/issuer/internal/service/core/identity/state"
 )
 
 type Issuer interface {
+	GetIdentityID() uint64
 	GetIdentifier() string
-	CreateClaimOffer(*core.DID, string) (*protocol.CredentialsOfferMessage, error)
-	IssueClaim(context.Context, *core.DID, *time.Time, claims.ClaimSchemaType, []byte) (string, error)
+	GetDID() string
+	GetDIDDocument() *verifiable.DIDDocument
+	NewPackerParams(mediaType iden3comm.MediaType, recipient string) (iden3comm.PackerParams, error)
+	PackMessage(mediaType iden3comm.MediaType, message interface{}, params iden3comm.PackerParams) ([]byte, error)
+	CreateClaimOffer(context.Context, *core.DID, string) (*protocol.CredentialsOfferMessage, error)
+	CreateClaimOfferByID(context.Context, *core.DID, uuid.UUID) (*protocol.CredentialsOfferMessage, error)
+	CreateClaimsBundleOffer(context.Context, *requests.ClaimsBundleOfferRequest) (*protocol.CredentialsOfferMessage, error)
+	CreateClaimOfferAuthRequest(*core.DID, string) (*protocol.AuthorizationRequestMessage, error)
+	AuthorizeClaimOffer(context.Context, *requests.ClaimOfferAuthCallbackRequest) (*protocol.CredentialsOfferMessage, error)
+	GetClaimOfferLinks(offerID string) *ClaimOfferLinks
+	GetClaimOfferMessage(offerID string) ([]byte, error)
+	CancelClaimOffer(offerID string) error
+	RenderClaimOfferQRCode(offerID, format string, size int) ([]byte, error)
+	GetCredential(ctx context.Context, claimID uuid.UUID) (*verifiable.W3CCredential, error)
+	GetClaimSchemaType(claimID uuid.UUID) (string, error)
+	GetClaimSchemaTypeByNonce(revNonce uint64) (string, error)
+	FetchCredential(context.Context, *requests.FetchCredentialRequest) (*protocol.CredentialIssuanceMessage, error)
+	ListClaims(*requests.ListClaimsRequest) ([]data.Claim, error)
+	IssueClaim(context.Context, *core.DID, schemas.CompactClaimOptions, claims.ClaimSchemaType, []byte) (string, error)
+	IssueClaimsBatch(context.Context, []BatchClaim, bool) ([]BatchClaimResult, error)
+	UpdateClaim(context.Context, *requests.UpdateClaimRequest) (string, error)
+	ListClaimVersions(claimID uuid.UUID) ([]ClaimVersion, error)
+	GetClaimVersion(claimID uuid.UUID, version uint32) (*ClaimVersion, error)
+	ReissueClaim(context.Context, *requests.ReissueClaimRequest) (*ClaimReissue, error)
 	OfferCallback(context.Context, *requests.OfferCallbackRequest) (*protocol.CredentialIssuanceMessage, error)
-	GetRevocationStatus(context.Context, *big.Int) (*verifiable.RevocationStatus, error)
+	GetRevocationStatus(context.Context, *big.Int) (*RevocationStatus, error)
+	HandleAgentMessage(context.Context, *requests.AgentRequest) (interface{}, error)
 	GetInclusionMTP(ctx context.Context, claimID uuid.UUID) (*ClaimInclusionMTP, error)
-	RevokeClaim(context.Context, *core.ID, claims.ClaimSchemaType) error
+	GetMerklePaths(ctx context.Context, claimID uuid.UUID, fields []string) (*ClaimMerklePaths, error)
+	RevokeClaim(context.Context, *core.ID, claims.ClaimSchemaType, RevocationDetails) (*ClaimRevocation, error)
+	RevokeClaimByID(context.Context, uuid.UUID, RevocationDetails) (*ClaimRevocation, error)
+	RevokeClaimByNonce(context.Context, uint64, RevocationDetails) (*ClaimRevocation, error)
+	RevokeClaimsBulk(context.Context, []BulkRevocation, RevocationDetails) ([]BulkRevocationResult, error)
+	GetRevocation(ctx context.Context, revNonce uint64) (*ClaimRevocation, error)
+	ListAuditEvents(*requests.ListAuditEventsRequest) ([]data.AuditEvent, error)
+	ExportAuditEvents(*requests.ListAuditEventsRequest, func([]data.AuditEvent) error) error
+
+	RegisterClaimSchema(context.Context, *data.ClaimSchema) error
+	GetClaimSchemas() ([]data.ClaimSchema, error)
+	GetClaimSchema(schemaType string) (*data.ClaimSchema, error)
+	DeprecateClaimSchema(schemaType string) error
+
+	GetClaimTypes(context.Context) ([]ClaimType, error)
+	GetClaimType(context.Context, claims.ClaimSchemaType) (*ClaimType, error)
 }
 
-func New(ctx context.Context, cfg config.Config) (Issuer, error) {
-	identity, err := identityPkg.New(ctx, cfg)
+// newIssuer hosts the identity and runs the background jobs of its issuer, the schemas, the holders tokens
+// verifier and the webhooks endpoints are shared by the issuers of all the hosted identities.
+func newIssuer(ctx context.Context, cfg config.Config, shared *sharedServices, model *data.Identity) (*issuer, error) {
+	identity, err := identityPkg.New(ctx, cfg, model, shared.webhooks)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to create the new identity")
 	}
 
-	schemaBuilder, err := schemas.NewBuilder(ctx, cfg.Issuer().SchemasBaseURL)
+	did := identity.Identifier.String()
+	packer, err := newMessagePacker(did, identity.SigningKey(), cfg.Iden3Comm())
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to create new schema builder")
+		return nil, errors.Wrap(err, "failed to create message packer")
+	}
+
+	isr := &issuer{
+		Identity:            identity,
+		schemaBuilder:       shared.schemaBuilder,
+		claimsOffersQ:       pg.NewClaimsOffersQ(cfg.DB(), model.ID),
+		claimOfferTTL:       cfg.ClaimOffers().TTL,
+		offerAuthRequestsQ:  pg.NewOfferAuthRequestsQ(cfg.DB(), model.ID),
+		offerAuthTypes:      newSchemaTypesSet(cfg.ClaimOffers().AuthSchemaTypes),
+		offerAuthRequestTTL: cfg.ClaimOffers().AuthRequestTTL,
+		offerAuthKey:        identity.SecretKey(offerAuthKeyPurpose),
+		packer:              packer,
+		tokenVerifier:       shared.tokenVerifier,
+		baseURL:             cfg.Issuer().BaseURL + IdentitiesPath + did,
+		webhooks:            shared.webhooks.WithIssuer(did),
+	}
+
+	log := cfg.Log().WithField("did", did)
+
+	if !cfg.ExpirationSweeper().Disabled {
+		sweeperLog := log.WithField("service", expirationSweeperRunnerName)
+		go newExpirationSweeper(sweeperLog, cfg.ExpirationSweeper(), isr).Run(ctx)
+	}
+
+	if !cfg.ClaimOffers().CleanupDisabled {
+		cleanerLog := log.WithField("service", offersCleanerRunnerName)
+		go newOffersCleaner(cleanerLog, cfg.ClaimOffers(), isr.claimsOffersQ, isr.offerAuthRequestsQ).Run(ctx)
+	}
+
+	if !cfg.Idempotency().CleanupDisabled {
+		cleanerLog := log.WithField("service", idempotency.CleanerRunnerName)
+		idempotencyKeysQ := pg.NewIdempotencyKeysQ(cfg.DB(), model.ID)
+		go idempotency.NewCleaner(cleanerLog, cfg.Idempotency(), idempotencyKeysQ).Run(ctx)
 	}
 
-	authVerificationKey, err := statePkg.ReadFileByPath(cfg.Identity().CircuitsPath, basicAuthKeyPath)
+	return isr, nil
+}
+
+// readAuthVerificationKeys reads the verification keys of the auth circuits the holders tokens are proven with,
+// the authV2 key is optional, so the tokens of the circuit are rejected if it isn't provided.
+func readAuthVerificationKeys(circuitsPath string) (map[circuits.CircuitID][]byte, error) {
+	authKey, err := statePkg.ReadFileByPath(circuitsPath, basicAuthKeyPath)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to read basic auth verification key")
 	}
 
-	return &issuer{
-		Identity:            identity,
-		schemaBuilder:       schemaBuilder,
-		claimsOffersQ:       pg.NewClaimsOffersQ(cfg.DB()),
-		authVerificationKey: authVerificationKey,
-		baseURL:             cfg.Issuer().BaseURL,
-	}, nil
+	verificationKeys := map[circuits.CircuitID][]byte{
+		circuits.AuthCircuitID: authKey,
+	}
+
+	if _, err := os.Stat(filepath.Join(circuitsPath, authV2KeyPath)); os.IsNotExist(err) {
+		return verificationKeys, nil
+	}
+
+	authV2Key, err := statePkg.ReadFileByPath(circuitsPath, authV2KeyPath)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read auth v2 verification key")
+	}
+	verificationKeys[circuits.AuthV2CircuitID] = authV2Key
+
+	return verificationKeys, nil
 }
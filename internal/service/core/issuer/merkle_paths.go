@@ -0,0 +1,68 @@
+package issuer
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+
+	"github.com/google/uuid"
+	"github.com/iden3/go-schema-processor/verifiable"
+	"github.com/pkg/errors"
+
+	"github.com/rarimo/issuer/internal/service/core/claims"
+)
+
+func (isr *issuer) GetMerklePaths(
+	ctx context.Context,
+	claimID uuid.UUID,
+	fields []string,
+) (*ClaimMerklePaths, error) {
+	claim, err := isr.State.DB.ClaimsQ().Get(claimID.String())
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get claim from db")
+	}
+	if claim == nil {
+		return nil, ErrClaimIsNotExist
+	}
+	if len(claim.MerklizedDocument) == 0 {
+		return nil, ErrClaimIsNotMerklized
+	}
+
+	if len(fields) == 0 {
+		fields, err = credentialSubjectFields(claim.Credential)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get credential subject fields")
+		}
+	}
+
+	root, paths, err := isr.schemaBuilder.MerklePaths(
+		ctx, claims.ClaimSchemaType(claim.ClaimType), claim.MerklizedDocument, fields,
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get merkle paths")
+	}
+
+	return &ClaimMerklePaths{
+		MerklizedRoot: root.Hex(),
+		Paths:         paths,
+	}, nil
+}
+
+// credentialSubjectFields returns the top level credential subject fields sorted by name.
+func credentialSubjectFields(credentialRaw []byte) ([]string, error) {
+	var credential verifiable.W3CCredential
+	if err := json.Unmarshal(credentialRaw, &credential); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal credential")
+	}
+
+	fields := make([]string, 0, len(credential.CredentialSubject))
+	for field := range credential.CredentialSubject {
+		if field == "id" || field == "type" {
+			continue
+		}
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	return fields, nil
+}
@@ -1,41 +1,91 @@
 package issuer
 
 import (
+	"time"
+
 	"github.com/iden3/go-merkletree-sql/v2"
+	"github.com/iden3/go-schema-processor/verifiable"
 	"github.com/pkg/errors"
 
 	"github.com/rarimo/issuer/internal/data"
+	"github.com/rarimo/issuer/internal/service/core/claims"
 	"github.com/rarimo/issuer/internal/service/core/claims/schemas"
+	"github.com/rarimo/issuer/internal/service/core/claims/validation"
 	identityPkg "github.com/rarimo/issuer/internal/service/core/identity"
+	"github.com/rarimo/issuer/internal/service/core/jwzauth"
+	"github.com/rarimo/issuer/internal/service/core/webhooks"
 )
 
 const (
-	ClaimIssueCallBackPath = "/integrations/issuer/v1/public/claims/offers/callback"
-	GetClaimPath           = "/integrations/issuer/v1/private/claims/"
-	basicAuthKeyPath       = "/auth/verification_key.json"
+	// IdentitiesPath is the prefix of the URLs scoped by the issuer DID, the paths
+	// the issuer puts to the credentials and the messages are relative to it.
+	IdentitiesPath             = "/integrations/issuer/v1/identities/"
+	ClaimIssueCallBackPath     = "/public/claims/offers/callback"
+	ClaimOfferMessagePath      = "/public/claims/offers/messages/"
+	ClaimOfferAuthCallbackPath = "/public/claims/offers/auth/callback"
+	GetClaimPath               = "/private/claims/"
+	basicAuthKeyPath           = "/auth/verification_key.json"
+	authV2KeyPath              = "/authV2/verification_key.json"
 )
 
 const (
 	uuidStringSize = 36
+	versionOffset  = 20
 )
 
 var (
 	ErrProofVerifyFailed             = errors.New("failed to verify proof")
 	ErrClaimIsNotExist               = errors.New("claim is not exist")
 	ErrClaimOfferIsNotExist          = errors.New("claim offer is not exist")
+	ErrClaimOfferIsExpired           = errors.New("claim offer is expired")
+	ErrClaimOfferIsCanceled          = errors.New("claim offer is canceled")
+	ErrClaimOfferIsAlreadyReceived   = errors.New("claim offer is already received")
+	ErrClaimIsNotOffered             = errors.New("claim is not offered in the thread")
 	ErrClaimRetrieverIsNotClaimOwner = errors.New("claim retriever is not claim owner")
 	ErrMessageRecipientIsNotIssuer   = errors.New("the message recipient is not an issuer")
 	ErrRepeatedCallbackRequest       = errors.New("repeated callback request")
+	ErrTokenIsReplayed               = errors.New("token is already used")
 	ErrClaimIsAlreadyRevoked         = errors.New("claim is already revoked")
+	ErrAuthClaimIsNotRevocable       = errors.New("auth claim of the issuer can't be revoked")
 	ErrInvalidCredentialID           = errors.New("invalid credential id")
+	ErrClaimIsNotMerklized           = errors.New("claim is not merklized")
+	ErrClaimIsNotUpdatable           = errors.New("claim is not updatable")
+	ErrClaimIsExpired                = errors.New("claim is expired")
+	ErrClaimIndexChanged             = errors.New("claim index data was changed")
+	ErrClaimVersionIsNotExist        = errors.New("claim version is not exist")
+	ErrRevocationIsNotExist          = errors.New("revocation is not exist")
+	ErrMediaTypeIsNotSupported       = errors.New("media type is not supported")
+	ErrRecipientKeyIsNotFound        = errors.New("recipient has no key to encrypt the message to")
+	ErrOfferAuthIsRequired           = errors.New("holder authorization is required to get the claim offer")
+	ErrOfferAuthRequestIsNotExist    = errors.New("offer auth request is not exist")
+	ErrOfferAuthRequestIsExpired     = errors.New("offer auth request is expired")
+	ErrOfferAuthRequestIsAnswered    = errors.New("offer auth request is already answered")
+	ErrIdentityIsAlreadyExist        = identityPkg.ErrIdentityIsAlreadyExist
+	ErrKeysEncryptionKeyIsNotSet     = identityPkg.ErrKeysEncryptionKeyIsNotSet
 )
 
 type issuer struct {
 	*identityPkg.Identity
 	schemaBuilder       *schemas.Builder
 	claimsOffersQ       data.ClaimsOffersQ
-	authVerificationKey []byte
+	claimOfferTTL       time.Duration
+	offerAuthRequestsQ  data.OfferAuthRequestsQ
+	offerAuthTypes      map[string]struct{}
+	offerAuthRequestTTL time.Duration
+	offerAuthKey        []byte
+	packer              *messagePacker
+	tokenVerifier       *jwzauth.Verifier
 	baseURL             string
+	webhooks            *webhooks.Outbox
+}
+
+// ClaimOfferLinks are the links to the hosted offer message, the DeepLink opens it in the wallet
+// and the QR codes encode the DeepLink.
+type ClaimOfferLinks struct {
+	MessageURL string
+	DeepLink   string
+	QRCodePNG  string
+	QRCodeSVG  string
 }
 
 // ClaimInclusionMTP info required to check that claim is included in the issuer's claims tree.
@@ -49,3 +99,70 @@ type ClaimInclusionMTP struct {
 	} `json:"issuer"`
 	MTP merkletree.Proof `json:"mtp"`
 }
+
+// ClaimMerklePaths proofs of the credential subject fields inclusion into the merklized credential,
+// they allow to build the selective disclosure queries against any credential field.
+type ClaimMerklePaths struct {
+	MerklizedRoot string               `json:"merklizedRoot"`
+	Paths         []schemas.MerklePath `json:"paths"`
+}
+
+// ClaimType describes the claim type for the integrators: the schema it is
+// issued with and the credential subject format the issuance request expects.
+type ClaimType struct {
+	SchemaType               claims.ClaimSchemaType
+	DisplayName              string
+	SchemaURL                string
+	JSONLdContext            string
+	IsDeprecated             bool
+	Schema                   []byte
+	RequestFields            []validation.Field
+	ExampleCredentialSubject map[string]interface{}
+}
+
+// ClaimVersion is the version of the updatable claim, the ReplacedAt is nil for the current one.
+type ClaimVersion struct {
+	ClaimID    string
+	Version    uint32
+	Credential []byte
+	ReplacedAt *time.Time
+}
+
+// RevocationDetails is stored with every revoked nonce to explain the revocation, the operator
+// that revoked the nonce is the actor of the revocation context.
+type RevocationDetails struct {
+	Reason data.RevocationReason
+}
+
+// ClaimRevocation is the revocation record with the state of its publishing.
+type ClaimRevocation struct {
+	data.ClaimRevocation
+	State RevocationState
+}
+
+// RevocationStatus extends the revocation status with the expiration
+// of the claim that has the checked revocation nonce.
+type RevocationStatus struct {
+	verifiable.RevocationStatus
+	Expired    bool       `json:"expired"`
+	Expiration *time.Time `json:"expiration,omitempty"`
+}
+
+type RevocationStateStatus string
+
+const (
+	// RevocationStatePending means that the revocation will be included into the next committed state
+	RevocationStatePending RevocationStateStatus = "pending"
+	// RevocationStateProcessing means that the committed state that includes the revocation is being published
+	RevocationStateProcessing RevocationStateStatus = "processing"
+	// RevocationStateCompleted means that the committed state that includes the revocation is published
+	RevocationStateCompleted RevocationStateStatus = "completed"
+)
+
+// RevocationState points to the committed state that includes the revocation,
+// the CommittedStateID is nil until the state that includes it is committed.
+type RevocationState struct {
+	Status           RevocationStateStatus
+	CommittedStateID *uint64
+	TxID             string
+}
@@ -0,0 +1,255 @@
+package issuer
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	core "github.com/iden3/go-iden3-core"
+	"github.com/iden3/iden3comm/packers"
+	"github.com/iden3/iden3comm/protocol"
+	"github.com/pkg/errors"
+
+	"github.com/rarimo/issuer/internal/data"
+	"github.com/rarimo/issuer/internal/service/api/requests"
+)
+
+const (
+	offerAuthRequestReason = "claim offer"
+	// offerAuthKeyPurpose is the purpose of the identity secret key the offer auth requests are signed with
+	offerAuthKeyPurpose = "offer auth request"
+)
+
+func newSchemaTypesSet(schemaTypes []string) map[string]struct{} {
+	set := make(map[string]struct{}, len(schemaTypes))
+	for _, schemaType := range schemaTypes {
+		set[schemaType] = struct{}{}
+	}
+
+	return set
+}
+
+// isOfferAuthRequired is true if the offers of the schema type are given only to the authorized holders.
+func (isr *issuer) isOfferAuthRequired(schemaType string) bool {
+	_, ok := isr.offerAuthTypes[schemaType]
+	return ok
+}
+
+// checkOfferAuthIsNotRequired rejects offering the claims without the holder authorization
+// if any of them requires it.
+func (isr *issuer) checkOfferAuthIsNotRequired(offeredClaims ...*data.Claim) error {
+	for _, claim := range offeredClaims {
+		if isr.isOfferAuthRequired(claim.ClaimType) {
+			return ErrOfferAuthIsRequired
+		}
+	}
+
+	return nil
+}
+
+func (isr *issuer) filterOfferAuthIsNotRequired(offeredClaims []*data.Claim) []*data.Claim {
+	filtered := make([]*data.Claim, 0, len(offeredClaims))
+	for _, claim := range offeredClaims {
+		if !isr.isOfferAuthRequired(claim.ClaimType) {
+			filtered = append(filtered, claim)
+		}
+	}
+
+	return filtered
+}
+
+// CreateClaimOfferAuthRequest creates the iden3comm authorization request the user answers with the JWZ
+// authorization response to the callback to get the offer of the claim of the type. The request is created
+// regardless of the claim existence, so it doesn't reveal the claims of the user. The request isn't stored,
+// its thread ID carries it signed by the issuer, so the public endpoint doesn't write to the db.
+func (isr *issuer) CreateClaimOfferAuthRequest(
+	userDID *core.DID, claimType string,
+) (*protocol.AuthorizationRequestMessage, error) {
+	now := time.Now().UTC()
+	threadID, err := isr.encodeOfferAuthRequest(&data.OfferAuthRequest{
+		ID:         uuid.NewString(),
+		UserID:     userDID.ID.String(),
+		SchemaType: claimType,
+		CreatedAt:  now,
+		ExpiresAt:  now.Add(isr.offerAuthRequestTTL),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to encode offer auth request")
+	}
+
+	return &protocol.AuthorizationRequestMessage{
+		ID:       threadID,
+		Typ:      packers.MediaTypePlainMessage,
+		Type:     protocol.AuthorizationRequestMessageType,
+		ThreadID: threadID,
+		Body: protocol.AuthorizationRequestMessageBody{
+			CallbackURL: isr.baseURL + ClaimOfferAuthCallbackPath,
+			Reason:      offerAuthRequestReason,
+			Scope:       []protocol.ZeroKnowledgeProofRequest{},
+		},
+		From: isr.Identifier.String(),
+		To:   userDID.String(),
+	}, nil
+}
+
+// AuthorizeClaimOffer checks the authorization response of the user to the request of the thread and offers
+// the claim of the requested type to the user. The request can be answered only once before it expires.
+func (isr *issuer) AuthorizeClaimOffer(
+	ctx context.Context,
+	request *requests.ClaimOfferAuthCallbackRequest,
+) (*protocol.CredentialsOfferMessage, error) {
+	authRequest, err := isr.decodeOfferAuthRequest(request.AuthResponse.ThreadID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = isr.checkOfferAuthResponse(ctx, authRequest, request)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid offer auth response")
+	}
+
+	claim, err := isr.State.DB.ClaimsQ().GetBySchemaType(authRequest.SchemaType, authRequest.UserID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get claim from db")
+	}
+	if claim == nil {
+		return nil, ErrClaimIsNotExist
+	}
+
+	userDID, err := core.ParseDID(request.AuthResponse.From)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse user did")
+	}
+
+	// the request is stored as answered and the token is spent along with the offer,
+	// so the response can be retried if the offer isn't created
+	var offer *protocol.CredentialsOfferMessage
+
+	now := time.Now().UTC()
+	authRequest.AuthorizedAt = &now
+
+	db := isr.State.DB.New()
+	err = db.Transaction(func() error {
+		isInserted, err := db.OfferAuthRequestsQ().Insert(authRequest)
+		if err != nil {
+			return errors.Wrap(err, "failed to insert offer auth request")
+		}
+		if !isInserted {
+			return ErrOfferAuthRequestIsAnswered
+		}
+
+		err = isr.markTokenUsed(db, request.Token, request.AuthResponse.From)
+		if err != nil {
+			return errors.Wrap(err, "failed to spend auth response token")
+		}
+
+		offer, err = isr.insertClaimOffer(ctx, db, userDID, claim)
+		if err != nil {
+			return errors.Wrap(err, "failed to offer claim")
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to execute db transaction")
+	}
+
+	return offer, nil
+}
+
+// checkOfferAuthResponse checks that the authorization response answers the request that isn't expired,
+// is addressed to the issuer and is signed by the user the request was created for.
+func (isr *issuer) checkOfferAuthResponse(
+	ctx context.Context,
+	authRequest *data.OfferAuthRequest,
+	request *requests.ClaimOfferAuthCallbackRequest,
+) error {
+	if !time.Now().UTC().Before(authRequest.ExpiresAt) {
+		return ErrOfferAuthRequestIsExpired
+	}
+
+	userDID, err := core.ParseDID(request.AuthResponse.From)
+	if err != nil {
+		return errors.Wrap(err, "failed to parse user did")
+	}
+	if userDID.ID.String() != authRequest.UserID {
+		return ErrClaimRetrieverIsNotClaimOwner
+	}
+
+	if isr.Identifier.String() != request.AuthResponse.To {
+		return ErrMessageRecipientIsNotIssuer
+	}
+
+	return isr.verifyToken(ctx, request.Token, &userDID.ID)
+}
+
+// offerAuthRequestPayload is the offer auth request carried by its thread ID, the times are unix seconds.
+type offerAuthRequestPayload struct {
+	ID         string `json:"id"`
+	UserID     string `json:"user_id"`
+	SchemaType string `json:"schema_type"`
+	CreatedAt  int64  `json:"created_at"`
+	ExpiresAt  int64  `json:"expires_at"`
+}
+
+// encodeOfferAuthRequest returns the thread ID of the request, that is the request payload and its HMAC
+// with the offer auth key of the issuer, so the request answered to the issuer is the one it created.
+func (isr *issuer) encodeOfferAuthRequest(authRequest *data.OfferAuthRequest) (string, error) {
+	payload, err := json.Marshal(offerAuthRequestPayload{
+		ID:         authRequest.ID,
+		UserID:     authRequest.UserID,
+		SchemaType: authRequest.SchemaType,
+		CreatedAt:  authRequest.CreatedAt.Unix(),
+		ExpiresAt:  authRequest.ExpiresAt.Unix(),
+	})
+	if err != nil {
+		return "", errors.Wrap(err, "failed to marshal offer auth request")
+	}
+
+	encodedPayload := base64.RawURLEncoding.EncodeToString(payload)
+	return encodedPayload + "." + base64.RawURLEncoding.EncodeToString(isr.offerAuthRequestMAC(encodedPayload)), nil
+}
+
+// decodeOfferAuthRequest returns the request carried by the thread ID, ErrOfferAuthRequestIsNotExist
+// is returned if the thread ID is malformed or isn't signed by the issuer.
+func (isr *issuer) decodeOfferAuthRequest(threadID string) (*data.OfferAuthRequest, error) {
+	encodedPayload, encodedMAC, ok := strings.Cut(threadID, ".")
+	if !ok {
+		return nil, ErrOfferAuthRequestIsNotExist
+	}
+
+	mac, err := base64.RawURLEncoding.DecodeString(encodedMAC)
+	if err != nil || !hmac.Equal(mac, isr.offerAuthRequestMAC(encodedPayload)) {
+		return nil, ErrOfferAuthRequestIsNotExist
+	}
+
+	rawPayload, err := base64.RawURLEncoding.DecodeString(encodedPayload)
+	if err != nil {
+		return nil, ErrOfferAuthRequestIsNotExist
+	}
+
+	var payload offerAuthRequestPayload
+	if err := json.Unmarshal(rawPayload, &payload); err != nil {
+		return nil, ErrOfferAuthRequestIsNotExist
+	}
+
+	return &data.OfferAuthRequest{
+		ID:         payload.ID,
+		UserID:     payload.UserID,
+		SchemaType: payload.SchemaType,
+		CreatedAt:  time.Unix(payload.CreatedAt, 0).UTC(),
+		ExpiresAt:  time.Unix(payload.ExpiresAt, 0).UTC(),
+	}, nil
+}
+
+func (isr *issuer) offerAuthRequestMAC(encodedPayload string) []byte {
+	mac := hmac.New(sha256.New, isr.offerAuthKey)
+	mac.Write([]byte(encodedPayload))
+
+	return mac.Sum(nil)
+}
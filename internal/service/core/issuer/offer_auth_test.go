@@ -0,0 +1,126 @@
+package issuer
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	core "github.com/iden3/go-iden3-core"
+
+	"github.com/rarimo/issuer/internal/data"
+	identityPkg "github.com/rarimo/issuer/internal/service/core/identity"
+)
+
+const testDID = "did:iden3:polygon:mumbai:x6suHR8HkEYczV9yVeAKKiXCZAd25P8WS6QvNhszk"
+
+func newTestOfferAuthIssuer(t *testing.T, key string) *issuer {
+	t.Helper()
+
+	did, err := core.ParseDID(testDID)
+	if err != nil {
+		t.Fatalf("failed to parse did: %v", err)
+	}
+
+	return &issuer{
+		Identity:            &identityPkg.Identity{Identifier: did},
+		offerAuthKey:        []byte(key),
+		offerAuthRequestTTL: time.Minute,
+	}
+}
+
+func TestCreateClaimOfferAuthRequest(t *testing.T) {
+	// the issuer has no db, so the request is created without storing it
+	isr := newTestOfferAuthIssuer(t, "key")
+
+	message, err := isr.CreateClaimOfferAuthRequest(isr.Identifier, "KYCAgeCredential")
+	if err != nil {
+		t.Fatalf("failed to create offer auth request: %v", err)
+	}
+	if message.ThreadID != message.ID || message.To != testDID {
+		t.Fatalf("unexpected message %+v", message)
+	}
+
+	authRequest, err := isr.decodeOfferAuthRequest(message.ThreadID)
+	if err != nil {
+		t.Fatalf("failed to decode offer auth request: %v", err)
+	}
+	if authRequest.UserID != isr.Identifier.ID.String() || authRequest.SchemaType != "KYCAgeCredential" {
+		t.Fatalf("unexpected offer auth request %+v", authRequest)
+	}
+	if ttl := authRequest.ExpiresAt.Sub(authRequest.CreatedAt); ttl != time.Minute {
+		t.Fatalf("request expires in %s, want %s", ttl, time.Minute)
+	}
+
+	// every request is the other one, so the answered request doesn't block the next ones
+	next, err := isr.CreateClaimOfferAuthRequest(isr.Identifier, "KYCAgeCredential")
+	if err != nil {
+		t.Fatalf("failed to create offer auth request: %v", err)
+	}
+	nextRequest, err := isr.decodeOfferAuthRequest(next.ThreadID)
+	if err != nil || nextRequest.ID == authRequest.ID {
+		t.Fatalf("offer auth requests share the id: %v", err)
+	}
+}
+
+func TestDecodeOfferAuthRequest(t *testing.T) {
+	isr := newTestOfferAuthIssuer(t, "key")
+	now := time.Now().UTC().Truncate(time.Second)
+
+	threadID, err := isr.encodeOfferAuthRequest(&data.OfferAuthRequest{
+		ID:         "request",
+		UserID:     "user",
+		SchemaType: "KYCAgeCredential",
+		CreatedAt:  now,
+		ExpiresAt:  now.Add(time.Minute),
+	})
+	if err != nil {
+		t.Fatalf("failed to encode offer auth request: %v", err)
+	}
+
+	authRequest, err := isr.decodeOfferAuthRequest(threadID)
+	if err != nil {
+		t.Fatalf("failed to decode offer auth request: %v", err)
+	}
+	if authRequest.ID != "request" || authRequest.UserID != "user" || authRequest.SchemaType != "KYCAgeCredential" ||
+		!authRequest.CreatedAt.Equal(now) || !authRequest.ExpiresAt.Equal(now.Add(time.Minute)) {
+		t.Fatalf("unexpected offer auth request %+v", authRequest)
+	}
+
+	payload, mac, _ := strings.Cut(threadID, ".")
+
+	otherThreadID, err := newTestOfferAuthIssuer(t, "other").encodeOfferAuthRequest(authRequest)
+	if err != nil {
+		t.Fatalf("failed to encode offer auth request: %v", err)
+	}
+
+	authRequest.UserID = "other"
+	changedThreadID, err := isr.encodeOfferAuthRequest(authRequest)
+	if err != nil {
+		t.Fatalf("failed to encode offer auth request: %v", err)
+	}
+	changedPayload, _, _ := strings.Cut(changedThreadID, ".")
+
+	cases := []struct {
+		name     string
+		threadID string
+	}{
+		{"uuid", "5f4d2b1c-8a43-4f6e-9a57-7c0ad2d0e5b1"},
+		{"request of the other issuer", otherThreadID},
+		{"payload is changed", changedPayload + "." + mac},
+		{"mac is changed", payload + "." + strings.Repeat("A", len(mac))},
+		{"mac is missing", payload + "."},
+		{"mac is malformed", payload + ".!"},
+		{"payload is not json", "bm90IGpzb24." + base64.RawURLEncoding.EncodeToString(isr.offerAuthRequestMAC("bm90IGpzb24"))},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := isr.decodeOfferAuthRequest(tc.threadID)
+			if !errors.Is(err, ErrOfferAuthRequestIsNotExist) {
+				t.Fatalf("got error %v, want %v", err, ErrOfferAuthRequestIsNotExist)
+			}
+		})
+	}
+}
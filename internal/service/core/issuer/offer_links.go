@@ -0,0 +1,61 @@
+package issuer
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/pkg/errors"
+
+	"github.com/rarimo/issuer/internal/service/core/qrcode"
+)
+
+const (
+	iden3commDeepLinkScheme = "iden3comm://"
+	requestURIQueryParam    = "request_uri"
+)
+
+// GetClaimOfferLinks returns the links of the hosted offer message, the deep link refers to the message
+// by the request_uri, so it stays short enough for the QR code whatever the offer size is.
+func (isr *issuer) GetClaimOfferLinks(offerID string) *ClaimOfferLinks {
+	messageURL := fmt.Sprint(isr.baseURL, ClaimOfferMessagePath, offerID)
+	qrCodeURL := fmt.Sprint(messageURL, "/qr?format=")
+
+	return &ClaimOfferLinks{
+		MessageURL: messageURL,
+		DeepLink:   fmt.Sprint(iden3commDeepLinkScheme, "?", url.Values{requestURIQueryParam: {messageURL}}.Encode()),
+		QRCodePNG:  fmt.Sprint(qrCodeURL, qrcode.FormatPNG),
+		QRCodeSVG:  fmt.Sprint(qrCodeURL, qrcode.FormatSVG),
+	}
+}
+
+// GetClaimOfferMessage returns the stored plain offer message, the offers created before
+// the messages were stored aren't served, as well as the expired and canceled ones.
+func (isr *issuer) GetClaimOfferMessage(offerID string) ([]byte, error) {
+	claimOffer, err := isr.claimsOffersQ.Get(offerID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get claim offer")
+	}
+	if claimOffer == nil || claimOffer.Message == nil {
+		return nil, ErrClaimOfferIsNotExist
+	}
+
+	if err := isr.checkClaimOfferIsActive(claimOffer); err != nil {
+		return nil, err
+	}
+
+	return claimOffer.Message, nil
+}
+
+// RenderClaimOfferQRCode renders the QR code of the offer deep link.
+func (isr *issuer) RenderClaimOfferQRCode(offerID, format string, size int) ([]byte, error) {
+	if _, err := isr.GetClaimOfferMessage(offerID); err != nil {
+		return nil, err
+	}
+
+	image, err := qrcode.Render(isr.GetClaimOfferLinks(offerID).DeepLink, format, size)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to render qr code")
+	}
+
+	return image, nil
+}
@@ -0,0 +1,217 @@
+package issuer
+
+import (
+	"context"
+	"time"
+
+	"github.com/iden3/iden3comm/protocol"
+	"github.com/pkg/errors"
+	"gitlab.com/distributed_lab/kit/pgdb"
+
+	"github.com/rarimo/issuer/internal/data"
+	"github.com/rarimo/issuer/internal/service/api/requests"
+	"github.com/rarimo/issuer/internal/service/core/audit"
+	"github.com/rarimo/issuer/internal/service/core/webhooks"
+)
+
+// CancelClaimOffer cancels the offer that isn't received yet, so its thread ID can't be redeemed anymore.
+func (isr *issuer) CancelClaimOffer(offerID string) error {
+	claimOffer, err := isr.claimsOffersQ.Get(offerID)
+	if err != nil {
+		return errors.Wrap(err, "failed to get claim offer")
+	}
+	if claimOffer == nil {
+		return ErrClaimOfferIsNotExist
+	}
+
+	switch {
+	case claimOffer.IsReceived:
+		return ErrClaimOfferIsAlreadyReceived
+	case claimOffer.CanceledAt != nil:
+		return ErrClaimOfferIsCanceled
+	}
+
+	isCanceled, err := isr.claimsOffersQ.Cancel(claimOffer.ID, time.Now().UTC())
+	if err != nil {
+		return errors.Wrap(err, "failed to cancel claim offer")
+	}
+	if !isCanceled {
+		// the offer was redeemed by the concurrent callback after it had been checked
+		return ErrClaimOfferIsAlreadyReceived
+	}
+
+	return nil
+}
+
+// checkClaimOfferIsActive checks that the offer is neither canceled nor expired,
+// the offers don't expire if the TTL is zero.
+func (isr *issuer) checkClaimOfferIsActive(claimOffer *data.ClaimOffer) error {
+	if claimOffer.CanceledAt != nil {
+		return ErrClaimOfferIsCanceled
+	}
+
+	if isr.claimOfferTTL > 0 && time.Now().UTC().After(claimOffer.CreatedAt.Add(isr.claimOfferTTL)) {
+		return ErrClaimOfferIsExpired
+	}
+
+	return nil
+}
+
+// markOfferCredentialReceived marks the offered credential as received along with storing the offer received
+// event, false is returned if the credential isn't offered or is already received. The offer is locked first,
+// so the delivery and the concurrent cancel or delivery of the same offer are serialized, ErrClaimOfferIsCanceled
+// is returned if the offer is canceled or expired by the time it is locked. The offer is received only when all
+// its credentials are, so it is marked after the credential in the same transaction. The token of the callback
+// request is spent in the same transaction, so it isn't spent if the delivery fails, the callback is nil if
+// the token is already spent.
+func (isr *issuer) markOfferCredentialReceived(
+	ctx context.Context,
+	claimOffer *data.ClaimOffer,
+	claim *data.Claim,
+	callback *requests.OfferCallbackRequest,
+) (bool, error) {
+	var isMarked bool
+
+	var createdAfter time.Time
+	if isr.claimOfferTTL > 0 {
+		createdAfter = time.Now().UTC().Add(-isr.claimOfferTTL)
+	}
+
+	db := isr.State.DB.New()
+	err := db.Transaction(func() (err error) {
+		isActive, err := db.ClaimsOffersQ().LockActive(claimOffer.ID, createdAfter)
+		if err != nil {
+			return errors.Wrap(err, "failed to lock claim offer")
+		}
+		if !isActive {
+			return ErrClaimOfferIsCanceled
+		}
+
+		isMarked, err = db.ClaimOfferCredentialsQ().MarkReceived(claimOffer.ID, claim.ID, time.Now().UTC())
+		if err != nil {
+			return errors.Wrap(err, "failed to mark claim offer credential as received")
+		}
+		if !isMarked {
+			return nil
+		}
+
+		// the offer stays active if any of its credentials is pending
+		_, err = db.ClaimsOffersQ().MarkReceived(claimOffer.ID)
+		if err != nil {
+			return errors.Wrap(err, "failed to mark claim offer as received")
+		}
+
+		if callback != nil {
+			err = isr.markTokenUsed(db, callback.Token, callback.FetchMessage.From)
+			if err != nil {
+				return errors.Wrap(err, "failed to spend callback request token")
+			}
+		}
+
+		err = isr.webhooks.Enqueue(
+			db.WebhookEventsQ(), webhooks.EventOfferReceived, webhooks.NewOfferReceivedData(claimOffer, claim),
+		)
+		if err != nil {
+			return errors.Wrap(err, "failed to enqueue offer received event")
+		}
+
+		err = db.AuditEventsQ().Insert(
+			audit.NewEvent(ctx, audit.EventOfferReceived, audit.NewOfferSubject(claimOffer, claim)),
+		)
+		if err != nil {
+			return errors.Wrap(err, "failed to insert offer received audit event")
+		}
+
+		return nil
+	})
+	if err != nil {
+		return false, errors.Wrap(err, "failed to execute db transaction")
+	}
+
+	return isMarked, nil
+}
+
+func findOfferCredential(credentials []data.ClaimOfferCredential, claimID string) *data.ClaimOfferCredential {
+	for i := range credentials {
+		if credentials[i].ClaimID == claimID {
+			return &credentials[i]
+		}
+	}
+
+	return nil
+}
+
+// CreateClaimsBundleOffer offers the chosen claims of the user in the single offer, if none is chosen
+// all the claims of the user that are neither received by any offer, revoked nor expired are offered.
+func (isr *issuer) CreateClaimsBundleOffer(
+	ctx context.Context,
+	request *requests.ClaimsBundleOfferRequest,
+) (*protocol.CredentialsOfferMessage, error) {
+	offeredClaims, err := isr.selectBundleOfferClaims(request)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(request.ClaimIDs) == 0 {
+		// the pending claims that require the holder authorization are left for their authorized offers
+		offeredClaims = isr.filterOfferAuthIsNotRequired(offeredClaims)
+	} else if err := isr.checkOfferAuthIsNotRequired(offeredClaims...); err != nil {
+		return nil, err
+	}
+
+	if len(offeredClaims) == 0 {
+		return nil, ErrClaimIsNotExist
+	}
+
+	return isr.offerClaims(ctx, request.UserDID, offeredClaims...)
+}
+
+func (isr *issuer) selectBundleOfferClaims(request *requests.ClaimsBundleOfferRequest) ([]*data.Claim, error) {
+	claimsQ := isr.State.DB.ClaimsQ()
+
+	if len(request.ClaimIDs) == 0 {
+		pendingClaims, err := claimsQ.
+			FilterByUserID(request.UserDID.ID.String()).
+			FilterByRevoked(false).
+			FilterByExpired(false).
+			FilterByReceived(false).
+			Page(pgdb.OffsetPageParams{
+				Limit: requests.MaxBundleOfferClaims,
+				Order: pgdb.OrderTypeAsc,
+			}).
+			Select()
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to select pending claims")
+		}
+
+		offeredClaims := make([]*data.Claim, 0, len(pendingClaims))
+		for i := range pendingClaims {
+			offeredClaims = append(offeredClaims, &pendingClaims[i])
+		}
+
+		return offeredClaims, nil
+	}
+
+	chosenClaims, err := claimsQ.FilterByID(request.ClaimIDs...).Select()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to select chosen claims")
+	}
+
+	claimsByID := make(map[string]*data.Claim, len(chosenClaims))
+	for i := range chosenClaims {
+		claimsByID[chosenClaims[i].ID] = &chosenClaims[i]
+	}
+
+	// the claims are offered in the order they were chosen
+	offeredClaims := make([]*data.Claim, 0, len(request.ClaimIDs))
+	for _, claimID := range request.ClaimIDs {
+		claim, ok := claimsByID[claimID]
+		if !ok {
+			return nil, ErrClaimIsNotExist
+		}
+
+		offeredClaims = append(offeredClaims, claim)
+	}
+
+	return offeredClaims, nil
+}
@@ -0,0 +1,95 @@
+package issuer
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"gitlab.com/distributed_lab/logan/v3"
+	"gitlab.com/distributed_lab/running"
+
+	"github.com/rarimo/issuer/internal/config"
+	"github.com/rarimo/issuer/internal/data"
+)
+
+const offersCleanerRunnerName = "offers_cleaner"
+
+// offersCleaner deletes the stale offers: the ones that expired or were canceled longer than the retention
+// ago, along with the expired offer authorization requests. The offers any credential of which was received
+// are kept, they record the delivery of the claims, so the delivered claims aren't offered again in bundles.
+type offersCleaner struct {
+	log                *logan.Entry
+	claimsOffersQ      data.ClaimsOffersQ
+	offerAuthRequestsQ data.OfferAuthRequestsQ
+	period             time.Duration
+	ttl                time.Duration
+	retention          time.Duration
+}
+
+func newOffersCleaner(
+	log *logan.Entry,
+	cfg *config.ClaimOffersConfig,
+	claimsOffersQ data.ClaimsOffersQ,
+	offerAuthRequestsQ data.OfferAuthRequestsQ,
+) *offersCleaner {
+	return &offersCleaner{
+		log:                log,
+		claimsOffersQ:      claimsOffersQ,
+		offerAuthRequestsQ: offerAuthRequestsQ,
+		period:             cfg.CleanupPeriod,
+		ttl:                cfg.TTL,
+		retention:          cfg.Retention,
+	}
+}
+
+func (c *offersCleaner) Run(ctx context.Context) {
+	ticker := time.NewTicker(c.period)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			running.UntilSuccess(ctx, c.log, offersCleanerRunnerName,
+				func(ctx context.Context) (bool, error) {
+					return true, c.clean()
+				}, c.period, c.period,
+			)
+
+			ticker.Reset(c.period)
+		}
+	}
+}
+
+func (c *offersCleaner) clean() error {
+	staleBefore := time.Now().UTC().Add(-c.retention)
+
+	canceled, err := c.claimsOffersQ.DeleteCanceledBefore(staleBefore)
+	if err != nil {
+		return errors.Wrap(err, "failed to delete canceled offers")
+	}
+
+	var expired int64
+	// the offers never expire with the zero ttl, so only the canceled ones are stale
+	if c.ttl > 0 {
+		expired, err = c.claimsOffersQ.DeleteCreatedBefore(staleBefore.Add(-c.ttl))
+		if err != nil {
+			return errors.Wrap(err, "failed to delete expired offers")
+		}
+	}
+
+	authRequests, err := c.offerAuthRequestsQ.DeleteExpiredBefore(staleBefore)
+	if err != nil {
+		return errors.Wrap(err, "failed to delete expired offer auth requests")
+	}
+
+	if canceled > 0 || expired > 0 || authRequests > 0 {
+		c.log.WithFields(logan.F{
+			"canceled":      canceled,
+			"expired":       expired,
+			"auth_requests": authRequests,
+		}).Info("Stale offers are deleted")
+	}
+
+	return nil
+}
@@ -0,0 +1,158 @@
+package issuer
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+
+	"github.com/iden3/go-schema-processor/verifiable"
+	"github.com/iden3/iden3comm"
+	"github.com/iden3/iden3comm/packers"
+	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/pkg/errors"
+
+	"github.com/rarimo/issuer/internal/config"
+	"github.com/rarimo/issuer/internal/service/core/didresolver"
+)
+
+const (
+	didContext             = "https://www.w3.org/ns/did/v1"
+	secp256k1Context       = "https://w3id.org/security/suites/secp256k1-2019/v1"
+	secp256k1KeyType       = "EcdsaSecp256k1VerificationKey2019"
+	secp256k1CurveName     = "secp256k1"
+	secp256k1CoordinateLen = 32
+)
+
+// messagePacker packs the iden3comm messages of the issuer, the signed ones are signed with the issuer
+// secp256k1 key published in the issuer DID document, the encrypted ones are encrypted to the recipient
+// key agreement key from the recipient DID document.
+type messagePacker struct {
+	packageManager *iden3comm.PackageManager
+	resolver       *didresolver.Resolver
+	didDocument    *verifiable.DIDDocument
+	signingKeyID   string
+}
+
+func newMessagePacker(did string, signingKey *ecdsa.PrivateKey, cfg *config.Iden3CommConfig) (*messagePacker, error) {
+	didDocument := newDIDDocument(did, &signingKey.PublicKey, cfg.SigningKeyID)
+	signingKeyID := didDocument.VerificationMethod[0].ID
+	resolver := didresolver.New(cfg.ResolverURL, cfg.ResolverTimeout)
+
+	packageManager := iden3comm.NewPackageManager()
+	err := packageManager.RegisterPackers(
+		&packers.PlainMessagePacker{},
+		packers.NewJWSPacker(resolver.Resolve, func(kid string) (crypto.Signer, error) {
+			if kid != signingKeyID {
+				return nil, errors.Errorf("unknown signing key %s", kid)
+			}
+			return signingKey, nil
+		}),
+		// the issuer only encrypts the messages to the recipients, so it never resolves its own keys
+		packers.NewAnoncryptPacker(func(string) (interface{}, error) {
+			return nil, errors.New("issuer doesn't decrypt messages")
+		}),
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to register packers")
+	}
+
+	return &messagePacker{
+		packageManager: packageManager,
+		resolver:       resolver,
+		didDocument:    didDocument,
+		signingKeyID:   signingKeyID,
+	}, nil
+}
+
+// newDIDDocument returns the issuer DID document with the verification method of the signing key,
+// the signed messages are verified with it by the kid.
+func newDIDDocument(did string, signingKey *ecdsa.PublicKey, signingKeyID string) *verifiable.DIDDocument {
+	method := verifiable.CommonVerificationMethod{
+		ID:         fmt.Sprintf("%s#%s", did, signingKeyID),
+		Type:       secp256k1KeyType,
+		Controller: did,
+		PublicKeyJwk: map[string]interface{}{
+			"kty": "EC",
+			"crv": secp256k1CurveName,
+			"x":   encodeCoordinate(signingKey.X.Bytes()),
+			"y":   encodeCoordinate(signingKey.Y.Bytes()),
+		},
+	}
+
+	return &verifiable.DIDDocument{
+		Context:            []string{didContext, secp256k1Context},
+		ID:                 did,
+		VerificationMethod: []verifiable.CommonVerificationMethod{method},
+		// the method is embedded, the authentication referencing it can't be marshaled
+		Authentication: []verifiable.Authentication{{CommonVerificationMethod: method}},
+	}
+}
+
+func encodeCoordinate(coordinate []byte) string {
+	padded := make([]byte, secp256k1CoordinateLen)
+	copy(padded[secp256k1CoordinateLen-len(coordinate):], coordinate)
+	return base64.RawURLEncoding.EncodeToString(padded)
+}
+
+// GetDIDDocument returns the issuer DID document with the key the signed messages are verified with.
+func (isr *issuer) GetDIDDocument() *verifiable.DIDDocument {
+	return isr.packer.didDocument
+}
+
+// NewPackerParams returns the params of the envelope of the media type, they are resolved before the
+// message is handled, so the recipient without the key agreement key doesn't lose the response. The
+// recipient is the DID the message is encrypted to, it is required only for the encrypted message.
+func (isr *issuer) NewPackerParams(mediaType iden3comm.MediaType, recipient string) (iden3comm.PackerParams, error) {
+	switch mediaType {
+	case packers.MediaTypePlainMessage:
+		return packers.PlainPackerParams{}, nil
+	case packers.MediaTypeSignedMessage:
+		return packers.SigningParams{
+			Alg:    jwa.ES256K,
+			KID:    isr.packer.signingKeyID,
+			DIDDoc: isr.packer.didDocument,
+		}, nil
+	case packers.MediaTypeEncryptedMessage:
+		if recipient == "" {
+			return nil, ErrRecipientKeyIsNotFound
+		}
+
+		document, err := isr.packer.resolver.Resolve(recipient)
+		if errors.Is(err, didresolver.ErrDIDIsNotFound) {
+			return nil, errors.Wrap(ErrRecipientKeyIsNotFound, err.Error())
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to resolve recipient did document")
+		}
+
+		recipientKey, err := didresolver.KeyAgreementKey(document)
+		if err != nil {
+			return nil, errors.Wrap(ErrRecipientKeyIsNotFound, err.Error())
+		}
+
+		return packers.AnoncryptPackerParams{RecipientKey: recipientKey}, nil
+	default:
+		return nil, ErrMediaTypeIsNotSupported
+	}
+}
+
+// PackMessage packs the iden3comm message to the envelope of the media type with the params of it.
+func (isr *issuer) PackMessage(
+	mediaType iden3comm.MediaType,
+	message interface{},
+	params iden3comm.PackerParams,
+) ([]byte, error) {
+	payload, err := json.Marshal(message)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal message")
+	}
+
+	envelope, err := isr.packer.packageManager.Pack(mediaType, payload, params)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to pack message")
+	}
+
+	return envelope, nil
+}
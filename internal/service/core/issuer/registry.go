@@ -0,0 +1,216 @@
+package issuer
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"gitlab.com/distributed_lab/logan/v3"
+	"gitlab.com/distributed_lab/running"
+
+	"github.com/rarimo/issuer/internal/config"
+	"github.com/rarimo/issuer/internal/data"
+	"github.com/rarimo/issuer/internal/data/pg"
+	"github.com/rarimo/issuer/internal/service/core/claims/schemas"
+	identityPkg "github.com/rarimo/issuer/internal/service/core/identity"
+	"github.com/rarimo/issuer/internal/service/core/identity/state_publisher/contracts"
+	"github.com/rarimo/issuer/internal/service/core/jwzauth"
+	"github.com/rarimo/issuer/internal/service/core/webhooks"
+)
+
+const registryReloaderRunnerName = "identities_reloader"
+
+// sharedServices are the services the issuers of all the hosted identities use.
+type sharedServices struct {
+	schemaBuilder *schemas.Builder
+	tokenVerifier *jwzauth.Verifier
+	webhooks      *webhooks.Outbox
+}
+
+// Registry hosts the issuers of all the identities. The identities created by the other instances or the CLI
+// are hosted once the registry is reloaded, so every instance serves every identity after the reload period.
+type Registry struct {
+	log         *logan.Entry
+	cfg         config.Config
+	identitiesQ data.IdentitiesQ
+	shared      *sharedServices
+	period      time.Duration
+	// ctx is the lifetime of the hosted issuers, the ones hosted on the request outlive it
+	ctx context.Context
+
+	mu      sync.RWMutex
+	byID    map[uint64]Issuer
+	byDID   map[string]Issuer
+	hosting sync.Mutex
+}
+
+// NewRegistry hosts all the existing identities and runs the jobs shared by their issuers.
+func NewRegistry(ctx context.Context, cfg config.Config) (*Registry, error) {
+	schemaBuilder, err := schemas.NewBuilder(ctx, schemas.Config{
+		Log:            cfg.Log().WithField("service", "schemas"),
+		DB:             cfg.DB(),
+		SchemasBaseURL: cfg.Issuer().SchemasBaseURL,
+		LocalDir:       cfg.Issuer().SchemasLocalDir,
+		Offline:        cfg.Issuer().SchemasOffline,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create new schema builder")
+	}
+
+	authVerificationKeys, err := readAuthVerificationKeys(cfg.Identity().CircuitsPath)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read auth verification keys")
+	}
+
+	stateContract, err := contracts.NewStateStoreCaller(*cfg.EthClient().StateStorageContract, cfg.EthClient().EthClient)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create new state store contract caller")
+	}
+
+	webhooksOutbox, err := webhooks.NewOutbox(cfg.Webhooks())
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create webhooks outbox")
+	}
+
+	registry := &Registry{
+		log:         cfg.Log().WithField("service", registryReloaderRunnerName),
+		cfg:         cfg,
+		identitiesQ: pg.NewIdentitiesQ(cfg.DB()),
+		shared: &sharedServices{
+			schemaBuilder: schemaBuilder,
+			tokenVerifier: jwzauth.NewVerifier(cfg.JWZ(), authVerificationKeys, stateContract),
+			webhooks:      webhooksOutbox,
+		},
+		period: cfg.Issuer().IdentitiesReloadPeriod,
+		ctx:    ctx,
+		byID:   make(map[uint64]Issuer),
+		byDID:  make(map[string]Issuer),
+	}
+
+	if err := registry.reload(); err != nil {
+		return nil, errors.Wrap(err, "failed to host identities")
+	}
+
+	if !cfg.JWZ().CleanupDisabled {
+		cleanerLog := cfg.Log().WithField("service", jwzauth.CleanerRunnerName)
+		go jwzauth.NewCleaner(cleanerLog, cfg.JWZ(), pg.NewUsedTokensQ(cfg.DB())).Run(ctx)
+	}
+
+	if !cfg.Webhooks().Disabled && len(cfg.Webhooks().Endpoints) > 0 {
+		senderLog := cfg.Log().WithField("service", webhooks.SenderRunnerName)
+		go webhooks.NewSender(senderLog, cfg.Webhooks(), pg.NewWebhookEventsQ(cfg.DB())).Run(ctx)
+	}
+
+	return registry, nil
+}
+
+// Run hosts the identities created since the previous reload every reload period.
+func (r *Registry) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.period)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			running.UntilSuccess(ctx, r.log, registryReloaderRunnerName,
+				func(ctx context.Context) (bool, error) {
+					return true, r.reload()
+				}, r.period, r.period,
+			)
+
+			ticker.Reset(r.period)
+		}
+	}
+}
+
+// Default returns the issuer of the default identity, it serves the URLs that aren't scoped by the DID.
+func (r *Registry) Default() Issuer {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.byID[data.DefaultIdentityID]
+}
+
+// Get returns the issuer of the hosted identity with the DID, nil is returned if it isn't hosted.
+func (r *Registry) Get(did string) Issuer {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.byDID[did]
+}
+
+// List returns all the identities including the ones that aren't hosted by the instance yet.
+func (r *Registry) List() ([]data.Identity, error) {
+	identities, err := r.identitiesQ.New().Select()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to select identities")
+	}
+
+	return identities, nil
+}
+
+// Create creates the identity named the name with the keys of its own and hosts its issuer,
+// ErrIdentityIsAlreadyExist is returned if the name is taken.
+func (r *Registry) Create(ctx context.Context, name string) (*data.Identity, error) {
+	model, err := identityPkg.Create(ctx, r.cfg, name)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create identity")
+	}
+
+	r.hosting.Lock()
+	defer r.hosting.Unlock()
+
+	if _, err := r.host(model); err != nil {
+		return nil, errors.Wrap(err, "failed to host identity")
+	}
+
+	return model, nil
+}
+
+// reload hosts the identities that aren't hosted yet.
+func (r *Registry) reload() error {
+	r.hosting.Lock()
+	defer r.hosting.Unlock()
+
+	identities, err := r.identitiesQ.New().Select()
+	if err != nil {
+		return errors.Wrap(err, "failed to select identities")
+	}
+
+	for i := range identities {
+		if _, err := r.host(&identities[i]); err != nil {
+			return errors.Wrapf(err, "failed to host identity %s", identities[i].Name)
+		}
+	}
+
+	return nil
+}
+
+// host hosts the issuer of the identity if it isn't hosted yet, the caller holds the hosting lock.
+func (r *Registry) host(model *data.Identity) (Issuer, error) {
+	r.mu.RLock()
+	hosted, ok := r.byID[model.ID]
+	r.mu.RUnlock()
+	if ok {
+		return hosted, nil
+	}
+
+	isr, err := newIssuer(r.ctx, r.cfg, r.shared, model)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	r.byID[model.ID] = isr
+	r.byDID[isr.GetDID()] = isr
+	r.mu.Unlock()
+
+	r.log.WithFields(logan.F{
+		"identity": model.Name,
+		"did":      isr.GetDID(),
+	}).Info("Identity is hosted")
+
+	return isr, nil
+}
@@ -0,0 +1,170 @@
+package issuer
+
+import (
+	"context"
+
+	"github.com/iden3/iden3comm/protocol"
+	"github.com/pkg/errors"
+
+	"github.com/rarimo/issuer/internal/data"
+	"github.com/rarimo/issuer/internal/service/api/requests"
+	"github.com/rarimo/issuer/internal/service/core/audit"
+	"github.com/rarimo/issuer/internal/service/core/claims"
+	"github.com/rarimo/issuer/internal/service/core/claims/schemas"
+	"github.com/rarimo/issuer/internal/service/core/webhooks"
+)
+
+// ClaimReissue is the replacement claim with the revocation of its predecessor and the offer of it.
+type ClaimReissue struct {
+	Claim      *data.Claim
+	Revocation *ClaimRevocation
+	Offer      *protocol.CredentialsOfferMessage
+	OfferLinks *ClaimOfferLinks
+}
+
+// ReissueClaim revokes the claim and issues its replacement with the new credential subject in the
+// single db transaction, so the user always has the valid claim and nothing is changed on failure.
+func (isr *issuer) ReissueClaim(ctx context.Context, req *requests.ReissueClaimRequest) (*ClaimReissue, error) {
+	claim, err := isr.State.DB.ClaimsQ().Get(req.ClaimID.String())
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get claim from db")
+	}
+	if claim == nil {
+		return nil, ErrClaimIsNotExist
+	}
+
+	if err := isr.checkClaimIsRevocable(claim); err != nil {
+		return nil, err
+	}
+
+	if claim.Revoked {
+		return nil, ErrClaimIsAlreadyRevoked
+	}
+
+	// the schema type, the recipient and the updatable flag of the claim don't change,
+	// so the replacement is built before the lock, the revoked flag is checked again after it
+	claimType := claims.ClaimSchemaType(claim.ClaimType)
+	claimData, ok := claims.GetClaimSchema(ctx, claimType)
+	if !ok {
+		return nil, schemas.ErrSchemaIsNotExist
+	}
+	if claimData.IsDeprecated {
+		return nil, schemas.ErrSchemaIsDeprecated
+	}
+
+	credentialSubject, err := req.CredentialSubject(ctx, claimType)
+	if err != nil {
+		return nil, errors.Wrap(schemas.ErrValidationData, err.Error())
+	}
+
+	userDID, err := claimRecipientDID(claim)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get claim recipient did")
+	}
+
+	updatable := claim.CoreClaim.GetFlagUpdatable()
+	if req.Updatable != nil {
+		updatable = *req.Updatable
+	}
+
+	replacement, err := isr.compactClaim(
+		ctx,
+		userDID,
+		schemas.CompactClaimOptions{
+			Expiration: req.Expiration,
+			Updatable:  updatable,
+		},
+		claimType,
+		credentialSubject,
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to compact the replacement claim")
+	}
+	replacement.PreviousClaimID = &claim.ID
+
+	hi, hv, err := replacement.CoreClaim.HiHv()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get claim index and value hash")
+	}
+
+	revocation := newClaimRevocation(ctx, claim.CoreClaim.GetRevocationNonce(), &claim.ID, RevocationDetails{
+		Reason: req.Details.Reason,
+	})
+
+	var offer *protocol.CredentialsOfferMessage
+
+	db := isr.State.DB.New()
+	err = db.Transaction(func() error {
+		trees, err := isr.State.LockTrees(ctx, db)
+		if err != nil {
+			return errors.Wrap(err, "failed to lock identity trees")
+		}
+
+		current, err := db.ClaimsQ().Get(claim.ID)
+		if err != nil {
+			return errors.Wrap(err, "failed to get claim from db")
+		}
+		if current == nil {
+			return ErrClaimIsNotExist
+		}
+		if current.Revoked {
+			return ErrClaimIsAlreadyRevoked
+		}
+
+		err = db.ClaimsQ().MarkRevoked(claim.ID)
+		if err != nil {
+			return errors.Wrap(err, "failed to mark claim as revoked in db")
+		}
+
+		err = isr.revokeNonce(ctx, db, trees, revocation)
+		if err != nil {
+			return errors.Wrap(err, "failed to revoke claim")
+		}
+
+		err = db.ClaimsQ().Insert(replacement)
+		if err != nil {
+			return errors.Wrap(err, "failed to insert replacement claim into db")
+		}
+
+		err = trees.Claims.Add(ctx, hi, hv)
+		if err != nil {
+			return errors.Wrap(err, "failed to add replacement claim to the claims merkle tree")
+		}
+
+		err = isr.webhooks.Enqueue(
+			db.WebhookEventsQ(), webhooks.EventClaimIssued, webhooks.NewClaimIssuedData(replacement),
+		)
+		if err != nil {
+			return errors.Wrap(err, "failed to enqueue claim issued event")
+		}
+
+		err = db.AuditEventsQ().Insert(
+			audit.NewEvent(ctx, audit.EventClaimIssued, audit.NewClaimSubject(replacement)),
+		)
+		if err != nil {
+			return errors.Wrap(err, "failed to insert claim issued audit event")
+		}
+
+		offer, err = isr.insertClaimOffer(ctx, db, userDID, replacement)
+		if err != nil {
+			return errors.Wrap(err, "failed to offer replacement claim")
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to execute db transaction")
+	}
+
+	claimRevocation, err := isr.withRevocationState(ctx, revocation)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get claim revocation")
+	}
+
+	return &ClaimReissue{
+		Claim:      replacement,
+		Revocation: claimRevocation,
+		Offer:      offer,
+		OfferLinks: isr.GetClaimOfferLinks(offer.ThreadID),
+	}, nil
+}
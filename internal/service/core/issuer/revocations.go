@@ -0,0 +1,428 @@
+package issuer
+
+import (
+	"context"
+	"math/big"
+	"time"
+
+	"github.com/iden3/go-merkletree-sql/v2"
+	"github.com/pkg/errors"
+
+	"github.com/rarimo/issuer/internal/data"
+	"github.com/rarimo/issuer/internal/service/core/audit"
+	"github.com/rarimo/issuer/internal/service/core/claims"
+	"github.com/rarimo/issuer/internal/service/core/identity/state"
+	"github.com/rarimo/issuer/internal/service/core/webhooks"
+)
+
+type BulkRevocationStatus string
+
+const (
+	BulkRevocationStatusRevoked        BulkRevocationStatus = "revoked"
+	BulkRevocationStatusAlreadyRevoked BulkRevocationStatus = "already_revoked"
+	BulkRevocationStatusNotFound       BulkRevocationStatus = "not_found"
+)
+
+// BulkRevocation is the bulk revocation item, it addresses the claim
+// by the ClaimID or the revocation nonce if the ClaimID is empty.
+type BulkRevocation struct {
+	ClaimID  string
+	RevNonce uint64
+}
+
+type BulkRevocationResult struct {
+	ClaimID    *string
+	RevNonce   uint64
+	Status     BulkRevocationStatus
+	Revocation *ClaimRevocation
+}
+
+// RevokeClaimByNonce adds the raw revocation nonce to the revocations tree, it is intended for the
+// claims whose records were lost. The claim with the nonce is marked as revoked if it does exist.
+func (isr *issuer) RevokeClaimByNonce(
+	ctx context.Context,
+	revNonce uint64,
+	details RevocationDetails,
+) (*ClaimRevocation, error) {
+	var revocation *data.ClaimRevocation
+
+	if err := isr.checkNonceIsRevocable(revNonce); err != nil {
+		return nil, err
+	}
+
+	db := isr.State.DB.New()
+	err := db.Transaction(func() error {
+		trees, err := isr.State.LockTrees(ctx, db)
+		if err != nil {
+			return errors.Wrap(err, "failed to lock identity trees")
+		}
+
+		claim, err := db.ClaimsQ().GetByRevNonce(revNonce)
+		if err != nil {
+			return errors.Wrap(err, "failed to get claim by revocation nonce")
+		}
+
+		var claimID *string
+		if claim != nil {
+			if err := isr.checkClaimIsRevocable(claim); err != nil {
+				return err
+			}
+
+			claimID = &claim.ID
+			if err = db.ClaimsQ().MarkRevoked(claim.ID); err != nil {
+				return errors.Wrap(err, "failed to mark claim as revoked in db")
+			}
+		}
+
+		revocation = newClaimRevocation(ctx, revNonce, claimID, details)
+
+		return isr.revokeNonce(ctx, db, trees, revocation)
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to execute db transaction")
+	}
+
+	return isr.withRevocationState(ctx, revocation)
+}
+
+// RevokeClaimsBulk revokes the claims in the single db transaction, the items that
+// are not found or already revoked are reported in the results and don't fail the request.
+func (isr *issuer) RevokeClaimsBulk(
+	ctx context.Context,
+	items []BulkRevocation,
+	details RevocationDetails,
+) ([]BulkRevocationResult, error) {
+	if err := isr.checkBulkIsRevocable(items); err != nil {
+		return nil, err
+	}
+
+	var (
+		results       []BulkRevocationResult
+		revokedClaims []*data.Claim
+	)
+	revocations := make([]*data.ClaimRevocation, len(items))
+
+	db := isr.State.DB.New()
+	err := db.Transaction(func() error {
+		trees, err := isr.State.LockTrees(ctx, db)
+		if err != nil {
+			return errors.Wrap(err, "failed to lock identity trees")
+		}
+
+		results, revokedClaims, err = isr.compactBulkRevocations(ctx, db, trees, items)
+		if err != nil {
+			return err
+		}
+
+		for i, result := range results {
+			if result.Status != BulkRevocationStatusRevoked {
+				continue
+			}
+
+			if claim := revokedClaims[i]; claim != nil {
+				if err := db.ClaimsQ().MarkRevoked(claim.ID); err != nil {
+					return errors.Wrap(err, "failed to mark claim as revoked in db")
+				}
+			}
+
+			revocations[i] = newClaimRevocation(ctx, result.RevNonce, result.ClaimID, details)
+			if err := isr.revokeNonce(ctx, db, trees, revocations[i]); err != nil {
+				return errors.Wrapf(err, "failed to revoke item %d", i)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to execute db transaction")
+	}
+
+	// all the items are revoked in the same transaction,
+	// so they are included into the same committed state
+	var revocationState *RevocationState
+	for i, revocation := range revocations {
+		if revocation == nil {
+			continue
+		}
+
+		if revocationState == nil {
+			revocationState, err = isr.getRevocationState(ctx, revocation)
+			if err != nil {
+				return nil, errors.Wrap(err, "failed to get revocation state")
+			}
+		}
+
+		results[i].Revocation = &ClaimRevocation{
+			ClaimRevocation: *revocation,
+			State:           *revocationState,
+		}
+	}
+
+	return results, nil
+}
+
+// compactBulkRevocations compacts the bulk items in their order, the claims to mark as revoked are returned
+// for the items with the revoked status, the nonce addressed by several items is revoked by the first one.
+func (isr *issuer) compactBulkRevocations(
+	ctx context.Context,
+	db data.MasterQ,
+	trees *state.Trees,
+	items []BulkRevocation,
+) ([]BulkRevocationResult, []*data.Claim, error) {
+	results := make([]BulkRevocationResult, len(items))
+	revokedClaims := make([]*data.Claim, len(items))
+	revokedNonces := make(map[uint64]struct{}, len(items))
+
+	for i, item := range items {
+		result, claim, err := isr.compactBulkRevocation(ctx, db, trees, item, revokedNonces)
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "failed to compact bulk revocation item %d", i)
+		}
+
+		results[i] = *result
+		revokedClaims[i] = claim
+	}
+
+	return results, revokedClaims, nil
+}
+
+// compactBulkRevocation resolves the claim and its revocation nonce and checks that
+// the nonce wasn't revoked before or by the previous items of the bulk.
+func (isr *issuer) compactBulkRevocation(
+	ctx context.Context,
+	db data.MasterQ,
+	trees *state.Trees,
+	item BulkRevocation,
+	revokedNonces map[uint64]struct{},
+) (*BulkRevocationResult, *data.Claim, error) {
+	result := &BulkRevocationResult{RevNonce: item.RevNonce}
+
+	var claim *data.Claim
+	if item.ClaimID != "" {
+		var err error
+		claim, err = db.ClaimsQ().Get(item.ClaimID)
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "failed to get claim from db")
+		}
+		if claim == nil {
+			result.ClaimID = &item.ClaimID
+			result.Status = BulkRevocationStatusNotFound
+			return result, nil, nil
+		}
+
+		result.ClaimID = &claim.ID
+		result.RevNonce = claim.CoreClaim.GetRevocationNonce()
+	} else {
+		var err error
+		claim, err = db.ClaimsQ().GetByRevNonce(item.RevNonce)
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "failed to get claim by revocation nonce")
+		}
+		if claim != nil {
+			result.ClaimID = &claim.ID
+		}
+	}
+
+	if claim != nil {
+		if err := isr.checkClaimIsRevocable(claim); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if _, ok := revokedNonces[result.RevNonce]; ok {
+		result.Status = BulkRevocationStatusAlreadyRevoked
+		return result, nil, nil
+	}
+
+	revoked, err := isNonceRevoked(ctx, trees, result.RevNonce)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to check revocation nonce")
+	}
+	if revoked {
+		result.Status = BulkRevocationStatusAlreadyRevoked
+		return result, nil, nil
+	}
+
+	revokedNonces[result.RevNonce] = struct{}{}
+	result.Status = BulkRevocationStatusRevoked
+
+	return result, claim, nil
+}
+
+// checkNonceIsRevocable forbids the revocation of the auth claim nonce, the state transitions are proven with
+// the non-revocation proof of the auth claim, so the issuer couldn't publish any state after it is revoked.
+func (isr *issuer) checkNonceIsRevocable(revNonce uint64) error {
+	if revNonce == isr.AuthClaim.CoreClaim.GetRevocationNonce() {
+		return ErrAuthClaimIsNotRevocable
+	}
+
+	return nil
+}
+
+// checkClaimIsRevocable forbids the revocation of the auth claim of the issuer, see checkNonceIsRevocable.
+func (isr *issuer) checkClaimIsRevocable(claim *data.Claim) error {
+	if claim.ID == isr.AuthClaim.ID || claim.ClaimType == claims.AuthBJJCredentialClaimType {
+		return ErrAuthClaimIsNotRevocable
+	}
+
+	return isr.checkNonceIsRevocable(claim.CoreClaim.GetRevocationNonce())
+}
+
+// checkBulkIsRevocable rejects the bulk that addresses the auth claim before the trees are locked, the claims
+// that are addressed by the nonce of the other claims are checked once they are resolved.
+func (isr *issuer) checkBulkIsRevocable(items []BulkRevocation) error {
+	for _, item := range items {
+		if item.ClaimID == isr.AuthClaim.ID {
+			return ErrAuthClaimIsNotRevocable
+		}
+
+		if item.ClaimID == "" {
+			if err := isr.checkNonceIsRevocable(item.RevNonce); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (isr *issuer) GetRevocation(ctx context.Context, revNonce uint64) (*ClaimRevocation, error) {
+	revocation, err := isr.State.DB.ClaimRevocationsQ().Get(revNonce)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get claim revocation from db")
+	}
+	if revocation == nil {
+		return nil, ErrRevocationIsNotExist
+	}
+
+	return isr.withRevocationState(ctx, revocation)
+}
+
+// revokeNonce stores the revocation and adds its nonce to the revocations tree,
+// it has to be called inside the db transaction the trees were locked within.
+func (isr *issuer) revokeNonce(
+	ctx context.Context,
+	db data.MasterQ,
+	trees *state.Trees,
+	revocation *data.ClaimRevocation,
+) error {
+	// the callers check it to reject the request, it is checked here as well, so no path revokes the auth claim
+	if err := isr.checkNonceIsRevocable(uint64(revocation.RevNonce)); err != nil {
+		return err
+	}
+
+	existing, err := db.ClaimRevocationsQ().Get(uint64(revocation.RevNonce))
+	if err != nil {
+		return errors.Wrap(err, "failed to get claim revocation from db")
+	}
+	if existing != nil {
+		return ErrClaimIsAlreadyRevoked
+	}
+
+	err = db.ClaimRevocationsQ().Insert(revocation)
+	if err != nil {
+		return errors.Wrap(err, "failed to insert claim revocation into db")
+	}
+
+	err = trees.Revocations.Add(
+		ctx, new(big.Int).SetUint64(uint64(revocation.RevNonce)), merkletree.HashZero.BigInt(),
+	)
+	if err != nil {
+		if errors.Is(err, merkletree.ErrEntryIndexAlreadyExists) {
+			return ErrClaimIsAlreadyRevoked
+		}
+		return errors.Wrap(err, "failed to add revocation nonce to the revocations merkle tree")
+	}
+
+	err = isr.webhooks.Enqueue(db.WebhookEventsQ(), webhooks.EventClaimRevoked, webhooks.NewClaimRevokedData(revocation))
+	if err != nil {
+		return errors.Wrap(err, "failed to enqueue claim revoked event")
+	}
+
+	err = db.AuditEventsQ().Insert(
+		audit.NewEvent(ctx, audit.EventClaimRevoked, audit.NewRevocationSubject(revocation)),
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to insert claim revoked audit event")
+	}
+
+	return nil
+}
+
+func (isr *issuer) withRevocationState(ctx context.Context, revocation *data.ClaimRevocation) (*ClaimRevocation, error) {
+	revocationState, err := isr.getRevocationState(ctx, revocation)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get revocation state")
+	}
+
+	return &ClaimRevocation{
+		ClaimRevocation: *revocation,
+		State:           *revocationState,
+	}, nil
+}
+
+// getRevocationState finds the first not failed committed state that was created after the revocation
+// and includes its nonce. The state that was being created at the revocation moment may not include
+// it, so the inclusion is checked with the proof against the state revocations tree root.
+func (isr *issuer) getRevocationState(ctx context.Context, revocation *data.ClaimRevocation) (*RevocationState, error) {
+	revNonce := new(big.Int).SetUint64(uint64(revocation.RevNonce))
+
+	trees, err := isr.State.LoadTrees(ctx, isr.State.DB)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to load identity trees")
+	}
+
+	from := revocation.RevokedAt
+	for {
+		committedStateRaw, err := isr.State.DB.CommittedStatesQ().
+			WhereStatus(data.StatusProcessing, data.StatusCompleted).
+			WhereCreatedAtFrom(from).
+			GetEarliest()
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get committed state")
+		}
+		if committedStateRaw == nil {
+			return &RevocationState{Status: RevocationStatePending}, nil
+		}
+
+		committedState, err := state.CommittedStateFromRaw(committedStateRaw)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to parse committed state")
+		}
+
+		proof, _, err := trees.Revocations.GenerateProof(ctx, revNonce, committedState.RevocationsTreeRoot)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to generate revocation proof")
+		}
+		if !proof.Existence {
+			from = committedStateRaw.CreatedAt.Add(time.Microsecond)
+			continue
+		}
+
+		revocationState := RevocationState{
+			Status:           RevocationStateProcessing,
+			CommittedStateID: &committedStateRaw.ID,
+			TxID:             committedStateRaw.TxID,
+		}
+		if committedStateRaw.Status == data.StatusCompleted {
+			revocationState.Status = RevocationStateCompleted
+		}
+
+		return &revocationState, nil
+	}
+}
+
+func newClaimRevocation(
+	ctx context.Context,
+	revNonce uint64,
+	claimID *string,
+	details RevocationDetails,
+) *data.ClaimRevocation {
+	return &data.ClaimRevocation{
+		RevNonce:  data.RevocationNonce(revNonce),
+		ClaimID:   claimID,
+		Reason:    details.Reason,
+		Operator:  audit.ActorFromCtx(ctx).ID,
+		RevokedAt: time.Now().UTC(),
+	}
+}
@@ -0,0 +1,254 @@
+package issuer
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/google/uuid"
+	core "github.com/iden3/go-iden3-core"
+	"github.com/iden3/go-merkletree-sql/v2"
+	"github.com/iden3/go-merkletree-sql/v2/db/memory"
+
+	"github.com/rarimo/issuer/internal/data"
+	"github.com/rarimo/issuer/internal/service/core/claims"
+	identityPkg "github.com/rarimo/issuer/internal/service/core/identity"
+	"github.com/rarimo/issuer/internal/service/core/identity/state"
+)
+
+const (
+	testAuthClaimNonce = 1000
+	testTreeDepth      = 40
+)
+
+// testClaimsQ serves the claims the bulk revocation resolves, the other methods aren't used by it.
+type testClaimsQ struct {
+	data.ClaimsQ
+	claims []*data.Claim
+}
+
+func (q *testClaimsQ) Get(id string) (*data.Claim, error) {
+	for _, claim := range q.claims {
+		if claim.ID == id {
+			return claim, nil
+		}
+	}
+
+	return nil, nil
+}
+
+func (q *testClaimsQ) GetByRevNonce(revNonce uint64) (*data.Claim, error) {
+	for _, claim := range q.claims {
+		if uint64(claim.RevNonce) == revNonce {
+			return claim, nil
+		}
+	}
+
+	return nil, nil
+}
+
+type testMasterQ struct {
+	data.MasterQ
+	claimsQ *testClaimsQ
+}
+
+func (q *testMasterQ) ClaimsQ() data.ClaimsQ {
+	return q.claimsQ
+}
+
+func newTestClaim(t *testing.T, claimType string, revNonce uint64) *data.Claim {
+	t.Helper()
+
+	schemaHash := core.SchemaHash{1}
+	if claimType == claims.AuthBJJCredentialClaimType {
+		schemaHash = core.AuthSchemaHash
+	}
+
+	coreClaim, err := core.NewClaim(schemaHash, core.WithRevocationNonce(revNonce))
+	if err != nil {
+		t.Fatalf("failed to create core claim: %v", err)
+	}
+
+	return &data.Claim{
+		ID:        uuid.NewString(),
+		ClaimType: claimType,
+		CoreClaim: data.NewCoreClaim(coreClaim),
+		RevNonce:  data.RevocationNonce(revNonce),
+	}
+}
+
+// newTestIssuer returns the issuer that has only the auth claim, so the revocations that are
+// rejected before the db is used can be checked without the db.
+func newTestIssuer(t *testing.T) *issuer {
+	t.Helper()
+
+	return &issuer{
+		Identity: &identityPkg.Identity{
+			AuthClaim: newTestClaim(t, claims.AuthBJJCredentialClaimType, testAuthClaimNonce),
+		},
+	}
+}
+
+func newTestTrees(t *testing.T, revokedNonces ...uint64) *state.Trees {
+	t.Helper()
+
+	ctx := context.Background()
+	revocations, err := merkletree.NewMerkleTree(ctx, memory.NewMemoryStorage(), testTreeDepth)
+	if err != nil {
+		t.Fatalf("failed to create revocations tree: %v", err)
+	}
+
+	for _, revNonce := range revokedNonces {
+		err = revocations.Add(ctx, new(big.Int).SetUint64(revNonce), merkletree.HashZero.BigInt())
+		if err != nil {
+			t.Fatalf("failed to add revocation nonce: %v", err)
+		}
+	}
+
+	return &state.Trees{Revocations: revocations}
+}
+
+func TestRevokeAuthClaimByID(t *testing.T) {
+	isr := newTestIssuer(t)
+
+	_, err := isr.RevokeClaimByID(context.Background(), uuid.MustParse(isr.AuthClaim.ID), RevocationDetails{})
+	if !errors.Is(err, ErrAuthClaimIsNotRevocable) {
+		t.Fatalf("got error %v, want %v", err, ErrAuthClaimIsNotRevocable)
+	}
+
+	// the claim of the auth type is rejected even if it isn't the auth claim of the issuer
+	authTyped := newTestClaim(t, claims.AuthBJJCredentialClaimType, 1)
+	_, err = isr.revokeClaim(context.Background(), authTyped, RevocationDetails{})
+	if !errors.Is(err, ErrAuthClaimIsNotRevocable) {
+		t.Fatalf("got error %v, want %v", err, ErrAuthClaimIsNotRevocable)
+	}
+
+	// the claim that has the auth claim nonce is rejected as well
+	sameNonce := newTestClaim(t, "TestCredential", testAuthClaimNonce)
+	_, err = isr.revokeClaim(context.Background(), sameNonce, RevocationDetails{})
+	if !errors.Is(err, ErrAuthClaimIsNotRevocable) {
+		t.Fatalf("got error %v, want %v", err, ErrAuthClaimIsNotRevocable)
+	}
+
+	_, err = isr.RevokeClaim(context.Background(), &core.ID{}, claims.AuthBJJCredentialClaimType, RevocationDetails{})
+	if !errors.Is(err, ErrAuthClaimIsNotRevocable) {
+		t.Fatalf("got error %v, want %v", err, ErrAuthClaimIsNotRevocable)
+	}
+}
+
+func TestRevokeAuthClaimByNonce(t *testing.T) {
+	isr := newTestIssuer(t)
+
+	_, err := isr.RevokeClaimByNonce(context.Background(), testAuthClaimNonce, RevocationDetails{})
+	if !errors.Is(err, ErrAuthClaimIsNotRevocable) {
+		t.Fatalf("got error %v, want %v", err, ErrAuthClaimIsNotRevocable)
+	}
+
+	// the nonce isn't revoked by any path that stores the revocation
+	err = isr.revokeNonce(context.Background(), nil, nil, &data.ClaimRevocation{RevNonce: testAuthClaimNonce})
+	if !errors.Is(err, ErrAuthClaimIsNotRevocable) {
+		t.Fatalf("got error %v, want %v", err, ErrAuthClaimIsNotRevocable)
+	}
+}
+
+func TestRevokeAuthClaimInBulk(t *testing.T) {
+	isr := newTestIssuer(t)
+	claim := newTestClaim(t, "TestCredential", 1)
+
+	cases := []struct {
+		name  string
+		items []BulkRevocation
+	}{
+		{
+			name:  "by claim id",
+			items: []BulkRevocation{{ClaimID: claim.ID}, {ClaimID: isr.AuthClaim.ID}},
+		},
+		{
+			name:  "by nonce",
+			items: []BulkRevocation{{RevNonce: 1}, {RevNonce: testAuthClaimNonce}},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := isr.RevokeClaimsBulk(context.Background(), tc.items, RevocationDetails{})
+			if !errors.Is(err, ErrAuthClaimIsNotRevocable) {
+				t.Fatalf("got error %v, want %v", err, ErrAuthClaimIsNotRevocable)
+			}
+		})
+	}
+
+	// the claim of the auth type is rejected once it is resolved
+	authTyped := newTestClaim(t, claims.AuthBJJCredentialClaimType, 2)
+	db := &testMasterQ{claimsQ: &testClaimsQ{claims: []*data.Claim{authTyped}}}
+
+	for _, item := range []BulkRevocation{{ClaimID: authTyped.ID}, {RevNonce: 2}} {
+		_, _, err := isr.compactBulkRevocations(context.Background(), db, newTestTrees(t), []BulkRevocation{item})
+		if !errors.Is(err, ErrAuthClaimIsNotRevocable) {
+			t.Fatalf("got error %v, want %v", err, ErrAuthClaimIsNotRevocable)
+		}
+	}
+}
+
+func TestCompactBulkRevocations(t *testing.T) {
+	isr := newTestIssuer(t)
+
+	first := newTestClaim(t, "TestCredential", 1)
+	second := newTestClaim(t, "TestCredential", 2)
+	revoked := newTestClaim(t, "TestCredential", 3)
+	db := &testMasterQ{claimsQ: &testClaimsQ{claims: []*data.Claim{first, second, revoked}}}
+
+	items := []BulkRevocation{
+		{ClaimID: first.ID},
+		// the same claim addressed by the nonce is revoked by the first item
+		{RevNonce: 1},
+		{ClaimID: first.ID},
+		{RevNonce: 2},
+		{ClaimID: second.ID},
+		// the nonce of the revoked claim is in the revocations tree
+		{ClaimID: revoked.ID},
+		{ClaimID: uuid.NewString()},
+		// the nonce of the lost claim is revoked without the claim
+		{RevNonce: 4},
+		{RevNonce: 4},
+	}
+
+	results, revokedClaims, err := isr.compactBulkRevocations(context.Background(), db, newTestTrees(t, 3), items)
+	if err != nil {
+		t.Fatalf("failed to compact bulk revocations: %v", err)
+	}
+
+	expected := []struct {
+		status   BulkRevocationStatus
+		revNonce uint64
+		claim    *data.Claim
+	}{
+		{BulkRevocationStatusRevoked, 1, first},
+		{BulkRevocationStatusAlreadyRevoked, 1, nil},
+		{BulkRevocationStatusAlreadyRevoked, 1, nil},
+		{BulkRevocationStatusRevoked, 2, second},
+		{BulkRevocationStatusAlreadyRevoked, 2, nil},
+		{BulkRevocationStatusAlreadyRevoked, 3, nil},
+		{BulkRevocationStatusNotFound, 0, nil},
+		{BulkRevocationStatusRevoked, 4, nil},
+		{BulkRevocationStatusAlreadyRevoked, 4, nil},
+	}
+
+	for i, want := range expected {
+		if results[i].Status != want.status || results[i].RevNonce != want.revNonce {
+			t.Fatalf("item %d: got %s of nonce %d, want %s of nonce %d",
+				i, results[i].Status, results[i].RevNonce, want.status, want.revNonce)
+		}
+		if revokedClaims[i] != want.claim {
+			t.Fatalf("item %d: got claim %v to mark as revoked, want %v", i, revokedClaims[i], want.claim)
+		}
+	}
+
+	if results[1].ClaimID == nil || *results[1].ClaimID != first.ID {
+		t.Fatal("claim addressed by the nonce isn't reported")
+	}
+	if results[7].ClaimID != nil {
+		t.Fatal("lost claim is reported")
+	}
+}
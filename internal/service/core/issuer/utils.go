@@ -1,15 +1,18 @@
 package issuer
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 
+	core "github.com/iden3/go-iden3-core"
 	"github.com/iden3/go-jwz"
 	"github.com/pkg/errors"
 
 	"github.com/rarimo/issuer/internal/data"
 	"github.com/rarimo/issuer/internal/service/core/claims"
 	"github.com/rarimo/issuer/internal/service/core/identity"
+	"github.com/rarimo/issuer/internal/service/core/jwzauth"
 )
 
 func (isr *issuer) generateProofs(ctx context.Context, claim *data.Claim) (err error) {
@@ -50,22 +53,63 @@ func (isr *issuer) generateProofs(ctx context.Context, claim *data.Claim) (err e
 	return nil
 }
 
-func (isr *issuer) checkClaimRetriever(claim *data.Claim, claimRetriever string, token *jwz.Token) (bool, error) {
-	claimRecipientID, err := claim.CoreClaim.GetID()
+// verifyToken verifies that the JWZ token is proven by the sender, it is marked as used
+// by the markTokenUsed when the action the token authorizes is done.
+func (isr *issuer) verifyToken(ctx context.Context, token *jwz.Token, sender *core.ID) error {
+	err := isr.tokenVerifier.Verify(ctx, token, sender)
+	switch {
+	case errors.Is(err, jwzauth.ErrTokenIsInvalid):
+		return errors.Wrap(ErrProofVerifyFailed, err.Error())
+	case err != nil:
+		return errors.Wrap(err, "failed to verify token")
+	}
+
+	return nil
+}
+
+// markTokenUsed marks the verified token of the message sender as used in the transaction of the action
+// it authorizes after the checks of the action pass, so the token is spent only if the action is done.
+func (isr *issuer) markTokenUsed(db data.MasterQ, token *jwz.Token, from string) error {
+	senderDID, err := core.ParseDID(from)
 	if err != nil {
-		return false, errors.Wrap(err, "failed to get claim recipient identifier")
+		return errors.Wrap(err, "failed to parse sender did")
 	}
 
-	if claimRecipientID.String() != claimRetriever {
-		return false, nil
+	err = isr.tokenVerifier.MarkUsed(db.UsedTokensQ(), token, &senderDID.ID)
+	switch {
+	case errors.Is(err, jwzauth.ErrTokenIsUsed):
+		return ErrTokenIsReplayed
+	case err != nil:
+		return errors.Wrap(err, "failed to mark token as used")
 	}
 
-	isZKPValid, err := token.Verify(isr.authVerificationKey)
-	if err != nil {
-		return false, errors.Wrap(ErrProofVerifyFailed, err.Error())
+	return nil
+}
+
+// isSameIndexData checks that the claims have the same index except the version,
+// that is stored in the last bytes of the index header.
+func isSameIndexData(claim, updated *core.Claim) bool {
+	claimIndex, _ := claim.RawSlots()
+	updatedIndex, _ := updated.RawSlots()
+
+	if !bytes.Equal(claimIndex[0][:versionOffset], updatedIndex[0][:versionOffset]) {
+		return false
 	}
 
-	return isZKPValid, nil
+	for i := 1; i < len(claimIndex); i++ {
+		if claimIndex[i] != updatedIndex[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// hasIndexSlotB reports whether the claim has the index slot B set, it is empty
+// only for the claims issued before the revocation nonce was put to the index.
+func hasIndexSlotB(claim *core.Claim) bool {
+	index, _ := claim.RawSlots()
+	return index[3] != (core.ElemBytes{})
 }
 
 func strptr(str string) *string {
@@ -0,0 +1,64 @@
+package jwzauth
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"gitlab.com/distributed_lab/logan/v3"
+	"gitlab.com/distributed_lab/running"
+
+	"github.com/rarimo/issuer/internal/config"
+	"github.com/rarimo/issuer/internal/data"
+)
+
+const CleanerRunnerName = "used_tokens_cleaner"
+
+// Cleaner deletes the tokens that were used longer than the TTL ago.
+type Cleaner struct {
+	log         *logan.Entry
+	usedTokensQ data.UsedTokensQ
+	period      time.Duration
+	ttl         time.Duration
+}
+
+func NewCleaner(log *logan.Entry, cfg *config.JWZConfig, usedTokensQ data.UsedTokensQ) *Cleaner {
+	return &Cleaner{
+		log:         log,
+		usedTokensQ: usedTokensQ,
+		period:      cfg.CleanupPeriod,
+		ttl:         cfg.UsedTokensTTL,
+	}
+}
+
+func (c *Cleaner) Run(ctx context.Context) {
+	ticker := time.NewTicker(c.period)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			running.UntilSuccess(ctx, c.log, CleanerRunnerName,
+				func(ctx context.Context) (bool, error) {
+					return true, c.clean()
+				}, c.period, c.period,
+			)
+
+			ticker.Reset(c.period)
+		}
+	}
+}
+
+func (c *Cleaner) clean() error {
+	deleted, err := c.usedTokensQ.DeleteCreatedBefore(time.Now().UTC().Add(-c.ttl))
+	if err != nil {
+		return errors.Wrap(err, "failed to delete expired used tokens")
+	}
+
+	if deleted > 0 {
+		c.log.WithField("deleted", deleted).Info("Expired used tokens are deleted")
+	}
+
+	return nil
+}
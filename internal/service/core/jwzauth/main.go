@@ -0,0 +1,217 @@
+package jwzauth
+
+import (
+	"context"
+	"encoding/hex"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/iden3/go-circuits"
+	core "github.com/iden3/go-iden3-core"
+	"github.com/iden3/go-jwz"
+	"github.com/iden3/go-merkletree-sql/v2"
+	"github.com/pkg/errors"
+
+	"github.com/rarimo/issuer/internal/config"
+	"github.com/rarimo/issuer/internal/data"
+	"github.com/rarimo/issuer/internal/service/core/identity/state_publisher/contracts"
+)
+
+var (
+	// ErrTokenIsInvalid is wrapped by the reasons the token isn't accepted
+	ErrTokenIsInvalid = errors.New("token is invalid")
+	ErrTokenIsUsed    = errors.New("token is already used")
+)
+
+// stateContract is the part of the state contract that resolves the roots and the states the proofs refer to.
+type stateContract interface {
+	GetGISTRootInfo(opts *bind.CallOpts, root *big.Int) (contracts.Struct2, error)
+	GetStateInfoByState(opts *bind.CallOpts, state *big.Int) (contracts.Struct0, error)
+}
+
+// Verifier verifies the JWZ tokens of the holders strictly: besides the proof itself its public signals
+// have to bind the challenge to the token message, the user ID to the message sender and the GIST root
+// or the user state to the ones the state contract knows. The token is accepted only once, it is marked
+// as used by the action it authorizes, so the token isn't spent if the action isn't done.
+type Verifier struct {
+	verificationKeys map[circuits.CircuitID][]byte
+	stateContract    stateContract
+	rootExpiration   time.Duration
+}
+
+// NewVerifier creates the verifier of the tokens proven with the circuits the verification keys are given for.
+func NewVerifier(
+	cfg *config.JWZConfig,
+	verificationKeys map[circuits.CircuitID][]byte,
+	stateContract stateContract,
+) *Verifier {
+	return &Verifier{
+		verificationKeys: verificationKeys,
+		stateContract:    stateContract,
+		rootExpiration:   cfg.RootExpiration,
+	}
+}
+
+// Verify checks that the token is proven by the sender, it doesn't mark the token as used, so the
+// MarkUsed has to be called in the transaction of the authorized action after its checks pass.
+// The reasons the token isn't valid are wrapped with the ErrTokenIsInvalid.
+func (v *Verifier) Verify(ctx context.Context, token *jwz.Token, sender *core.ID) error {
+	circuitID := circuits.CircuitID(token.CircuitID)
+	verificationKey, ok := v.verificationKeys[circuitID]
+	if !ok {
+		return errors.Wrapf(ErrTokenIsInvalid, "circuit %s is not supported", circuitID)
+	}
+
+	if _, err := token.Verify(verificationKey); err != nil {
+		return errors.Wrap(ErrTokenIsInvalid, err.Error())
+	}
+
+	messageHash, err := token.GetMessageHash()
+	if err != nil {
+		return errors.Wrap(ErrTokenIsInvalid, err.Error())
+	}
+
+	switch circuitID {
+	case circuits.AuthV2CircuitID:
+		var pubSignals circuits.AuthV2PubSignals
+		if err := token.ParsePubSignals(&pubSignals); err != nil {
+			return errors.Wrap(ErrTokenIsInvalid, err.Error())
+		}
+		if err := checkPubSignals(pubSignals.Challenge, pubSignals.UserID, messageHash, sender); err != nil {
+			return err
+		}
+		if err := v.checkGISTRoot(ctx, pubSignals.GISTRoot); err != nil {
+			return err
+		}
+	case circuits.AuthCircuitID:
+		var pubSignals circuits.AuthPubSignals
+		if err := token.ParsePubSignals(&pubSignals); err != nil {
+			return errors.Wrap(ErrTokenIsInvalid, err.Error())
+		}
+		if err := checkPubSignals(pubSignals.Challenge, pubSignals.UserID, messageHash, sender); err != nil {
+			return err
+		}
+		if err := v.checkUserState(ctx, pubSignals.UserID, pubSignals.UserState); err != nil {
+			return err
+		}
+	default:
+		return errors.Wrapf(ErrTokenIsInvalid, "circuit %s is not supported", circuitID)
+	}
+
+	return nil
+}
+
+// MarkUsed marks the verified token of the sender as used, ErrTokenIsUsed is returned if it is used already.
+// The used tokens query of the transaction of the authorized action is given, so the token is spent with it.
+func (v *Verifier) MarkUsed(usedTokensQ data.UsedTokensQ, token *jwz.Token, sender *core.ID) error {
+	messageHash, err := token.GetMessageHash()
+	if err != nil {
+		return errors.Wrap(err, "failed to get token message hash")
+	}
+
+	isInserted, err := usedTokensQ.Insert(&data.UsedToken{
+		ID:        hex.EncodeToString(messageHash),
+		UserID:    sender.String(),
+		CreatedAt: time.Now().UTC(),
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to insert used token")
+	}
+	if !isInserted {
+		return ErrTokenIsUsed
+	}
+
+	return nil
+}
+
+// checkPubSignals checks that the proof challenge is the token message hash and the proof is made by the sender.
+func checkPubSignals(challenge *big.Int, userID *core.ID, messageHash []byte, sender *core.ID) error {
+	if challenge == nil || challenge.Cmp(new(big.Int).SetBytes(messageHash)) != 0 {
+		return errors.Wrap(ErrTokenIsInvalid, "challenge is not equal to message hash")
+	}
+
+	if userID == nil || !userID.Equals(sender) {
+		return errors.Wrap(ErrTokenIsInvalid, "proof user id is not the message sender")
+	}
+
+	return nil
+}
+
+// checkGISTRoot checks that the GIST root is known to the state contract
+// and it is either the latest one or was replaced not longer than the root expiration ago.
+func (v *Verifier) checkGISTRoot(ctx context.Context, root *merkletree.Hash) error {
+	if root == nil {
+		return errors.Wrap(ErrTokenIsInvalid, "gist root is missing")
+	}
+
+	rootInfo, err := v.stateContract.GetGISTRootInfo(&bind.CallOpts{Context: ctx}, root.BigInt())
+	if err != nil {
+		if isExecutionReverted(err) {
+			return errors.Wrap(ErrTokenIsInvalid, "gist root is unknown")
+		}
+		return errors.Wrap(err, "failed to get gist root info")
+	}
+	if rootInfo.Root == nil || rootInfo.Root.Cmp(root.BigInt()) != 0 {
+		return errors.Wrap(ErrTokenIsInvalid, "gist root is unknown")
+	}
+
+	if v.isReplacedLongAgo(rootInfo.ReplacedAtTimestamp) {
+		return errors.Wrap(ErrTokenIsInvalid, "gist root is expired")
+	}
+
+	return nil
+}
+
+// checkUserState checks that the user state is the state of the user known to the state contract, that is
+// either the latest one or was replaced not longer than the root expiration ago, or the genesis one that
+// isn't published.
+func (v *Verifier) checkUserState(ctx context.Context, userID *core.ID, userState *merkletree.Hash) error {
+	if userState == nil {
+		return errors.Wrap(ErrTokenIsInvalid, "user state is missing")
+	}
+
+	stateInfo, err := v.stateContract.GetStateInfoByState(&bind.CallOpts{Context: ctx}, userState.BigInt())
+	if err != nil && !isExecutionReverted(err) {
+		return errors.Wrap(err, "failed to get user state info")
+	}
+
+	// the genesis state isn't published, so the state contract doesn't know it
+	if err != nil || stateInfo.State == nil || stateInfo.State.Sign() == 0 {
+		isGenesis, err := core.CheckGenesisStateID(userID.BigInt(), userState.BigInt())
+		if err != nil {
+			return errors.Wrap(ErrTokenIsInvalid, err.Error())
+		}
+		if !isGenesis {
+			return errors.Wrap(ErrTokenIsInvalid, "user state is unknown")
+		}
+		return nil
+	}
+
+	if stateInfo.Id == nil || stateInfo.Id.Cmp(userID.BigInt()) != 0 {
+		return errors.Wrap(ErrTokenIsInvalid, "user state is not the state of the user")
+	}
+
+	if v.isReplacedLongAgo(stateInfo.ReplacedAtTimestamp) {
+		return errors.Wrap(ErrTokenIsInvalid, "user state is expired")
+	}
+
+	return nil
+}
+
+// isReplacedLongAgo is true if the root or the state was replaced longer than the root expiration ago,
+// the zero replacement time means that it is the latest one.
+func (v *Verifier) isReplacedLongAgo(replacedAt *big.Int) bool {
+	if replacedAt == nil || replacedAt.Sign() == 0 {
+		return false
+	}
+
+	return time.Since(time.Unix(replacedAt.Int64(), 0)) > v.rootExpiration
+}
+
+// isExecutionReverted is true if the contract call reverted, the state contract reverts on the unknown
+// roots and states, so they are told apart from the node failures.
+func isExecutionReverted(err error) bool {
+	return strings.Contains(err.Error(), "execution reverted")
+}
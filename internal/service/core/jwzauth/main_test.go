@@ -0,0 +1,355 @@
+package jwzauth
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/iden3/go-circuits"
+	core "github.com/iden3/go-iden3-core"
+	"github.com/iden3/go-jwz"
+	"github.com/iden3/go-merkletree-sql/v2"
+
+	"github.com/rarimo/issuer/internal/config"
+	"github.com/rarimo/issuer/internal/data"
+	"github.com/rarimo/issuer/internal/service/core/identity/state_publisher/contracts"
+)
+
+const testRootExpiration = time.Hour
+
+// testStateContract knows the single GIST root and the single user state.
+type testStateContract struct {
+	rootInfo  contracts.Struct2
+	stateInfo contracts.Struct0
+	err       error
+}
+
+func (c *testStateContract) GetGISTRootInfo(_ *bind.CallOpts, root *big.Int) (contracts.Struct2, error) {
+	if c.err != nil {
+		return contracts.Struct2{}, c.err
+	}
+	if c.rootInfo.Root == nil || c.rootInfo.Root.Cmp(root) != 0 {
+		return contracts.Struct2{}, errors.New("execution reverted: root does not exist")
+	}
+
+	return c.rootInfo, nil
+}
+
+func (c *testStateContract) GetStateInfoByState(_ *bind.CallOpts, state *big.Int) (contracts.Struct0, error) {
+	if c.err != nil {
+		return contracts.Struct0{}, c.err
+	}
+	if c.stateInfo.State == nil || c.stateInfo.State.Cmp(state) != 0 {
+		return contracts.Struct0{}, errors.New("execution reverted: state does not exist")
+	}
+
+	return c.stateInfo, nil
+}
+
+// testUsedTokensQ keeps the used tokens in memory.
+type testUsedTokensQ struct {
+	data.UsedTokensQ
+	tokens map[string]*data.UsedToken
+}
+
+func (q *testUsedTokensQ) Insert(usedToken *data.UsedToken) (bool, error) {
+	if _, ok := q.tokens[usedToken.ID]; ok {
+		return false, nil
+	}
+
+	q.tokens[usedToken.ID] = usedToken
+	return true, nil
+}
+
+func newTestVerifier(stateContract stateContract) *Verifier {
+	return NewVerifier(&config.JWZConfig{RootExpiration: testRootExpiration}, nil, stateContract)
+}
+
+func newTestToken(t *testing.T, payload string) *jwz.Token {
+	t.Helper()
+
+	token, err := jwz.NewWithPayload(jwz.ProvingMethodGroth16AuthV2Instance, []byte(payload), nil)
+	if err != nil {
+		t.Fatalf("failed to create token: %v", err)
+	}
+
+	return token
+}
+
+// newTestGenesisID returns the ID of the identity with the genesis state.
+func newTestGenesisID(t *testing.T, state *big.Int) *core.ID {
+	t.Helper()
+
+	typ, err := core.BuildDIDType(core.DIDMethodIden3, core.Polygon, core.Mumbai)
+	if err != nil {
+		t.Fatalf("failed to build did type: %v", err)
+	}
+
+	id, err := core.IdGenesisFromIdenState(typ, state)
+	if err != nil {
+		t.Fatalf("failed to create genesis id: %v", err)
+	}
+
+	return id
+}
+
+func newTestHash(t *testing.T, value int64) *merkletree.Hash {
+	t.Helper()
+
+	hash, err := merkletree.NewHashFromBigInt(newTestState(value))
+	if err != nil {
+		t.Fatalf("failed to create hash: %v", err)
+	}
+
+	return hash
+}
+
+// newTestState returns the state that lands in the genesis ID, the ID is made of the high bytes of the state.
+func newTestState(value int64) *big.Int {
+	return new(big.Int).Lsh(big.NewInt(value), 240)
+}
+
+func unixAgo(d time.Duration) *big.Int {
+	return big.NewInt(time.Now().Add(-d).Unix())
+}
+
+func TestCheckPubSignals(t *testing.T) {
+	sender := newTestGenesisID(t, newTestState(1))
+	other := newTestGenesisID(t, newTestState(2))
+
+	messageHash, err := newTestToken(t, "message").GetMessageHash()
+	if err != nil {
+		t.Fatalf("failed to get message hash: %v", err)
+	}
+	challenge := new(big.Int).SetBytes(messageHash)
+
+	cases := []struct {
+		name      string
+		challenge *big.Int
+		userID    *core.ID
+		isValid   bool
+	}{
+		{"challenge and user are bound", challenge, sender, true},
+		{"challenge of the other message", new(big.Int).Add(challenge, big.NewInt(1)), sender, false},
+		{"no challenge", nil, sender, false},
+		{"proof of the other user", challenge, other, false},
+		{"no user", challenge, nil, false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := checkPubSignals(tc.challenge, tc.userID, messageHash, sender)
+			if tc.isValid {
+				if err != nil {
+					t.Fatalf("failed to check pub signals: %v", err)
+				}
+				return
+			}
+
+			if !errors.Is(err, ErrTokenIsInvalid) {
+				t.Fatalf("got error %v, want %v", err, ErrTokenIsInvalid)
+			}
+		})
+	}
+}
+
+func TestCheckGISTRoot(t *testing.T) {
+	root := newTestHash(t, 10)
+
+	cases := []struct {
+		name     string
+		root     *merkletree.Hash
+		contract *testStateContract
+		isValid  bool
+	}{
+		{
+			name:     "latest root",
+			root:     root,
+			contract: &testStateContract{rootInfo: contracts.Struct2{Root: root.BigInt(), ReplacedAtTimestamp: big.NewInt(0)}},
+			isValid:  true,
+		},
+		{
+			name:     "root replaced recently",
+			root:     root,
+			contract: &testStateContract{rootInfo: contracts.Struct2{Root: root.BigInt(), ReplacedAtTimestamp: unixAgo(testRootExpiration / 2)}},
+			isValid:  true,
+		},
+		{
+			name:     "root replaced long ago",
+			root:     root,
+			contract: &testStateContract{rootInfo: contracts.Struct2{Root: root.BigInt(), ReplacedAtTimestamp: unixAgo(2 * testRootExpiration)}},
+		},
+		{
+			name:     "unknown root",
+			root:     newTestHash(t, 11),
+			contract: &testStateContract{rootInfo: contracts.Struct2{Root: root.BigInt()}},
+		},
+		{
+			name:     "no root",
+			contract: &testStateContract{},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := newTestVerifier(tc.contract).checkGISTRoot(context.Background(), tc.root)
+			if tc.isValid {
+				if err != nil {
+					t.Fatalf("failed to check gist root: %v", err)
+				}
+				return
+			}
+
+			if !errors.Is(err, ErrTokenIsInvalid) {
+				t.Fatalf("got error %v, want %v", err, ErrTokenIsInvalid)
+			}
+		})
+	}
+
+	// the node failure isn't the invalid token, so the holder isn't told the proof is wrong
+	contract := &testStateContract{err: errors.New("connection refused")}
+	err := newTestVerifier(contract).checkGISTRoot(context.Background(), root)
+	if err == nil || errors.Is(err, ErrTokenIsInvalid) {
+		t.Fatalf("got error %v, want the node failure", err)
+	}
+}
+
+func TestCheckUserState(t *testing.T) {
+	genesisState := int64(20)
+	userID := newTestGenesisID(t, newTestState(genesisState))
+	otherID := newTestGenesisID(t, newTestState(21))
+	state := newTestHash(t, 30)
+
+	cases := []struct {
+		name     string
+		userID   *core.ID
+		state    *merkletree.Hash
+		contract *testStateContract
+		isValid  bool
+	}{
+		{
+			name:   "latest state",
+			userID: userID,
+			state:  state,
+			contract: &testStateContract{stateInfo: contracts.Struct0{
+				Id: userID.BigInt(), State: state.BigInt(), ReplacedAtTimestamp: big.NewInt(0),
+			}},
+			isValid: true,
+		},
+		{
+			name:   "state replaced recently",
+			userID: userID,
+			state:  state,
+			contract: &testStateContract{stateInfo: contracts.Struct0{
+				Id: userID.BigInt(), State: state.BigInt(), ReplacedAtTimestamp: unixAgo(testRootExpiration / 2),
+			}},
+			isValid: true,
+		},
+		{
+			name:   "state replaced long ago",
+			userID: userID,
+			state:  state,
+			contract: &testStateContract{stateInfo: contracts.Struct0{
+				Id: userID.BigInt(), State: state.BigInt(), ReplacedAtTimestamp: unixAgo(2 * testRootExpiration),
+			}},
+		},
+		{
+			name:   "state of the other user",
+			userID: userID,
+			state:  state,
+			contract: &testStateContract{stateInfo: contracts.Struct0{
+				Id: otherID.BigInt(), State: state.BigInt(),
+			}},
+		},
+		{
+			name:     "unpublished genesis state",
+			userID:   userID,
+			state:    newTestHash(t, genesisState),
+			contract: &testStateContract{},
+			isValid:  true,
+		},
+		{
+			name:     "genesis state of the other user",
+			userID:   otherID,
+			state:    newTestHash(t, genesisState),
+			contract: &testStateContract{},
+		},
+		{
+			name:     "unknown state",
+			userID:   userID,
+			state:    newTestHash(t, 31),
+			contract: &testStateContract{},
+		},
+		{
+			name:     "no state",
+			userID:   userID,
+			contract: &testStateContract{},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := newTestVerifier(tc.contract).checkUserState(context.Background(), tc.userID, tc.state)
+			if tc.isValid {
+				if err != nil {
+					t.Fatalf("failed to check user state: %v", err)
+				}
+				return
+			}
+
+			if !errors.Is(err, ErrTokenIsInvalid) {
+				t.Fatalf("got error %v, want %v", err, ErrTokenIsInvalid)
+			}
+		})
+	}
+}
+
+func TestVerifyUnsupportedCircuit(t *testing.T) {
+	verifier := NewVerifier(
+		&config.JWZConfig{RootExpiration: testRootExpiration},
+		map[circuits.CircuitID][]byte{circuits.AuthCircuitID: []byte("{}")},
+		&testStateContract{},
+	)
+
+	err := verifier.Verify(context.Background(), newTestToken(t, "message"), newTestGenesisID(t, newTestState(1)))
+	if !errors.Is(err, ErrTokenIsInvalid) {
+		t.Fatalf("got error %v, want %v", err, ErrTokenIsInvalid)
+	}
+}
+
+func TestMarkUsed(t *testing.T) {
+	verifier := newTestVerifier(&testStateContract{})
+	q := &testUsedTokensQ{tokens: make(map[string]*data.UsedToken)}
+	sender := newTestGenesisID(t, newTestState(1))
+	token := newTestToken(t, "message")
+
+	if err := verifier.MarkUsed(q, token, sender); err != nil {
+		t.Fatalf("failed to mark token as used: %v", err)
+	}
+
+	messageHash, err := token.GetMessageHash()
+	if err != nil {
+		t.Fatalf("failed to get message hash: %v", err)
+	}
+	usedToken, ok := q.tokens[hex.EncodeToString(messageHash)]
+	if !ok || usedToken.UserID != sender.String() {
+		t.Fatalf("token isn't stored by its message hash: %+v", q.tokens)
+	}
+
+	// the replayed token is rejected, even if it is sent by the other user
+	if err := verifier.MarkUsed(q, token, sender); !errors.Is(err, ErrTokenIsUsed) {
+		t.Fatalf("got error %v, want %v", err, ErrTokenIsUsed)
+	}
+	if err := verifier.MarkUsed(q, newTestToken(t, "message"), newTestGenesisID(t, newTestState(2))); !errors.Is(err, ErrTokenIsUsed) {
+		t.Fatalf("got error %v, want %v", err, ErrTokenIsUsed)
+	}
+
+	// the token of the other message isn't blocked
+	if err := verifier.MarkUsed(q, newTestToken(t, "other message"), sender); err != nil {
+		t.Fatalf("failed to mark token as used: %v", err)
+	}
+}
@@ -0,0 +1,74 @@
+package qrcode
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/pkg/errors"
+	"github.com/skip2/go-qrcode"
+)
+
+const (
+	FormatPNG = "png"
+	FormatSVG = "svg"
+
+	ContentTypePNG = "image/png"
+	ContentTypeSVG = "image/svg+xml"
+
+	DefaultSize = 256
+	MaxSize     = 1024
+)
+
+// Render encodes the content to the QR code image of the format with the width and height of the size in pixels.
+func Render(content, format string, size int) ([]byte, error) {
+	code, err := qrcode.New(content, qrcode.Medium)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to encode qr code")
+	}
+
+	switch format {
+	case FormatPNG:
+		image, err := code.PNG(size)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to render png")
+		}
+		return image, nil
+	case FormatSVG:
+		return renderSVG(code.Bitmap(), size), nil
+	default:
+		return nil, errors.Errorf("unsupported qr code format %s", format)
+	}
+}
+
+// ContentType returns the content type of the image of the format.
+func ContentType(format string) string {
+	if format == FormatSVG {
+		return ContentTypeSVG
+	}
+
+	return ContentTypePNG
+}
+
+// renderSVG draws the dark modules of the bitmap as the single path, each module is the unit square
+// of the view box, so the image is scaled to the size without the blurred edges.
+func renderSVG(bitmap [][]bool, size int) []byte {
+	var path bytes.Buffer
+	for y, row := range bitmap {
+		for x, isDark := range row {
+			if isDark {
+				_, _ = fmt.Fprintf(&path, "M%d %dh1v1h-1z", x, y)
+			}
+		}
+	}
+
+	var image bytes.Buffer
+	_, _ = fmt.Fprintf(&image,
+		`<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" shape-rendering="crispEdges">`,
+		size, size, len(bitmap), len(bitmap),
+	)
+	image.WriteString(`<rect width="100%" height="100%" fill="#ffffff"/>`)
+	_, _ = fmt.Fprintf(&image, `<path fill="#000000" d="%s"/>`, path.String())
+	image.WriteString(`</svg>`)
+
+	return image.Bytes()
+}
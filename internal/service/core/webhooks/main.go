@@ -0,0 +1,108 @@
+package webhooks
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+
+	"github.com/rarimo/issuer/internal/config"
+	"github.com/rarimo/issuer/internal/data"
+)
+
+// Outbox stores the events for the endpoints subscribed to them, the stored events
+// are delivered by the Sender.
+type Outbox struct {
+	endpoints []config.WebhookEndpoint
+	issuer    string
+}
+
+// NewOutbox creates the outbox of the configured endpoints, the disabled outbox stores nothing.
+func NewOutbox(cfg *config.WebhooksConfig) (*Outbox, error) {
+	if cfg.Disabled {
+		return &Outbox{}, nil
+	}
+
+	for _, endpoint := range cfg.Endpoints {
+		for _, eventType := range endpoint.Events {
+			if _, ok := eventTypes[EventType(eventType)]; !ok {
+				return nil, errors.Errorf("unknown event type %s of the webhook endpoint %s", eventType, endpoint.URL)
+			}
+		}
+	}
+
+	return &Outbox{
+		endpoints: cfg.Endpoints,
+	}, nil
+}
+
+// WithIssuer returns the outbox of the same endpoints that reports the events of the issuer DID.
+func (o *Outbox) WithIssuer(did string) *Outbox {
+	return &Outbox{
+		endpoints: o.endpoints,
+		issuer:    did,
+	}
+}
+
+// Enqueue stores the event for every endpoint subscribed to its type. The event is inserted with the passed
+// query, so when it belongs to the transaction the event is stored only if the change it reports is committed.
+func (o *Outbox) Enqueue(webhookEventsQ data.WebhookEventsQ, eventType EventType, eventData interface{}) error {
+	var endpoints []string
+	for _, endpoint := range o.endpoints {
+		if isSubscribed(endpoint, eventType) {
+			endpoints = append(endpoints, endpoint.URL)
+		}
+	}
+	if len(endpoints) == 0 {
+		return nil
+	}
+
+	now := time.Now().UTC()
+	event := Event{
+		ID:        uuid.NewString(),
+		Type:      eventType,
+		Issuer:    o.issuer,
+		CreatedAt: now,
+		Data:      eventData,
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal webhook event")
+	}
+
+	events := make([]data.WebhookEvent, 0, len(endpoints))
+	for _, endpoint := range endpoints {
+		events = append(events, data.WebhookEvent{
+			EventID:       event.ID,
+			EventType:     string(eventType),
+			Endpoint:      endpoint,
+			Payload:       payload,
+			Status:        data.WebhookEventStatusPending,
+			CreatedAt:     now,
+			NextAttemptAt: now,
+		})
+	}
+
+	err = webhookEventsQ.Insert(events...)
+	if err != nil {
+		return errors.Wrap(err, "failed to insert webhook events")
+	}
+
+	return nil
+}
+
+func isSubscribed(endpoint config.WebhookEndpoint, eventType EventType) bool {
+	if len(endpoint.Events) == 0 {
+		return true
+	}
+
+	for _, subscribed := range endpoint.Events {
+		if EventType(subscribed) == eventType {
+			return true
+		}
+	}
+
+	return false
+}
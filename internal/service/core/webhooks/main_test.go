@@ -0,0 +1,84 @@
+package webhooks
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/rarimo/issuer/internal/config"
+	"github.com/rarimo/issuer/internal/data"
+)
+
+type testInsertedEventsQ struct {
+	data.WebhookEventsQ
+	inserted []data.WebhookEvent
+}
+
+func (q *testInsertedEventsQ) Insert(events ...data.WebhookEvent) error {
+	q.inserted = append(q.inserted, events...)
+	return nil
+}
+
+func TestOutboxEnqueue(t *testing.T) {
+	outbox, err := NewOutbox(&config.WebhooksConfig{
+		Endpoints: []config.WebhookEndpoint{
+			{URL: "http://all"},
+			{URL: "http://issued", Events: []string{string(EventClaimIssued)}},
+			{URL: "http://revoked", Events: []string{string(EventClaimRevoked)}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to create outbox: %v", err)
+	}
+
+	webhookEventsQ := &testInsertedEventsQ{}
+	err = outbox.WithIssuer("did:iden3:issuer").Enqueue(webhookEventsQ, EventClaimIssued, map[string]string{"claim_id": "1"})
+	if err != nil {
+		t.Fatalf("failed to enqueue: %v", err)
+	}
+
+	if len(webhookEventsQ.inserted) != 2 {
+		t.Fatalf("event is stored for %d endpoints, want 2", len(webhookEventsQ.inserted))
+	}
+	first, second := webhookEventsQ.inserted[0], webhookEventsQ.inserted[1]
+	if first.Endpoint != "http://all" || second.Endpoint != "http://issued" {
+		t.Fatalf("event is stored for %s and %s", first.Endpoint, second.Endpoint)
+	}
+	if first.EventID != second.EventID || string(first.Payload) != string(second.Payload) {
+		t.Fatal("entries of the same event differ")
+	}
+
+	var event Event
+	if err := json.Unmarshal(first.Payload, &event); err != nil {
+		t.Fatalf("failed to unmarshal payload: %v", err)
+	}
+	if event.ID != first.EventID || event.Type != EventClaimIssued || event.Issuer != "did:iden3:issuer" {
+		t.Fatalf("unexpected event %+v", event)
+	}
+}
+
+func TestDisabledOutbox(t *testing.T) {
+	outbox, err := NewOutbox(&config.WebhooksConfig{
+		Disabled:  true,
+		Endpoints: []config.WebhookEndpoint{{URL: "http://all"}},
+	})
+	if err != nil {
+		t.Fatalf("failed to create outbox: %v", err)
+	}
+
+	webhookEventsQ := &testInsertedEventsQ{}
+	if err := outbox.Enqueue(webhookEventsQ, EventClaimIssued, nil); err != nil {
+		t.Fatalf("failed to enqueue: %v", err)
+	}
+	if len(webhookEventsQ.inserted) != 0 {
+		t.Fatal("disabled outbox stores the events")
+	}
+}
+
+func TestNewOutboxRejectsUnknownEvent(t *testing.T) {
+	_, err := NewOutbox(&config.WebhooksConfig{
+		Endpoints: []config.WebhookEndpoint{{URL: "http://all", Events: []string{"claim.unknown"}}},
+	})
+	if err == nil {
+		t.Fatal("unknown event type is accepted")
+	}
+}
@@ -0,0 +1,129 @@
+package webhooks
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/rarimo/issuer/internal/data"
+	"github.com/rarimo/issuer/internal/service/core/identity/state"
+)
+
+type EventType string
+
+const (
+	EventClaimIssued    EventType = "claim.issued"
+	EventOfferReceived  EventType = "offer.received"
+	EventClaimRevoked   EventType = "claim.revoked"
+	EventStateCommitted EventType = "state.committed"
+	EventStateFailed    EventType = "state.failed"
+)
+
+var eventTypes = map[EventType]struct{}{
+	EventClaimIssued:    {},
+	EventOfferReceived:  {},
+	EventClaimRevoked:   {},
+	EventStateCommitted: {},
+	EventStateFailed:    {},
+}
+
+// Event is the body of the webhook request, the ID is the same for all the retries of the event.
+// The Issuer is the DID of the hosted identity the event is about.
+type Event struct {
+	ID        string      `json:"id"`
+	Type      EventType   `json:"type"`
+	Issuer    string      `json:"issuer,omitempty"`
+	CreatedAt time.Time   `json:"created_at"`
+	Data      interface{} `json:"data"`
+}
+
+type ClaimIssuedData struct {
+	ClaimID         string     `json:"claim_id"`
+	UserID          string     `json:"user_id"`
+	SchemaType      string     `json:"schema_type"`
+	RevNonce        uint64     `json:"rev_nonce"`
+	Expiration      *time.Time `json:"expiration,omitempty"`
+	PreviousClaimID *string    `json:"previous_claim_id,omitempty"`
+}
+
+type OfferReceivedData struct {
+	OfferID string `json:"offer_id"`
+	ClaimID string `json:"claim_id"`
+	UserID  string `json:"user_id"`
+}
+
+type ClaimRevokedData struct {
+	RevNonce  uint64    `json:"rev_nonce"`
+	ClaimID   *string   `json:"claim_id,omitempty"`
+	Reason    string    `json:"reason"`
+	Operator  string    `json:"operator,omitempty"`
+	RevokedAt time.Time `json:"revoked_at"`
+}
+
+// StateData is the data of the state events, the Message is the failure reason of the failed state.
+type StateData struct {
+	CommittedStateID    uint64 `json:"committed_state_id"`
+	Status              string `json:"status"`
+	Message             string `json:"message,omitempty"`
+	TxID                string `json:"tx_id,omitempty"`
+	BlockNumber         uint64 `json:"block_number,omitempty"`
+	BlockTimestamp      uint64 `json:"block_timestamp,omitempty"`
+	State               string `json:"state"`
+	ClaimsTreeRoot      string `json:"claims_tree_root"`
+	RevocationsTreeRoot string `json:"revocations_tree_root"`
+	RootsTreeRoot       string `json:"roots_tree_root"`
+}
+
+func NewClaimIssuedData(claim *data.Claim) ClaimIssuedData {
+	return ClaimIssuedData{
+		ClaimID:         claim.ID,
+		UserID:          claim.UserID,
+		SchemaType:      claim.ClaimType,
+		RevNonce:        uint64(claim.RevNonce),
+		Expiration:      claim.Expiration,
+		PreviousClaimID: claim.PreviousClaimID,
+	}
+}
+
+func NewOfferReceivedData(claimOffer *data.ClaimOffer, claim *data.Claim) OfferReceivedData {
+	return OfferReceivedData{
+		OfferID: claimOffer.ID,
+		ClaimID: claim.ID,
+		UserID:  claim.UserID,
+	}
+}
+
+func NewClaimRevokedData(revocation *data.ClaimRevocation) ClaimRevokedData {
+	return ClaimRevokedData{
+		RevNonce:  uint64(revocation.RevNonce),
+		ClaimID:   revocation.ClaimID,
+		Reason:    string(revocation.Reason),
+		Operator:  revocation.Operator,
+		RevokedAt: revocation.RevokedAt,
+	}
+}
+
+func NewStateData(committedStateRaw *data.CommittedState) (*StateData, error) {
+	committedState, err := state.CommittedStateFromRaw(committedStateRaw)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse committed state")
+	}
+
+	stateHash, err := committedState.StateHash()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get state hash")
+	}
+
+	return &StateData{
+		CommittedStateID:    committedStateRaw.ID,
+		Status:              string(committedStateRaw.Status),
+		Message:             committedStateRaw.Message,
+		TxID:                committedStateRaw.TxID,
+		BlockNumber:         committedStateRaw.BlockNumber,
+		BlockTimestamp:      committedStateRaw.BlockTimestamp,
+		State:               stateHash.Hex(),
+		ClaimsTreeRoot:      committedState.ClaimsTreeRoot.Hex(),
+		RevocationsTreeRoot: committedState.RevocationsTreeRoot.Hex(),
+		RootsTreeRoot:       committedState.RootsTreeRoot.Hex(),
+	}, nil
+}
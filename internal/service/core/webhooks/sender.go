@@ -0,0 +1,211 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+	"gitlab.com/distributed_lab/logan/v3"
+	"gitlab.com/distributed_lab/running"
+
+	"github.com/rarimo/issuer/internal/config"
+	"github.com/rarimo/issuer/internal/data"
+)
+
+const (
+	SenderRunnerName = "webhooks_sender"
+
+	HeaderEventID   = "X-Webhook-ID"
+	HeaderEventType = "X-Webhook-Event"
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	HeaderSignature = "X-Webhook-Signature"
+
+	signaturePrefix = "sha256="
+	// maxErrorBodyLen is the length of the failed response body kept as the last delivery error
+	maxErrorBodyLen = 256
+)
+
+// Sender delivers the pending events of the outbox to their endpoints. The event is retried until
+// the endpoint responds with 2xx, so it may be delivered more than once and receivers should
+// deduplicate the events by the ID. The due events are leased for the time the batch is delivered
+// in, so the senders of the several instances don't deliver the same event concurrently, the events
+// of the sender stopped during the delivery are retried after the lease expires.
+type Sender struct {
+	log           *logan.Entry
+	webhookEvents data.WebhookEventsQ
+	client        *http.Client
+	secrets       map[string]string
+	period        time.Duration
+	batchSize     uint64
+	lease         time.Duration
+	maxAttempts   int
+	minBackoff    time.Duration
+	maxBackoff    time.Duration
+	retention     time.Duration
+}
+
+func NewSender(log *logan.Entry, cfg *config.WebhooksConfig, webhookEventsQ data.WebhookEventsQ) *Sender {
+	secrets := make(map[string]string, len(cfg.Endpoints))
+	for _, endpoint := range cfg.Endpoints {
+		secrets[endpoint.URL] = endpoint.Secret
+	}
+
+	return &Sender{
+		log:           log,
+		webhookEvents: webhookEventsQ,
+		client:        &http.Client{Timeout: cfg.Timeout},
+		secrets:       secrets,
+		period:        cfg.Period,
+		batchSize:     cfg.BatchSize,
+		lease:         time.Duration(cfg.BatchSize) * cfg.Timeout,
+		maxAttempts:   cfg.MaxAttempts,
+		minBackoff:    cfg.MinBackoff,
+		maxBackoff:    cfg.MaxBackoff,
+		retention:     cfg.Retention,
+	}
+}
+
+func (s *Sender) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.period)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			running.UntilSuccess(ctx, s.log, SenderRunnerName,
+				func(ctx context.Context) (bool, error) {
+					return true, s.send(ctx)
+				}, s.period, s.period,
+			)
+
+			ticker.Reset(s.period)
+		}
+	}
+}
+
+func (s *Sender) send(ctx context.Context) error {
+	now := time.Now().UTC()
+	events, err := s.webhookEvents.LeaseDue(now, now.Add(s.lease), s.batchSize)
+	if err != nil {
+		return errors.Wrap(err, "failed to lease due webhook events")
+	}
+
+	for i := range events {
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		err = s.webhookEvents.Update(s.attempt(ctx, &events[i]))
+		if err != nil {
+			return errors.Wrap(err, "failed to update webhook event")
+		}
+	}
+
+	if s.retention > 0 {
+		_, err = s.webhookEvents.DeleteFinishedBefore(time.Now().UTC().Add(-s.retention))
+		if err != nil {
+			return errors.Wrap(err, "failed to delete finished webhook events")
+		}
+	}
+
+	return nil
+}
+
+// attempt delivers the event and sets its status according to the result, the failed event
+// is scheduled for the next attempt with the backoff or is given up after the max attempts.
+func (s *Sender) attempt(ctx context.Context, event *data.WebhookEvent) *data.WebhookEvent {
+	now := time.Now().UTC()
+	event.Attempts++
+
+	err := s.deliver(ctx, event)
+	if err == nil {
+		event.Status = data.WebhookEventStatusDelivered
+		event.LastError = ""
+		event.FinishedAt = &now
+		return event
+	}
+
+	event.LastError = err.Error()
+	logFields := logan.F{
+		"event_id": event.EventID,
+		"endpoint": event.Endpoint,
+		"attempts": event.Attempts,
+	}
+
+	if event.Attempts >= s.maxAttempts {
+		event.Status = data.WebhookEventStatusFailed
+		event.FinishedAt = &now
+		s.log.WithError(err).WithFields(logFields).Error("Webhook event delivery is given up")
+		return event
+	}
+
+	event.NextAttemptAt = now.Add(s.backoff(event.Attempts))
+	s.log.WithError(err).WithFields(logFields).Warn("Failed to deliver webhook event")
+
+	return event
+}
+
+func (s *Sender) deliver(ctx context.Context, event *data.WebhookEvent) error {
+	secret, ok := s.secrets[event.Endpoint]
+	if !ok {
+		return errors.New("webhook endpoint is not configured")
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, event.Endpoint, bytes.NewReader(event.Payload))
+	if err != nil {
+		return errors.Wrap(err, "failed to create request")
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set(HeaderEventID, event.EventID)
+	request.Header.Set(HeaderEventType, event.EventType)
+	request.Header.Set(HeaderTimestamp, timestamp)
+	request.Header.Set(HeaderSignature, signaturePrefix+Sign(secret, timestamp, event.Payload))
+
+	response, err := s.client.Do(request)
+	if err != nil {
+		return errors.Wrap(err, "failed to send request")
+	}
+	defer func() { _ = response.Body.Close() }()
+
+	if response.StatusCode < http.StatusOK || response.StatusCode >= http.StatusMultipleChoices {
+		body, _ := io.ReadAll(io.LimitReader(response.Body, maxErrorBodyLen))
+		return errors.Errorf("unexpected response status %d: %s", response.StatusCode, body)
+	}
+
+	return nil
+}
+
+// backoff doubles the min backoff for every failed attempt up to the max backoff.
+func (s *Sender) backoff(attempts int) time.Duration {
+	backoff := s.minBackoff
+	for i := 1; i < attempts && backoff < s.maxBackoff; i++ {
+		backoff *= 2
+	}
+
+	if backoff > s.maxBackoff {
+		return s.maxBackoff
+	}
+
+	return backoff
+}
+
+// Sign returns the hex encoded HMAC-SHA256 of the timestamp and the payload joined with the dot, the signed
+// timestamp lets the receivers reject the replayed requests.
+func Sign(secret, timestamp string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	_, _ = fmt.Fprintf(mac, "%s.", timestamp)
+	mac.Write(payload)
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,193 @@
+package webhooks
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"gitlab.com/distributed_lab/logan/v3"
+
+	"github.com/rarimo/issuer/internal/config"
+	"github.com/rarimo/issuer/internal/data"
+)
+
+const testSecret = "secret"
+
+// testWebhookEventsQ leases the stored events and records the updated ones.
+type testWebhookEventsQ struct {
+	data.WebhookEventsQ
+	due     []data.WebhookEvent
+	leased  time.Time
+	updated []data.WebhookEvent
+}
+
+func (q *testWebhookEventsQ) LeaseDue(now, leasedUntil time.Time, limit uint64) ([]data.WebhookEvent, error) {
+	q.leased = leasedUntil
+	if uint64(len(q.due)) > limit {
+		return q.due[:limit], nil
+	}
+	return q.due, nil
+}
+
+func (q *testWebhookEventsQ) Update(event *data.WebhookEvent) error {
+	q.updated = append(q.updated, *event)
+	return nil
+}
+
+func (q *testWebhookEventsQ) DeleteFinishedBefore(time.Time) (int64, error) {
+	return 0, nil
+}
+
+func newTestSender(endpoints ...string) (*Sender, *testWebhookEventsQ) {
+	cfg := &config.WebhooksConfig{
+		Period:      time.Second,
+		BatchSize:   10,
+		Timeout:     time.Second,
+		MaxAttempts: 3,
+		MinBackoff:  time.Second,
+		MaxBackoff:  3 * time.Second,
+	}
+	for _, endpoint := range endpoints {
+		cfg.Endpoints = append(cfg.Endpoints, config.WebhookEndpoint{URL: endpoint, Secret: testSecret})
+	}
+
+	webhookEventsQ := &testWebhookEventsQ{}
+	return NewSender(logan.New(), cfg, webhookEventsQ), webhookEventsQ
+}
+
+func TestSign(t *testing.T) {
+	// the signature of the "1700000000.{"id":"1"}" computed independently
+	expected := "086f6aff7bd084c98679825129c5a64dbad88c760016d6d2c0fb123f27951d54"
+
+	if signature := Sign(testSecret, "1700000000", []byte(`{"id":"1"}`)); signature != expected {
+		t.Fatalf("got signature %s, want %s", signature, expected)
+	}
+	if Sign(testSecret, "1700000001", []byte(`{"id":"1"}`)) == expected {
+		t.Fatal("signature doesn't depend on the timestamp")
+	}
+	if Sign("other", "1700000000", []byte(`{"id":"1"}`)) == expected {
+		t.Fatal("signature doesn't depend on the secret")
+	}
+}
+
+func TestSenderSignsRequest(t *testing.T) {
+	payload := []byte(`{"id":"event"}`)
+
+	var received *http.Request
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r
+		body, _ = io.ReadAll(r.Body)
+	}))
+	defer server.Close()
+
+	sender, webhookEventsQ := newTestSender(server.URL)
+	webhookEventsQ.due = []data.WebhookEvent{{
+		EventID:   "event",
+		EventType: string(EventClaimIssued),
+		Endpoint:  server.URL,
+		Payload:   payload,
+		Status:    data.WebhookEventStatusPending,
+	}}
+
+	if err := sender.send(context.Background()); err != nil {
+		t.Fatalf("failed to send: %v", err)
+	}
+
+	if received == nil {
+		t.Fatal("event isn't delivered")
+	}
+	if received.Header.Get(HeaderEventID) != "event" || received.Header.Get(HeaderEventType) != string(EventClaimIssued) {
+		t.Fatalf("unexpected event headers %v", received.Header)
+	}
+
+	timestamp := received.Header.Get(HeaderTimestamp)
+	if _, err := strconv.ParseInt(timestamp, 10, 64); err != nil {
+		t.Fatalf("invalid timestamp %q", timestamp)
+	}
+	if signature := received.Header.Get(HeaderSignature); signature != signaturePrefix+Sign(testSecret, timestamp, body) {
+		t.Fatalf("signature %s doesn't match the delivered body", signature)
+	}
+
+	if len(webhookEventsQ.updated) != 1 || webhookEventsQ.updated[0].Status != data.WebhookEventStatusDelivered {
+		t.Fatalf("delivered event isn't marked as delivered: %v", webhookEventsQ.updated)
+	}
+	if webhookEventsQ.leased.Sub(time.Now().UTC()) <= 0 {
+		t.Fatal("due events aren't leased")
+	}
+}
+
+func TestSenderRetries(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	sender, _ := newTestSender(server.URL)
+	event := &data.WebhookEvent{Endpoint: server.URL, Status: data.WebhookEventStatusPending}
+
+	expectedBackoffs := []time.Duration{time.Second, 2 * time.Second}
+	for _, backoff := range expectedBackoffs {
+		before := time.Now().UTC()
+		sender.attempt(context.Background(), event)
+
+		if event.Status != data.WebhookEventStatusPending || event.FinishedAt != nil {
+			t.Fatalf("event is finished after %d attempts", event.Attempts)
+		}
+		if event.LastError == "" {
+			t.Fatal("failed attempt has no error")
+		}
+		if delay := event.NextAttemptAt.Sub(before); delay < backoff || delay > backoff+time.Second {
+			t.Fatalf("attempt %d is retried in %s, want %s", event.Attempts, delay, backoff)
+		}
+	}
+
+	sender.attempt(context.Background(), event)
+	if event.Status != data.WebhookEventStatusFailed || event.FinishedAt == nil {
+		t.Fatal("event isn't given up after the max attempts")
+	}
+}
+
+func TestSenderRejectsUnconfiguredEndpoint(t *testing.T) {
+	var delivered bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		delivered = true
+	}))
+	defer server.Close()
+
+	// the endpoint is removed from the config after the event was stored, so there is no secret to sign it
+	sender, _ := newTestSender()
+	event := &data.WebhookEvent{Endpoint: server.URL, Status: data.WebhookEventStatusPending}
+
+	sender.attempt(context.Background(), event)
+	if delivered {
+		t.Fatal("event is delivered to the endpoint that isn't configured")
+	}
+	if event.Status != data.WebhookEventStatusPending || event.LastError == "" {
+		t.Fatal("event isn't retried")
+	}
+}
+
+func TestSenderBackoff(t *testing.T) {
+	sender, _ := newTestSender()
+
+	cases := []struct {
+		attempts int
+		expected time.Duration
+	}{
+		{1, time.Second},
+		{2, 2 * time.Second},
+		{3, 3 * time.Second},
+		{100, 3 * time.Second},
+	}
+
+	for _, tc := range cases {
+		if backoff := sender.backoff(tc.attempts); backoff != tc.expected {
+			t.Fatalf("got backoff %s of %d attempts, want %s", backoff, tc.attempts, tc.expected)
+		}
+	}
+}
@@ -0,0 +1,31 @@
+/*
+ * GENERATED. Do not modify. Your changes might be overwritten!
+ */
+
+package resources
+
+type AuditEvent struct {
+	Key
+	Attributes AuditEventAttributes `json:"attributes"`
+}
+type AuditEventResponse struct {
+	Data     AuditEvent `json:"data"`
+	Included Included   `json:"included"`
+}
+
+type AuditEventListResponse struct {
+	Data     []AuditEvent `json:"data"`
+	Included Included     `json:"included"`
+	Links    *Links       `json:"links"`
+}
+
+// MustAuditEvent - returns AuditEvent from include collection.
+// if entry with specified key does not exist - returns nil
+// if entry with specified key exists but type or ID mismatches - panics
+func (c *Included) MustAuditEvent(key Key) *AuditEvent {
+	var auditEvent AuditEvent
+	if c.tryFindEntry(key, &auditEvent) {
+		return &auditEvent
+	}
+	return nil
+}
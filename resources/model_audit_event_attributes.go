@@ -0,0 +1,28 @@
+/*
+ * GENERATED. Do not modify. Your changes might be overwritten!
+ */
+
+package resources
+
+import "time"
+
+type AuditEventAttributes struct {
+	// The principal ID, the holder DID or the service component name, empty for the anonymous actor
+	ActorId string `json:"actor_id"`
+	// The type of the actor that made the change
+	ActorType string `json:"actor_type"`
+	// The identifier of the claim the event is about
+	ClaimId *string `json:"claim_id,omitempty"`
+	// The identifier of the committed state the event is about
+	CommittedStateId *uint64 `json:"committed_state_id,omitempty"`
+	// The time the change was made
+	CreatedAt time.Time `json:"created_at"`
+	// The type of the change
+	EventType string `json:"event_type"`
+	// The identifier of the offer the event is about
+	OfferId *string `json:"offer_id,omitempty"`
+	// The ID of the API request that made the change, it is omitted for the changes made by the service itself
+	RequestId *string `json:"request_id,omitempty"`
+	// The revoked revocation nonce
+	RevNonce *uint64 `json:"rev_nonce,omitempty"`
+}
@@ -0,0 +1,29 @@
+/*
+ * GENERATED. Do not modify. Your changes might be overwritten!
+ */
+
+package resources
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type ClaimAttributes struct {
+	// The claim issuance time
+	CreatedAt time.Time `json:"created_at"`
+	// The W3C credential without proofs
+	Credential json.RawMessage `json:"credential"`
+	// The claim expiration time
+	Expiration *time.Time `json:"expiration,omitempty"`
+	// Whether the claim is expired
+	Expired bool `json:"expired"`
+	// The identifier of the claim that was replaced by this one on reissue
+	PreviousClaimId *string `json:"previous_claim_id,omitempty"`
+	// Whether the claim is revoked
+	Revoked bool `json:"revoked"`
+	// The claim schema type
+	SchemaType string `json:"schema_type"`
+	// The claim recipient identifier
+	UserId string `json:"user_id"`
+}
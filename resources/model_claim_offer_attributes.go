@@ -9,8 +9,9 @@ type ClaimOfferAttributes struct {
 	// The unique identity identifer who will get the claim
 	From string `json:"from"`
 	// The uniquer offer identifier
-	Id   string `json:"id"`
-	Thid string `json:"thid"`
+	Id    string           `json:"id"`
+	Links *ClaimOfferLinks `json:"links,omitempty"`
+	Thid  string           `json:"thid"`
 	// The unique issuer identity identifier
 	To string `json:"to"`
 	// The Iden3 message media type
@@ -0,0 +1,16 @@
+/*
+ * GENERATED. Do not modify. Your changes might be overwritten!
+ */
+
+package resources
+
+type ClaimOfferLinks struct {
+	// The iden3comm deep link that refers to the hosted offer message with the request_uri
+	DeepLink string `json:"deep_link"`
+	// The url of the hosted plain offer message
+	Message string `json:"message"`
+	// The url of the PNG QR code of the deep link
+	QrPng string `json:"qr_png"`
+	// The url of the SVG QR code of the deep link
+	QrSvg string `json:"qr_svg"`
+}
@@ -0,0 +1,32 @@
+/*
+ * GENERATED. Do not modify. Your changes might be overwritten!
+ */
+
+package resources
+
+type ClaimReissue struct {
+	Key
+	Attributes    ClaimReissueAttributes    `json:"attributes"`
+	Relationships ClaimReissueRelationships `json:"relationships"`
+}
+type ClaimReissueResponse struct {
+	Data     ClaimReissue `json:"data"`
+	Included Included     `json:"included"`
+}
+
+type ClaimReissueListResponse struct {
+	Data     []ClaimReissue `json:"data"`
+	Included Included       `json:"included"`
+	Links    *Links         `json:"links"`
+}
+
+// MustClaimReissue - returns ClaimReissue from include collection.
+// if entry with specified key does not exist - returns nil
+// if entry with specified key exists but type or ID mismatches - panics
+func (c *Included) MustClaimReissue(key Key) *ClaimReissue {
+	var claimReissue ClaimReissue
+	if c.tryFindEntry(key, &claimReissue) {
+		return &claimReissue
+	}
+	return nil
+}
@@ -0,0 +1,10 @@
+/*
+ * GENERATED. Do not modify. Your changes might be overwritten!
+ */
+
+package resources
+
+type ClaimReissueAttributes struct {
+	// The identifier of the revoked claim that is replaced
+	PreviousClaimId string `json:"previous_claim_id"`
+}
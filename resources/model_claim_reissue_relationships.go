@@ -0,0 +1,10 @@
+/*
+ * GENERATED. Do not modify. Your changes might be overwritten!
+ */
+
+package resources
+
+type ClaimReissueRelationships struct {
+	Offer      Relation `json:"offer"`
+	Revocation Relation `json:"revocation"`
+}
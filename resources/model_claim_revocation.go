@@ -0,0 +1,31 @@
+/*
+ * GENERATED. Do not modify. Your changes might be overwritten!
+ */
+
+package resources
+
+type ClaimRevocation struct {
+	Key
+	Attributes ClaimRevocationAttributes `json:"attributes"`
+}
+type ClaimRevocationResponse struct {
+	Data     ClaimRevocation `json:"data"`
+	Included Included        `json:"included"`
+}
+
+type ClaimRevocationListResponse struct {
+	Data     []ClaimRevocation `json:"data"`
+	Included Included          `json:"included"`
+	Links    *Links            `json:"links"`
+}
+
+// MustClaimRevocation - returns ClaimRevocation from include collection.
+// if entry with specified key does not exist - returns nil
+// if entry with specified key exists but type or ID mismatches - panics
+func (c *Included) MustClaimRevocation(key Key) *ClaimRevocation {
+	var claimRevocation ClaimRevocation
+	if c.tryFindEntry(key, &claimRevocation) {
+		return &claimRevocation
+	}
+	return nil
+}
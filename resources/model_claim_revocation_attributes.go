@@ -0,0 +1,21 @@
+/*
+ * GENERATED. Do not modify. Your changes might be overwritten!
+ */
+
+package resources
+
+import "time"
+
+type ClaimRevocationAttributes struct {
+	// The revoked claim identifier
+	ClaimId *string `json:"claim_id,omitempty"`
+	// The revocation operator
+	Operator string `json:"operator"`
+	// The revocation reason code
+	Reason string `json:"reason"`
+	// The revocation nonce
+	RevNonce string `json:"rev_nonce"`
+	// The revocation time
+	RevokedAt time.Time            `json:"revoked_at"`
+	State     ClaimRevocationState `json:"state"`
+}
@@ -0,0 +1,14 @@
+/*
+ * GENERATED. Do not modify. Your changes might be overwritten!
+ */
+
+package resources
+
+type ClaimRevocationState struct {
+	// The committed state that includes the revocation
+	CommittedStateId *uint64 `json:"committed_state_id,omitempty"`
+	// The revocation publishing status: pending, processing or completed
+	Status string `json:"status"`
+	// The state transition transaction hash
+	TxId *string `json:"tx_id,omitempty"`
+}
@@ -0,0 +1,36 @@
+/*
+ * GENERATED. Do not modify. Your changes might be overwritten!
+ */
+
+package resources
+
+type ClaimSchema struct {
+	Key
+	Attributes ClaimSchemaAttributes `json:"attributes"`
+}
+type ClaimSchemaResponse struct {
+	Data     ClaimSchema `json:"data"`
+	Included Included    `json:"included"`
+}
+
+type ClaimSchemaListResponse struct {
+	Data     []ClaimSchema `json:"data"`
+	Included Included      `json:"included"`
+	Links    *Links        `json:"links"`
+}
+
+type ClaimSchemaRequest struct {
+	Data     ClaimSchema `json:"data"`
+	Included Included    `json:"included"`
+}
+
+// MustClaimSchema - returns ClaimSchema from include collection.
+// if entry with specified key does not exist - returns nil
+// if entry with specified key exists but type or ID mismatches - panics
+func (c *Included) MustClaimSchema(key Key) *ClaimSchema {
+	var claimSchema ClaimSchema
+	if c.tryFindEntry(key, &claimSchema) {
+		return &claimSchema
+	}
+	return nil
+}
@@ -0,0 +1,24 @@
+/*
+ * GENERATED. Do not modify. Your changes might be overwritten!
+ */
+
+package resources
+
+import "time"
+
+type ClaimSchemaAttributes struct {
+	// The claim schema creation time
+	CreatedAt *time.Time `json:"created_at,omitempty"`
+	// The human readable claim schema name that is shown in the claim offer
+	DisplayName string `json:"display_name"`
+	// Whether the new claims with the schema can be issued
+	IsDeprecated *bool `json:"is_deprecated,omitempty"`
+	// The JSON-LD context URL, taken from the JSON schema metadata if empty
+	JsonLdContext *string `json:"json_ld_context,omitempty"`
+	// The claim merklized root position: index, value or none
+	MerklizedRootPosition *string `json:"merklized_root_position,omitempty"`
+	// Whether the expired claims of the schema are revoked by the expiration sweeper
+	RevokeOnExpiration *bool `json:"revoke_on_expiration,omitempty"`
+	// The JSON schema URL, relative URLs are resolved against the schemas base URL
+	SchemaUrl string `json:"schema_url"`
+}
@@ -0,0 +1,31 @@
+/*
+ * GENERATED. Do not modify. Your changes might be overwritten!
+ */
+
+package resources
+
+type ClaimType struct {
+	Key
+	Attributes ClaimTypeAttributes `json:"attributes"`
+}
+type ClaimTypeResponse struct {
+	Data     ClaimType `json:"data"`
+	Included Included  `json:"included"`
+}
+
+type ClaimTypeListResponse struct {
+	Data     []ClaimType `json:"data"`
+	Included Included    `json:"included"`
+	Links    *Links      `json:"links"`
+}
+
+// MustClaimType - returns ClaimType from include collection.
+// if entry with specified key does not exist - returns nil
+// if entry with specified key exists but type or ID mismatches - panics
+func (c *Included) MustClaimType(key Key) *ClaimType {
+	var claimType ClaimType
+	if c.tryFindEntry(key, &claimType) {
+		return &claimType
+	}
+	return nil
+}
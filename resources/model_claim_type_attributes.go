@@ -0,0 +1,24 @@
+/*
+ * GENERATED. Do not modify. Your changes might be overwritten!
+ */
+
+package resources
+
+import "encoding/json"
+
+type ClaimTypeAttributes struct {
+	// The human readable claim type name that is shown in the claim offer
+	DisplayName string `json:"display_name"`
+	// The example of the issuance request body
+	ExampleRequest json.RawMessage `json:"example_request"`
+	// Whether the new claims of the type can be issued
+	IsDeprecated bool `json:"is_deprecated"`
+	// The JSON-LD context URL
+	JsonLdContext string `json:"json_ld_context"`
+	// The credential subject fields the issuance request expects
+	RequestFields []ClaimTypeField `json:"request_fields"`
+	// The cached raw JSON schema
+	Schema json.RawMessage `json:"schema"`
+	// The JSON schema URL
+	SchemaUrl string `json:"schema_url"`
+}
@@ -0,0 +1,22 @@
+/*
+ * GENERATED. Do not modify. Your changes might be overwritten!
+ */
+
+package resources
+
+type ClaimTypeField struct {
+	// The field default value that is used if the field is absent
+	Default interface{} `json:"default,omitempty"`
+	// The allowed field values
+	Enum []interface{} `json:"enum,omitempty"`
+	// The field format, e.g. date or date-time
+	Format *string `json:"format,omitempty"`
+	// The credential subject field name in the snake case
+	Name string `json:"name"`
+	// Whether the field can be null
+	Nullable bool `json:"nullable"`
+	// Whether the field is required
+	Required bool `json:"required"`
+	// The field JSON schema type
+	Type string `json:"type"`
+}
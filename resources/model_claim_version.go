@@ -0,0 +1,31 @@
+/*
+ * GENERATED. Do not modify. Your changes might be overwritten!
+ */
+
+package resources
+
+type ClaimVersion struct {
+	Key
+	Attributes ClaimVersionAttributes `json:"attributes"`
+}
+type ClaimVersionResponse struct {
+	Data     ClaimVersion `json:"data"`
+	Included Included     `json:"included"`
+}
+
+type ClaimVersionListResponse struct {
+	Data     []ClaimVersion `json:"data"`
+	Included Included       `json:"included"`
+	Links    *Links         `json:"links"`
+}
+
+// MustClaimVersion - returns ClaimVersion from include collection.
+// if entry with specified key does not exist - returns nil
+// if entry with specified key exists but type or ID mismatches - panics
+func (c *Included) MustClaimVersion(key Key) *ClaimVersion {
+	var claimVersion ClaimVersion
+	if c.tryFindEntry(key, &claimVersion) {
+		return &claimVersion
+	}
+	return nil
+}
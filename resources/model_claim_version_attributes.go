@@ -0,0 +1,19 @@
+/*
+ * GENERATED. Do not modify. Your changes might be overwritten!
+ */
+
+package resources
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type ClaimVersionAttributes struct {
+	// The identifier of the claim the version belongs to
+	ClaimId string `json:"claim_id"`
+	// The W3C credential of the version without proofs
+	Credential json.RawMessage `json:"credential"`
+	// The time the version was replaced by the next one, it is omitted for the current version
+	ReplacedAt *time.Time `json:"replaced_at,omitempty"`
+}
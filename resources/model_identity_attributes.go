@@ -0,0 +1,16 @@
+/*
+ * GENERATED. Do not modify. Your changes might be overwritten!
+ */
+
+package resources
+
+import "time"
+
+type IdentityAttributes struct {
+	// The identity creation time
+	CreatedAt *time.Time `json:"created_at,omitempty"`
+	// The issuer DID of the identity, its URLs are scoped by it
+	Did *string `json:"did,omitempty"`
+	// The unique name of the identity
+	Name string `json:"name"`
+}
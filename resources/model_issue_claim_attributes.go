@@ -10,4 +10,6 @@ type IssueClaimAttributes struct {
 	CredentialSubject json.RawMessage `json:"credential_subject"`
 	// The claim expiration date in RFC3339 format
 	Expiration string `json:"expiration"`
+	// Whether the claim can be updated to the new version later
+	Updatable *bool `json:"updatable,omitempty"`
 }
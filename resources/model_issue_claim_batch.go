@@ -0,0 +1,31 @@
+/*
+ * GENERATED. Do not modify. Your changes might be overwritten!
+ */
+
+package resources
+
+type IssueClaimBatch struct {
+	Key
+	Attributes IssueClaimBatchAttributes `json:"attributes"`
+}
+type IssueClaimBatchRequest struct {
+	Data     IssueClaimBatch `json:"data"`
+	Included Included        `json:"included"`
+}
+
+type IssueClaimBatchListRequest struct {
+	Data     []IssueClaimBatch `json:"data"`
+	Included Included          `json:"included"`
+	Links    *Links            `json:"links"`
+}
+
+// MustIssueClaimBatch - returns IssueClaimBatch from include collection.
+// if entry with specified key does not exist - returns nil
+// if entry with specified key exists but type or ID mismatches - panics
+func (c *Included) MustIssueClaimBatch(key Key) *IssueClaimBatch {
+	var issueClaimBatch IssueClaimBatch
+	if c.tryFindEntry(key, &issueClaimBatch) {
+		return &issueClaimBatch
+	}
+	return nil
+}
@@ -0,0 +1,12 @@
+/*
+ * GENERATED. Do not modify. Your changes might be overwritten!
+ */
+
+package resources
+
+type IssueClaimBatchAttributes struct {
+	// Whether no claims are issued if any of the items fails
+	Atomic *bool `json:"atomic,omitempty"`
+	// The claims to issue
+	Items []IssueClaimBatchItem `json:"items"`
+}
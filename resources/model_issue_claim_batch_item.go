@@ -0,0 +1,19 @@
+/*
+ * GENERATED. Do not modify. Your changes might be overwritten!
+ */
+
+package resources
+
+import "encoding/json"
+
+type IssueClaimBatchItem struct {
+	// The claim type
+	ClaimType         string          `json:"claim_type"`
+	CredentialSubject json.RawMessage `json:"credential_subject"`
+	// The claim expiration date in RFC3339 format
+	Expiration string `json:"expiration"`
+	// Whether the claim can be updated to the new version later
+	Updatable *bool `json:"updatable,omitempty"`
+	// The user identifier
+	UserId string `json:"user_id"`
+}
@@ -0,0 +1,31 @@
+/*
+ * GENERATED. Do not modify. Your changes might be overwritten!
+ */
+
+package resources
+
+type IssueClaimBatchResult struct {
+	Key
+	Attributes IssueClaimBatchResultAttributes `json:"attributes"`
+}
+type IssueClaimBatchResultResponse struct {
+	Data     IssueClaimBatchResult `json:"data"`
+	Included Included              `json:"included"`
+}
+
+type IssueClaimBatchResultListResponse struct {
+	Data     []IssueClaimBatchResult `json:"data"`
+	Included Included                `json:"included"`
+	Links    *Links                  `json:"links"`
+}
+
+// MustIssueClaimBatchResult - returns IssueClaimBatchResult from include collection.
+// if entry with specified key does not exist - returns nil
+// if entry with specified key exists but type or ID mismatches - panics
+func (c *Included) MustIssueClaimBatchResult(key Key) *IssueClaimBatchResult {
+	var issueClaimBatchResult IssueClaimBatchResult
+	if c.tryFindEntry(key, &issueClaimBatchResult) {
+		return &issueClaimBatchResult
+	}
+	return nil
+}
@@ -0,0 +1,14 @@
+/*
+ * GENERATED. Do not modify. Your changes might be overwritten!
+ */
+
+package resources
+
+type IssueClaimBatchResultAttributes struct {
+	// The reason of the item failure
+	Error *string `json:"error,omitempty"`
+	// The item index in the request
+	Index int `json:"index"`
+	// The item issuance status: issued, failed or skipped
+	Status string `json:"status"`
+}
@@ -0,0 +1,31 @@
+/*
+ * GENERATED. Do not modify. Your changes might be overwritten!
+ */
+
+package resources
+
+type ReissueClaim struct {
+	Key
+	Attributes ReissueClaimAttributes `json:"attributes"`
+}
+type ReissueClaimRequest struct {
+	Data     ReissueClaim `json:"data"`
+	Included Included     `json:"included"`
+}
+
+type ReissueClaimListRequest struct {
+	Data     []ReissueClaim `json:"data"`
+	Included Included       `json:"included"`
+	Links    *Links         `json:"links"`
+}
+
+// MustReissueClaim - returns ReissueClaim from include collection.
+// if entry with specified key does not exist - returns nil
+// if entry with specified key exists but type or ID mismatches - panics
+func (c *Included) MustReissueClaim(key Key) *ReissueClaim {
+	var reissueClaim ReissueClaim
+	if c.tryFindEntry(key, &reissueClaim) {
+		return &reissueClaim
+	}
+	return nil
+}
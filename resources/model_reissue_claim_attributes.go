@@ -0,0 +1,18 @@
+/*
+ * GENERATED. Do not modify. Your changes might be overwritten!
+ */
+
+package resources
+
+import "encoding/json"
+
+type ReissueClaimAttributes struct {
+	// The credential subject of the replacement claim
+	CredentialSubject json.RawMessage `json:"credential_subject"`
+	// The replacement claim expiration date in RFC3339 format
+	Expiration string `json:"expiration"`
+	// The revocation reason code
+	Reason *string `json:"reason,omitempty"`
+	// Whether the replacement claim can be updated to the new version later
+	Updatable *bool `json:"updatable,omitempty"`
+}
@@ -8,8 +8,22 @@ type ResourceType string
 
 // List of ResourceType
 const (
-	CLAIM_OFFER ResourceType = "claim_offer"
-	IDENTIFIER  ResourceType = "identifier"
-	CLAIM_ISSUE ResourceType = "claim_issue"
-	CLAIM_ID    ResourceType = "claim_id"
+	CLAIM_OFFER          ResourceType = "claim_offer"
+	IDENTIFIER           ResourceType = "identifier"
+	CLAIM_ISSUE          ResourceType = "claim_issue"
+	CLAIM_ID             ResourceType = "claim_id"
+	CLAIM_SCHEMA         ResourceType = "claim_schema"
+	CLAIM_TYPE           ResourceType = "claim_type"
+	CLAIM_ISSUE_BATCH    ResourceType = "claim_issue_batch"
+	CLAIM_ISSUE_RESULT   ResourceType = "claim_issue_result"
+	CLAIM                ResourceType = "claim"
+	CLAIM_REVOKE         ResourceType = "claim_revoke"
+	CLAIM_REVOKE_BULK    ResourceType = "claim_revoke_bulk"
+	CLAIM_REVOCATION     ResourceType = "claim_revocation"
+	CLAIM_REVOKE_RESULT  ResourceType = "claim_revoke_result"
+	CLAIM_REISSUE        ResourceType = "claim_reissue"
+	CLAIM_REISSUE_RESULT ResourceType = "claim_reissue_result"
+	AUDIT_EVENT          ResourceType = "audit_event"
+	CLAIM_VERSION        ResourceType = "claim_version"
+	IDENTITY             ResourceType = "identity"
 )
@@ -0,0 +1,31 @@
+/*
+ * GENERATED. Do not modify. Your changes might be overwritten!
+ */
+
+package resources
+
+type RevokeClaim struct {
+	Key
+	Attributes RevokeClaimAttributes `json:"attributes"`
+}
+type RevokeClaimRequest struct {
+	Data     RevokeClaim `json:"data"`
+	Included Included    `json:"included"`
+}
+
+type RevokeClaimListRequest struct {
+	Data     []RevokeClaim `json:"data"`
+	Included Included      `json:"included"`
+	Links    *Links        `json:"links"`
+}
+
+// MustRevokeClaim - returns RevokeClaim from include collection.
+// if entry with specified key does not exist - returns nil
+// if entry with specified key exists but type or ID mismatches - panics
+func (c *Included) MustRevokeClaim(key Key) *RevokeClaim {
+	var revokeClaim RevokeClaim
+	if c.tryFindEntry(key, &revokeClaim) {
+		return &revokeClaim
+	}
+	return nil
+}
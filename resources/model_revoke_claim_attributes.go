@@ -0,0 +1,10 @@
+/*
+ * GENERATED. Do not modify. Your changes might be overwritten!
+ */
+
+package resources
+
+type RevokeClaimAttributes struct {
+	// The revocation reason code
+	Reason *string `json:"reason,omitempty"`
+}
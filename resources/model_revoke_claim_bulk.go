@@ -0,0 +1,31 @@
+/*
+ * GENERATED. Do not modify. Your changes might be overwritten!
+ */
+
+package resources
+
+type RevokeClaimBulk struct {
+	Key
+	Attributes RevokeClaimBulkAttributes `json:"attributes"`
+}
+type RevokeClaimBulkRequest struct {
+	Data     RevokeClaimBulk `json:"data"`
+	Included Included        `json:"included"`
+}
+
+type RevokeClaimBulkListRequest struct {
+	Data     []RevokeClaimBulk `json:"data"`
+	Included Included          `json:"included"`
+	Links    *Links            `json:"links"`
+}
+
+// MustRevokeClaimBulk - returns RevokeClaimBulk from include collection.
+// if entry with specified key does not exist - returns nil
+// if entry with specified key exists but type or ID mismatches - panics
+func (c *Included) MustRevokeClaimBulk(key Key) *RevokeClaimBulk {
+	var revokeClaimBulk RevokeClaimBulk
+	if c.tryFindEntry(key, &revokeClaimBulk) {
+		return &revokeClaimBulk
+	}
+	return nil
+}
@@ -0,0 +1,12 @@
+/*
+ * GENERATED. Do not modify. Your changes might be overwritten!
+ */
+
+package resources
+
+type RevokeClaimBulkAttributes struct {
+	// The claims to revoke
+	Items []RevokeClaimBulkItem `json:"items"`
+	// The revocation reason code
+	Reason *string `json:"reason,omitempty"`
+}
@@ -0,0 +1,12 @@
+/*
+ * GENERATED. Do not modify. Your changes might be overwritten!
+ */
+
+package resources
+
+type RevokeClaimBulkItem struct {
+	// The claim identifier
+	ClaimId *string `json:"claim_id,omitempty"`
+	// The revocation nonce of the claim without the record
+	RevNonce *string `json:"rev_nonce,omitempty"`
+}
@@ -0,0 +1,31 @@
+/*
+ * GENERATED. Do not modify. Your changes might be overwritten!
+ */
+
+package resources
+
+type RevokeClaimBulkResult struct {
+	Key
+	Attributes RevokeClaimBulkResultAttributes `json:"attributes"`
+}
+type RevokeClaimBulkResultResponse struct {
+	Data     RevokeClaimBulkResult `json:"data"`
+	Included Included              `json:"included"`
+}
+
+type RevokeClaimBulkResultListResponse struct {
+	Data     []RevokeClaimBulkResult `json:"data"`
+	Included Included                `json:"included"`
+	Links    *Links                  `json:"links"`
+}
+
+// MustRevokeClaimBulkResult - returns RevokeClaimBulkResult from include collection.
+// if entry with specified key does not exist - returns nil
+// if entry with specified key exists but type or ID mismatches - panics
+func (c *Included) MustRevokeClaimBulkResult(key Key) *RevokeClaimBulkResult {
+	var revokeClaimBulkResult RevokeClaimBulkResult
+	if c.tryFindEntry(key, &revokeClaimBulkResult) {
+		return &revokeClaimBulkResult
+	}
+	return nil
+}
@@ -0,0 +1,21 @@
+/*
+ * GENERATED. Do not modify. Your changes might be overwritten!
+ */
+
+package resources
+
+import "time"
+
+type RevokeClaimBulkResultAttributes struct {
+	// The claim identifier
+	ClaimId *string `json:"claim_id,omitempty"`
+	// The item index in the request
+	Index int `json:"index"`
+	// The revocation nonce
+	RevNonce *string `json:"rev_nonce,omitempty"`
+	// The revocation time
+	RevokedAt *time.Time            `json:"revoked_at,omitempty"`
+	State     *ClaimRevocationState `json:"state,omitempty"`
+	// The item revocation status: revoked, already_revoked or not_found
+	Status string `json:"status"`
+}